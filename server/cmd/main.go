@@ -8,17 +8,30 @@ import (
 	"os/signal"
 	"skyvault/internal/api"
 	"skyvault/internal/bootstrap"
+	"skyvault/internal/domain/auth"
+	"skyvault/internal/domain/event"
+	"skyvault/internal/domain/media"
+	"skyvault/internal/domain/outbox"
+	"skyvault/internal/domain/webhook"
 	"skyvault/internal/infrastructure"
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/applog"
+	"skyvault/pkg/common"
 	"syscall"
 	"time"
 )
 
 // Flags
 var (
-	isDev       bool
-	envFilePath string
+	isDev            bool
+	envFilePath      string
+	backfillPreviews bool
+	backfillMetadata bool
+	backfillScans    bool
+	backfillSearch   bool
+	compactChanges   bool
+	quotaProfileID   string
+	quotaMB          int64
 )
 
 var app *appconfig.App
@@ -26,6 +39,13 @@ var app *appconfig.App
 func main() {
 	flag.BoolVar(&isDev, "dev", false, "Run in development mode")
 	flag.StringVar(&envFilePath, "env", ".env", "Environment file name")
+	flag.BoolVar(&backfillPreviews, "backfill-previews", false, "Generate the missing file previews and exit")
+	flag.BoolVar(&backfillMetadata, "backfill-metadata", false, "Extract the missing image and audio metadata and exit")
+	flag.BoolVar(&backfillScans, "backfill-scans", false, "Scan the files not scanned yet or whose scan failed and exit")
+	flag.BoolVar(&backfillSearch, "backfill-search", false, "Index the content of the text files not indexed yet and exit")
+	flag.BoolVar(&compactChanges, "compact-changes", false, "Purge the deletes older than the change retention from the change feed and exit")
+	flag.StringVar(&quotaProfileID, "set-quota", "", "Set the storage quota of the profile ID to -quota-mb and exit")
+	flag.Int64Var(&quotaMB, "quota-mb", -1, "Storage quota in MB for -set-quota, 0 is unlimited and a negative value resets to the default quota")
 	flag.Parse()
 
 	// Context with cancellation
@@ -34,6 +54,36 @@ func main() {
 
 	app = initApp(ctx)
 
+	if backfillPreviews {
+		runBackfillPreviews(ctx)
+		return
+	}
+
+	if backfillMetadata {
+		runBackfillMetadata(ctx)
+		return
+	}
+
+	if backfillScans {
+		runBackfillScans(ctx)
+		return
+	}
+
+	if backfillSearch {
+		runBackfillSearch(ctx)
+		return
+	}
+
+	if compactChanges {
+		runCompactChanges(ctx)
+		return
+	}
+
+	if quotaProfileID != "" {
+		runSetQuota(ctx)
+		return
+	}
+
 	apiServer := initDependencies(ctx)
 
 	startServer(ctx, apiServer)
@@ -63,6 +113,24 @@ func initDependencies(ctx context.Context) *api.API {
 	// Add health check endpoint
 	go monitorInfraHealth(ctx, infra)
 
+	// Purge the events the clients can't resume from anymore
+	go purgeEvents(ctx, infra)
+	// Purge the deletes the sync clients can't resume from anymore
+	go compactChangeLog(ctx, infra)
+	// Purge the files trashed for longer than the trash retention, they still count in the quota
+	go purgeTrash(ctx, infra)
+	go deliverWebhooks(ctx, infra)
+
+	// Publish the domain events of the outbox to the subscribers
+	bootstrap.InitSubscribers(app, infra)
+	go dispatchOutbox(ctx, infra)
+
+	// Purge the sessions that can't be refreshed anymore
+	go purgeSessions(ctx, infra)
+
+	// Pick up the archive extractions interrupted by the last shutdown
+	go resumeArchiveExtractions(ctx, infra)
+
 	// Register cleanup on shutdown
 	app.RegisterCleanup(infra.Cleanup)
 
@@ -77,6 +145,131 @@ func initDependencies(ctx context.Context) *api.API {
 	return apiServer
 }
 
+func runBackfillPreviews(ctx context.Context) {
+	infra := bootstrap.InitInfrastructure(app)
+	defer func() {
+		if err := infra.Cleanup(ctx); err != nil {
+			app.Logger.Error().Err(err).Msg("failed to cleanup")
+		}
+	}()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	count, err := mediaCmd.BackfillPreviews(ctx, &media.BackfillPreviewsCommand{BatchSize: 100})
+	if err != nil {
+		app.Logger.Error().Err(err).Int("count", count).Msg("failed to backfill previews")
+		return
+	}
+
+	app.Logger.Info().Int("count", count).Msg("previews backfilled")
+}
+
+func runBackfillMetadata(ctx context.Context) {
+	infra := bootstrap.InitInfrastructure(app)
+	defer func() {
+		if err := infra.Cleanup(ctx); err != nil {
+			app.Logger.Error().Err(err).Msg("failed to cleanup")
+		}
+	}()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	count, err := mediaCmd.BackfillMetadata(ctx, &media.BackfillMetadataCommand{BatchSize: 100})
+	if err != nil {
+		app.Logger.Error().Err(err).Int("count", count).Msg("failed to backfill metadata")
+		return
+	}
+
+	app.Logger.Info().Int("count", count).Msg("metadata backfilled")
+}
+
+func runBackfillScans(ctx context.Context) {
+	infra := bootstrap.InitInfrastructure(app)
+	defer func() {
+		if err := infra.Cleanup(ctx); err != nil {
+			app.Logger.Error().Err(err).Msg("failed to cleanup")
+		}
+	}()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	count, err := mediaCmd.BackfillScans(ctx, &media.BackfillScansCommand{BatchSize: 100})
+	if err != nil {
+		app.Logger.Error().Err(err).Int("count", count).Msg("failed to backfill scans")
+		return
+	}
+
+	app.Logger.Info().Int("count", count).Msg("scans backfilled")
+}
+
+func runBackfillSearch(ctx context.Context) {
+	infra := bootstrap.InitInfrastructure(app)
+	defer func() {
+		if err := infra.Cleanup(ctx); err != nil {
+			app.Logger.Error().Err(err).Msg("failed to cleanup")
+		}
+	}()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	count, err := mediaCmd.BackfillFileTexts(ctx, &media.BackfillFileTextsCommand{BatchSize: 100})
+	if err != nil {
+		app.Logger.Error().Err(err).Int("count", count).Msg("failed to backfill file texts")
+		return
+	}
+
+	app.Logger.Info().Int("count", count).Msg("file texts backfilled")
+}
+
+func runCompactChanges(ctx context.Context) {
+	infra := bootstrap.InitInfrastructure(app)
+	defer func() {
+		if err := infra.Cleanup(ctx); err != nil {
+			app.Logger.Error().Err(err).Msg("failed to cleanup")
+		}
+	}()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	count, err := mediaCmd.CompactChangeLog(ctx, &media.CompactChangeLogCommand{})
+	if err != nil {
+		app.Logger.Error().Err(err).Msg("failed to compact changes")
+		return
+	}
+
+	app.Logger.Info().Int("count", count).Msg("changes compacted")
+}
+
+func runSetQuota(ctx context.Context) {
+	infra := bootstrap.InitInfrastructure(app)
+	defer func() {
+		if err := infra.Cleanup(ctx); err != nil {
+			app.Logger.Error().Err(err).Msg("failed to cleanup")
+		}
+	}()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	cmd := &media.SetStorageQuotaCommand{OwnerID: quotaProfileID}
+	if quotaMB >= 0 {
+		cmd.QuotaMB = &quotaMB
+	}
+
+	err := mediaCmd.SetStorageQuota(ctx, cmd)
+	if err != nil {
+		app.Logger.Error().Err(err).Str("profile_id", quotaProfileID).Msg("failed to set storage quota")
+		return
+	}
+
+	app.Logger.Info().Str("profile_id", quotaProfileID).Int64("quota_mb", quotaMB).Msg("storage quota set")
+}
+
 func monitorInfraHealth(ctx context.Context, infra *infrastructure.Infrastructure) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
@@ -93,6 +286,178 @@ func monitorInfraHealth(ctx context.Context, infra *infrastructure.Infrastructur
 	}
 }
 
+func purgeEvents(ctx context.Context, infra *infrastructure.Infrastructure) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	eventCmd := bootstrap.InitEventCommands(infra)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := eventCmd.PurgeEvents(ctx, &event.PurgeEventsCommand{})
+			if err != nil {
+				app.Logger.Error().Err(err).Msg("failed to purge events")
+				continue
+			}
+			app.Logger.Debug().Int("count", count).Msg("events purged")
+		}
+	}
+}
+
+func compactChangeLog(ctx context.Context, infra *infrastructure.Infrastructure) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := mediaCmd.CompactChangeLog(ctx, &media.CompactChangeLogCommand{})
+			if err != nil {
+				app.Logger.Error().Err(err).Msg("failed to compact changes")
+				continue
+			}
+			app.Logger.Debug().Int("count", count).Msg("changes compacted")
+		}
+	}
+}
+
+func purgeTrash(ctx context.Context, infra *infrastructure.Infrastructure) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := mediaCmd.PurgeTrash(ctx, &media.PurgeTrashCommand{BatchSize: 100})
+			if err != nil {
+				app.Logger.Error().Err(err).Msg("failed to purge trash")
+				continue
+			}
+			app.Logger.Debug().Int("count", count).Msg("trash purged")
+		}
+	}
+}
+
+func deliverWebhooks(ctx context.Context, infra *infrastructure.Infrastructure) {
+	deliverTicker := time.NewTicker(5 * time.Second)
+	defer deliverTicker.Stop()
+	purgeTicker := time.NewTicker(time.Hour)
+	defer purgeTicker.Stop()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	webhookCmd := bootstrap.InitWebhookCommands(infra)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deliverTicker.C:
+			count, err := webhookCmd.DeliverWebhooks(ctx, &webhook.DeliverWebhooksCommand{})
+			if err != nil {
+				app.Logger.Error().Err(err).Msg("failed to deliver webhooks")
+				continue
+			}
+			if count > 0 {
+				app.Logger.Debug().Int("count", count).Msg("webhooks delivered")
+			}
+		case <-purgeTicker.C:
+			count, err := webhookCmd.PurgeDeliveries(ctx, &webhook.PurgeDeliveriesCommand{})
+			if err != nil {
+				app.Logger.Error().Err(err).Msg("failed to purge webhook deliveries")
+				continue
+			}
+			app.Logger.Debug().Int("count", count).Msg("webhook deliveries purged")
+		}
+	}
+}
+
+func dispatchOutbox(ctx context.Context, infra *infrastructure.Infrastructure) {
+	dispatchTicker := time.NewTicker(time.Second)
+	defer dispatchTicker.Stop()
+	purgeTicker := time.NewTicker(time.Hour)
+	defer purgeTicker.Stop()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	outboxCmd := bootstrap.InitOutboxCommands(infra)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-dispatchTicker.C:
+			// Until the outbox is drained, a batch at a time
+			for {
+				count, err := outboxCmd.DispatchMessages(ctx, &outbox.DispatchMessagesCommand{})
+				if err != nil {
+					app.Logger.Error().Err(err).Msg("failed to dispatch the outbox")
+					break
+				}
+				if count == 0 || ctx.Err() != nil {
+					break
+				}
+				app.Logger.Debug().Int("count", count).Msg("domain events published")
+			}
+		case <-purgeTicker.C:
+			count, err := outboxCmd.PurgeMessages(ctx, &outbox.PurgeMessagesCommand{})
+			if err != nil {
+				app.Logger.Error().Err(err).Msg("failed to purge the outbox")
+				continue
+			}
+			app.Logger.Debug().Int("count", count).Msg("failed domain events purged")
+		}
+	}
+}
+
+func purgeSessions(ctx context.Context, infra *infrastructure.Infrastructure) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	authCmd := bootstrap.InitAuthCommands(app, infra)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := authCmd.PurgeSessions(ctx, &auth.PurgeSessionsCommand{})
+			if err != nil {
+				app.Logger.Error().Err(err).Msg("failed to purge sessions")
+				continue
+			}
+			app.Logger.Debug().Int("count", count).Msg("sessions purged")
+		}
+	}
+}
+
+func resumeArchiveExtractions(ctx context.Context, infra *infrastructure.Infrastructure) {
+	ctx = context.WithValue(ctx, common.CtxKeyLogger, app.Logger)
+	mediaCmd := bootstrap.InitMediaCommands(app, infra)
+
+	count, err := mediaCmd.ResumeArchiveExtractions(ctx, &media.ResumeArchiveExtractionsCommand{BatchSize: 100})
+	if err != nil {
+		app.Logger.Error().Err(err).Int("count", count).Msg("failed to resume archive extractions")
+		return
+	}
+	if count > 0 {
+		app.Logger.Info().Int("count", count).Msg("archive extractions resumed")
+	}
+}
+
 func startServer(_ context.Context, apiServer *api.API) {
 	app.Server = &http.Server{
 		Addr:    app.Config.Server.Addr,
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"skyvault/internal/api/helper"
 	"skyvault/internal/api/middlewares"
+	"skyvault/internal/domain/auth"
 	"skyvault/internal/infrastructure"
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
@@ -24,14 +25,18 @@ type API struct {
 	Auth    *AuthAPI
 	Profile *ProfileAPI
 	Media   *MediaAPI
+	Sharing *SharingAPI
 	System  *SystemAPI
+	WebDAV  *WebDAVAPI
+	Event   *EventAPI
+	Webhook *WebhookAPI
 }
 
 func NewAPI(app *appconfig.App) *API {
 	return &API{app: app}
 }
 
-func (a *API) InitRoutes(infra *infrastructure.Infrastructure) *API {
+func (a *API) InitRoutes(infra *infrastructure.Infrastructure, authQueries auth.Queries, authCommands auth.Commands) *API {
 	// Base router
 	router := chi.NewRouter()
 
@@ -55,7 +60,7 @@ func (a *API) InitRoutes(infra *infrastructure.Infrastructure) *API {
 	// API routers
 	v1Pub := chi.NewRouter()
 	v1Pvt := chi.NewRouter().With(
-		middlewares.JWT(infra.Auth.JWT),
+		middlewares.JWT(infra.Auth.JWT, authQueries, authCommands),
 		middlewares.RequestSizeLimit(a.app.Config),
 	)
 
@@ -9,22 +9,32 @@ import (
 	"skyvault/internal/domain/auth"
 	"skyvault/internal/workflows"
 	"skyvault/pkg/apperror"
+	"skyvault/pkg/common"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jinzhu/copier"
 )
 
+const (
+	urlParamAppPasswordID = "app-password-id"
+	urlParamSessionID     = "session-id"
+)
+
 type AuthAPI struct {
 	api        *API
 	signUpFlow *workflows.SignUpFlow
 	signInFlow *workflows.SignInFlow
+	commands   auth.Commands
+	queries    auth.Queries
 }
 
-func NewAuthAPI(a *API, signUpFlow *workflows.SignUpFlow, signInFlow *workflows.SignInFlow) *AuthAPI {
+func NewAuthAPI(a *API, signUpFlow *workflows.SignUpFlow, signInFlow *workflows.SignInFlow, commands auth.Commands, queries auth.Queries) *AuthAPI {
 	return &AuthAPI{
 		api:        a,
 		signUpFlow: signUpFlow,
 		signInFlow: signInFlow,
+		commands:   commands,
+		queries:    queries,
 	}
 }
 
@@ -33,6 +43,21 @@ func (a *AuthAPI) InitRoutes() *AuthAPI {
 	pubRouter.Route("/auth", func(r chi.Router) {
 		r.Post("/sign-up", a.SignUp)
 		r.Post("/sign-in", a.SignIn)
+		r.Post("/refresh", a.RefreshToken)
+	})
+
+	pvtRouter := a.api.v1Pvt
+	pvtRouter.Post("/auth/logout", a.Logout)
+	pvtRouter.Put("/auth/password", a.ChangePassword)
+	pvtRouter.Route("/auth/sessions", func(r chi.Router) {
+		r.Get("/", a.GetSessions)
+		r.Post("/revoke-others", a.RevokeOtherSessions)
+		r.Delete(fmt.Sprintf("/{%s}", urlParamSessionID), a.RevokeSession)
+	})
+	pvtRouter.Route("/auth/app-passwords", func(r chi.Router) {
+		r.Get("/", a.GetAppPasswords)
+		r.Post("/", a.CreateAppPassword)
+		r.Delete(fmt.Sprintf("/{%s}", urlParamAppPasswordID), a.DeleteAppPassword)
 	})
 
 	return a
@@ -57,6 +82,7 @@ func (a *AuthAPI) SignUp(w http.ResponseWriter, r *http.Request) {
 		Provider: auth.Provider(req.Provider),
 		//TODO: ProviderUserID should be based on the provider
 		ProviderUserID: req.Email,
+		Client:         sessionClient(r),
 	}
 
 	res, err := a.signUpFlow.Run(r.Context(), flowReq)
@@ -67,6 +93,7 @@ func (a *AuthAPI) SignUp(w http.ResponseWriter, r *http.Request) {
 
 	var dto dtos.SignUp
 	dto.Token = res.Token
+	dto.RefreshToken = res.RefreshToken
 	dto.Profile = &dtos.GetProfileRes{}
 
 	err = copier.Copy(&dto.Profile, res.Profile)
@@ -93,6 +120,7 @@ func (a *AuthAPI) SignIn(w http.ResponseWriter, r *http.Request) {
 		Provider:       auth.Provider(req.Provider),
 		ProviderUserID: req.ProviderUserID,
 		Password:       req.Password,
+		Client:         sessionClient(r),
 	}
 
 	res, err := a.signInFlow.Run(r.Context(), flowReq)
@@ -103,6 +131,7 @@ func (a *AuthAPI) SignIn(w http.ResponseWriter, r *http.Request) {
 
 	var dto dtos.SignUp
 	dto.Token = res.Token
+	dto.RefreshToken = res.RefreshToken
 	dto.Profile = &dtos.GetProfileRes{}
 
 	err = copier.Copy(&dto.Profile, res.Profile)
@@ -113,3 +142,196 @@ func (a *AuthAPI) SignIn(w http.ResponseWriter, r *http.Request) {
 
 	helper.RespondJSON(w, http.StatusOK, dto)
 }
+
+func (a *AuthAPI) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "authAPI.RefreshToken:DecodeBody"))
+		return
+	}
+
+	tokens, err := a.commands.RefreshToken(r.Context(), &auth.RefreshTokenCommand{RefreshToken: req.RefreshToken})
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.RefreshToken:RefreshToken"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dtos.RefreshToken{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+func (a *AuthAPI) Logout(w http.ResponseWriter, r *http.Request) {
+	cmd := &auth.LogoutCommand{
+		ProfileID: common.GetProfileIDFromContext(r.Context()),
+		SessionID: common.GetSessionIDFromContext(r.Context()),
+	}
+
+	err := a.commands.Logout(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.Logout:Logout"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *AuthAPI) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "authAPI.ChangePassword:DecodeBody"))
+		return
+	}
+
+	cmd := &auth.ChangePasswordCommand{
+		ProfileID:       common.GetProfileIDFromContext(r.Context()),
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	}
+
+	err := a.commands.ChangePassword(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.ChangePassword:ChangePassword"))
+		return
+	}
+
+	// All the sessions are revoked, this one included, the client must sign in again
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *AuthAPI) GetSessions(w http.ResponseWriter, r *http.Request) {
+	query := &auth.GetSessionsQuery{
+		ProfileID: common.GetProfileIDFromContext(r.Context()),
+	}
+
+	sessions, err := a.queries.GetSessions(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.GetSessions:GetSessions"))
+		return
+	}
+
+	dto := []*dtos.GetSession{}
+	err = copier.Copy(&dto, &sessions)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.GetSessions:Copy"))
+		return
+	}
+
+	currentSessionID := common.GetSessionIDFromContext(r.Context())
+	for _, session := range dto {
+		session.Current = session.ID == currentSessionID
+	}
+
+	helper.RespondJSON(w, http.StatusOK, dto)
+}
+
+func (a *AuthAPI) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	cmd := &auth.RevokeSessionCommand{
+		ProfileID: common.GetProfileIDFromContext(r.Context()),
+		SessionID: chi.URLParam(r, urlParamSessionID),
+	}
+
+	err := a.commands.RevokeSession(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.RevokeSession:RevokeSession"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *AuthAPI) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	cmd := &auth.RevokeOtherSessionsCommand{
+		ProfileID:        common.GetProfileIDFromContext(r.Context()),
+		CurrentSessionID: common.GetSessionIDFromContext(r.Context()),
+	}
+
+	count, err := a.commands.RevokeOtherSessions(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.RevokeOtherSessions:RevokeOtherSessions"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dtos.RevokeSessions{Count: count})
+}
+
+func (a *AuthAPI) GetAppPasswords(w http.ResponseWriter, r *http.Request) {
+	query := &auth.GetAppPasswordsQuery{
+		ProfileID: common.GetProfileIDFromContext(r.Context()),
+	}
+
+	appPasswords, err := a.queries.GetAppPasswords(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.GetAppPasswords:GetAppPasswords"))
+		return
+	}
+
+	dto := []*dtos.GetAppPassword{}
+	err = copier.Copy(&dto, &appPasswords)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.GetAppPasswords:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, dto)
+}
+
+func (a *AuthAPI) CreateAppPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "authAPI.CreateAppPassword:DecodeBody"))
+		return
+	}
+
+	cmd := &auth.CreateAppPasswordCommand{
+		ProfileID: common.GetProfileIDFromContext(r.Context()),
+		Name:      req.Name,
+	}
+
+	appPassword, secret, err := a.commands.CreateAppPassword(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.CreateAppPassword:CreateAppPassword"))
+		return
+	}
+
+	var dto dtos.CreateAppPassword
+	err = copier.Copy(&dto.GetAppPassword, appPassword)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.CreateAppPassword:Copy"))
+		return
+	}
+	dto.Secret = secret
+
+	helper.RespondJSON(w, http.StatusCreated, &dto)
+}
+
+func (a *AuthAPI) DeleteAppPassword(w http.ResponseWriter, r *http.Request) {
+	cmd := &auth.DeleteAppPasswordCommand{
+		ProfileID:     common.GetProfileIDFromContext(r.Context()),
+		AppPasswordID: chi.URLParam(r, urlParamAppPasswordID),
+	}
+
+	err := a.commands.DeleteAppPassword(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "authAPI.DeleteAppPassword:DeleteAppPassword"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+// sessionClient is the client signing in with the request, recorded with its session.
+func sessionClient(r *http.Request) auth.SessionClient {
+	return auth.SessionClient{
+		UserAgent: r.UserAgent(),
+		IPAddress: helper.ClientIP(r),
+	}
+}
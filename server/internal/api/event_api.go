@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"skyvault/internal/api/helper"
+	"skyvault/internal/api/helper/dtos"
+	"skyvault/internal/domain/auth"
+	"skyvault/internal/domain/event"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/applog"
+	"skyvault/pkg/common"
+	"strconv"
+	"time"
+)
+
+const (
+	// Comment sent when there are no events, so that the proxies keep the stream open
+	eventsHeartbeat = 25 * time.Second
+
+	// Delay before the browsers reconnect a dropped stream
+	eventsRetry = 3 * time.Second
+)
+
+type EventAPI struct {
+	api         *API
+	queries     event.Queries
+	notifier    event.Notifier
+	authQueries auth.Queries
+}
+
+func NewEventAPI(a *API, queries event.Queries, notifier event.Notifier, authQueries auth.Queries) *EventAPI {
+	return &EventAPI{
+		api:         a,
+		queries:     queries,
+		notifier:    notifier,
+		authQueries: authQueries,
+	}
+}
+
+func (a *EventAPI) InitRoutes() *EventAPI {
+	pvtRouter := a.api.v1Pvt
+	pvtRouter.Get("/events", a.StreamEvents)
+
+	return a
+}
+
+// StreamEvents streams the events of the profile with Server-Sent Events.
+// The id of an event is its sequence, the clients reconnect with the Last-Event-ID header, or the
+// last-event-id query param, to get the events they missed. A new stream starts with a "ready" event.
+// A "resync" event tells that the missed events are gone, the client must reload its data.
+// The stream is closed once its session is signed out or revoked, it is checked on each heartbeat.
+func (a *EventAPI) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ownerID := common.GetProfileIDFromContext(ctx)
+
+	query := &event.GetEventsQuery{OwnerID: ownerID}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last-event-id")
+	}
+	if lastEventID != "" {
+		seq, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "eventAPI.StreamEvents:ParseLastEventID").WithMetadata("last_event_id", lastEventID))
+			return
+		}
+		query.AfterSeq = &seq
+	}
+
+	// Subscribe before reading, to not miss the events committed in between
+	signal, unsubscribe := a.notifier.Subscribe(ownerID)
+	defer unsubscribe()
+
+	// The first read is before the response starts, so that its errors are returned as usual
+	res, err := a.getEvents(r, query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "eventAPI.StreamEvents:GetEvents"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Disables the buffering of nginx
+	w.WriteHeader(http.StatusOK)
+	stream := &eventStream{w: w, rc: http.NewResponseController(w)}
+	if err := stream.writeLine(fmt.Sprintf("retry: %d", eventsRetry.Milliseconds())); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		if err := stream.send(res); err != nil {
+			applog.GetLoggerFromContext(ctx).Debug().Err(err).Msg("event stream closed")
+			return
+		}
+		query.AfterSeq = &res.LastSeq
+
+		if !res.HasMore {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-signal:
+				if !ok {
+					return // The server shuts down, the client reconnects to another one
+				}
+			case <-heartbeat.C:
+				if err := a.validateSession(r); err != nil {
+					applog.GetLoggerFromContext(ctx).Debug().Err(err).Msg("event stream session ended")
+					return
+				}
+				if err := stream.writeLine(": ping"); err != nil {
+					return
+				}
+				continue
+			}
+		}
+
+		res, err = a.getEvents(r, query)
+		if err != nil {
+			applog.GetLoggerFromContext(ctx).Error().Err(apperror.NewAppError(err, "eventAPI.StreamEvents:GetEvents")).Msg("failed to get the events")
+			return
+		}
+	}
+}
+
+// validateSession checks that the session of the stream is still active, the stream outlives the
+// check of its access token. The app passwords have no session.
+func (a *EventAPI) validateSession(r *http.Request) error {
+	sessionID := common.GetSessionIDFromContext(r.Context())
+	if sessionID == "" {
+		return nil
+	}
+
+	_, err := a.authQueries.ValidateSession(r.Context(), &auth.ValidateSessionQuery{
+		ProfileID: common.GetProfileIDFromContext(r.Context()),
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return apperror.NewAppError(err, "eventAPI.validateSession:ValidateSession")
+	}
+	return nil
+}
+
+// getEvents starts a new stream, with a resync event, if the client can't resume from its sequence.
+func (a *EventAPI) getEvents(r *http.Request, query *event.GetEventsQuery) (*getEventsRes, error) {
+	resync := false
+	res, err := a.queries.GetEvents(r.Context(), query)
+	if errors.Is(err, apperror.ErrEventExpired) || (query.AfterSeq != nil && errors.Is(err, apperror.ErrCommonInvalidValue)) {
+		resync = true
+		res, err = a.queries.GetEvents(r.Context(), &event.GetEventsQuery{OwnerID: query.OwnerID})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &getEventsRes{GetEventsRes: res, start: query.AfterSeq == nil || resync, resync: resync}, nil
+}
+
+type getEventsRes struct {
+	*event.GetEventsRes
+	start  bool // A new stream, without events
+	resync bool // The missed events are gone
+}
+
+type eventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *eventStream) send(res *getEventsRes) error {
+	if res.start {
+		name := "ready"
+		if res.resync {
+			name = "resync"
+		}
+		_, err := fmt.Fprintf(s.w, "id: %d\nevent: %s\ndata: {}\n\n", res.LastSeq, name)
+		if err != nil {
+			return err
+		}
+		return s.rc.Flush()
+	}
+
+	for _, e := range res.Events {
+		data, err := json.Marshal(&dtos.GetEvent{
+			Type:      e.Type,
+			Data:      json.RawMessage(e.Payload),
+			CreatedAt: e.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(s.w, "id: %d\ndata: %s\n\n", e.Seq, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(res.Events) == 0 {
+		return nil
+	}
+	return s.rc.Flush()
+}
+
+// writeLine writes a line that is not an event, the retry delay or a heartbeat
+func (s *eventStream) writeLine(line string) error {
+	_, err := fmt.Fprintf(s.w, "%s\n\n", line)
+	if err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -1,6 +1,38 @@
 package dtos
 
+import "time"
+
 type SignUp struct {
-	Token   string         `json:"token" copier:"must,nopanic"`
-	Profile *GetProfileRes `json:"profile" copier:"must,nopanic"`
+	Token        string         `json:"token" copier:"must,nopanic"`
+	RefreshToken string         `json:"refreshToken" copier:"must,nopanic"`
+	Profile      *GetProfileRes `json:"profile" copier:"must,nopanic"`
+}
+
+type RefreshToken struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"` // Replaces the one sent, which can't be used again
+}
+
+type GetAppPassword struct {
+	ID        string    `json:"id" copier:"must,nopanic"`
+	Name      string    `json:"name" copier:"must,nopanic"`
+	CreatedAt time.Time `json:"createdAt" copier:"must,nopanic"`
+}
+
+type CreateAppPassword struct {
+	GetAppPassword
+	Secret string `json:"secret"` // Shown only once
+}
+
+type GetSession struct {
+	ID         string    `json:"id" copier:"must,nopanic"`
+	UserAgent  string    `json:"userAgent" copier:"must,nopanic"`
+	IPAddress  string    `json:"ipAddress" copier:"must,nopanic"`
+	CreatedAt  time.Time `json:"createdAt" copier:"must,nopanic"`
+	LastSeenAt time.Time `json:"lastSeenAt" copier:"must,nopanic"`
+	Current    bool      `json:"current"` // The session of the request
+}
+
+type RevokeSessions struct {
+	Count int `json:"count"`
 }
@@ -0,0 +1,13 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GetEvent is the data of an event of the live stream, the SSE id of the event is its sequence.
+type GetEvent struct {
+	Type      string          `json:"type"` // e.g. file.created, folder.moved or share.created
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
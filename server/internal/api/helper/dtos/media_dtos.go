@@ -1,7 +1,6 @@
 package dtos
 
 import (
-	"encoding/base64"
 	"skyvault/pkg/paging"
 	"time"
 )
@@ -15,19 +14,19 @@ type GetFileInfo struct {
 	Extension     *string   `json:"extension,omitempty"`
 	MimeType      string    `json:"mimeType" copier:"must,nopanic"`
 	Category      string    `json:"category" copier:"must,nopanic"`
-	PreviewBase64 *string   `json:"previewBase64"`
+	PreviewStatus string    `json:"previewStatus,omitempty"`
+	ScanStatus    string    `json:"scanStatus,omitempty"`
+	ScanSignature *string   `json:"scanSignature,omitempty"`
 	CreatedAt     time.Time `json:"createdAt" copier:"must,nopanic"`
 	UpdatedAt     time.Time `json:"updatedAt" copier:"must,nopanic"`
 }
 
-func (r *GetFileInfo) Preview(preview []byte) {
-	if len(preview) > 0 {
-		previewBase64 := base64.StdEncoding.EncodeToString(preview)
-		r.PreviewBase64 = &previewBase64
-	}
+type GetFolderContent struct {
+	FilePage   *paging.Page[*GetFileInfo]   `json:"filePage" copier:"must,nopanic"`
+	FolderPage *paging.Page[*GetFolderInfo] `json:"folderPage" copier:"must,nopanic"`
 }
 
-type GetFolderContent struct {
+type Search struct {
 	FilePage   *paging.Page[*GetFileInfo]   `json:"filePage" copier:"must,nopanic"`
 	FolderPage *paging.Page[*GetFolderInfo] `json:"folderPage" copier:"must,nopanic"`
 }
@@ -39,5 +38,161 @@ type GetFolderInfo struct {
 	ParentFolderID *string    `json:"parentFolderId,omitempty"`
 	CreatedAt      time.Time  `json:"createdAt" copier:"must,nopanic"`
 	UpdatedAt      time.Time  `json:"updatedAt" copier:"must,nopanic"`
+	Size           int64      `json:"size"`        // Recursive, trashed items excluded
+	FileCount      int64      `json:"fileCount"`   // Recursive, trashed items excluded
+	FolderCount    int64      `json:"folderCount"` // Recursive, trashed items excluded
 	Ancestors      []BaseInfo `json:"ancestors" copier:"nopanic"`
 }
+
+type GetTag struct {
+	ID        string    `json:"id" copier:"must,nopanic"`
+	Name      string    `json:"name" copier:"must,nopanic"`
+	Color     string    `json:"color" copier:"must,nopanic"`
+	CreatedAt time.Time `json:"createdAt" copier:"must,nopanic"`
+	UpdatedAt time.Time `json:"updatedAt" copier:"must,nopanic"`
+}
+
+type GetTaggedItems struct {
+	FilePage   *paging.Page[*GetFileInfo]   `json:"filePage" copier:"must,nopanic"`
+	FolderPage *paging.Page[*GetFolderInfo] `json:"folderPage" copier:"must,nopanic"`
+}
+
+type GetStarredItems struct {
+	FilePage   *paging.Page[*GetFileInfo]   `json:"filePage" copier:"must,nopanic"`
+	FolderPage *paging.Page[*GetFolderInfo] `json:"folderPage" copier:"must,nopanic"`
+}
+
+type RecentItem struct {
+	File       *GetFileInfo `json:"file" copier:"must,nopanic"`
+	RecentFile *RecentFile  `json:"recent" copier:"must,nopanic"`
+}
+
+type RecentFile struct {
+	Activity   string    `json:"activity"`
+	ActivityAt time.Time `json:"activityAt"`
+}
+
+type GetStorageUsage struct {
+	UsedBytes    int64            `json:"usedBytes"`
+	TrashedBytes int64            `json:"trashedBytes"`
+	QuotaBytes   int64            `json:"quotaBytes"` // 0 is unlimited
+	Categories   []*CategoryUsage `json:"categories" copier:"must,nopanic"`
+}
+
+type CategoryUsage struct {
+	Category string `json:"category"`
+	Bytes    int64  `json:"bytes"`
+	Files    int64  `json:"files"`
+}
+
+type GetChanges struct {
+	Changes []*GetChange `json:"changes" copier:"must,nopanic"`
+	Cursor  string       `json:"cursor"`
+	HasMore bool         `json:"hasMore"`
+}
+
+// GetChange has the state of the item after the change, apply all of them but the deletes as upserts.
+type GetChange struct {
+	ItemType       string    `json:"itemType"` // file or folder
+	ItemID         string    `json:"itemId"`
+	ChangeType     string    `json:"changeType"` // created, renamed, moved, updated, trashed, restored or deleted
+	Name           string    `json:"name"`
+	ParentFolderID *string   `json:"parentFolderId"` // null for the root folder
+	Size           int64     `json:"size"`
+	Trashed        bool      `json:"trashed"`
+	ChangedAt      time.Time `json:"changedAt"`
+}
+
+// Properties are the custom key/value metadata of a file or folder.
+type Properties struct {
+	Properties map[string]string `json:"properties"`
+}
+
+type GetFileMetadata struct {
+	FileID      string     `json:"fileId" copier:"must,nopanic"`
+	Width       *int       `json:"width,omitempty"`
+	Height      *int       `json:"height,omitempty"`
+	CapturedAt  *time.Time `json:"capturedAt,omitempty"`
+	CameraMake  *string    `json:"cameraMake,omitempty"`
+	CameraModel *string    `json:"cameraModel,omitempty"`
+	Latitude    *float64   `json:"latitude,omitempty"`
+	Longitude   *float64   `json:"longitude,omitempty"`
+	DurationMs  *int64     `json:"durationMs,omitempty"`
+	Title       *string    `json:"title,omitempty"`
+	Artist      *string    `json:"artist,omitempty"`
+	Album       *string    `json:"album,omitempty"`
+	Genre       *string    `json:"genre,omitempty"`
+	Year        *int       `json:"year,omitempty"`
+	Track       *int       `json:"track,omitempty"`
+	TimelineAt  time.Time  `json:"timelineAt" copier:"must,nopanic"`
+}
+
+type GetTimeline struct {
+	Groups     []*TimelineGroup `json:"groups" copier:"must,nopanic"`
+	PrevCursor string           `json:"prevCursor"`
+	NextCursor string           `json:"nextCursor"`
+	HasMore    bool             `json:"hasMore"`
+
+	Total          *int64 `json:"total,omitempty"`
+	TotalEstimated bool   `json:"totalEstimated,omitempty"`
+}
+
+type TimelineGroup struct {
+	Date  string          `json:"date" copier:"must,nopanic"`
+	Items []*TimelineItem `json:"items" copier:"must,nopanic"`
+}
+
+type TimelineItem struct {
+	File     *GetFileInfo     `json:"file" copier:"must,nopanic"`
+	Metadata *GetFileMetadata `json:"metadata" copier:"must,nopanic"`
+}
+
+type GetArchiveEntry struct {
+	Path     string    `json:"path" copier:"must,nopanic"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	IsDir    bool      `json:"isDir"`
+}
+
+type GetArchiveExtraction struct {
+	ID             string    `json:"id" copier:"must,nopanic"`
+	FileID         string    `json:"fileId" copier:"must,nopanic"`
+	FolderID       *string   `json:"folderId,omitempty"`
+	Conflict       string    `json:"conflict" copier:"must,nopanic"`
+	Status         string    `json:"status" copier:"must,nopanic"`
+	FileCount      int64     `json:"fileCount"`
+	FolderCount    int64     `json:"folderCount"`
+	SkippedCount   int64     `json:"skippedCount"`
+	ExtractedBytes int64     `json:"extractedBytes"`
+	ErrorCode      *string   `json:"errorCode,omitempty"`
+	CreatedAt      time.Time `json:"createdAt" copier:"must,nopanic"`
+	UpdatedAt      time.Time `json:"updatedAt" copier:"must,nopanic"`
+}
+
+// ResolvePath has either the folder or the file at the path, both are null for the root folder.
+type ResolvePath struct {
+	Folder    *GetFolderInfo `json:"folder"`
+	File      *GetFileInfo   `json:"file"`
+	Ancestors []BaseInfo     `json:"ancestors"` // The root folder's child first
+}
+
+type GetPath struct {
+	Path string `json:"path"`
+}
+
+type GetFolderTree struct {
+	Folder    *GetFolderInfo       `json:"folder"`    // null for the root folder
+	Ancestors []BaseInfo           `json:"ancestors"` // The parent folder first
+	Nodes     []*GetFolderTreeNode `json:"nodes"`
+	Truncated bool                 `json:"truncated"` // The deepest levels were left out, they are expanded lazily
+}
+
+type GetFolderTreeNode struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Size        int64                `json:"size"`
+	FileCount   int64                `json:"fileCount"`
+	FolderCount int64                `json:"folderCount"`
+	HasChildren bool                 `json:"hasChildren"`
+	Children    []*GetFolderTreeNode `json:"children"` // null if not loaded, expand with the tree of the folder
+}
@@ -0,0 +1,47 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GetWebhook struct {
+	ID         string    `json:"id" copier:"must,nopanic"`
+	URL        string    `json:"url" copier:"must,nopanic"`
+	EventTypes []string  `json:"eventTypes" copier:"must,nopanic"` // Empty for all the events
+	FolderID   *string   `json:"folderId"`                         // null for the events of all the folders
+	Enabled    bool      `json:"enabled" copier:"must,nopanic"`
+	CreatedAt  time.Time `json:"createdAt" copier:"must,nopanic"`
+	UpdatedAt  time.Time `json:"updatedAt" copier:"must,nopanic"`
+}
+
+// GetWebhookSecret is the webhook with its secret, returned when it is created or rotated only.
+type GetWebhookSecret struct {
+	GetWebhook
+	Secret string `json:"secret"`
+}
+
+type GetWebhookDelivery struct {
+	ID             string          `json:"id" copier:"must,nopanic"`
+	WebhookID      string          `json:"webhookId" copier:"must,nopanic"`
+	EventSeq       *int64          `json:"eventSeq"` // null for the pings
+	EventType      string          `json:"eventType" copier:"must,nopanic"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status" copier:"must,nopanic"` // pending, succeeded or failed
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt"`
+	ResponseStatus *int            `json:"responseStatus"`
+	LastError      *string         `json:"lastError"`
+	CreatedAt      time.Time       `json:"createdAt" copier:"must,nopanic"`
+}
+
+type GetWebhookDeliveries struct {
+	Items      []*GetWebhookDelivery `json:"items" copier:"must,nopanic"`
+	PrevCursor string                `json:"prevCursor"`
+	NextCursor string                `json:"nextCursor"`
+	HasMore    bool                  `json:"hasMore"`
+
+	Total          *int64 `json:"total,omitempty"`
+	TotalEstimated bool   `json:"totalEstimated,omitempty"`
+}
@@ -0,0 +1,16 @@
+package helper
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the IP address of the client, as seen by the server.
+// Behind a reverse proxy, it is the address of the proxy.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -3,7 +3,11 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"path"
 	"skyvault/internal/api/helper"
 	"skyvault/internal/api/helper/dtos"
 	"skyvault/internal/domain/media"
@@ -15,6 +19,7 @@ import (
 	"skyvault/pkg/validate"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jinzhu/copier"
@@ -23,6 +28,9 @@ import (
 const (
 	urlParamFileID   = "file-id"
 	urlParamFolderID = "folder-id"
+	urlParamTagID    = "tag-id"
+
+	urlParamExtractionID = "extraction-id"
 )
 
 type MediaAPI struct {
@@ -50,14 +58,60 @@ func (a *MediaAPI) InitRoutes() *MediaAPI {
 		r.Route("/files", func(r chi.Router) {
 			// Bulk operations
 			r.Get("/", a.GetFileInfosByCategory)
+			r.Get("/timeline", a.GetTimeline)
+			r.Get("/quarantine", a.GetQuarantinedFiles)
 			r.Delete("/", a.TrashFiles)
 
 			// Single file operations
 			r.Route(fmt.Sprintf("/{%s}", urlParamFileID), func(r chi.Router) {
 				r.Post("/download", a.DownloadFile)
+				r.Get("/preview", a.GetPreview)
+				r.Get("/metadata", a.GetFileMetadata)
+				r.Get("/tags", a.GetFileTags)
+				r.Get("/properties", a.GetFileProperties)
+				r.Put("/properties", a.SetFileProperties)
 				r.Patch("/rename", a.RenameFile)
 				r.Patch("/move", a.MoveFile)
 				r.Patch("/restore", a.RestoreFile)
+				r.Get("/archive", a.GetArchiveEntries)
+				r.Post("/archive/download", a.DownloadArchiveEntry)
+				r.Post("/extract", a.ExtractArchive)
+				r.Get("/path", a.GetFilePath)
+			})
+		})
+
+		r.Get(fmt.Sprintf("/extractions/{%s}", urlParamExtractionID), a.GetArchiveExtraction)
+
+		// Files and folders addressed by their path, e.g. "/Projects/2026/spec.pdf"
+		r.Route("/paths", func(r chi.Router) {
+			r.Get("/", a.ResolvePath)
+			r.Post("/download", a.DownloadFileByPath)
+			r.Post("/files", a.UploadFileByPath)
+			r.Post("/folders", a.CreateFolderPath)
+		})
+
+		r.Post("/zip", a.DownloadZip)
+		r.Get("/search", a.Search)
+		r.Get("/recent", a.GetRecentFiles)
+		r.Get("/usage", a.GetStorageUsage)
+		r.Get("/changes", a.GetChanges)
+
+		r.Route("/starred", func(r chi.Router) {
+			r.Get("/", a.GetStarredItems)
+			r.Post("/", a.StarItems)
+			r.Delete("/", a.UnstarItems)
+		})
+
+		r.Route("/tags", func(r chi.Router) {
+			r.Get("/", a.GetTags)
+			r.Post("/", a.CreateTag)
+
+			r.Route(fmt.Sprintf("/{%s}", urlParamTagID), func(r chi.Router) {
+				r.Patch("/", a.UpdateTag)
+				r.Delete("/", a.DeleteTag)
+				r.Get("/items", a.GetTaggedItems)
+				r.Post("/items", a.TagItems)
+				r.Delete("/items", a.UntagItems)
 			})
 		})
 
@@ -69,10 +123,16 @@ func (a *MediaAPI) InitRoutes() *MediaAPI {
 			r.Route(fmt.Sprintf("/{%s}", urlParamFolderID), func(r chi.Router) {
 				r.Get("/", a.GetFolderInfo)
 				r.Get("/content", a.GetFolderContent)
+				r.Post("/download", a.DownloadFolder)
+				r.Get("/tags", a.GetFolderTags)
+				r.Get("/properties", a.GetFolderProperties)
+				r.Put("/properties", a.SetFolderProperties)
 				r.Post("/", a.CreateFolder)
 				r.Patch("/rename", a.RenameFolder)
 				r.Patch("/move", a.MoveFolder)
 				r.Patch("/restore", a.RestoreFolder)
+				r.Get("/path", a.GetFolderPath)
+				r.Get("/tree", a.GetFolderTree)
 
 				// Files routes that need folderID
 				r.Route("/files", func(r chi.Router) {
@@ -92,18 +152,9 @@ func (a *MediaAPI) InitRoutes() *MediaAPI {
 //--------------------------------
 
 func (a *MediaAPI) UploadFile(w http.ResponseWriter, r *http.Request) {
-	profileID := common.GetProfileIDFromContext(r.Context())
-
-	// Allocate max. 15MB for in-memory parsing.
-	err := r.ParseMultipartForm(15 * common.BytesPerMB)
-	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.UploadFile:ParseMultipartForm"))
-		return
-	}
-
-	file, handler, err := r.FormFile("file")
+	file, handler, err := parseUploadForm(r)
 	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.UploadFile:FormFile"))
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UploadFile:parseUploadForm"))
 		return
 	}
 	defer file.Close()
@@ -113,25 +164,43 @@ func (a *MediaAPI) UploadFile(w http.ResponseWriter, r *http.Request) {
 		folderID = &id
 	}
 
+	a.uploadFile(w, r, folderID, file, handler)
+}
+
+// parseUploadForm returns the "file" of the multipart form of an upload.
+func parseUploadForm(r *http.Request) (multipart.File, *multipart.FileHeader, error) {
+	// Allocate max. 15MB for in-memory parsing.
+	err := r.ParseMultipartForm(15 * common.BytesPerMB)
+	if err != nil {
+		return nil, nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.parseUploadForm:ParseMultipartForm")
+	}
+
+	file, handler, err := r.FormFile("file")
+	if err != nil {
+		return nil, nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.parseUploadForm:FormFile")
+	}
+	return file, handler, nil
+}
+
+func (a *MediaAPI) uploadFile(w http.ResponseWriter, r *http.Request, folderID *string, file multipart.File, handler *multipart.FileHeader) {
 	cmd := &media.UploadFileCommand{
-		OwnerID:  profileID,
+		OwnerID:  common.GetProfileIDFromContext(r.Context()),
 		FolderID: folderID,
 		Name:     handler.Filename,
 		Size:     handler.Size,
-		MimeType: handler.Header.Get("Content-Type"),
 		File:     file,
 	}
 
 	fileInfo, err := a.commands.UploadFile(r.Context(), cmd)
 	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UploadFile:UploadFile").WithMetadata("file_name", handler.Filename).WithMetadata("folder_id", folderID))
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.uploadFile:UploadFile").WithMetadata("file_name", handler.Filename).WithMetadata("folder_id", folderID))
 		return
 	}
 
 	var dto dtos.GetFileInfo
 	err = copier.Copy(&dto, &fileInfo)
 	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UploadFile:Copy"))
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.uploadFile:Copy"))
 		return
 	}
 
@@ -208,7 +277,6 @@ func (a *MediaAPI) FinalizeChunkedUpload(w http.ResponseWriter, r *http.Request)
 	var req struct {
 		FileName    string `json:"fileName"`
 		FileSize    int64  `json:"fileSize"`
-		MimeType    string `json:"mimeType"`
 		TotalChunks int64  `json:"totalChunks"`
 	}
 
@@ -223,7 +291,6 @@ func (a *MediaAPI) FinalizeChunkedUpload(w http.ResponseWriter, r *http.Request)
 		UploadID:    uploadID,
 		FileName:    req.FileName,
 		FileSize:    req.FileSize,
-		MimeType:    req.MimeType,
 		TotalChunks: req.TotalChunks,
 	}
 
@@ -261,9 +328,63 @@ func pagingOptionsFromQuery(r *http.Request, prefix string) (*paging.Options, er
 		opt.Limit = limit
 	}
 
+	includeTotalStr := r.URL.Query().Get(prefix + "include-total")
+	if includeTotalStr != "" {
+		includeTotal, err := strconv.ParseBool(includeTotalStr)
+		if err != nil {
+			return nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "api.pagingOptionsFromQuery:ParseBool").WithMetadata("include_total_str", includeTotalStr)
+		}
+		opt.IncludeTotal = includeTotal
+	}
+
+	var err error
+	opt.Filter.Category = r.URL.Query().Get(prefix + "category")
+	opt.Filter.Extension = r.URL.Query().Get(prefix + "extension")
+	if opt.Filter.MinSize, err = int64FromQuery(r, prefix+"min-size"); err != nil {
+		return nil, apperror.NewAppError(err, "api.pagingOptionsFromQuery:MinSize")
+	}
+	if opt.Filter.MaxSize, err = int64FromQuery(r, prefix+"max-size"); err != nil {
+		return nil, apperror.NewAppError(err, "api.pagingOptionsFromQuery:MaxSize")
+	}
+	if opt.Filter.CreatedFrom, err = timeFromQuery(r, prefix+"created-from"); err != nil {
+		return nil, apperror.NewAppError(err, "api.pagingOptionsFromQuery:CreatedFrom")
+	}
+	if opt.Filter.CreatedTo, err = timeFromQuery(r, prefix+"created-to"); err != nil {
+		return nil, apperror.NewAppError(err, "api.pagingOptionsFromQuery:CreatedTo")
+	}
+
 	return opt, nil
 }
 
+// int64FromQuery returns nil if the query param is not set.
+func int64FromQuery(r *http.Request, key string) (*int64, error) {
+	str := r.URL.Query().Get(key)
+	if str == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "api.int64FromQuery:ParseInt").WithMetadata(key, str)
+	}
+	return &v, nil
+}
+
+// timeFromQuery parses a RFC3339 time, it returns nil if the query param is not set.
+func timeFromQuery(r *http.Request, key string) (*time.Time, error) {
+	str := r.URL.Query().Get(key)
+	if str == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "api.timeFromQuery:Parse").WithMetadata(key, str)
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
 func (a *MediaAPI) GetFileInfosByCategory(w http.ResponseWriter, r *http.Request) {
 	profileID := common.GetProfileIDFromContext(r.Context())
 	pagingOpt, err := pagingOptionsFromQuery(r, "")
@@ -294,6 +415,35 @@ func (a *MediaAPI) GetFileInfosByCategory(w http.ResponseWriter, r *http.Request
 	helper.RespondJSON(w, http.StatusOK, &dto)
 }
 
+func (a *MediaAPI) GetQuarantinedFiles(w http.ResponseWriter, r *http.Request) {
+	profileID := common.GetProfileIDFromContext(r.Context())
+	pagingOpt, err := pagingOptionsFromQuery(r, "")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetQuarantinedFiles:PagingOptionsFromQuery"))
+		return
+	}
+
+	query := &media.GetQuarantinedFilesQuery{
+		OwnerID:   profileID,
+		PagingOpt: pagingOpt,
+	}
+
+	page, err := a.queries.GetQuarantinedFiles(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetQuarantinedFiles:GetQuarantinedFiles"))
+		return
+	}
+
+	var dto paging.Page[*dtos.GetFileInfo]
+	err = copier.Copy(&dto, &page)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetQuarantinedFiles:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
 func (a *MediaAPI) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	fileID := chi.URLParam(r, urlParamFileID)
 	if !validate.UUID(fileID) {
@@ -301,9 +451,8 @@ func (a *MediaAPI) DownloadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	profileID := common.GetProfileIDFromContext(r.Context())
 	query := &media.GetFileQuery{
-		OwnerID: profileID,
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
 		FileID:  fileID,
 	}
 
@@ -314,9 +463,187 @@ func (a *MediaAPI) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	}
 	defer res.File.Close()
 
+	a.serveFile(w, r, res)
+}
+
+// serveFile sends the file as an attachment, with support of range requests.
+func (a *MediaAPI) serveFile(w http.ResponseWriter, r *http.Request, res *media.GetFileRes) {
+	info := res.Info
+	if isNewDownload(r) {
+		cmd := &media.RecordFileDownloadCommand{
+			OwnerID: common.GetProfileIDFromContext(r.Context()),
+			FileID:  info.ID,
+		}
+		if err := a.commands.RecordFileDownload(r.Context(), cmd); err != nil {
+			applog.GetLoggerFromContext(r.Context()).Warn().Err(err).Str("file_id", info.ID).Msg("failed to record file download")
+		}
+	}
+
+	// The type is detected on upload, browsers must not guess another one or render the file inline
+	w.Header().Set("Content-Type", info.MimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name, info.UpdatedAt, res.File)
+}
+
+// isNewDownload reports whether the request starts a download, the following range requests of
+// a resumed download must not be recorded again.
+func isNewDownload(r *http.Request) bool {
+	rng := r.Header.Get("Range")
+	return rng == "" || strings.HasPrefix(rng, "bytes=0-")
+}
+
+// DownloadZip downloads a mix of files and folders as one zip archive.
+func (a *MediaAPI) DownloadZip(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FileIDs   []string `json:"fileIds"`
+		FolderIDs []string `json:"folderIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.DownloadZip:DecodeJSON"))
+		return
+	}
+
+	query := &media.GetZipArchiveQuery{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		FileIDs:   req.FileIDs,
+		FolderIDs: req.FolderIDs,
+	}
+
+	archive, err := a.queries.GetZipArchive(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.DownloadZip:GetZipArchive"))
+		return
+	}
+
+	writeZipArchive(w, r, archive)
+}
+
+// DownloadFolder downloads the folder with its whole subtree as a zip archive.
+func (a *MediaAPI) DownloadFolder(w http.ResponseWriter, r *http.Request) {
+	folderID := chi.URLParam(r, urlParamFolderID)
+	if !validate.UUID(folderID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.DownloadFolder:folderID"))
+		return
+	}
+
+	query := &media.GetZipArchiveQuery{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		FolderIDs: []string{folderID},
+	}
+
+	archive, err := a.queries.GetZipArchive(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.DownloadFolder:GetZipArchive"))
+		return
+	}
+
+	writeZipArchive(w, r, archive)
+}
+
+// writeZipArchive streams the archive, its size is not known upfront so there is no Content-Length
+// and no range support. Once the first bytes are sent an error can only be logged, the client gets
+// a truncated archive.
+func writeZipArchive(w http.ResponseWriter, r *http.Request, archive *media.ZipArchive) {
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": archive.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	err := archive.Write(r.Context(), w)
+	if err != nil {
+		applog.GetLoggerFromContext(r.Context()).Error().Err(err).Str("archive", archive.Name).Msg("failed to write the zip archive")
+	}
+}
+
+func (a *MediaAPI) GetPreview(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, urlParamFileID)
+	if !validate.UUID(fileID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.GetPreview:fileID"))
+		return
+	}
+
+	profileID := common.GetProfileIDFromContext(r.Context())
+	query := &media.GetPreviewQuery{
+		OwnerID: profileID,
+		FileID:  fileID,
+		Size:    media.PreviewSize(r.URL.Query().Get("size")),
+	}
+
+	res, err := a.queries.GetPreview(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetPreview:GetPreview"))
+		return
+	}
+	defer res.Preview.Close()
+
+	// Previews only change when the file changes, so the clients can cache them
 	info := res.Info
-	file := res.File
-	http.ServeContent(w, r, info.Name, info.UpdatedAt, file)
+	w.Header().Set("Content-Type", info.PreviewMimeType())
+	// Document previews are html, make sure nothing in them can run when shown inline
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src data:; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Header().Set("ETag", fmt.Sprintf(`"%s-%s-%d"`, info.ID, query.Size, info.UpdatedAt.Unix()))
+	http.ServeContent(w, r, "", info.UpdatedAt, res.Preview)
+}
+
+func (a *MediaAPI) GetFileMetadata(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, urlParamFileID)
+	if !validate.UUID(fileID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.GetFileMetadata:fileID"))
+		return
+	}
+
+	profileID := common.GetProfileIDFromContext(r.Context())
+	query := &media.GetFileMetadataQuery{
+		OwnerID: profileID,
+		FileID:  fileID,
+	}
+
+	meta, err := a.queries.GetFileMetadata(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFileMetadata:GetFileMetadata"))
+		return
+	}
+
+	var dto dtos.GetFileMetadata
+	err = copier.Copy(&dto, meta)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFileMetadata:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func (a *MediaAPI) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	profileID := common.GetProfileIDFromContext(r.Context())
+	pagingOpt, err := pagingOptionsFromQuery(r, "")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTimeline:PagingOptionsFromQuery"))
+		return
+	}
+
+	query := &media.GetTimelineQuery{
+		OwnerID:   profileID,
+		PagingOpt: pagingOpt,
+	}
+
+	res, err := a.queries.GetTimeline(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTimeline:GetTimeline"))
+		return
+	}
+
+	var dto dtos.GetTimeline
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTimeline:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
 }
 
 func (a *MediaAPI) TrashFiles(w http.ResponseWriter, r *http.Request) {
@@ -444,110 +771,251 @@ func (a *MediaAPI) RestoreFile(w http.ResponseWriter, r *http.Request) {
 }
 
 //--------------------------------
-// Folders
+// Archives
 //--------------------------------
 
-func (a *MediaAPI) CreateFolder(w http.ResponseWriter, r *http.Request) {
-	profileID := common.GetProfileIDFromContext(r.Context())
-	var parentFolderID *string
-	if id := chi.URLParam(r, urlParamFolderID); validate.UUID(id) {
-		parentFolderID = &id
-	}
-
-	var req struct {
-		Name string `json:"name"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.CreateFolder:DecodeJSON"))
-		return
-	}
-
-	cmd := &media.CreateFolderCommand{
-		OwnerID:        profileID,
-		Name:           req.Name,
-		ParentFolderID: parentFolderID,
+// GetArchiveEntries lists the files and folders of a zip or tar archive without extracting it.
+func (a *MediaAPI) GetArchiveEntries(w http.ResponseWriter, r *http.Request) {
+	query := &media.GetArchiveEntriesQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		FileID:  chi.URLParam(r, urlParamFileID),
 	}
 
-	folder, err := a.commands.CreateFolder(r.Context(), cmd)
+	entries, err := a.queries.GetArchiveEntries(r.Context(), query)
 	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateFolder:CreateFolder"))
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetArchiveEntries:GetArchiveEntries"))
 		return
 	}
 
-	var dto dtos.GetFolderInfo
-	err = copier.Copy(&dto, &folder)
+	var dto []*dtos.GetArchiveEntry
+	err = copier.Copy(&dto, &entries)
 	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateFolder:Copy"))
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetArchiveEntries:Copy"))
 		return
 	}
 
-	helper.RespondJSON(w, http.StatusCreated, &dto)
+	helper.RespondJSON(w, http.StatusOK, &dto)
 }
 
-func (a *MediaAPI) TrashFolders(w http.ResponseWriter, r *http.Request) {
+// DownloadArchiveEntry downloads one file of a zip or tar archive. The files stored without compression
+// support range requests, the others are streamed while they are decompressed.
+func (a *MediaAPI) DownloadArchiveEntry(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		FolderIDs []string `json:"folderIds"`
+		Path string `json:"path"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.TrashFolder:DecodeJSON"))
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.DownloadArchiveEntry:DecodeJSON"))
 		return
 	}
 
-	validFolderIDs, invalidFolderIDs := validate.UUIDs(req.FolderIDs)
-	if len(invalidFolderIDs) > 0 {
-		applog.GetLoggerFromContext(r.Context()).Warn().Str("invalid_folder_ids", strings.Join(invalidFolderIDs, ",")).Msg("failed to trash invalid folders")
+	query := &media.GetArchiveEntryQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		FileID:  chi.URLParam(r, urlParamFileID),
+		Path:    req.Path,
 	}
-	if len(validFolderIDs) == 0 {
-		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.TrashFolder:NoValidFolderIDs"))
+
+	res, err := a.queries.GetArchiveEntry(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.DownloadArchiveEntry:GetArchiveEntry"))
 		return
 	}
+	defer res.Content.Close()
 
-	profileID := common.GetProfileIDFromContext(r.Context())
-	cmd := &media.TrashFoldersCommand{
-		OwnerID:   profileID,
-		FolderIDs: validFolderIDs,
+	// The type of the entries is not detected, it is only guessed from the name
+	name := path.Base(res.Entry.Path)
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
 	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 
-	err := a.commands.TrashFolders(r.Context(), cmd)
-	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.TrashFolder:TrashFolders").WithMetadata("folder_ids", req.FolderIDs))
+	if content, ok := res.Content.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, name, res.Entry.Modified, content)
 		return
 	}
 
-	helper.RespondEmpty(w, http.StatusNoContent)
+	// No Content-Length, the size is declared by the archive and the content may turn out shorter or
+	// longer, it is sent chunked instead
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, res.Content); err != nil {
+		applog.GetLoggerFromContext(r.Context()).Error().Err(err).Str("path", res.Entry.Path).Msg("failed to write the archive entry")
+	}
 }
 
-func (a *MediaAPI) RenameFolder(w http.ResponseWriter, r *http.Request) {
-	folderID := chi.URLParam(r, urlParamFolderID)
-	if !validate.UUID(folderID) {
-		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.RenameFolder:folderID"))
+// ExtractArchive starts the extraction of the archive into a folder, the progress is polled with GetArchiveExtraction.
+func (a *MediaAPI) ExtractArchive(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, urlParamFileID)
+	if !validate.UUID(fileID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.ExtractArchive:fileID"))
 		return
 	}
 
-	profileID := common.GetProfileIDFromContext(r.Context())
-
 	var req struct {
-		Name string `json:"name"`
+		FolderID string `json:"folderId"`
+		Conflict string `json:"conflict"`
 	}
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.RenameFolder:DecodeJSON"))
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.ExtractArchive:DecodeJSON"))
 		return
 	}
 
-	cmd := &media.RenameFolderCommand{
-		OwnerID:  profileID,
+	var folderID *string
+	if validate.UUID(req.FolderID) {
+		folderID = &req.FolderID
+	}
+
+	cmd := &media.ExtractArchiveCommand{
+		OwnerID:  common.GetProfileIDFromContext(r.Context()),
+		FileID:   fileID,
 		FolderID: folderID,
-		Name:     req.Name,
+		Conflict: media.ExtractConflict(req.Conflict),
 	}
 
-	err = a.commands.RenameFolder(r.Context(), cmd)
+	extraction, err := a.commands.ExtractArchive(r.Context(), cmd)
 	if err != nil {
-		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.RenameFolder:RenameFolder").WithMetadata("new_name", req.Name))
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.ExtractArchive:ExtractArchive"))
 		return
 	}
 
-	helper.RespondEmpty(w, http.StatusNoContent)
+	var dto dtos.GetArchiveExtraction
+	err = copier.Copy(&dto, extraction)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.ExtractArchive:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusAccepted, &dto)
+}
+
+func (a *MediaAPI) GetArchiveExtraction(w http.ResponseWriter, r *http.Request) {
+	query := &media.GetArchiveExtractionQuery{
+		OwnerID:      common.GetProfileIDFromContext(r.Context()),
+		ExtractionID: chi.URLParam(r, urlParamExtractionID),
+	}
+
+	extraction, err := a.queries.GetArchiveExtraction(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetArchiveExtraction:GetArchiveExtraction"))
+		return
+	}
+
+	var dto dtos.GetArchiveExtraction
+	err = copier.Copy(&dto, extraction)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetArchiveExtraction:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+//--------------------------------
+// Folders
+//--------------------------------
+
+func (a *MediaAPI) CreateFolder(w http.ResponseWriter, r *http.Request) {
+	profileID := common.GetProfileIDFromContext(r.Context())
+	var parentFolderID *string
+	if id := chi.URLParam(r, urlParamFolderID); validate.UUID(id) {
+		parentFolderID = &id
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.CreateFolder:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.CreateFolderCommand{
+		OwnerID:        profileID,
+		Name:           req.Name,
+		ParentFolderID: parentFolderID,
+	}
+
+	folder, err := a.commands.CreateFolder(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateFolder:CreateFolder"))
+		return
+	}
+
+	var dto dtos.GetFolderInfo
+	err = copier.Copy(&dto, &folder)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateFolder:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusCreated, &dto)
+}
+
+func (a *MediaAPI) TrashFolders(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FolderIDs []string `json:"folderIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.TrashFolder:DecodeJSON"))
+		return
+	}
+
+	validFolderIDs, invalidFolderIDs := validate.UUIDs(req.FolderIDs)
+	if len(invalidFolderIDs) > 0 {
+		applog.GetLoggerFromContext(r.Context()).Warn().Str("invalid_folder_ids", strings.Join(invalidFolderIDs, ",")).Msg("failed to trash invalid folders")
+	}
+	if len(validFolderIDs) == 0 {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.TrashFolder:NoValidFolderIDs"))
+		return
+	}
+
+	profileID := common.GetProfileIDFromContext(r.Context())
+	cmd := &media.TrashFoldersCommand{
+		OwnerID:   profileID,
+		FolderIDs: validFolderIDs,
+	}
+
+	err := a.commands.TrashFolders(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.TrashFolder:TrashFolders").WithMetadata("folder_ids", req.FolderIDs))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *MediaAPI) RenameFolder(w http.ResponseWriter, r *http.Request) {
+	folderID := chi.URLParam(r, urlParamFolderID)
+	if !validate.UUID(folderID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.RenameFolder:folderID"))
+		return
+	}
+
+	profileID := common.GetProfileIDFromContext(r.Context())
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.RenameFolder:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.RenameFolderCommand{
+		OwnerID:  profileID,
+		FolderID: folderID,
+		Name:     req.Name,
+	}
+
+	err = a.commands.RenameFolder(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.RenameFolder:RenameFolder").WithMetadata("new_name", req.Name))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
 }
 
 func (a *MediaAPI) MoveFolder(w http.ResponseWriter, r *http.Request) {
@@ -693,3 +1161,790 @@ func (a *MediaAPI) GetFolderContent(w http.ResponseWriter, r *http.Request) {
 
 	helper.RespondJSON(w, http.StatusOK, &dto)
 }
+
+//--------------------------------
+// Search
+//--------------------------------
+
+func (a *MediaAPI) Search(w http.ResponseWriter, r *http.Request) {
+	profileID := common.GetProfileIDFromContext(r.Context())
+
+	filePagingOpt, err := pagingOptionsFromQuery(r, "file-")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:PagingOptionsFromQuery.File"))
+		return
+	}
+
+	folderPagingOpt, err := pagingOptionsFromQuery(r, "folder-")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:PagingOptionsFromQuery.Folder"))
+		return
+	}
+
+	query := &media.SearchQuery{
+		OwnerID:         profileID,
+		Term:            r.URL.Query().Get("q"),
+		Category:        media.Category(r.URL.Query().Get("category")),
+		Extension:       r.URL.Query().Get("extension"),
+		FilePagingOpt:   filePagingOpt,
+		FolderPagingOpt: folderPagingOpt,
+	}
+
+	if id := r.URL.Query().Get("folder-id"); id != "" {
+		query.FolderID = &id
+	}
+
+	if query.MinSize, err = int64FromQuery(r, "min-size"); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:MinSize"))
+		return
+	}
+
+	if query.MaxSize, err = int64FromQuery(r, "max-size"); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:MaxSize"))
+		return
+	}
+
+	if query.UpdatedFrom, err = timeFromQuery(r, "updated-from"); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:UpdatedFrom"))
+		return
+	}
+
+	if query.UpdatedTo, err = timeFromQuery(r, "updated-to"); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:UpdatedTo"))
+		return
+	}
+
+	res, err := a.queries.Search(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:Search"))
+		return
+	}
+
+	var dto dtos.Search
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.Search:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+//--------------------------------
+// Tags
+//--------------------------------
+
+func (a *MediaAPI) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.CreateTag:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.CreateTagCommand{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		Name:    req.Name,
+		Color:   req.Color,
+	}
+
+	tag, err := a.commands.CreateTag(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateTag:CreateTag"))
+		return
+	}
+
+	var dto dtos.GetTag
+	err = copier.Copy(&dto, tag)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateTag:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusCreated, &dto)
+}
+
+func (a *MediaAPI) GetTags(w http.ResponseWriter, r *http.Request) {
+	pagingOpt, err := pagingOptionsFromQuery(r, "")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTags:PagingOptionsFromQuery"))
+		return
+	}
+
+	query := &media.GetTagsQuery{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		PagingOpt: pagingOpt,
+	}
+
+	page, err := a.queries.GetTags(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTags:GetTags"))
+		return
+	}
+
+	var dto paging.Page[*dtos.GetTag]
+	err = copier.Copy(&dto, &page)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTags:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func (a *MediaAPI) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.UpdateTag:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.UpdateTagCommand{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		TagID:   chi.URLParam(r, urlParamTagID),
+		Name:    req.Name,
+		Color:   req.Color,
+	}
+
+	err := a.commands.UpdateTag(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UpdateTag:UpdateTag"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *MediaAPI) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	cmd := &media.DeleteTagCommand{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		TagID:   chi.URLParam(r, urlParamTagID),
+	}
+
+	err := a.commands.DeleteTag(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.DeleteTag:DeleteTag"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *MediaAPI) GetTaggedItems(w http.ResponseWriter, r *http.Request) {
+	filePagingOpt, err := pagingOptionsFromQuery(r, "file-")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTaggedItems:PagingOptionsFromQuery.File"))
+		return
+	}
+
+	folderPagingOpt, err := pagingOptionsFromQuery(r, "folder-")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTaggedItems:PagingOptionsFromQuery.Folder"))
+		return
+	}
+
+	query := &media.GetTaggedItemsQuery{
+		OwnerID:         common.GetProfileIDFromContext(r.Context()),
+		TagID:           chi.URLParam(r, urlParamTagID),
+		FilePagingOpt:   filePagingOpt,
+		FolderPagingOpt: folderPagingOpt,
+	}
+
+	res, err := a.queries.GetTaggedItems(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTaggedItems:GetTaggedItems"))
+		return
+	}
+
+	var dto dtos.GetTaggedItems
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetTaggedItems:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+type taggedItemsReq struct {
+	FileIDs   []string `json:"fileIds"`
+	FolderIDs []string `json:"folderIds"`
+}
+
+func (a *MediaAPI) TagItems(w http.ResponseWriter, r *http.Request) {
+	var req taggedItemsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.TagItems:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.TagItemsCommand{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		TagID:     chi.URLParam(r, urlParamTagID),
+		FileIDs:   req.FileIDs,
+		FolderIDs: req.FolderIDs,
+	}
+
+	err := a.commands.TagItems(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.TagItems:TagItems"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *MediaAPI) UntagItems(w http.ResponseWriter, r *http.Request) {
+	var req taggedItemsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.UntagItems:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.UntagItemsCommand{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		TagID:     chi.URLParam(r, urlParamTagID),
+		FileIDs:   req.FileIDs,
+		FolderIDs: req.FolderIDs,
+	}
+
+	err := a.commands.UntagItems(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UntagItems:UntagItems"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+//--------------------------------
+// Starred & Recent
+//--------------------------------
+
+func (a *MediaAPI) GetStarredItems(w http.ResponseWriter, r *http.Request) {
+	filePagingOpt, err := pagingOptionsFromQuery(r, "file-")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetStarredItems:PagingOptionsFromQuery.File"))
+		return
+	}
+
+	folderPagingOpt, err := pagingOptionsFromQuery(r, "folder-")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetStarredItems:PagingOptionsFromQuery.Folder"))
+		return
+	}
+
+	query := &media.GetStarredItemsQuery{
+		OwnerID:         common.GetProfileIDFromContext(r.Context()),
+		FilePagingOpt:   filePagingOpt,
+		FolderPagingOpt: folderPagingOpt,
+	}
+
+	res, err := a.queries.GetStarredItems(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetStarredItems:GetStarredItems"))
+		return
+	}
+
+	var dto dtos.GetStarredItems
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetStarredItems:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+type starredItemsReq struct {
+	FileIDs   []string `json:"fileIds"`
+	FolderIDs []string `json:"folderIds"`
+}
+
+func (a *MediaAPI) StarItems(w http.ResponseWriter, r *http.Request) {
+	var req starredItemsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.StarItems:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.StarItemsCommand{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		FileIDs:   req.FileIDs,
+		FolderIDs: req.FolderIDs,
+	}
+
+	err := a.commands.StarItems(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.StarItems:StarItems"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *MediaAPI) UnstarItems(w http.ResponseWriter, r *http.Request) {
+	var req starredItemsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.UnstarItems:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.UnstarItemsCommand{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		FileIDs:   req.FileIDs,
+		FolderIDs: req.FolderIDs,
+	}
+
+	err := a.commands.UnstarItems(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UnstarItems:UnstarItems"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *MediaAPI) GetRecentFiles(w http.ResponseWriter, r *http.Request) {
+	pagingOpt, err := pagingOptionsFromQuery(r, "")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetRecentFiles:PagingOptionsFromQuery"))
+		return
+	}
+
+	query := &media.GetRecentFilesQuery{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		PagingOpt: pagingOpt,
+	}
+
+	res, err := a.queries.GetRecentFiles(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetRecentFiles:GetRecentFiles"))
+		return
+	}
+
+	var dto paging.Page[*dtos.RecentItem]
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetRecentFiles:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+//--------------------------------
+// Storage Usage
+//--------------------------------
+
+func (a *MediaAPI) GetStorageUsage(w http.ResponseWriter, r *http.Request) {
+	query := &media.GetStorageUsageQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+	}
+
+	res, err := a.queries.GetStorageUsage(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetStorageUsage:GetStorageUsage"))
+		return
+	}
+
+	var dto dtos.GetStorageUsage
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetStorageUsage:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func (a *MediaAPI) GetChanges(w http.ResponseWriter, r *http.Request) {
+	query := &media.GetChangesQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		Cursor:  r.URL.Query().Get("cursor"),
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.GetChanges:ParseLimit").WithMetadata("limit_str", limitStr))
+			return
+		}
+		query.Limit = limit
+	}
+
+	res, err := a.queries.GetChanges(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetChanges:GetChanges"))
+		return
+	}
+
+	var dto dtos.GetChanges
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetChanges:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func (a *MediaAPI) GetFileTags(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, urlParamFileID)
+	if !validate.UUID(fileID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.GetFileTags:fileID"))
+		return
+	}
+
+	query := &media.GetFileTagsQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		FileID:  fileID,
+	}
+
+	tags, err := a.queries.GetFileTags(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFileTags:GetFileTags"))
+		return
+	}
+
+	var dto []*dtos.GetTag
+	err = copier.Copy(&dto, &tags)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFileTags:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func (a *MediaAPI) GetFolderTags(w http.ResponseWriter, r *http.Request) {
+	folderID := chi.URLParam(r, urlParamFolderID)
+	if !validate.UUID(folderID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.GetFolderTags:folderID"))
+		return
+	}
+
+	query := &media.GetFolderTagsQuery{
+		OwnerID:  common.GetProfileIDFromContext(r.Context()),
+		FolderID: folderID,
+	}
+
+	tags, err := a.queries.GetFolderTags(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFolderTags:GetFolderTags"))
+		return
+	}
+
+	var dto []*dtos.GetTag
+	err = copier.Copy(&dto, &tags)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFolderTags:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+//--------------------------------
+// Properties
+//--------------------------------
+
+func (a *MediaAPI) GetFileProperties(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, urlParamFileID)
+	if !validate.UUID(fileID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.GetFileProperties:fileID"))
+		return
+	}
+
+	query := &media.GetFilePropertiesQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		FileID:  fileID,
+	}
+
+	props, err := a.queries.GetFileProperties(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFileProperties:GetFileProperties"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dtos.Properties{Properties: props})
+}
+
+func (a *MediaAPI) SetFileProperties(w http.ResponseWriter, r *http.Request) {
+	var req dtos.Properties
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.SetFileProperties:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.SetFilePropertiesCommand{
+		OwnerID:    common.GetProfileIDFromContext(r.Context()),
+		FileID:     chi.URLParam(r, urlParamFileID),
+		Properties: req.Properties,
+	}
+
+	err := a.commands.SetFileProperties(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.SetFileProperties:SetFileProperties"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *MediaAPI) GetFolderProperties(w http.ResponseWriter, r *http.Request) {
+	folderID := chi.URLParam(r, urlParamFolderID)
+	if !validate.UUID(folderID) {
+		helper.RespondError(w, r, apperror.NewAppError(apperror.ErrCommonInvalidValue, "mediaAPI.GetFolderProperties:folderID"))
+		return
+	}
+
+	query := &media.GetFolderPropertiesQuery{
+		OwnerID:  common.GetProfileIDFromContext(r.Context()),
+		FolderID: folderID,
+	}
+
+	props, err := a.queries.GetFolderProperties(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFolderProperties:GetFolderProperties"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dtos.Properties{Properties: props})
+}
+
+func (a *MediaAPI) SetFolderProperties(w http.ResponseWriter, r *http.Request) {
+	var req dtos.Properties
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.SetFolderProperties:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.SetFolderPropertiesCommand{
+		OwnerID:    common.GetProfileIDFromContext(r.Context()),
+		FolderID:   chi.URLParam(r, urlParamFolderID),
+		Properties: req.Properties,
+	}
+
+	err := a.commands.SetFolderProperties(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.SetFolderProperties:SetFolderProperties"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+//--------------------------------
+// Paths
+//--------------------------------
+
+// ResolvePath returns the folder or file at the path of the query, e.g. "?path=/Projects/2026/spec.pdf".
+// Both are null for the root folder.
+func (a *MediaAPI) ResolvePath(w http.ResponseWriter, r *http.Request) {
+	query := &media.ResolvePathQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		Path:    r.URL.Query().Get("path"),
+	}
+
+	res, err := a.queries.ResolvePath(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.ResolvePath:ResolvePath"))
+		return
+	}
+
+	var dto dtos.ResolvePath
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.ResolvePath:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func (a *MediaAPI) DownloadFileByPath(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path string `json:"path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.DownloadFileByPath:DecodeJSON"))
+		return
+	}
+
+	profileID := common.GetProfileIDFromContext(r.Context())
+	resolved, err := a.queries.ResolvePath(r.Context(), &media.ResolvePathQuery{OwnerID: profileID, Path: req.Path})
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.DownloadFileByPath:ResolvePath"))
+		return
+	}
+	if resolved.File == nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: not a file", apperror.ErrCommonInvalidValue), "mediaAPI.DownloadFileByPath:File").WithMetadata("path", req.Path))
+		return
+	}
+
+	res, err := a.queries.GetFile(r.Context(), &media.GetFileQuery{OwnerID: profileID, FileID: resolved.File.ID})
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.DownloadFileByPath:GetFile"))
+		return
+	}
+	defer res.File.Close()
+
+	a.serveFile(w, r, res)
+}
+
+// UploadFileByPath uploads the "file" of the form into the folder at "path", the missing folders are created.
+func (a *MediaAPI) UploadFileByPath(w http.ResponseWriter, r *http.Request) {
+	file, handler, err := parseUploadForm(r)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UploadFileByPath:parseUploadForm"))
+		return
+	}
+	defer file.Close()
+
+	var folderID *string
+	if folderPath := r.FormValue("path"); strings.Trim(folderPath, "/") != "" {
+		cmd := &media.CreateFolderPathCommand{
+			OwnerID: common.GetProfileIDFromContext(r.Context()),
+			Path:    folderPath,
+		}
+
+		folder, err := a.commands.CreateFolderPath(r.Context(), cmd)
+		if err != nil {
+			helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.UploadFileByPath:CreateFolderPath"))
+			return
+		}
+		folderID = &folder.ID
+	}
+
+	a.uploadFile(w, r, folderID, file, handler)
+}
+
+// CreateFolderPath creates the missing folders of the path, like mkdir -p, and returns the last one.
+func (a *MediaAPI) CreateFolderPath(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Path string `json:"path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.CreateFolderPath:DecodeJSON"))
+		return
+	}
+
+	cmd := &media.CreateFolderPathCommand{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		Path:    req.Path,
+	}
+
+	folder, err := a.commands.CreateFolderPath(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateFolderPath:CreateFolderPath"))
+		return
+	}
+
+	var dto dtos.GetFolderInfo
+	err = copier.Copy(&dto, &folder)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.CreateFolderPath:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusCreated, &dto)
+}
+
+func (a *MediaAPI) GetFilePath(w http.ResponseWriter, r *http.Request) {
+	query := &media.GetFilePathQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+		FileID:  chi.URLParam(r, urlParamFileID),
+	}
+
+	p, err := a.queries.GetFilePath(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFilePath:GetFilePath"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dtos.GetPath{Path: p})
+}
+
+func (a *MediaAPI) GetFolderPath(w http.ResponseWriter, r *http.Request) {
+	query := &media.GetFolderPathQuery{
+		OwnerID:  common.GetProfileIDFromContext(r.Context()),
+		FolderID: chi.URLParam(r, urlParamFolderID),
+	}
+
+	p, err := a.queries.GetFolderPath(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFolderPath:GetFolderPath"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dtos.GetPath{Path: p})
+}
+
+func (a *MediaAPI) GetFolderTree(w http.ResponseWriter, r *http.Request) {
+	query := &media.GetFolderTreeQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+	}
+	if id := chi.URLParam(r, urlParamFolderID); validate.UUID(id) {
+		query.FolderID = &id
+	}
+
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil {
+			helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "mediaAPI.GetFolderTree:ParseDepth").WithMetadata("depth_str", depthStr))
+			return
+		}
+		query.Depth = depth
+	}
+
+	res, err := a.queries.GetFolderTree(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFolderTree:GetFolderTree"))
+		return
+	}
+
+	dto := dtos.GetFolderTree{
+		Ancestors: []dtos.BaseInfo{},
+		Nodes:     folderTreeNodesToDTO(res.Nodes),
+		Truncated: res.Truncated,
+	}
+	if res.Folder != nil {
+		dto.Folder = &dtos.GetFolderInfo{}
+		if err := copier.Copy(dto.Folder, res.Folder); err != nil {
+			helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFolderTree:CopyFolder"))
+			return
+		}
+	}
+	if err := copier.Copy(&dto.Ancestors, res.Ancestors); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "mediaAPI.GetFolderTree:CopyAncestors"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func folderTreeNodesToDTO(nodes []*media.FolderTreeNode) []*dtos.GetFolderTreeNode {
+	if nodes == nil {
+		return nil
+	}
+
+	dto := make([]*dtos.GetFolderTreeNode, 0, len(nodes))
+	for _, n := range nodes {
+		dto = append(dto, &dtos.GetFolderTreeNode{
+			ID:          n.Folder.ID,
+			Name:        n.Folder.Name,
+			Size:        n.Folder.Size,
+			FileCount:   n.Folder.FileCount,
+			FolderCount: n.Folder.FolderCount,
+			HasChildren: n.HasChildren,
+			Children:    folderTreeNodesToDTO(n.Children),
+		})
+	}
+	return dto
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"sync"
+	"time"
+)
+
+// authLimiter locks out a client after too many failed sign-ins in a row, see DAVAuth.
+// The failures are kept in memory, per server.
+type authLimiter struct {
+	maxFailures int
+	lockout     time.Duration // Also how long the failures are remembered
+
+	mu       sync.Mutex
+	failures map[string]*authFailures
+	sweptAt  time.Time
+}
+
+type authFailures struct {
+	count       int
+	failedAt    time.Time
+	lockedUntil time.Time
+}
+
+func newAuthLimiter(maxFailures int, lockout time.Duration) *authLimiter {
+	return &authLimiter{
+		maxFailures: maxFailures,
+		lockout:     lockout,
+		failures:    map[string]*authFailures{},
+	}
+}
+
+// locked returns how long the key is still locked out, 0 if it is not.
+func (l *authLimiter) locked(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[key]
+	if !ok || !now.Before(f.lockedUntil) {
+		return 0
+	}
+	return f.lockedUntil.Sub(now)
+}
+
+// fail counts a failed sign-in of the key, it is locked out once it reaches the max failures.
+func (l *authLimiter) fail(key string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The failures are only counted for a while, so are the keys kept
+	if now.Sub(l.sweptAt) >= l.lockout {
+		for k, f := range l.failures {
+			if now.Sub(f.failedAt) >= l.lockout {
+				delete(l.failures, k)
+			}
+		}
+		l.sweptAt = now
+	}
+
+	f, ok := l.failures[key]
+	if !ok || now.Sub(f.failedAt) >= l.lockout {
+		f = &authFailures{}
+		l.failures[key] = f
+	}
+
+	f.count++
+	f.failedAt = now
+	if f.count >= l.maxFailures {
+		f.count = 0
+		f.lockedUntil = now.Add(l.lockout)
+	}
+}
+
+// succeed forgets the failures of the key.
+func (l *authLimiter) succeed(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+}
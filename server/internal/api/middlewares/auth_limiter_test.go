@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthLimiter(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	t.Run("locked out after the max failures", func(t *testing.T) {
+		t.Parallel()
+		l := newAuthLimiter(3, time.Minute)
+		for range 2 {
+			l.fail("key", now)
+		}
+		assert.Zero(t, l.locked("key", now))
+
+		l.fail("key", now)
+		assert.Equal(t, time.Minute, l.locked("key", now))
+		assert.Zero(t, l.locked("other", now))
+		assert.Zero(t, l.locked("key", now.Add(time.Minute)))
+	})
+
+	t.Run("failures forgotten", func(t *testing.T) {
+		t.Parallel()
+		l := newAuthLimiter(2, time.Minute)
+		l.fail("key", now)
+		l.succeed("key")
+		l.fail("key", now)
+		assert.Zero(t, l.locked("key", now))
+
+		// Too long ago to count
+		l.fail("key", now.Add(time.Minute))
+		assert.Zero(t, l.locked("key", now.Add(time.Minute)))
+	})
+
+	t.Run("old keys swept", func(t *testing.T) {
+		t.Parallel()
+		l := newAuthLimiter(2, time.Minute)
+		l.fail("old", now)
+		l.fail("new", now.Add(2*time.Minute))
+		assert.NotContains(t, l.failures, "old")
+		assert.Contains(t, l.failures, "new")
+	})
+}
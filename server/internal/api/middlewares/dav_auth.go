@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"skyvault/internal/api/helper"
+	"skyvault/internal/domain/auth"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/applog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A client failing to sign in with Basic auth so many times in a row is locked out for a while.
+// The client is the IP address with the email, so that guessing the app passwords of an email
+// from one address doesn't lock its owner out from the others.
+const (
+	davMaxAuthFailures = 10
+	davAuthLockout     = 15 * time.Minute
+)
+
+// DAVAuth signs in the WebDAV clients with Basic auth, the email and an app password, or with a Bearer token.
+// Unlike JWT it doesn't redirect, it asks the client for the credentials with a 401.
+func DAVAuth(authenticator auth.Authenticator, authQueries auth.Queries, authCommands auth.Commands) func(http.Handler) http.Handler {
+	limiter := newAuthLimiter(davMaxAuthFailures, davAuthLockout)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := applog.GetLoggerFromContext(r.Context())
+
+			var profileID, sessionID string
+			var err error
+			if email, secret, ok := r.BasicAuth(); ok {
+				key := helper.ClientIP(r) + " " + strings.ToLower(strings.TrimSpace(email))
+				if retryAfter := limiter.locked(key, time.Now()); retryAfter > 0 {
+					logger.Warn().Str("ip", helper.ClientIP(r)).Msg("webdav client locked out")
+					w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+
+				query := &auth.ValidateAppPasswordQuery{Email: email, Secret: secret}
+				profileID, err = authQueries.ValidateAppPassword(r.Context(), query)
+				if errors.Is(err, apperror.ErrAuthInvalidCredentials) {
+					limiter.fail(key, time.Now())
+				} else if err == nil {
+					limiter.succeed(key)
+				}
+			} else if tokenStr, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				var claims auth.Claims
+				claims, err = authenticator.ValidateToken(r.Context(), strings.TrimSpace(tokenStr))
+				if err == nil {
+					err = validateSession(r.Context(), authQueries, authCommands, claims)
+				}
+				if err == nil {
+					profileID, sessionID = claims.GetProfileID(), claims.GetSessionID()
+				}
+			} else {
+				err = apperror.ErrAuthInvalidCredentials
+			}
+
+			if err != nil {
+				if errors.Is(err, apperror.ErrAuthInvalidCredentials) || errors.Is(err, apperror.ErrAuthInvalidToken) ||
+					errors.Is(err, apperror.ErrAuthTokenExpired) || errors.Is(err, apperror.ErrAuthSessionRevoked) {
+					logger.Debug().Err(err).Msg("webdav client not signed in")
+					w.Header().Set("WWW-Authenticate", `Basic realm="SkyVault", charset="UTF-8"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				helper.RespondError(w, r, apperror.NewAppError(err, "middlewares.DAVAuth"))
+				return
+			}
+
+			ctx := withProfile(r.Context(), profileID)
+			if sessionID != "" {
+				ctx = withSession(ctx, sessionID)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
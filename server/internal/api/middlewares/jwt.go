@@ -11,11 +11,12 @@ import (
 	"skyvault/pkg/applog"
 	"skyvault/pkg/common"
 	"strings"
+	"time"
 )
 
-// JWT checks if the request has a valid JWT token.
+// JWT checks if the request has a valid JWT token of an active session.
 // If the token is valid, it will set the claims in the request context.
-func JWT(authenticator auth.Authenticator) func(http.Handler) http.Handler {
+func JWT(authenticator auth.Authenticator, authQueries auth.Queries, authCommands auth.Commands) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			logger := applog.GetLoggerFromContext(r.Context())
@@ -51,16 +52,62 @@ func JWT(authenticator auth.Authenticator) func(http.Handler) http.Handler {
 				return
 			}
 
-			profileID := claims.GetProfileID()
-			ctx := context.WithValue(r.Context(), common.CtxKeyProfileID, profileID)
-
-			logger = logger.
-				With().
-				Str("session_profile_id", profileID).
-				Logger()
-			ctx = context.WithValue(ctx, common.CtxKeyLogger, logger)
+			// The token is valid until it expires, unless its session was signed out or revoked
+			err = validateSession(r.Context(), authQueries, authCommands, claims)
+			if err != nil {
+				if errors.Is(err, apperror.ErrAuthSessionRevoked) || errors.Is(err, apperror.ErrAuthTokenExpired) || errors.Is(err, apperror.ErrAuthInvalidToken) {
+					logger.Debug().Err(err).Msg("session ended, redirecting to /sign-in")
+					http.Redirect(w, r, "/sign-in", http.StatusSeeOther)
+					return
+				}
+				helper.RespondError(w, r, fmt.Errorf("failed to validate session: %w", err))
+				return
+			}
 
+			ctx := withSession(withProfile(r.Context(), claims.GetProfileID()), claims.GetSessionID())
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// withProfile sets the signed in profile, and adds it to the logger.
+func withProfile(ctx context.Context, profileID string) context.Context {
+	ctx = context.WithValue(ctx, common.CtxKeyProfileID, profileID)
+
+	logger := applog.GetLoggerFromContext(ctx).
+		With().
+		Str("session_profile_id", profileID).
+		Logger()
+	return context.WithValue(ctx, common.CtxKeyLogger, logger)
+}
+
+// validateSession checks that the session of the claims is active, and records that it was seen.
+//
+// App Errors:
+// - ErrAuthInvalidToken
+// - ErrAuthTokenExpired
+// - ErrAuthSessionRevoked
+func validateSession(ctx context.Context, authQueries auth.Queries, authCommands auth.Commands, claims auth.Claims) error {
+	session, err := authQueries.ValidateSession(ctx, &auth.ValidateSessionQuery{
+		ProfileID: claims.GetProfileID(),
+		SessionID: claims.GetSessionID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if session.NeedsTouch(time.Now().UTC()) {
+		// Only the list of the sessions shows it, the request goes on without it
+		err = authCommands.TouchSession(ctx, &auth.TouchSessionCommand{SessionID: session.ID})
+		if err != nil {
+			applog.GetLoggerFromContext(ctx).Warn().Err(err).Str("session_id", session.ID).Msg("failed to touch session")
+		}
+	}
+
+	return nil
+}
+
+// withSession sets the session of the access token, e.g. for signing it out.
+func withSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, common.CtxKeySessionID, sessionID)
+}
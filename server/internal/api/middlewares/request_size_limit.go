@@ -7,6 +7,7 @@ import (
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
+	"slices"
 	"strings"
 )
 
@@ -14,9 +15,9 @@ import (
 func RequestSizeLimit(config *appconfig.Config) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Only check for requests with bodies (POST, PUT, PATCH)
-			if r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch {
-				maxSizeBytes := getMaxSizeForRoute(r.URL.Path, config)
+			// Only check for requests with bodies (POST, PUT, PATCH and the WebDAV ones)
+			if slices.Contains(bodyMethods, r.Method) {
+				maxSizeBytes := getMaxSizeForRoute(r.Method, r.URL.Path, config)
 
 				if r.ContentLength > maxSizeBytes {
 					helper.RespondError(w, r, apperror.NewAppError(
@@ -38,10 +39,17 @@ func RequestSizeLimit(config *appconfig.Config) func(http.Handler) http.Handler
 	}
 }
 
+var bodyMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, "PROPFIND", "PROPPATCH", "LOCK"}
+
 // getMaxSizeForRoute returns the appropriate size limit based on the route
-func getMaxSizeForRoute(path string, config *appconfig.Config) int64 {
+func getMaxSizeForRoute(method, path string, config *appconfig.Config) int64 {
 	cleanPath := strings.TrimRight(path, "/")
 
+	// WebDAV uploads have the file as body
+	if method == http.MethodPut && strings.HasPrefix(cleanPath, "/dav/") {
+		return config.Media.MaxDirectUploadSizeMB * common.BytesPerMB
+	}
+
 	// Upload routes need higher limits
 	// Add 1MB extra buffer to the upload size to account for extra fields in the request body
 	if strings.Contains(cleanPath, "/media/folders/") {
@@ -54,6 +62,10 @@ func getMaxSizeForRoute(path string, config *appconfig.Config) int64 {
 		}
 	}
 
+	if strings.HasSuffix(cleanPath, "/media/paths/files") {
+		return (config.Media.MaxDirectUploadSizeMB + 1) * common.BytesPerMB
+	}
+
 	// Default conservative limit for all other API endpoints (2MB)
 	return 2 * common.BytesPerMB
 }
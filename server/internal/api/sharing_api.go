@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"skyvault/internal/api/helper"
+	"skyvault/internal/workflows"
+	"skyvault/pkg/apperror"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const urlParamShareID = "share-id"
+
+type SharingAPI struct {
+	api               *API
+	shareDownloadFlow *workflows.ShareDownloadFlow
+}
+
+func NewSharingAPI(a *API, shareDownloadFlow *workflows.ShareDownloadFlow) *SharingAPI {
+	return &SharingAPI{
+		api:               a,
+		shareDownloadFlow: shareDownloadFlow,
+	}
+}
+
+func (a *SharingAPI) InitRoutes() *SharingAPI {
+	// Share links are opened by visitors who are not signed in
+	pubRouter := a.api.v1Pub
+	pubRouter.Route("/shares", func(r chi.Router) {
+		r.Route(fmt.Sprintf("/{%s}", urlParamShareID), func(r chi.Router) {
+			r.Post("/download", a.DownloadShare)
+		})
+	})
+
+	return a
+}
+
+// DownloadShare downloads the shared file or folder as a zip archive.
+func (a *SharingAPI) DownloadShare(w http.ResponseWriter, r *http.Request) {
+	// The credentials are optional, the share may not need them
+	var req struct {
+		Email    *string `json:"email"`
+		Password *string `json:"password"`
+	}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "sharingAPI.DownloadShare:DecodeJSON"))
+		return
+	}
+
+	archive, err := a.shareDownloadFlow.Run(r.Context(), &workflows.ShareDownloadReq{
+		ShareID:  chi.URLParam(r, urlParamShareID),
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "sharingAPI.DownloadShare:Run"))
+		return
+	}
+
+	writeZipArchive(w, r, archive)
+}
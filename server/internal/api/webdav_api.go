@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+	"skyvault/internal/api/middlewares"
+	"skyvault/internal/domain/auth"
+	"skyvault/internal/domain/media"
+	"skyvault/pkg/appconfig"
+	"skyvault/pkg/applog"
+	"skyvault/pkg/common"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/webdav"
+)
+
+// davPrefix is the URL the WebDAV clients mount, e.g. https://skyvault.example.com/dav
+const davPrefix = "/dav"
+
+// davMethods are the WebDAV methods chi doesn't know
+var davMethods = []string{"PROPFIND", "PROPPATCH", "MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK"}
+
+// davLockIdleTimeout is how long the locks of a profile are kept without any WebDAV request of the profile,
+// a lock held longer is lost. It bounds the lock systems to the profiles recently active.
+const davLockIdleTimeout = time.Hour
+
+type WebDAVAPI struct {
+	api           *API
+	authenticator auth.Authenticator
+	authQueries   auth.Queries
+	authCommands  auth.Commands
+	fs            *davFS
+
+	// The WebDAV paths are per profile, so are the locks. They are kept in memory, the WebDAV
+	// clients must all reach the same server, i.e. a single replica or sticky sessions.
+	mu          sync.Mutex
+	lockSystems map[string]*davLockSystem
+	sweptAt     time.Time
+}
+
+type davLockSystem struct {
+	webdav.LockSystem
+	usedAt time.Time
+}
+
+func NewWebDAVAPI(a *API, app *appconfig.App, authenticator auth.Authenticator, authQueries auth.Queries, authCommands auth.Commands, commands media.Commands, queries media.Queries) *WebDAVAPI {
+	return &WebDAVAPI{
+		api:           a,
+		authenticator: authenticator,
+		authQueries:   authQueries,
+		authCommands:  authCommands,
+		fs: &davFS{
+			app:      app,
+			commands: commands,
+			queries:  queries,
+		},
+		lockSystems: map[string]*davLockSystem{},
+	}
+}
+
+func (a *WebDAVAPI) InitRoutes() *WebDAVAPI {
+	// The methods must be registered before their routes
+	for _, method := range davMethods {
+		chi.RegisterMethod(method)
+	}
+
+	// Mounted out of /api/v1, the clients sign in with Basic auth instead of the JWT redirect
+	a.api.Router.Route(davPrefix, func(r chi.Router) {
+		r.Use(
+			middlewares.DAVAuth(a.authenticator, a.authQueries, a.authCommands),
+			middlewares.RequestSizeLimit(a.api.app.Config),
+		)
+		r.Handle("/", a)
+		r.Handle("/*", a)
+	})
+
+	return a
+}
+
+func (a *WebDAVAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler := &webdav.Handler{
+		Prefix:     davPrefix,
+		FileSystem: a.fs,
+		LockSystem: a.lockSystem(common.GetProfileIDFromContext(r.Context())),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				applog.GetLoggerFromContext(r.Context()).Warn().Err(err).Str("method", r.Method).Str("path", r.URL.Path).Msg("webdav request failed")
+			}
+		},
+	}
+	handler.ServeHTTP(w, r)
+}
+
+func (a *WebDAVAPI) lockSystem(profileID string) webdav.LockSystem {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.sweptAt) >= davLockIdleTimeout {
+		for id, ls := range a.lockSystems {
+			if now.Sub(ls.usedAt) >= davLockIdleTimeout {
+				delete(a.lockSystems, id)
+			}
+		}
+		a.sweptAt = now
+	}
+
+	ls, ok := a.lockSystems[profileID]
+	if !ok {
+		ls = &davLockSystem{LockSystem: webdav.NewMemLS()}
+		a.lockSystems[profileID] = ls
+	}
+	ls.usedAt = now
+	return ls
+}
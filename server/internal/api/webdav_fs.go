@@ -0,0 +1,504 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"skyvault/internal/domain/media"
+	"skyvault/pkg/appconfig"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/applog"
+	"skyvault/pkg/common"
+	"skyvault/pkg/paging"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+var _ webdav.FileSystem = (*davFS)(nil)
+
+// davFS is the WebDAV view of the media of the signed in profile. The WebDAV paths are the folder
+// and file names from the root folder, e.g. "/docs/report.pdf".
+// Deleted files and folders are moved to the trash, and overwritten files are trashed before the upload.
+type davFS struct {
+	app      *appconfig.App
+	commands media.Commands
+	queries  media.Queries
+}
+
+// davError maps the app errors to the fs errors the webdav package turns into HTTP statuses.
+func davError(ctx context.Context, op, name string, err error) error {
+	var fsErr error
+	switch {
+	case errors.Is(err, apperror.ErrCommonNoData):
+		fsErr = fs.ErrNotExist
+	case errors.Is(err, apperror.ErrCommonDuplicateData):
+		fsErr = fs.ErrExist
+	case errors.Is(err, apperror.ErrCommonNoAccess), errors.Is(err, apperror.ErrMediaFileInfected):
+		fsErr = fs.ErrPermission
+	case errors.Is(err, apperror.ErrCommonInvalidValue):
+		fsErr = fs.ErrInvalid
+	default:
+		return err
+	}
+
+	applog.GetLoggerFromContext(ctx).Debug().Err(err).Str("op", op).Str("path", name).Msg("webdav request failed")
+	return &fs.PathError{Op: op, Path: name, Err: fsErr}
+}
+
+func (d *davFS) resolve(ctx context.Context, op, name string) (*media.ResolvePathRes, error) {
+	query := &media.ResolvePathQuery{
+		OwnerID: common.GetProfileIDFromContext(ctx),
+		Path:    name,
+	}
+
+	res, err := d.queries.ResolvePath(ctx, query)
+	if err != nil {
+		return nil, davError(ctx, op, name, apperror.NewAppError(err, "davFS.resolve:ResolvePath"))
+	}
+	return res, nil
+}
+
+// resolveParent returns the ID of the folder the name is in, nil for the root folder, and the base name.
+func (d *davFS) resolveParent(ctx context.Context, op, name string) (*string, string, error) {
+	dir, base := path.Split(strings.TrimSuffix(name, "/"))
+	if base == "" {
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+	}
+
+	parent, err := d.resolve(ctx, op, dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if parent.File != nil {
+		// Files can't have children
+		return nil, "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	if parent.Folder == nil {
+		return nil, base, nil
+	}
+	return &parent.Folder.ID, base, nil
+}
+
+func (d *davFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	res, err := d.resolve(ctx, "stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return newDavFileInfo(res), nil
+}
+
+func (d *davFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	parentFolderID, base, err := d.resolveParent(ctx, "mkdir", name)
+	if err != nil {
+		return err
+	}
+
+	cmd := &media.CreateFolderCommand{
+		OwnerID:        common.GetProfileIDFromContext(ctx),
+		Name:           base,
+		ParentFolderID: parentFolderID,
+	}
+
+	_, err = d.commands.CreateFolder(ctx, cmd)
+	if err != nil {
+		return davError(ctx, "mkdir", name, apperror.NewAppError(err, "davFS.Mkdir:CreateFolder"))
+	}
+	return nil
+}
+
+func (d *davFS) RemoveAll(ctx context.Context, name string) error {
+	res, err := d.resolve(ctx, "remove", name)
+	if err != nil {
+		return err
+	}
+
+	ownerID := common.GetProfileIDFromContext(ctx)
+	switch {
+	case res.File != nil:
+		err = d.commands.TrashFiles(ctx, &media.TrashFilesCommand{OwnerID: ownerID, FileIDs: []string{res.File.ID}})
+	case res.Folder != nil:
+		err = d.commands.TrashFolders(ctx, &media.TrashFoldersCommand{OwnerID: ownerID, FolderIDs: []string{res.Folder.ID}})
+	default:
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+	}
+	if err != nil {
+		return davError(ctx, "remove", name, apperror.NewAppError(err, "davFS.RemoveAll:Trash"))
+	}
+	return nil
+}
+
+// Rename renames and moves the file or folder. The webdav package already removed an overwritten destination.
+func (d *davFS) Rename(ctx context.Context, oldName, newName string) error {
+	res, err := d.resolve(ctx, "rename", oldName)
+	if err != nil {
+		return err
+	}
+	if res.File == nil && res.Folder == nil {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrPermission}
+	}
+
+	folderID, base, err := d.resolveParent(ctx, "rename", newName)
+	if err != nil {
+		return err
+	}
+
+	ownerID := common.GetProfileIDFromContext(ctx)
+	if res.File != nil {
+		err = d.renameFile(ctx, ownerID, res.File, folderID, base)
+	} else {
+		err = d.renameFolder(ctx, ownerID, res.Folder, folderID, base)
+	}
+	if err != nil {
+		return davError(ctx, "rename", oldName, err)
+	}
+	return nil
+}
+
+// renameFile renames the file in its folder before moving it, the name is restored if the move fails.
+func (d *davFS) renameFile(ctx context.Context, ownerID string, info *media.FileInfo, folderID *string, name string) error {
+	if info.Name != name {
+		err := d.commands.RenameFile(ctx, &media.RenameFileCommand{OwnerID: ownerID, FileID: info.ID, Name: name})
+		if err != nil {
+			return apperror.NewAppError(err, "davFS.renameFile:RenameFile")
+		}
+	}
+
+	if !sameFolder(info.FolderID, folderID) {
+		err := d.commands.MoveFile(ctx, &media.MoveFileCommand{OwnerID: ownerID, FileID: info.ID, FolderID: folderID})
+		if err != nil {
+			if info.Name != name {
+				_ = d.commands.RenameFile(ctx, &media.RenameFileCommand{OwnerID: ownerID, FileID: info.ID, Name: info.Name})
+			}
+			return apperror.NewAppError(err, "davFS.renameFile:MoveFile")
+		}
+	}
+	return nil
+}
+
+// renameFolder is renameFile for folders.
+func (d *davFS) renameFolder(ctx context.Context, ownerID string, info *media.FolderInfo, parentFolderID *string, name string) error {
+	if info.Name != name {
+		err := d.commands.RenameFolder(ctx, &media.RenameFolderCommand{OwnerID: ownerID, FolderID: info.ID, Name: name})
+		if err != nil {
+			return apperror.NewAppError(err, "davFS.renameFolder:RenameFolder")
+		}
+	}
+
+	if !sameFolder(info.ParentFolderID, parentFolderID) {
+		err := d.commands.MoveFolder(ctx, &media.MoveFolderCommand{OwnerID: ownerID, FolderID: info.ID, ParentFolderID: parentFolderID})
+		if err != nil {
+			if info.Name != name {
+				_ = d.commands.RenameFolder(ctx, &media.RenameFolderCommand{OwnerID: ownerID, FolderID: info.ID, Name: info.Name})
+			}
+			return apperror.NewAppError(err, "davFS.renameFolder:MoveFolder")
+		}
+	}
+	return nil
+}
+
+func sameFolder(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// OpenFile opens the folder or file for reading, or a new file for writing. The written content
+// replaces the file on Close, appending and partial writes are not supported.
+func (d *davFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return d.create(ctx, name, flag)
+	}
+
+	res, err := d.resolve(ctx, "open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.File != nil {
+		return &davReadFile{ctx: ctx, queries: d.queries, info: res.File}, nil
+	}
+	return &davDir{ctx: ctx, queries: d.queries, name: name, res: res}, nil
+}
+
+func (d *davFS) create(ctx context.Context, name string, flag int) (webdav.File, error) {
+	if flag&os.O_APPEND != 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	folderID, base, err := d.resolveParent(ctx, "open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing *media.FileInfo
+	res, err := d.resolve(ctx, "open", name)
+	if err == nil {
+		if res.File == nil {
+			// A folder can't be overwritten by a file
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+		}
+		if flag&os.O_EXCL != 0 {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+		}
+		existing = res.File
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "skyvault-webdav-*")
+	if err != nil {
+		return nil, apperror.NewAppError(err, "davFS.create:CreateTemp")
+	}
+
+	return &davWriteFile{
+		ctx:      ctx,
+		commands: d.commands,
+		folderID: folderID,
+		name:     base,
+		existing: existing,
+		tmp:      tmp,
+		maxSize:  d.app.Config.Media.MaxDirectUploadSizeMB * common.BytesPerMB,
+	}, nil
+}
+
+// davFileInfo is the fs.FileInfo of the folders and files
+type davFileInfo struct {
+	name     string
+	size     int64
+	modTime  time.Time
+	isDir    bool
+	mimeType string
+}
+
+var _ webdav.ContentTyper = (*davFileInfo)(nil)
+
+func newDavFileInfo(res *media.ResolvePathRes) *davFileInfo {
+	switch {
+	case res.File != nil:
+		return &davFileInfo{name: res.File.Name, size: res.File.Size, modTime: res.File.UpdatedAt, mimeType: res.File.MimeType}
+	case res.Folder != nil:
+		return &davFileInfo{name: res.Folder.Name, modTime: res.Folder.UpdatedAt, isDir: true}
+	default:
+		return &davFileInfo{name: "/", isDir: true}
+	}
+}
+
+func (i *davFileInfo) Name() string       { return i.name }
+func (i *davFileInfo) Size() int64        { return i.size }
+func (i *davFileInfo) ModTime() time.Time { return i.modTime }
+func (i *davFileInfo) IsDir() bool        { return i.isDir }
+func (i *davFileInfo) Sys() any           { return nil }
+
+func (i *davFileInfo) Mode() fs.FileMode {
+	if i.isDir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// ContentType saves the webdav package from sniffing the content of every listed file.
+func (i *davFileInfo) ContentType(ctx context.Context) (string, error) {
+	if i.isDir || i.mimeType == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return i.mimeType, nil
+}
+
+// davDir is an opened folder, its content is loaded by Readdir.
+type davDir struct {
+	ctx     context.Context
+	queries media.Queries
+	name    string
+	res     *media.ResolvePathRes
+}
+
+func (d *davDir) Close() error                                 { return nil }
+func (d *davDir) Read(p []byte) (int, error)                   { return 0, fs.ErrInvalid }
+func (d *davDir) Seek(offset int64, whence int) (int64, error) { return 0, fs.ErrInvalid }
+func (d *davDir) Write(p []byte) (int, error)                  { return 0, fs.ErrInvalid }
+func (d *davDir) Stat() (fs.FileInfo, error)                   { return newDavFileInfo(d.res), nil }
+
+// Readdir returns the whole content of the folder, count is ignored.
+func (d *davDir) Readdir(count int) ([]fs.FileInfo, error) {
+	var folderID *string
+	if d.res.Folder != nil {
+		folderID = &d.res.Folder.ID
+	}
+
+	fileOpt := &paging.Options{Direction: paging.DirectionForward, Limit: paging.MaxLimit, Sort: paging.SortAsc, SortBy: paging.SortByName}
+	folderOpt := &paging.Options{Direction: paging.DirectionForward, Limit: paging.MaxLimit, Sort: paging.SortAsc, SortBy: paging.SortByName}
+	fileOpt.Validate()
+	folderOpt.Validate()
+
+	// GetFolderContent lists both, the listing done first keeps loading its last page until the other is done
+	infos := []fs.FileInfo{}
+	filesDone, foldersDone := false, false
+	for !filesDone || !foldersDone {
+		query := &media.GetFolderContentQuery{
+			OwnerID:         common.GetProfileIDFromContext(d.ctx),
+			FolderID:        folderID,
+			FilePagingOpt:   fileOpt,
+			FolderPagingOpt: folderOpt,
+		}
+
+		content, err := d.queries.GetFolderContent(d.ctx, query)
+		if err != nil {
+			return nil, davError(d.ctx, "readdir", d.name, apperror.NewAppError(err, "davDir.Readdir:GetFolderContent"))
+		}
+
+		if !filesDone {
+			for _, file := range content.FilePage.Items {
+				infos = append(infos, newDavFileInfo(&media.ResolvePathRes{File: file}))
+			}
+			filesDone = !content.FilePage.HasMore
+			fileOpt.NextCursor = content.FilePage.NextCursor
+		}
+
+		if !foldersDone {
+			for _, folder := range content.FolderPage.Items {
+				infos = append(infos, newDavFileInfo(&media.ResolvePathRes{Folder: folder}))
+			}
+			foldersDone = !content.FolderPage.HasMore
+			folderOpt.NextCursor = content.FolderPage.NextCursor
+		}
+	}
+
+	return infos, nil
+}
+
+// davReadFile is an opened file, the content is opened on the first Read or Seek.
+// The webdav package opens every listed file to read its properties, which only need the info.
+type davReadFile struct {
+	ctx     context.Context
+	queries media.Queries
+	info    *media.FileInfo
+	file    io.ReadSeekCloser
+}
+
+func (f *davReadFile) open() error {
+	if f.file != nil {
+		return nil
+	}
+
+	query := &media.GetFileQuery{
+		OwnerID: common.GetProfileIDFromContext(f.ctx),
+		FileID:  f.info.ID,
+	}
+
+	res, err := f.queries.GetFile(f.ctx, query)
+	if err != nil {
+		return davError(f.ctx, "open", f.info.Name, apperror.NewAppError(err, "davReadFile.open:GetFile"))
+	}
+	f.file = res.File
+	return nil
+}
+
+func (f *davReadFile) Read(p []byte) (int, error) {
+	if err := f.open(); err != nil {
+		return 0, err
+	}
+	return f.file.Read(p)
+}
+
+func (f *davReadFile) Seek(offset int64, whence int) (int64, error) {
+	if err := f.open(); err != nil {
+		return 0, err
+	}
+	return f.file.Seek(offset, whence)
+}
+
+func (f *davReadFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+func (f *davReadFile) Readdir(count int) ([]fs.FileInfo, error) { return nil, fs.ErrInvalid }
+func (f *davReadFile) Write(p []byte) (int, error)              { return 0, fs.ErrInvalid }
+func (f *davReadFile) Stat() (fs.FileInfo, error) {
+	return newDavFileInfo(&media.ResolvePathRes{File: f.info}), nil
+}
+
+// davWriteFile spools the written content to a temp file, it is uploaded on Close.
+type davWriteFile struct {
+	ctx      context.Context
+	commands media.Commands
+	folderID *string
+	name     string
+	existing *media.FileInfo // Overwritten file, its content is replaced on Close
+	tmp      *os.File
+	size     int64
+	maxSize  int64
+}
+
+func (f *davWriteFile) Write(p []byte) (int, error) {
+	if f.size+int64(len(p)) > f.maxSize {
+		err := fmt.Errorf("%w: file larger than %d bytes", apperror.ErrCommonInvalidValue, f.maxSize)
+		return 0, davError(f.ctx, "write", f.name, apperror.NewAppError(err, "davWriteFile.Write:maxSize"))
+	}
+
+	n, err := f.tmp.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, &fs.PathError{Op: "write", Path: f.name, Err: err}
+	}
+	return n, nil
+}
+
+// Close uploads the content. An overwritten file keeps its ID, only its content is replaced.
+func (f *davWriteFile) Close() (err error) {
+	defer func() {
+		_ = f.tmp.Close()
+		_ = os.Remove(f.tmp.Name())
+	}()
+
+	if _, err := f.tmp.Seek(0, io.SeekStart); err != nil {
+		return apperror.NewAppError(err, "davWriteFile.Close:Seek")
+	}
+
+	ownerID := common.GetProfileIDFromContext(f.ctx)
+	if f.existing != nil {
+		cmd := &media.ReplaceFileContentCommand{
+			OwnerID: ownerID,
+			FileID:  f.existing.ID,
+			Size:    f.size,
+			File:    f.tmp,
+		}
+
+		_, err = f.commands.ReplaceFileContent(f.ctx, cmd)
+		if err != nil {
+			return davError(f.ctx, "close", f.name, apperror.NewAppError(err, "davWriteFile.Close:ReplaceFileContent"))
+		}
+		return nil
+	}
+
+	cmd := &media.UploadFileCommand{
+		OwnerID:  ownerID,
+		FolderID: f.folderID,
+		Name:     f.name,
+		Size:     f.size,
+		File:     f.tmp,
+	}
+
+	_, err = f.commands.UploadFile(f.ctx, cmd)
+	if err != nil {
+		return davError(f.ctx, "close", f.name, apperror.NewAppError(err, "davWriteFile.Close:UploadFile"))
+	}
+	return nil
+}
+
+func (f *davWriteFile) Read(p []byte) (int, error)                   { return 0, fs.ErrInvalid }
+func (f *davWriteFile) Seek(offset int64, whence int) (int64, error) { return 0, fs.ErrInvalid }
+func (f *davWriteFile) Readdir(count int) ([]fs.FileInfo, error)     { return nil, fs.ErrInvalid }
+func (f *davWriteFile) Stat() (fs.FileInfo, error) {
+	return &davFileInfo{name: f.name, size: f.size, modTime: time.Now().UTC()}, nil
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"skyvault/internal/api/helper"
+	"skyvault/internal/api/helper/dtos"
+	"skyvault/internal/domain/webhook"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/common"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jinzhu/copier"
+)
+
+const (
+	urlParamWebhookID  = "webhook-id"
+	urlParamDeliveryID = "delivery-id"
+)
+
+type WebhookAPI struct {
+	api      *API
+	commands webhook.Commands
+	queries  webhook.Queries
+}
+
+func NewWebhookAPI(a *API, commands webhook.Commands, queries webhook.Queries) *WebhookAPI {
+	return &WebhookAPI{
+		api:      a,
+		commands: commands,
+		queries:  queries,
+	}
+}
+
+func (a *WebhookAPI) InitRoutes() *WebhookAPI {
+	pvtRouter := a.api.v1Pvt
+	pvtRouter.Route("/webhooks", func(r chi.Router) {
+		r.Get("/", a.GetWebhooks)
+		r.Post("/", a.CreateWebhook)
+
+		r.Route(fmt.Sprintf("/{%s}", urlParamWebhookID), func(r chi.Router) {
+			r.Get("/", a.GetWebhook)
+			r.Put("/", a.UpdateWebhook)
+			r.Delete("/", a.DeleteWebhook)
+			r.Post("/rotate-secret", a.RotateWebhookSecret)
+			r.Post("/ping", a.PingWebhook)
+			r.Get("/deliveries", a.GetDeliveries)
+			r.Post(fmt.Sprintf("/deliveries/{%s}/redeliver", urlParamDeliveryID), a.Redeliver)
+		})
+	})
+
+	return a
+}
+
+type webhookReq struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"` // e.g. ["file.created", "share.*"], empty for all the events
+	FolderID   *string  `json:"folderId"`   // Only the events of the folder and its descendants
+	Enabled    *bool    `json:"enabled"`    // true if not set
+}
+
+func (a *WebhookAPI) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	query := &webhook.GetWebhooksQuery{
+		OwnerID: common.GetProfileIDFromContext(r.Context()),
+	}
+
+	webhooks, err := a.queries.GetWebhooks(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.GetWebhooks:GetWebhooks"))
+		return
+	}
+
+	dto := []*dtos.GetWebhook{}
+	err = copier.Copy(&dto, &webhooks)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.GetWebhooks:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, dto)
+}
+
+func (a *WebhookAPI) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "webhookAPI.CreateWebhook:DecodeBody"))
+		return
+	}
+
+	cmd := &webhook.CreateWebhookCommand{
+		OwnerID:    common.GetProfileIDFromContext(r.Context()),
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		FolderID:   req.FolderID,
+	}
+
+	res, err := a.commands.CreateWebhook(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.CreateWebhook:CreateWebhook"))
+		return
+	}
+
+	a.respondWebhookSecret(w, r, http.StatusCreated, res)
+}
+
+func (a *WebhookAPI) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	query := &webhook.GetWebhookQuery{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		WebhookID: chi.URLParam(r, urlParamWebhookID),
+	}
+
+	res, err := a.queries.GetWebhook(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.GetWebhook:GetWebhook"))
+		return
+	}
+
+	var dto dtos.GetWebhook
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.GetWebhook:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+// UpdateWebhook replaces the settings of the webhook, the secret is kept.
+func (a *WebhookAPI) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "webhookAPI.UpdateWebhook:DecodeBody"))
+		return
+	}
+
+	cmd := &webhook.UpdateWebhookCommand{
+		OwnerID:    common.GetProfileIDFromContext(r.Context()),
+		WebhookID:  chi.URLParam(r, urlParamWebhookID),
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		FolderID:   req.FolderID,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+
+	res, err := a.commands.UpdateWebhook(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.UpdateWebhook:UpdateWebhook"))
+		return
+	}
+
+	var dto dtos.GetWebhook
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.UpdateWebhook:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+func (a *WebhookAPI) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	cmd := &webhook.DeleteWebhookCommand{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		WebhookID: chi.URLParam(r, urlParamWebhookID),
+	}
+
+	err := a.commands.DeleteWebhook(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.DeleteWebhook:DeleteWebhook"))
+		return
+	}
+
+	helper.RespondEmpty(w, http.StatusNoContent)
+}
+
+func (a *WebhookAPI) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	cmd := &webhook.RotateWebhookSecretCommand{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		WebhookID: chi.URLParam(r, urlParamWebhookID),
+	}
+
+	res, err := a.commands.RotateWebhookSecret(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.RotateWebhookSecret:RotateWebhookSecret"))
+		return
+	}
+
+	a.respondWebhookSecret(w, r, http.StatusOK, res)
+}
+
+func (a *WebhookAPI) respondWebhookSecret(w http.ResponseWriter, r *http.Request, status int, res *webhook.Webhook) {
+	var dto dtos.GetWebhookSecret
+	err := copier.Copy(&dto.GetWebhook, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.respondWebhookSecret:Copy"))
+		return
+	}
+	dto.Secret = res.Secret
+
+	helper.RespondJSON(w, status, &dto)
+}
+
+//--------------------------------
+// Deliveries
+//--------------------------------
+
+// PingWebhook queues a test delivery, see its result in the deliveries.
+func (a *WebhookAPI) PingWebhook(w http.ResponseWriter, r *http.Request) {
+	cmd := &webhook.PingWebhookCommand{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		WebhookID: chi.URLParam(r, urlParamWebhookID),
+	}
+
+	res, err := a.commands.PingWebhook(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.PingWebhook:PingWebhook"))
+		return
+	}
+
+	a.respondDelivery(w, r, res)
+}
+
+func (a *WebhookAPI) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	pagingOpt, err := pagingOptionsFromQuery(r, "")
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.GetDeliveries:pagingOptionsFromQuery"))
+		return
+	}
+
+	query := &webhook.GetDeliveriesQuery{
+		OwnerID:   common.GetProfileIDFromContext(r.Context()),
+		WebhookID: chi.URLParam(r, urlParamWebhookID),
+		PagingOpt: pagingOpt,
+	}
+
+	res, err := a.queries.GetDeliveries(r.Context(), query)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.GetDeliveries:GetDeliveries"))
+		return
+	}
+
+	var dto dtos.GetWebhookDeliveries
+	err = copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.GetDeliveries:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusOK, &dto)
+}
+
+// Redeliver queues a new delivery of the event, the delivery in the URL is kept in the log as is.
+func (a *WebhookAPI) Redeliver(w http.ResponseWriter, r *http.Request) {
+	cmd := &webhook.RedeliverCommand{
+		OwnerID:    common.GetProfileIDFromContext(r.Context()),
+		DeliveryID: chi.URLParam(r, urlParamDeliveryID),
+	}
+
+	res, err := a.commands.Redeliver(r.Context(), cmd)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.Redeliver:Redeliver"))
+		return
+	}
+
+	a.respondDelivery(w, r, res)
+}
+
+func (a *WebhookAPI) respondDelivery(w http.ResponseWriter, r *http.Request, res *webhook.Delivery) {
+	var dto dtos.GetWebhookDelivery
+	err := copier.Copy(&dto, res)
+	if err != nil {
+		helper.RespondError(w, r, apperror.NewAppError(err, "webhookAPI.respondDelivery:Copy"))
+		return
+	}
+
+	helper.RespondJSON(w, http.StatusAccepted, &dto)
+}
@@ -3,8 +3,12 @@ package bootstrap
 import (
 	"skyvault/internal/api"
 	"skyvault/internal/domain/auth"
+	"skyvault/internal/domain/event"
 	"skyvault/internal/domain/media"
+	"skyvault/internal/domain/outbox"
 	"skyvault/internal/domain/profile"
+	"skyvault/internal/domain/sharing"
+	"skyvault/internal/domain/webhook"
 	"skyvault/internal/infrastructure"
 	"skyvault/internal/workflows"
 	"skyvault/pkg/appconfig"
@@ -22,32 +26,79 @@ func InitAPI(app *appconfig.App, infra *infrastructure.Infrastructure) *api.API
 	proCmdRoot := profile.NewCommandsSanitizer(proCmd)
 	proQrs := profile.NewQueryHandlers(infra.Repository.Profile)
 	proQrsRoot := profile.NewQueriesSanitizer(proQrs)
-	authCmd := auth.NewCommandHandlers(infra.Repository.Auth, infra.Auth)
+	authCmd := auth.NewCommandHandlers(app, infra.Repository.Auth, infra.Auth)
 	authCmdRoot := auth.NewCommandsSanitizer(authCmd)
 	authQrs := auth.NewQueryHandlers(infra.Repository.Auth, infra.Auth)
 	authQrsRoot := auth.NewQueriesSanitizer(authQrs)
 	signUpFlow := workflows.NewSignUpFlow(app, authCmdRoot, infra.Repository.Auth, proCmdRoot, infra.Repository.Profile)
 	signInFlow := workflows.NewSignInFlow(authCmdRoot, authQrsRoot, proCmdRoot, proQrsRoot)
-	mediaCmd := media.NewCommandHandlers(app, infra.Repository.Media, infra.Storage.LocalStorage)
+	mediaCmd := media.NewCommandHandlers(app, infra.Repository.Media, infra.Storage.LocalStorage, infra.Scanner.Scanner, infra.Jobs)
 	mediaCmdRoot := media.NewCommandsSanitizer(mediaCmd)
-	mediaQrs := media.NewQueryHandlers(infra.Repository.Media, infra.Storage.LocalStorage)
+	mediaQrs := media.NewQueryHandlers(app, infra.Repository.Media, infra.Storage.LocalStorage)
 	mediaQrsRoot := media.NewQueriesSanitizer(mediaQrs)
+	sharingCmd := sharing.NewCommandHandlers(app, infra.Repository.Sharing, infra.Repository.Media)
+	sharingCmdRoot := sharing.NewCommandsSanitizer(sharingCmd)
+	sharingQrs := sharing.NewQueryHandlers(infra.Repository.Sharing)
+	sharingQrsRoot := sharing.NewQueriesSanitizer(sharingQrs)
+	eventCmd := event.NewCommandHandlers(infra.Repository.Event)
+	eventCmdRoot := event.NewCommandsSanitizer(eventCmd)
+	eventQrs := event.NewQueryHandlers(infra.Repository.Event)
+	eventQrsRoot := event.NewQueriesSanitizer(eventQrs)
+	shareDownloadFlow := workflows.NewShareDownloadFlow(sharingCmdRoot, sharingQrsRoot, mediaQrsRoot, eventCmdRoot)
+	webhookCmd := webhook.NewCommandHandlers(infra.Repository.Webhook, infra.Repository.Media, infra.Webhooks.Sender)
+	webhookCmdRoot := webhook.NewCommandsSanitizer(webhookCmd)
+	webhookQrs := webhook.NewQueryHandlers(infra.Repository.Webhook)
+	webhookQrsRoot := webhook.NewQueriesSanitizer(webhookQrs)
 
 	// Init API
-	apiServer := api.NewAPI(app).InitRoutes(infra)
-	apiServer.Auth = api.NewAuthAPI(apiServer, signUpFlow, signInFlow).InitRoutes()
+	apiServer := api.NewAPI(app).InitRoutes(infra, authQrsRoot, authCmdRoot)
+	apiServer.Auth = api.NewAuthAPI(apiServer, signUpFlow, signInFlow, authCmdRoot, authQrsRoot).InitRoutes()
 	apiServer.Media = api.NewMediaAPI(apiServer, app, mediaCmdRoot, mediaQrsRoot).InitRoutes()
 	apiServer.Profile = api.NewProfileAPI(apiServer, proCmdRoot, proQrsRoot).InitRoutes()
+	apiServer.Sharing = api.NewSharingAPI(apiServer, shareDownloadFlow).InitRoutes()
 	apiServer.System = api.NewSystemAPI(apiServer).InitRoutes()
+	apiServer.WebDAV = api.NewWebDAVAPI(apiServer, app, infra.Auth.JWT, authQrsRoot, authCmdRoot, mediaCmdRoot, mediaQrsRoot).InitRoutes()
+	apiServer.Event = api.NewEventAPI(apiServer, eventQrsRoot, infra.Events.Notifier, authQrsRoot).InitRoutes()
+	apiServer.Webhook = api.NewWebhookAPI(apiServer, webhookCmdRoot, webhookQrsRoot).InitRoutes()
 
 	return apiServer
 }
 
 // InitSignUpFlow initializes and returns the signup workflow
 func InitSignUpFlow(app *appconfig.App, infra *infrastructure.Infrastructure) *workflows.SignUpFlow {
-	authCmd := auth.NewCommandHandlers(infra.Repository.Auth, infra.Auth)
+	authCmd := auth.NewCommandHandlers(app, infra.Repository.Auth, infra.Auth)
 	authCmdRoot := auth.NewCommandsSanitizer(authCmd)
 	proCmd := profile.NewCommandHandlers(infra.Repository.Profile)
 	proCmdRoot := profile.NewCommandsSanitizer(proCmd)
 	return workflows.NewSignUpFlow(app, authCmdRoot, infra.Repository.Auth, proCmdRoot, infra.Repository.Profile)
 }
+
+// InitAuthCommands initializes and returns the auth commands
+func InitAuthCommands(app *appconfig.App, infra *infrastructure.Infrastructure) auth.Commands {
+	authCmd := auth.NewCommandHandlers(app, infra.Repository.Auth, infra.Auth)
+	return auth.NewCommandsSanitizer(authCmd)
+}
+
+// InitMediaCommands initializes and returns the media commands
+func InitMediaCommands(app *appconfig.App, infra *infrastructure.Infrastructure) media.Commands {
+	mediaCmd := media.NewCommandHandlers(app, infra.Repository.Media, infra.Storage.LocalStorage, infra.Scanner.Scanner, infra.Jobs)
+	return media.NewCommandsSanitizer(mediaCmd)
+}
+
+// InitEventCommands initializes and returns the event commands
+func InitEventCommands(infra *infrastructure.Infrastructure) event.Commands {
+	eventCmd := event.NewCommandHandlers(infra.Repository.Event)
+	return event.NewCommandsSanitizer(eventCmd)
+}
+
+// InitWebhookCommands initializes and returns the webhook commands
+func InitWebhookCommands(infra *infrastructure.Infrastructure) webhook.Commands {
+	webhookCmd := webhook.NewCommandHandlers(infra.Repository.Webhook, infra.Repository.Media, infra.Webhooks.Sender)
+	return webhook.NewCommandsSanitizer(webhookCmd)
+}
+
+// InitOutboxCommands initializes and returns the outbox commands, publishing to the event bus
+func InitOutboxCommands(infra *infrastructure.Infrastructure) outbox.Commands {
+	outboxCmd := outbox.NewCommandHandlers(infra.Repository.Outbox, infra.Bus)
+	return outbox.NewCommandsSanitizer(outboxCmd)
+}
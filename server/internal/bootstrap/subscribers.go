@@ -0,0 +1,105 @@
+package bootstrap
+
+import (
+	"context"
+	"skyvault/internal/domain/auth"
+	"skyvault/internal/domain/event"
+	"skyvault/internal/domain/media"
+	"skyvault/internal/domain/profile"
+	"skyvault/internal/domain/sharing"
+	"skyvault/internal/infrastructure"
+	"skyvault/pkg/appconfig"
+	"skyvault/pkg/applog"
+	"skyvault/pkg/eventbus"
+)
+
+// InitSubscribers subscribes the reactions to the domain events, before the outbox is dispatched.
+// The events are delivered at least once, the subscribers must be idempotent.
+//
+// The changes of the files, folders and shares the owners see (change log, event stream, webhooks) aren't subscribed here:
+// the database triggers record them in the transaction of the change, whatever the write path, with the sequence of the owner.
+// The bus carries the rest, the audit trail and the events the triggers can't see, e.g. the quarantine of a file.
+func InitSubscribers(app *appconfig.App, infra *infrastructure.Infrastructure) {
+	initAuditLog(app.Logger, infra.Bus)
+	initOwnerEvents(infra.Bus, InitEventCommands(infra))
+}
+
+// initOwnerEvents records the events the owners are told about, e.g. on their event stream.
+// A redelivered domain event records a duplicate, which only repeats the notice.
+func initOwnerEvents(bus *eventbus.Bus, eventCommands event.Commands) {
+	eventbus.Subscribe(bus, "owner-events", func(ctx context.Context, e media.FileQuarantined) error {
+		return eventCommands.AddEvent(ctx, &event.AddEventCommand{
+			OwnerID: e.OwnerID,
+			Type:    event.TypeFileQuarantined,
+			Payload: map[string]any{
+				"fileId":    e.FileID,
+				"name":      e.Name,
+				"signature": e.Signature,
+			},
+		})
+	})
+}
+
+// initAuditLog logs the security relevant events, the logs are the audit trail.
+func initAuditLog(logger applog.Logger, bus *eventbus.Bus) {
+	audit := func(eventType string) applog.LogEvent {
+		return logger.Info().Str("audit", eventType)
+	}
+
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.SignedUp) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Str("provider", string(e.Provider)).Msg("signed up")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.SignedIn) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Str("provider", string(e.Provider)).Str("session_id", e.SessionID).Msg("signed in")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.SignedOut) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Str("session_id", e.SessionID).Msg("signed out")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.SessionRevoked) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Str("session_id", e.SessionID).Str("reason", e.Reason).Msg("session revoked")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.PasswordChanged) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Msg("password changed")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.Deleted) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Str("auth_id", e.AuthID).Msg("sign-in method deleted")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.AppPasswordCreated) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Str("app_password_id", e.AppPasswordID).Str("name", e.Name).Msg("app password created")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e auth.AppPasswordDeleted) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Str("app_password_id", e.AppPasswordID).Msg("app password deleted")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e profile.Deleted) error {
+		audit(e.EventType()).Str("profile_id", e.ProfileID).Msg("profile deleted")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e sharing.ShareCreated) error {
+		audit(e.EventType()).Str("profile_id", e.OwnerID).Str("share_id", e.ShareID).Int("recipients", len(e.RecipientIDs)).Msg("share created")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e sharing.ShareUpdated) error {
+		audit(e.EventType()).Str("profile_id", e.OwnerID).Str("share_id", e.ShareID).Msg("share updated")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e sharing.ShareRecipientAdded) error {
+		audit(e.EventType()).Str("profile_id", e.OwnerID).Str("share_id", e.ShareID).Str("recipient_id", e.RecipientID).Msg("share recipient added")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e sharing.ShareRecipientRemoved) error {
+		audit(e.EventType()).Str("profile_id", e.OwnerID).Str("share_id", e.ShareID).Str("recipient_id", e.RecipientID).Msg("share recipient removed")
+		return nil
+	})
+	eventbus.Subscribe(bus, "audit", func(ctx context.Context, e sharing.ShareDeleted) error {
+		audit(e.EventType()).Str("profile_id", e.OwnerID).Str("share_id", e.ShareID).Msg("share deleted")
+		return nil
+	})
+}
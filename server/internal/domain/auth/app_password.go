@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/utils"
+	"strings"
+	"time"
+)
+
+const appPasswordSecretBytes = 20
+
+// AppPassword signs in clients that can't use tokens, e.g. WebDAV drives, with the email and
+// the secret instead of the account password. Only the hash of the secret is kept.
+type AppPassword struct {
+	ID         string
+	ProfileID  string
+	Name       string // To tell the clients apart, e.g. "Laptop"
+	SecretHash string
+	CreatedAt  time.Time
+}
+
+// NewAppPassword returns the app password and its secret. The secret is shown to the user only once.
+func NewAppPassword(profileID, name string) (*AppPassword, string, error) {
+	b := make([]byte, appPasswordSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.NewAppPassword:Read")
+	}
+	secret := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+
+	id, err := utils.ID()
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.NewAppPassword:ID")
+	}
+
+	return &AppPassword{
+		ID:         id,
+		ProfileID:  profileID,
+		Name:       name,
+		SecretHash: HashAppPasswordSecret(secret),
+		CreatedAt:  time.Now().UTC(),
+	}, secret, nil
+}
+
+// HashAppPasswordSecret returns the hash the secret is looked up by.
+// The secret is random, so unlike the account passwords it needs no salt nor a slow hash.
+func HashAppPasswordSecret(secret string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(secret))))
+	return hex.EncodeToString(sum[:])
+}
+
+// App Errors:
+// - ErrCommonNoAccess
+func (p *AppPassword) ValidateAccess(accessedByID string) error {
+	if p.ProfileID != accessedByID {
+		return apperror.NewAppError(apperror.ErrCommonNoAccess, "auth.AppPassword.ValidateAccess").WithMetadata("accessed_by_id", accessedByID).WithMetadata("profile_id", p.ProfileID)
+	}
+	return nil
+}
@@ -65,6 +65,25 @@ func NewAuth(profileID string, provider Provider, providerUserID string, passwor
 	}, nil
 }
 
+// ChangePassword replaces the password of the email provider.
+//
+// App Errors:
+// - ErrAuthWrongProvider
+func (a *Auth) ChangePassword(password string) error {
+	if a.Provider != ProviderEmail {
+		return apperror.NewAppError(apperror.ErrAuthWrongProvider, "auth.Auth.ChangePassword:Provider")
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.Auth.ChangePassword:HashPassword")
+	}
+
+	a.PasswordHash = &hash
+	a.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
 // App Errors:
 // - ErrCommonNoAccess
 func (a *Auth) ValidateAccess(accessedByID string) error {
@@ -1,7 +1,11 @@
 package auth
 
 import (
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/utils"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -120,3 +124,94 @@ func TestAuth_ValidateAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAppPassword(t *testing.T) {
+	appPassword, secret, err := NewAppPassword("1", "Laptop")
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, appPassword.ID)
+	assert.Equal(t, "1", appPassword.ProfileID)
+	assert.Equal(t, "Laptop", appPassword.Name)
+	assert.Len(t, secret, 32)
+	assert.NotContains(t, appPassword.SecretHash, secret, "should only keep the hash of the secret")
+
+	// The secret is found by its hash, whatever the case and spaces it is typed with
+	assert.Equal(t, appPassword.SecretHash, HashAppPasswordSecret(secret))
+	assert.Equal(t, appPassword.SecretHash, HashAppPasswordSecret(" "+strings.ToUpper(secret)+" "))
+
+	other, otherSecret, err := NewAppPassword("1", "Phone")
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, otherSecret)
+	assert.NotEqual(t, appPassword.SecretHash, other.SecretHash)
+
+	require.NoError(t, appPassword.ValidateAccess("1"))
+	require.Error(t, appPassword.ValidateAccess("2"))
+}
+
+func TestSession_Refresh(t *testing.T) {
+	session, refreshToken, err := NewSession("1", ProviderEmail, SessionClient{}, time.Hour)
+	require.NoError(t, err)
+	assert.NotContains(t, refreshToken, session.RefreshTokenHash, "should only keep the hash of the secret")
+
+	sessionID, secret, err := ParseRefreshToken(refreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, sessionID)
+	require.NoError(t, session.ValidateRefresh(secret, time.Now()))
+
+	// Once rotated, the previous token is a reuse
+	rotated, err := session.Rotate(time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, refreshToken, rotated)
+	assert.ErrorIs(t, session.ValidateRefresh(secret, time.Now()), apperror.ErrAuthRefreshTokenReused)
+
+	// An unknown secret, e.g. from someone who only knows the public session ID, is not a reuse
+	assert.ErrorIs(t, session.ValidateRefresh("garbage", time.Now()), apperror.ErrAuthInvalidToken)
+
+	_, rotatedSecret, err := ParseRefreshToken(rotated)
+	require.NoError(t, err)
+	require.NoError(t, session.ValidateRefresh(rotatedSecret, time.Now()))
+	assert.ErrorIs(t, session.ValidateRefresh(rotatedSecret, time.Now().Add(2*time.Hour)), apperror.ErrAuthTokenExpired)
+
+	session.Revoke(time.Now())
+	assert.ErrorIs(t, session.ValidateActive(), apperror.ErrAuthSessionRevoked)
+	assert.ErrorIs(t, session.ValidateRefresh(rotatedSecret, time.Now()), apperror.ErrAuthSessionRevoked)
+
+	require.NoError(t, session.ValidateAccess("1"))
+	require.Error(t, session.ValidateAccess("2"))
+}
+
+func TestNewSession_Client(t *testing.T) {
+	client := SessionClient{UserAgent: strings.Repeat("a", 1000), IPAddress: "192.0.2.1"}
+	session, _, err := NewSession("1", ProviderEmail, client, time.Hour)
+	require.NoError(t, err)
+
+	assert.Len(t, session.UserAgent, maxUserAgentLen, "should truncate the user agent")
+	assert.Equal(t, "192.0.2.1", session.IPAddress)
+
+	// Seen when signed in, touched again only once the last seen time is outdated
+	assert.False(t, session.NeedsTouch(session.LastSeenAt.Add(time.Minute)))
+	assert.True(t, session.NeedsTouch(session.LastSeenAt.Add(lastSeenInterval)))
+}
+
+func TestAuth_ChangePassword(t *testing.T) {
+	au, err := NewAuth("1", ProviderEmail, "test@example.com", utils.Ptr("password123"))
+	require.NoError(t, err)
+	previousHash := *au.PasswordHash
+
+	require.NoError(t, au.ChangePassword("password456"))
+	assert.NotEqual(t, previousHash, *au.PasswordHash)
+
+	ok, err := utils.SamePassword(*au.PasswordHash, "password456")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	au.Provider = ProviderOIDC
+	assert.ErrorIs(t, au.ChangePassword("password789"), apperror.ErrAuthWrongProvider)
+}
+
+func TestParseRefreshToken(t *testing.T) {
+	for _, token := range []string{"", "secret", ".secret", "not-a-uuid.secret", "0192f5e4-8a3b-7c2d-9e1f-0a1b2c3d4e5f."} {
+		_, _, err := ParseRefreshToken(token)
+		assert.ErrorIs(t, err, apperror.ErrAuthInvalidToken, "token %q", token)
+	}
+}
@@ -6,6 +6,9 @@ import (
 
 type Claims interface {
 	GetProfileID() string
+
+	// GetSessionID returns the session the token was issued for, it is rejected once the session is revoked.
+	GetSessionID() string
 }
 
 // CredKey is a key for credentials map.
@@ -19,7 +22,9 @@ const (
 
 // Authenticator is implemented by each Provider.
 type Authenticator interface {
-	GenerateToken(ctx context.Context, profileID string) (string, error)
+	// GenerateToken returns a short-lived access token of the session, the client renews it with the
+	// refresh token of the session.
+	GenerateToken(ctx context.Context, profileID, sessionID string) (string, error)
 
 	// App Errors:
 	// - ErrAuthInvalidToken
@@ -2,19 +2,26 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
+	"skyvault/pkg/eventbus"
+	"slices"
+	"time"
 )
 
 var _ Commands = (*CommandHandlers)(nil)
 
 type CommandHandlers struct {
+	app                  *appconfig.App
 	repository           Repository
 	authenticatorFactory AuthenticatorFactory
 }
 
-func NewCommandHandlers(repository Repository, authenticatorFactory AuthenticatorFactory) Commands {
+func NewCommandHandlers(app *appconfig.App, repository Repository, authenticatorFactory AuthenticatorFactory) Commands {
 	return &CommandHandlers{
+		app:                  app,
 		repository:           repository,
 		authenticatorFactory: authenticatorFactory,
 	}
@@ -22,44 +29,61 @@ func NewCommandHandlers(repository Repository, authenticatorFactory Authenticato
 
 func (h *CommandHandlers) WithTxRepository(ctx context.Context, repository Repository) Commands {
 	return &CommandHandlers{
+		app:                  h.app,
 		repository:           repository,
 		authenticatorFactory: h.authenticatorFactory,
 	}
 }
 
-func (h *CommandHandlers) SignUp(ctx context.Context, cmd *SignUpCommand) (token string, err error) {
+func (h *CommandHandlers) SignUp(ctx context.Context, cmd *SignUpCommand) (*Tokens, error) {
 	au, err := NewAuth(cmd.ProfileID, cmd.Provider, cmd.ProviderUserID, cmd.Password)
 	if err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandHandlers.SignUp:NewAuth")
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignUp:NewAuth")
 	}
 
 	authenticator, err := h.authenticatorFactory.GetAuthenticator(au.Provider)
 	if err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandHandlers.SignUp:GetAuthenticator")
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignUp:GetAuthenticator")
+	}
+
+	session, refreshToken, err := NewSession(au.ProfileID, au.Provider, cmd.Client, refreshTokenTTL(h.app.Config.Auth.RefreshTokenTimeoutDays))
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignUp:NewSession")
 	}
 
 	au, err = h.repository.Create(ctx, au)
 	if err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandHandlers.SignUp:Create")
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignUp:Create")
+	}
+
+	// The repository is the one of the SignUpFlow transaction, the session and the event are committed with the profile
+	session, err = h.repository.CreateSession(ctx, session)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignUp:CreateSession")
+	}
+
+	err = h.repository.AddDomainEvents(ctx, SignedUp{ProfileID: au.ProfileID, Provider: au.Provider})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignUp:AddDomainEvents")
 	}
 
-	token, err = authenticator.GenerateToken(ctx, au.ProfileID)
+	accessToken, err := authenticator.GenerateToken(ctx, au.ProfileID, session.ID)
 	if err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandHandlers.SignUp:GenerateToken")
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignUp:GenerateToken")
 	}
 
-	return token, nil
+	return &Tokens{AccessToken: accessToken, RefreshToken: refreshToken, SessionID: session.ID}, nil
 }
 
-func (h *CommandHandlers) SignIn(ctx context.Context, cmd *SignInCommand) (token string, err error) {
+func (h *CommandHandlers) SignIn(ctx context.Context, cmd *SignInCommand) (*Tokens, error) {
 	// TODO: Add support for other providers
 	if cmd.Provider != ProviderEmail {
-		return "", apperror.NewAppError(apperror.ErrAuthWrongProvider, "auth.CommandHandlers.SignIn")
+		return nil, apperror.NewAppError(apperror.ErrAuthWrongProvider, "auth.CommandHandlers.SignIn")
 	}
 
 	authenticator, err := h.authenticatorFactory.GetAuthenticator(cmd.Provider)
 	if err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandHandlers.SignIn:GetAuthenticator")
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:GetAuthenticator")
 	}
 
 	// Credentials for email provider
@@ -70,15 +94,314 @@ func (h *CommandHandlers) SignIn(ctx context.Context, cmd *SignInCommand) (token
 
 	err = authenticator.ValidateCredentials(ctx, credentials)
 	if err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandHandlers.SignIn:ValidateCredentials")
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:ValidateCredentials")
+	}
+
+	session, refreshToken, err := NewSession(cmd.ProfileID, cmd.Provider, cmd.Client, refreshTokenTTL(h.app.Config.Auth.RefreshTokenTimeoutDays))
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:NewSession")
+	}
+
+	accessToken, err := authenticator.GenerateToken(ctx, cmd.ProfileID, session.ID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:GenerateToken")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	session, err = repoTx.CreateSession(ctx, session)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:CreateSession")
+	}
+
+	err = repoTx.AddDomainEvents(ctx, SignedIn{ProfileID: cmd.ProfileID, Provider: cmd.Provider, SessionID: session.ID})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.SignIn:Commit")
+	}
+
+	return &Tokens{AccessToken: accessToken, RefreshToken: refreshToken, SessionID: session.ID}, nil
+}
+
+func (h *CommandHandlers) RefreshToken(ctx context.Context, cmd *RefreshTokenCommand) (*Tokens, error) {
+	sessionID, secret, err := ParseRefreshToken(cmd.RefreshToken)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.RefreshToken:ParseRefreshToken")
+	}
+
+	session, err := h.repository.GetSession(ctx, sessionID)
+	if errors.Is(err, apperror.ErrCommonNoData) {
+		// Purged after it expired or was revoked
+		return nil, apperror.NewAppError(apperror.ErrAuthInvalidToken, "auth.CommandHandlers.RefreshToken:GetSession")
+	}
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.RefreshToken:GetSession")
+	}
+
+	now := time.Now().UTC()
+	err = session.ValidateRefresh(secret, now)
+	if errors.Is(err, apperror.ErrAuthRefreshTokenReused) {
+		return nil, h.revokeReusedSession(ctx, session, now, err)
+	}
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.RefreshToken:ValidateRefresh")
+	}
+
+	authenticator, err := h.authenticatorFactory.GetAuthenticator(session.Provider)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.RefreshToken:GetAuthenticator")
+	}
+
+	refreshToken, err := session.Rotate(refreshTokenTTL(h.app.Config.Auth.RefreshTokenTimeoutDays))
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.RefreshToken:Rotate")
+	}
+
+	err = h.repository.RotateSession(ctx, session)
+	if errors.Is(err, apperror.ErrCommonNoData) {
+		// A concurrent refresh with the same token rotated it first, so the token was used twice
+		reusedErr := apperror.NewAppError(apperror.ErrAuthRefreshTokenReused, "auth.CommandHandlers.RefreshToken:RotateSession")
+		return nil, h.revokeReusedSession(ctx, session, now, reusedErr)
+	}
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.RefreshToken:RotateSession")
+	}
+
+	accessToken, err := authenticator.GenerateToken(ctx, session.ProfileID, session.ID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.CommandHandlers.RefreshToken:GenerateToken")
+	}
+
+	return &Tokens{AccessToken: accessToken, RefreshToken: refreshToken, SessionID: session.ID}, nil
+}
+
+// revokeReusedSession revokes the session of a reused refresh token, and returns reusedErr once revoked.
+// Either the client or whoever stole its token has the current one, so neither can be trusted.
+func (h *CommandHandlers) revokeReusedSession(ctx context.Context, session *Session, now time.Time, reusedErr error) error {
+	session.Revoke(now)
+
+	err := h.saveRevokedSession(ctx, session, SessionRevoked{ProfileID: session.ProfileID, SessionID: session.ID, Reason: RevokeReasonRefreshTokenReused})
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.revokeReusedSession:saveRevokedSession")
+	}
+
+	return apperror.NewAppError(reusedErr, "auth.CommandHandlers.revokeReusedSession")
+}
+
+// saveRevokedSession updates the revoked session with the event of its revocation.
+func (h *CommandHandlers) saveRevokedSession(ctx context.Context, session *Session, event eventbus.Event) error {
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.saveRevokedSession:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.UpdateSession(ctx, session)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.saveRevokedSession:UpdateSession")
+	}
+
+	err = repoTx.AddDomainEvents(ctx, event)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.saveRevokedSession:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.saveRevokedSession:Commit")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) Logout(ctx context.Context, cmd *LogoutCommand) error {
+	session, err := h.repository.GetSession(ctx, cmd.SessionID)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.Logout:GetSession")
+	}
+
+	err = session.ValidateAccess(cmd.ProfileID)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.Logout:ValidateAccess")
 	}
 
-	token, err = authenticator.GenerateToken(ctx, cmd.ProfileID)
+	// Signed out already, e.g. the request was retried
+	if session.RevokedAt != nil {
+		return nil
+	}
+	session.Revoke(time.Now().UTC())
+
+	err = h.saveRevokedSession(ctx, session, SignedOut{ProfileID: session.ProfileID, SessionID: session.ID})
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.Logout:saveRevokedSession")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) TouchSession(ctx context.Context, cmd *TouchSessionCommand) error {
+	err := h.repository.TouchSession(ctx, cmd.SessionID, time.Now().UTC())
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.TouchSession:TouchSession")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) RevokeSession(ctx context.Context, cmd *RevokeSessionCommand) error {
+	session, err := h.repository.GetSession(ctx, cmd.SessionID)
 	if err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandHandlers.SignIn:GenerateToken")
+		return apperror.NewAppError(err, "auth.CommandHandlers.RevokeSession:GetSession")
 	}
 
-	return token, nil
+	err = session.ValidateAccess(cmd.ProfileID)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.RevokeSession:ValidateAccess")
+	}
+
+	if session.RevokedAt != nil {
+		return nil
+	}
+	session.Revoke(time.Now().UTC())
+
+	err = h.saveRevokedSession(ctx, session, SessionRevoked{ProfileID: session.ProfileID, SessionID: session.ID, Reason: RevokeReasonRevokedByUser})
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.RevokeSession:saveRevokedSession")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) RevokeOtherSessions(ctx context.Context, cmd *RevokeOtherSessionsCommand) (int, error) {
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "auth.CommandHandlers.RevokeOtherSessions:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	revoked, err := revokeSessions(ctx, repoTx, cmd.ProfileID, cmd.CurrentSessionID, RevokeReasonRevokedByUser)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "auth.CommandHandlers.RevokeOtherSessions:revokeSessions")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, apperror.NewAppError(err, "auth.CommandHandlers.RevokeOtherSessions:Commit")
+	}
+
+	return revoked, nil
+}
+
+func (h *CommandHandlers) ChangePassword(ctx context.Context, cmd *ChangePasswordCommand) error {
+	auths, err := h.repository.GetByProfileID(ctx, cmd.ProfileID)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:GetByProfileID")
+	}
+
+	idx := slices.IndexFunc(auths, func(au *Auth) bool { return au.Provider == ProviderEmail })
+	if idx < 0 {
+		return apperror.NewAppError(apperror.ErrAuthWrongProvider, "auth.CommandHandlers.ChangePassword:ProviderEmail")
+	}
+	au := auths[idx]
+
+	authenticator, err := h.authenticatorFactory.GetAuthenticator(au.Provider)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:GetAuthenticator")
+	}
+
+	// Whoever has a session must still know the password to change it
+	credentials := map[CredKey]any{
+		CredKeyPasswordHash: au.PasswordHash,
+		CredKeyPassword:     &cmd.CurrentPassword,
+	}
+
+	err = authenticator.ValidateCredentials(ctx, credentials)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:ValidateCredentials")
+	}
+
+	err = au.ChangePassword(cmd.NewPassword)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:ChangePassword")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.Update(ctx, au)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:Update")
+	}
+
+	// The sessions may have been started by whoever learned the previous password
+	_, err = revokeSessions(ctx, repoTx, au.ProfileID, "", RevokeReasonPasswordChanged)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:revokeSessions")
+	}
+
+	err = repoTx.AddDomainEvents(ctx, PasswordChanged{ProfileID: au.ProfileID})
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.ChangePassword:Commit")
+	}
+
+	return nil
+}
+
+// revokeSessions revokes the active sessions of the profile but exceptSessionID, with their events,
+// through the repository of a transaction. Returns the number of revoked sessions.
+func revokeSessions(ctx context.Context, repoTx Repository, profileID, exceptSessionID, reason string) (int, error) {
+	sessions, err := repoTx.RevokeSessions(ctx, profileID, exceptSessionID, time.Now().UTC())
+	if err != nil {
+		return 0, apperror.NewAppError(err, "auth.revokeSessions:RevokeSessions")
+	}
+	if len(sessions) == 0 {
+		return 0, nil
+	}
+
+	events := make([]eventbus.Event, 0, len(sessions))
+	for _, session := range sessions {
+		events = append(events, SessionRevoked{ProfileID: session.ProfileID, SessionID: session.ID, Reason: reason})
+	}
+
+	err = repoTx.AddDomainEvents(ctx, events...)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "auth.revokeSessions:AddDomainEvents")
+	}
+
+	return len(sessions), nil
+}
+
+func (h *CommandHandlers) PurgeSessions(ctx context.Context, cmd *PurgeSessionsCommand) (int, error) {
+	count, err := h.repository.DeleteSessions(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, apperror.NewAppError(err, "auth.CommandHandlers.PurgeSessions:DeleteSessions")
+	}
+
+	return int(count), nil
 }
 
 func (h *CommandHandlers) Delete(ctx context.Context, cmd *DeleteCommand) error {
@@ -94,10 +417,97 @@ func (h *CommandHandlers) Delete(ctx context.Context, cmd *DeleteCommand) error
 		return apperror.NewAppError(err, "auth.CommandHandlers.Delete:ValidateAccess")
 	}
 
-	err = h.repository.Delete(ctx, cmd.ID)
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.Delete:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.Delete(ctx, cmd.ID)
 	if err != nil {
 		return apperror.NewAppError(err, "auth.CommandHandlers.Delete:Delete")
 	}
 
+	err = repoTx.AddDomainEvents(ctx, Deleted{ProfileID: au.ProfileID, AuthID: au.ID})
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.Delete:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.Delete:Commit")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) CreateAppPassword(ctx context.Context, cmd *CreateAppPasswordCommand) (*AppPassword, string, error) {
+	appPassword, secret, err := NewAppPassword(cmd.ProfileID, cmd.Name)
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.CommandHandlers.CreateAppPassword:NewAppPassword")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.CommandHandlers.CreateAppPassword:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	appPassword, err = repoTx.CreateAppPassword(ctx, appPassword)
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.CommandHandlers.CreateAppPassword:CreateAppPassword")
+	}
+
+	err = repoTx.AddDomainEvents(ctx, AppPasswordCreated{ProfileID: appPassword.ProfileID, AppPasswordID: appPassword.ID, Name: appPassword.Name})
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.CommandHandlers.CreateAppPassword:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.CommandHandlers.CreateAppPassword:Commit")
+	}
+
+	return appPassword, secret, nil
+}
+
+func (h *CommandHandlers) DeleteAppPassword(ctx context.Context, cmd *DeleteAppPasswordCommand) error {
+	appPassword, err := h.repository.GetAppPassword(ctx, cmd.AppPasswordID)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.DeleteAppPassword:GetAppPassword")
+	}
+
+	err = appPassword.ValidateAccess(cmd.ProfileID)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.DeleteAppPassword:ValidateAccess")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.DeleteAppPassword:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.DeleteAppPassword(ctx, appPassword.ID)
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.DeleteAppPassword:DeleteAppPassword")
+	}
+
+	err = repoTx.AddDomainEvents(ctx, AppPasswordDeleted{ProfileID: appPassword.ProfileID, AppPasswordID: appPassword.ID})
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.DeleteAppPassword:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "auth.CommandHandlers.DeleteAppPassword:Commit")
+	}
+
 	return nil
 }
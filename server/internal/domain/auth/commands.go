@@ -10,21 +10,90 @@ type Commands interface {
 	// WithTxRepository is to be used when the commands across domains(workflows) need to be executed in a transaction.
 	WithTxRepository(ctx context.Context, repository Repository) Commands
 
+	// SignUp returns the tokens of the first session of the profile.
+	//
 	// App Errors:
 	// - ErrCommonInvalidValue
 	// - ErrCommonDuplicateData
-	SignUp(ctx context.Context, cmd *SignUpCommand) (token string, err error)
+	SignUp(ctx context.Context, cmd *SignUpCommand) (*Tokens, error)
 
+	// SignIn starts a new session and returns its tokens.
+	//
 	// App Errors:
 	// - ErrAuthWrongProvider
 	// - ErrAuthInvalidCredentials
 	// - ErrCommonInvalidValue
 	// - ErrCommonNoData
-	SignIn(ctx context.Context, cmd *SignInCommand) (token string, err error)
+	SignIn(ctx context.Context, cmd *SignInCommand) (*Tokens, error)
+
+	// RefreshToken rotates the refresh token of the session and returns it with a new access token.
+	// The refresh token replaced by the last rotation revokes the session, it was probably stolen.
+	// Any other unknown token is only rejected.
+	//
+	// App Errors:
+	// - ErrAuthInvalidToken
+	// - ErrAuthTokenExpired
+	// - ErrAuthSessionRevoked
+	// - ErrAuthRefreshTokenReused
+	RefreshToken(ctx context.Context, cmd *RefreshTokenCommand) (*Tokens, error)
+
+	// Logout revokes the session, its access and refresh tokens are rejected from now on.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoAccess
+	// - ErrCommonNoData
+	Logout(ctx context.Context, cmd *LogoutCommand) error
+
+	// TouchSession records that the session was seen, see Session.NeedsTouch.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	TouchSession(ctx context.Context, cmd *TouchSessionCommand) error
+
+	// RevokeSession signs out a session of the profile, e.g. of a lost device.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoAccess
+	// - ErrCommonNoData
+	RevokeSession(ctx context.Context, cmd *RevokeSessionCommand) error
+
+	// RevokeOtherSessions signs out all the sessions of the profile but the current one.
+	// Returns the number of revoked sessions.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	RevokeOtherSessions(ctx context.Context, cmd *RevokeOtherSessionsCommand) (int, error)
+
+	// ChangePassword replaces the password of the email sign-in, and revokes all the sessions of the profile.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrAuthWrongProvider
+	// - ErrAuthInvalidCredentials
+	ChangePassword(ctx context.Context, cmd *ChangePasswordCommand) error
+
+	// PurgeSessions deletes the expired and the revoked sessions.
+	// Returns the number of deleted sessions.
+	PurgeSessions(ctx context.Context, cmd *PurgeSessionsCommand) (int, error)
 
 	// App Errors:
 	// - ErrCommonNoData
 	Delete(ctx context.Context, cmd *DeleteCommand) error
+
+	// CreateAppPassword returns the app password and its secret, the secret can't be read again.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	CreateAppPassword(ctx context.Context, cmd *CreateAppPasswordCommand) (appPassword *AppPassword, secret string, err error)
+
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoAccess
+	// - ErrCommonNoData
+	DeleteAppPassword(ctx context.Context, cmd *DeleteAppPasswordCommand) error
 }
 
 type SignUpCommand struct {
@@ -32,6 +101,7 @@ type SignUpCommand struct {
 	Provider       Provider
 	ProviderUserID string
 	Password       *string
+	Client         SessionClient
 }
 
 type SignInCommand struct {
@@ -39,8 +109,50 @@ type SignInCommand struct {
 	Provider     Provider
 	Password     *string
 	PasswordHash *string
+	Client       SessionClient
+}
+
+type RefreshTokenCommand struct {
+	RefreshToken string
+}
+
+type LogoutCommand struct {
+	ProfileID string
+	SessionID string
+}
+
+type TouchSessionCommand struct {
+	SessionID string
 }
 
+type RevokeSessionCommand struct {
+	ProfileID string
+	SessionID string
+}
+
+type RevokeOtherSessionsCommand struct {
+	ProfileID        string
+	CurrentSessionID string
+}
+
+type ChangePasswordCommand struct {
+	ProfileID       string
+	CurrentPassword string
+	NewPassword     string
+}
+
+type PurgeSessionsCommand struct{}
+
 type DeleteCommand struct {
 	ID string
 }
+
+type CreateAppPasswordCommand struct {
+	ProfileID string
+	Name      string
+}
+
+type DeleteAppPasswordCommand struct {
+	ProfileID     string
+	AppPasswordID string
+}
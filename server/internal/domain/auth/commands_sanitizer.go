@@ -37,22 +37,22 @@ func (s *CommandsSanitizer) WithTxRepository(ctx context.Context, repository Rep
 	}
 }
 
-func (s *CommandsSanitizer) SignUp(ctx context.Context, cmd *SignUpCommand) (token string, err error) {
+func (s *CommandsSanitizer) SignUp(ctx context.Context, cmd *SignUpCommand) (*Tokens, error) {
 	if p, err := validateProvider(cmd.Provider); err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandsSanitizer.SignUp:Provider")
+		return nil, apperror.NewAppError(err, "auth.CommandsSanitizer.SignUp:Provider")
 	} else {
 		cmd.Provider = p
 	}
 
 	if pUID, err := validate.Name(cmd.ProviderUserID); err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandsSanitizer.SignUp:ProviderUserID")
+		return nil, apperror.NewAppError(err, "auth.CommandsSanitizer.SignUp:ProviderUserID")
 	} else {
 		cmd.ProviderUserID = pUID
 	}
 
 	if cmd.Password != nil {
 		if p, err := validate.PasswordLen(*cmd.Password); err != nil {
-			return "", apperror.NewAppError(err, "auth.CommandsSanitizer.SignUp:Password")
+			return nil, apperror.NewAppError(err, "auth.CommandsSanitizer.SignUp:Password")
 		} else {
 			cmd.Password = &p
 		}
@@ -61,16 +61,16 @@ func (s *CommandsSanitizer) SignUp(ctx context.Context, cmd *SignUpCommand) (tok
 	return s.Commands.SignUp(ctx, cmd)
 }
 
-func (s *CommandsSanitizer) SignIn(ctx context.Context, cmd *SignInCommand) (token string, err error) {
+func (s *CommandsSanitizer) SignIn(ctx context.Context, cmd *SignInCommand) (*Tokens, error) {
 	if p, err := validateProvider(cmd.Provider); err != nil {
-		return "", apperror.NewAppError(err, "auth.CommandsSanitizer.SignIn:Provider")
+		return nil, apperror.NewAppError(err, "auth.CommandsSanitizer.SignIn:Provider")
 	} else {
 		cmd.Provider = p
 	}
 
 	if cmd.Password != nil {
 		if p, err := validate.PasswordLen(*cmd.Password); err != nil {
-			return "", apperror.NewAppError(err, "auth.CommandsSanitizer.SignIn:Password")
+			return nil, apperror.NewAppError(err, "auth.CommandsSanitizer.SignIn:Password")
 		} else {
 			cmd.Password = &p
 		}
@@ -78,3 +78,103 @@ func (s *CommandsSanitizer) SignIn(ctx context.Context, cmd *SignInCommand) (tok
 
 	return s.Commands.SignIn(ctx, cmd)
 }
+
+func (s *CommandsSanitizer) RefreshToken(ctx context.Context, cmd *RefreshTokenCommand) (*Tokens, error) {
+	cmd.RefreshToken = strings.TrimSpace(cmd.RefreshToken)
+	if cmd.RefreshToken == "" {
+		return nil, apperror.NewAppError(apperror.ErrAuthInvalidToken, "auth.CommandsSanitizer.RefreshToken:RefreshToken")
+	}
+
+	return s.Commands.RefreshToken(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) Logout(ctx context.Context, cmd *LogoutCommand) error {
+	if !validate.UUID(cmd.ProfileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.Logout:ProfileID")
+	}
+
+	if !validate.UUID(cmd.SessionID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.Logout:SessionID")
+	}
+
+	return s.Commands.Logout(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) TouchSession(ctx context.Context, cmd *TouchSessionCommand) error {
+	if !validate.UUID(cmd.SessionID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.TouchSession:SessionID")
+	}
+
+	return s.Commands.TouchSession(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) RevokeSession(ctx context.Context, cmd *RevokeSessionCommand) error {
+	if !validate.UUID(cmd.ProfileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.RevokeSession:ProfileID")
+	}
+
+	if !validate.UUID(cmd.SessionID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.RevokeSession:SessionID")
+	}
+
+	return s.Commands.RevokeSession(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) RevokeOtherSessions(ctx context.Context, cmd *RevokeOtherSessionsCommand) (int, error) {
+	if !validate.UUID(cmd.ProfileID) {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.RevokeOtherSessions:ProfileID")
+	}
+
+	// The current session is kept, without one all of them would be revoked
+	if !validate.UUID(cmd.CurrentSessionID) {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.RevokeOtherSessions:CurrentSessionID")
+	}
+
+	return s.Commands.RevokeOtherSessions(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) ChangePassword(ctx context.Context, cmd *ChangePasswordCommand) error {
+	if !validate.UUID(cmd.ProfileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.ChangePassword:ProfileID")
+	}
+
+	if p, err := validate.PasswordLen(cmd.CurrentPassword); err != nil {
+		return apperror.NewAppError(err, "auth.CommandsSanitizer.ChangePassword:CurrentPassword")
+	} else {
+		cmd.CurrentPassword = p
+	}
+
+	if p, err := validate.PasswordLen(cmd.NewPassword); err != nil {
+		return apperror.NewAppError(err, "auth.CommandsSanitizer.ChangePassword:NewPassword")
+	} else {
+		cmd.NewPassword = p
+	}
+
+	return s.Commands.ChangePassword(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) CreateAppPassword(ctx context.Context, cmd *CreateAppPasswordCommand) (*AppPassword, string, error) {
+	if !validate.UUID(cmd.ProfileID) {
+		return nil, "", apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.CreateAppPassword:ProfileID")
+	}
+
+	if n, err := validate.Name(cmd.Name); err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.CommandsSanitizer.CreateAppPassword:Name")
+	} else {
+		cmd.Name = n
+	}
+
+	return s.Commands.CreateAppPassword(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) DeleteAppPassword(ctx context.Context, cmd *DeleteAppPasswordCommand) error {
+	if !validate.UUID(cmd.ProfileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.DeleteAppPassword:ProfileID")
+	}
+
+	if !validate.UUID(cmd.AppPasswordID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.CommandsSanitizer.DeleteAppPassword:AppPasswordID")
+	}
+
+	return s.Commands.DeleteAppPassword(ctx, cmd)
+}
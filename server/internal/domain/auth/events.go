@@ -0,0 +1,63 @@
+package auth
+
+// Domain events of the sign-ins, written to the outbox in the transaction of the change
+// and published to the subscribers of the event bus once committed.
+
+type SignedUp struct {
+	ProfileID string
+	Provider  Provider
+}
+
+func (SignedUp) EventType() string { return "auth.signed_up" }
+
+type SignedIn struct {
+	ProfileID string
+	Provider  Provider
+	SessionID string
+}
+
+func (SignedIn) EventType() string { return "auth.signed_in" }
+
+type SignedOut struct {
+	ProfileID string
+	SessionID string
+}
+
+func (SignedOut) EventType() string { return "auth.signed_out" }
+
+// SessionRevoked is a session ended without a sign-out, e.g. a refresh token was reused.
+type SessionRevoked struct {
+	ProfileID string
+	SessionID string
+	Reason    string
+}
+
+func (SessionRevoked) EventType() string { return "auth.session.revoked" }
+
+type PasswordChanged struct {
+	ProfileID string
+}
+
+func (PasswordChanged) EventType() string { return "auth.password_changed" }
+
+type Deleted struct {
+	ProfileID string
+	AuthID    string
+}
+
+func (Deleted) EventType() string { return "auth.deleted" }
+
+type AppPasswordCreated struct {
+	ProfileID     string
+	AppPasswordID string
+	Name          string
+}
+
+func (AppPasswordCreated) EventType() string { return "auth.app_password.created" }
+
+type AppPasswordDeleted struct {
+	ProfileID     string
+	AppPasswordID string
+}
+
+func (AppPasswordDeleted) EventType() string { return "auth.app_password.deleted" }
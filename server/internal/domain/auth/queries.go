@@ -6,9 +6,51 @@ type Queries interface {
 	// App Errors:
 	// - ErrCommonNoData
 	GetByProvider(ctx context.Context, query *GetByProviderQuery) (*Auth, error)
+
+	// App Errors:
+	// - ErrCommonInvalidValue
+	GetAppPasswords(ctx context.Context, query *GetAppPasswordsQuery) ([]*AppPassword, error)
+
+	// ValidateAppPassword returns the profile the email and app password secret sign in.
+	//
+	// App Errors:
+	// - ErrAuthInvalidCredentials
+	ValidateAppPassword(ctx context.Context, query *ValidateAppPasswordQuery) (profileID string, err error)
+
+	// ValidateSession returns the session of an access token, if it is still active.
+	//
+	// App Errors:
+	// - ErrAuthInvalidToken
+	// - ErrAuthTokenExpired
+	// - ErrAuthSessionRevoked
+	ValidateSession(ctx context.Context, query *ValidateSessionQuery) (*Session, error)
+
+	// GetSessions returns the active sessions of the profile, the last seen first.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	GetSessions(ctx context.Context, query *GetSessionsQuery) ([]*Session, error)
 }
 
 type GetByProviderQuery struct {
 	Provider       Provider
 	ProviderUserID string
 }
+
+type GetAppPasswordsQuery struct {
+	ProfileID string
+}
+
+type ValidateAppPasswordQuery struct {
+	Email  string
+	Secret string
+}
+
+type ValidateSessionQuery struct {
+	ProfileID string
+	SessionID string
+}
+
+type GetSessionsQuery struct {
+	ProfileID string
+}
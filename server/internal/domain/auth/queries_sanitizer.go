@@ -4,6 +4,7 @@ import (
 	"context"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/validate"
+	"strings"
 )
 
 var _ Queries = (*QueriesSanitizer)(nil)
@@ -31,3 +32,41 @@ func (s *QueriesSanitizer) GetByProvider(ctx context.Context, query *GetByProvid
 
 	return s.Queries.GetByProvider(ctx, query)
 }
+
+func (s *QueriesSanitizer) GetAppPasswords(ctx context.Context, query *GetAppPasswordsQuery) ([]*AppPassword, error) {
+	if !validate.UUID(query.ProfileID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.QueriesSanitizer.GetAppPasswords:ProfileID")
+	}
+
+	return s.Queries.GetAppPasswords(ctx, query)
+}
+
+func (s *QueriesSanitizer) ValidateAppPassword(ctx context.Context, query *ValidateAppPasswordQuery) (string, error) {
+	email, err := validate.Email(query.Email)
+	if err != nil {
+		return "", apperror.NewAppError(apperror.ErrAuthInvalidCredentials, "auth.QueriesSanitizer.ValidateAppPassword:Email")
+	}
+	query.Email = email
+
+	if strings.TrimSpace(query.Secret) == "" {
+		return "", apperror.NewAppError(apperror.ErrAuthInvalidCredentials, "auth.QueriesSanitizer.ValidateAppPassword:Secret")
+	}
+
+	return s.Queries.ValidateAppPassword(ctx, query)
+}
+
+func (s *QueriesSanitizer) ValidateSession(ctx context.Context, query *ValidateSessionQuery) (*Session, error) {
+	if !validate.UUID(query.ProfileID) || !validate.UUID(query.SessionID) {
+		return nil, apperror.NewAppError(apperror.ErrAuthInvalidToken, "auth.QueriesSanitizer.ValidateSession")
+	}
+
+	return s.Queries.ValidateSession(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetSessions(ctx context.Context, query *GetSessionsQuery) ([]*Session, error) {
+	if !validate.UUID(query.ProfileID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "auth.QueriesSanitizer.GetSessions:ProfileID")
+	}
+
+	return s.Queries.GetSessions(ctx, query)
+}
@@ -2,7 +2,9 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"skyvault/pkg/apperror"
+	"time"
 )
 
 var _ Queries = (*QueryHandlers)(nil)
@@ -27,3 +29,73 @@ func (h *QueryHandlers) GetByProvider(ctx context.Context, query *GetByProviderQ
 
 	return auth, nil
 }
+
+func (h *QueryHandlers) GetAppPasswords(ctx context.Context, query *GetAppPasswordsQuery) ([]*AppPassword, error) {
+	appPasswords, err := h.repository.GetAppPasswords(ctx, query.ProfileID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.QueryHandlers.GetAppPasswords:GetAppPasswords")
+	}
+
+	return appPasswords, nil
+}
+
+func (h *QueryHandlers) ValidateAppPassword(ctx context.Context, query *ValidateAppPasswordQuery) (string, error) {
+	au, err := h.repository.GetByProvider(ctx, ProviderEmail, query.Email)
+	if errors.Is(err, apperror.ErrCommonNoData) {
+		return "", apperror.NewAppError(apperror.ErrAuthInvalidCredentials, "auth.QueryHandlers.ValidateAppPassword:GetByProvider")
+	}
+	if err != nil {
+		return "", apperror.NewAppError(err, "auth.QueryHandlers.ValidateAppPassword:GetByProvider")
+	}
+
+	appPassword, err := h.repository.GetAppPasswordBySecretHash(ctx, HashAppPasswordSecret(query.Secret))
+	if errors.Is(err, apperror.ErrCommonNoData) {
+		return "", apperror.NewAppError(apperror.ErrAuthInvalidCredentials, "auth.QueryHandlers.ValidateAppPassword:GetAppPasswordBySecretHash")
+	}
+	if err != nil {
+		return "", apperror.NewAppError(err, "auth.QueryHandlers.ValidateAppPassword:GetAppPasswordBySecretHash")
+	}
+
+	// The secret alone finds the app password, the email must still be of the same profile
+	if appPassword.ProfileID != au.ProfileID {
+		return "", apperror.NewAppError(apperror.ErrAuthInvalidCredentials, "auth.QueryHandlers.ValidateAppPassword:ProfileID")
+	}
+
+	return au.ProfileID, nil
+}
+
+func (h *QueryHandlers) ValidateSession(ctx context.Context, query *ValidateSessionQuery) (*Session, error) {
+	session, err := h.repository.GetSession(ctx, query.SessionID)
+	if errors.Is(err, apperror.ErrCommonNoData) {
+		// Purged after it expired or was revoked
+		return nil, apperror.NewAppError(apperror.ErrAuthSessionRevoked, "auth.QueryHandlers.ValidateSession:GetSession")
+	}
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.QueryHandlers.ValidateSession:GetSession")
+	}
+
+	if err := session.ValidateAccess(query.ProfileID); err != nil {
+		return nil, apperror.NewAppError(apperror.ErrAuthInvalidToken, "auth.QueryHandlers.ValidateSession:ValidateAccess")
+	}
+
+	err = session.ValidateActive()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.QueryHandlers.ValidateSession:ValidateActive")
+	}
+
+	// Not refreshed in time, its access tokens end with it even before they expire
+	if !time.Now().UTC().Before(session.ExpiresAt) {
+		return nil, apperror.NewAppError(apperror.ErrAuthTokenExpired, "auth.QueryHandlers.ValidateSession:ExpiresAt")
+	}
+
+	return session, nil
+}
+
+func (h *QueryHandlers) GetSessions(ctx context.Context, query *GetSessionsQuery) ([]*Session, error) {
+	sessions, err := h.repository.GetActiveSessions(ctx, query.ProfileID, time.Now().UTC())
+	if err != nil {
+		return nil, apperror.NewAppError(err, "auth.QueryHandlers.GetSessions:GetActiveSessions")
+	}
+
+	return sessions, nil
+}
@@ -3,6 +3,8 @@ package auth
 import (
 	"context"
 	"skyvault/internal/domain/internal"
+	"skyvault/pkg/eventbus"
+	"time"
 )
 
 type Repository interface {
@@ -29,4 +31,72 @@ type Repository interface {
 	// App Errors:
 	// - ErrCommonNoData
 	Delete(ctx context.Context, id string) error
+
+	//--------------------------------
+	// App Passwords
+	//--------------------------------
+
+	// App Errors:
+	// - ErrCommonDuplicateData
+	CreateAppPassword(ctx context.Context, appPassword *AppPassword) (*AppPassword, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetAppPassword(ctx context.Context, id string) (*AppPassword, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetAppPasswordBySecretHash(ctx context.Context, secretHash string) (*AppPassword, error)
+
+	GetAppPasswords(ctx context.Context, profileID string) ([]*AppPassword, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	DeleteAppPassword(ctx context.Context, id string) error
+
+	//--------------------------------
+	// Sessions
+	//--------------------------------
+
+	CreateSession(ctx context.Context, session *Session) (*Session, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetSession(ctx context.Context, id string) (*Session, error)
+
+	// RotateSession saves the new refresh token of the session, only if its current one is still
+	// the PreviousRefreshTokenHash of the session and it is not revoked.
+	// So of two refreshes with the same token only one succeeds.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	RotateSession(ctx context.Context, session *Session) error
+
+	// GetActiveSessions returns the sessions of the profile not revoked nor expired at the time,
+	// the last seen first.
+	GetActiveSessions(ctx context.Context, profileID string, now time.Time) ([]*Session, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	UpdateSession(ctx context.Context, session *Session) error
+
+	// App Errors:
+	// - ErrCommonNoData
+	TouchSession(ctx context.Context, id string, lastSeenAt time.Time) error
+
+	// RevokeSessions revokes the active sessions of the profile, except the one of exceptSessionID
+	// when it is not empty. Returns the revoked sessions.
+	RevokeSessions(ctx context.Context, profileID, exceptSessionID string, revokedAt time.Time) ([]*Session, error)
+
+	// DeleteSessions deletes the sessions that expired or were revoked before the time,
+	// and returns how many were deleted.
+	DeleteSessions(ctx context.Context, before time.Time) (int64, error)
+
+	//--------------------------------
+	// Domain events
+	//--------------------------------
+
+	// AddDomainEvents writes the events to the outbox. With the repository of a transaction,
+	// they are published only once it commits.
+	AddDomainEvents(ctx context.Context, events ...eventbus.Event) error
 }
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/utils"
+	"skyvault/pkg/validate"
+	"strings"
+	"time"
+)
+
+const (
+	refreshTokenSecretBytes = 32
+
+	// Used when the config doesn't set the lifetime of the refresh tokens
+	defaultRefreshTokenTimeoutDays = 30
+
+	RevokeReasonRefreshTokenReused = "refresh_token_reused"
+	RevokeReasonRevokedByUser      = "revoked_by_user"
+	RevokeReasonPasswordChanged    = "password_changed"
+
+	// The last seen time is only as accurate, to not write the session on every request
+	lastSeenInterval = 5 * time.Minute
+
+	maxUserAgentLen = 512
+)
+
+// Session is a sign-in of a profile, kept alive by rotating its refresh token.
+// The access tokens carry its ID and stop working once it is revoked.
+//
+// Every refresh replaces the refresh token, only the hashes of the current and the previous one are kept.
+// A refresh with the previous token means a token was stolen, and revokes the session. Any other secret
+// is rejected without revoking, since the session IDs are not secret.
+type Session struct {
+	ID                       string
+	ProfileID                string
+	Provider                 Provider // Of the sign-in, its authenticator issues the access tokens
+	RefreshTokenHash         string
+	PreviousRefreshTokenHash string    // Of the token replaced by the last rotation, empty before the first one
+	ExpiresAt                time.Time // Of the refresh token, extended by every refresh
+	RevokedAt                *time.Time
+	UserAgent                string // Of the client signed in, to tell the sessions apart
+	IPAddress                string // Of the sign-in
+	LastSeenAt               time.Time
+	CreatedAt                time.Time
+	UpdatedAt                time.Time
+}
+
+// SessionClient is the client signing in.
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// NewSession returns the session and its first refresh token, the token is given to the client only.
+func NewSession(profileID string, provider Provider, client SessionClient, ttl time.Duration) (*Session, string, error) {
+	id, err := utils.ID()
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.NewSession:ID")
+	}
+
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLen {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLen], "")
+	}
+
+	now := time.Now().UTC()
+	s := &Session{
+		ID:         id,
+		ProfileID:  profileID,
+		Provider:   provider,
+		UserAgent:  userAgent,
+		IPAddress:  client.IPAddress,
+		LastSeenAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	refreshToken, err := s.Rotate(ttl)
+	if err != nil {
+		return nil, "", apperror.NewAppError(err, "auth.NewSession:Rotate")
+	}
+
+	return s, refreshToken, nil
+}
+
+// Rotate replaces the refresh token and returns the new one, the previous one is a reuse from now on.
+// Only the last replaced token is remembered, the older ones are invalid like any unknown secret.
+func (s *Session) Rotate(ttl time.Duration) (string, error) {
+	b := make([]byte, refreshTokenSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", apperror.NewAppError(err, "auth.Session.Rotate:Read")
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now().UTC()
+	s.PreviousRefreshTokenHash = s.RefreshTokenHash
+	s.RefreshTokenHash = hashRefreshTokenSecret(secret)
+	s.ExpiresAt = now.Add(ttl)
+	s.LastSeenAt = now
+	s.UpdatedAt = now
+
+	// The session is looked up by its ID, the secret proves the token is the current one
+	return s.ID + "." + secret, nil
+}
+
+// ParseRefreshToken returns the session ID and the secret of a refresh token.
+//
+// App Errors:
+// - ErrAuthInvalidToken
+func ParseRefreshToken(refreshToken string) (sessionID, secret string, err error) {
+	sessionID, secret, ok := strings.Cut(strings.TrimSpace(refreshToken), ".")
+	if !ok || !validate.UUID(sessionID) || secret == "" {
+		return "", "", apperror.NewAppError(apperror.ErrAuthInvalidToken, "auth.ParseRefreshToken")
+	}
+	return sessionID, secret, nil
+}
+
+// ValidateRefresh checks that the secret is the one of the current refresh token.
+// The secret of the previous token is a reuse, any other one is invalid.
+//
+// App Errors:
+// - ErrAuthSessionRevoked
+// - ErrAuthTokenExpired
+// - ErrAuthRefreshTokenReused
+// - ErrAuthInvalidToken
+func (s *Session) ValidateRefresh(secret string, now time.Time) error {
+	if err := s.ValidateActive(); err != nil {
+		return err
+	}
+
+	hash := hashRefreshTokenSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(s.RefreshTokenHash)) != 1 {
+		if s.PreviousRefreshTokenHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(s.PreviousRefreshTokenHash)) == 1 {
+			return apperror.NewAppError(apperror.ErrAuthRefreshTokenReused, "auth.Session.ValidateRefresh:PreviousHash").WithMetadata("session_id", s.ID)
+		}
+		return apperror.NewAppError(apperror.ErrAuthInvalidToken, "auth.Session.ValidateRefresh:Hash").WithMetadata("session_id", s.ID)
+	}
+
+	if !now.Before(s.ExpiresAt) {
+		return apperror.NewAppError(apperror.ErrAuthTokenExpired, "auth.Session.ValidateRefresh:ExpiresAt").WithMetadata("session_id", s.ID)
+	}
+
+	return nil
+}
+
+// ValidateActive checks that the session is not revoked, the access tokens of a revoked session are rejected.
+//
+// App Errors:
+// - ErrAuthSessionRevoked
+func (s *Session) ValidateActive() error {
+	if s.RevokedAt != nil {
+		return apperror.NewAppError(apperror.ErrAuthSessionRevoked, "auth.Session.ValidateActive").WithMetadata("session_id", s.ID)
+	}
+	return nil
+}
+
+// Revoke ends the session, it is a no-op if the session is already revoked.
+func (s *Session) Revoke(now time.Time) {
+	if s.RevokedAt != nil {
+		return
+	}
+	s.RevokedAt = &now
+	s.UpdatedAt = now
+}
+
+// NeedsTouch tells if the last seen time is outdated, and the session should be touched.
+func (s *Session) NeedsTouch(now time.Time) bool {
+	return now.Sub(s.LastSeenAt) >= lastSeenInterval
+}
+
+// App Errors:
+// - ErrCommonNoAccess
+func (s *Session) ValidateAccess(accessedByID string) error {
+	if s.ProfileID != accessedByID {
+		return apperror.NewAppError(apperror.ErrCommonNoAccess, "auth.Session.ValidateAccess").WithMetadata("accessed_by_id", accessedByID).WithMetadata("profile_id", s.ProfileID)
+	}
+	return nil
+}
+
+// The secrets are random, so like the app passwords they need no salt nor a slow hash
+func hashRefreshTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// refreshTokenTTL is the lifetime of the refresh tokens, in days in the config.
+func refreshTokenTTL(days int) time.Duration {
+	if days <= 0 {
+		days = defaultRefreshTokenTimeoutDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Tokens are issued by a sign-in or a refresh of a session.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+}
@@ -0,0 +1,41 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"skyvault/pkg/apperror"
+	"time"
+)
+
+var _ Commands = (*CommandHandlers)(nil)
+
+type CommandHandlers struct {
+	repository Repository
+}
+
+func NewCommandHandlers(repository Repository) Commands {
+	return &CommandHandlers{repository: repository}
+}
+
+func (h *CommandHandlers) AddEvent(ctx context.Context, cmd *AddEventCommand) error {
+	payload, err := json.Marshal(cmd.Payload)
+	if err != nil {
+		return apperror.NewAppError(err, "event.CommandHandlers.AddEvent:Marshal")
+	}
+
+	err = h.repository.AddEvent(ctx, cmd.OwnerID, cmd.Type, string(payload))
+	if err != nil {
+		return apperror.NewAppError(err, "event.CommandHandlers.AddEvent:AddEvent")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) PurgeEvents(ctx context.Context, cmd *PurgeEventsCommand) (int, error) {
+	count, err := h.repository.DeleteEvents(ctx, time.Now().UTC().Add(-eventRetention))
+	if err != nil {
+		return 0, apperror.NewAppError(err, "event.CommandHandlers.PurgeEvents:DeleteEvents")
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,23 @@
+package event
+
+import "context"
+
+type Commands interface {
+	// AddEvent records an event that is not recorded by the repository, see Types.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	AddEvent(ctx context.Context, cmd *AddEventCommand) error
+
+	// PurgeEvents deletes the events older than the retention, the streams resuming before them must resync.
+	// Returns the number of deleted events.
+	PurgeEvents(ctx context.Context, cmd *PurgeEventsCommand) (int, error)
+}
+
+type AddEventCommand struct {
+	OwnerID string
+	Type    string
+	Payload map[string]any // Marshaled to a JSON object
+}
+
+type PurgeEventsCommand struct{}
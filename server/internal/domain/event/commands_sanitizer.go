@@ -0,0 +1,34 @@
+package event
+
+import (
+	"context"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/validate"
+	"slices"
+)
+
+var _ Commands = (*CommandsSanitizer)(nil)
+
+type CommandsSanitizer struct {
+	Commands
+}
+
+func NewCommandsSanitizer(commands Commands) Commands {
+	return &CommandsSanitizer{Commands: commands}
+}
+
+func (s *CommandsSanitizer) AddEvent(ctx context.Context, cmd *AddEventCommand) error {
+	if !validate.UUID(cmd.OwnerID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "event.CommandsSanitizer.AddEvent:OwnerID")
+	}
+
+	if !slices.Contains(Types, cmd.Type) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "event.CommandsSanitizer.AddEvent:Type").WithMetadata("type", cmd.Type)
+	}
+
+	if cmd.Payload == nil {
+		cmd.Payload = map[string]any{}
+	}
+
+	return s.Commands.AddEvent(ctx, cmd)
+}
@@ -0,0 +1,54 @@
+package event
+
+import (
+	"skyvault/pkg/apperror"
+	"time"
+)
+
+const (
+	// Events are kept this long for the clients to resume their stream, older ones are purged by PurgeEvents
+	eventRetention = 24 * time.Hour
+
+	defaultEventsLimit = 100
+	maxEventsLimit     = 500
+)
+
+// The events recorded by the application, the others are recorded by the repository with the changes
+const (
+	TypeShareAccessed   = "share.accessed"   // A visitor downloaded a share
+	TypeFileQuarantined = "file.quarantined" // Malware was found in a file
+)
+
+// Types are all the types of events, the file and folder ones are the changes of the media change feed.
+var Types = []string{
+	"file.created", "file.renamed", "file.moved", "file.updated", "file.trashed", "file.restored", "file.deleted", TypeFileQuarantined,
+	"folder.created", "folder.renamed", "folder.moved", "folder.updated", "folder.trashed", "folder.restored", "folder.deleted",
+	"share.created", "share.updated", "share.deleted", TypeShareAccessed,
+}
+
+// Event is a change of the owner's files, folders or shares, recorded in the transaction of the change.
+// The events of an owner take the sequence of the media change log, without gaps.
+type Event struct {
+	OwnerID   string
+	Seq       int64
+	Type      string // e.g. "file.created", "folder.moved" or "share.created"
+	Payload   string // JSON object, e.g. the item and its state after the change
+	CreatedAt time.Time
+}
+
+// validateNoGap checks that the events right after the sequence are still kept.
+// The sequence has no gaps, so a missing event was purged.
+//
+// App Errors:
+// - ErrEventExpired
+func validateNoGap(afterSeq, lastSeq int64, events []*Event) error {
+	if afterSeq == lastSeq {
+		return nil
+	}
+
+	if len(events) == 0 || events[0].Seq != afterSeq+1 {
+		return apperror.ErrEventExpired
+	}
+
+	return nil
+}
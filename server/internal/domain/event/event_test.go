@@ -0,0 +1,47 @@
+package event
+
+import (
+	"testing"
+
+	"skyvault/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNoGap(t *testing.T) {
+	t.Parallel()
+	events := func(seqs ...int64) []*Event {
+		res := make([]*Event, 0, len(seqs))
+		for _, seq := range seqs {
+			res = append(res, &Event{Seq: seq})
+		}
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		afterSeq int64
+		lastSeq  int64
+		events   []*Event
+		wantErr  bool
+	}{
+		{"up to date", 10, 10, events(), false},
+		{"next events", 10, 12, events(11, 12), false},
+		{"committed after the last sequence", 10, 10, events(11), false},
+		{"first event purged", 10, 12, events(12), true},
+		{"all events purged", 10, 12, events(), true},
+		{"new owner", 0, 0, events(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateNoGap(tt.afterSeq, tt.lastSeq, tt.events)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, apperror.ErrEventExpired)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package event
+
+// Notifier wakes up the streams of an owner when new events are committed, on any server.
+// A signal only tells to read the events again, it may be sent for events already read.
+type Notifier interface {
+	// Subscribe returns the channel signaled when the owner has new events, it is closed when the server
+	// shuts down. The subscription must be ended by calling unsubscribe.
+	Subscribe(ownerID string) (signal <-chan struct{}, unsubscribe func())
+}
@@ -0,0 +1,25 @@
+package event
+
+import "context"
+
+type Queries interface {
+	// GetEvents returns the events of the owner after the sequence, for the live streams.
+	// Without a sequence, it returns no events and the sequence of the last event, to start a new stream.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrEventExpired
+	GetEvents(ctx context.Context, query *GetEventsQuery) (*GetEventsRes, error)
+}
+
+type GetEventsQuery struct {
+	OwnerID  string
+	AfterSeq *int64
+	Limit    int
+}
+
+type GetEventsRes struct {
+	Events  []*Event
+	LastSeq int64 // The sequence to get the next events after
+	HasMore bool
+}
@@ -0,0 +1,31 @@
+package event
+
+import (
+	"context"
+	"skyvault/pkg/apperror"
+)
+
+var _ Queries = (*QueriesSanitizer)(nil)
+
+type QueriesSanitizer struct {
+	Queries
+}
+
+func NewQueriesSanitizer(queries Queries) Queries {
+	return &QueriesSanitizer{Queries: queries}
+}
+
+func (s *QueriesSanitizer) GetEvents(ctx context.Context, query *GetEventsQuery) (*GetEventsRes, error) {
+	if query.AfterSeq != nil && *query.AfterSeq < 0 {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "event.QueriesSanitizer.GetEvents:AfterSeq").WithMetadata("after_seq", *query.AfterSeq)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultEventsLimit
+	}
+	if query.Limit < 1 || query.Limit > maxEventsLimit {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "event.QueriesSanitizer.GetEvents:Limit").WithMetadata("limit", query.Limit)
+	}
+
+	return s.Queries.GetEvents(ctx, query)
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"context"
+	"skyvault/pkg/apperror"
+)
+
+var _ Queries = (*QueryHandlers)(nil)
+
+type QueryHandlers struct {
+	repository Repository
+}
+
+func NewQueryHandlers(repository Repository) Queries {
+	return &QueryHandlers{repository: repository}
+}
+
+func (h *QueryHandlers) GetEvents(ctx context.Context, query *GetEventsQuery) (*GetEventsRes, error) {
+	// The last sequence is read before the events, so the events committed in between are not taken for a gap
+	lastSeq, err := h.repository.GetLastSeq(ctx, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "event.QueryHandlers.GetEvents:GetLastSeq")
+	}
+
+	if query.AfterSeq == nil {
+		return &GetEventsRes{Events: []*Event{}, LastSeq: lastSeq}, nil
+	}
+
+	afterSeq := *query.AfterSeq
+	if afterSeq > lastSeq {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "event.QueryHandlers.GetEvents:AfterSeq").WithMetadata("after_seq", afterSeq).WithMetadata("last_seq", lastSeq)
+	}
+
+	// One more than the limit tells whether there are more
+	events, err := h.repository.GetEvents(ctx, query.OwnerID, afterSeq, query.Limit+1)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "event.QueryHandlers.GetEvents:GetEvents")
+	}
+
+	err = validateNoGap(afterSeq, lastSeq, events)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "event.QueryHandlers.GetEvents:validateNoGap").WithMetadata("after_seq", afterSeq)
+	}
+
+	res := &GetEventsRes{Events: events, LastSeq: afterSeq}
+	if len(events) > query.Limit {
+		res.Events = events[:query.Limit]
+		res.HasMore = true
+	}
+	if len(res.Events) > 0 {
+		res.LastSeq = res.Events[len(res.Events)-1].Seq
+	}
+
+	return res, nil
+}
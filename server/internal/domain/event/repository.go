@@ -0,0 +1,26 @@
+package event
+
+import (
+	"context"
+	"skyvault/internal/domain/internal"
+	"time"
+)
+
+type Repository interface {
+	internal.RepositoryTx[Repository]
+
+	// The events are recorded by the repository itself, in the transaction of every change.
+
+	// AddEvent records an event with the next sequence of the owner, the payload is a JSON object.
+	AddEvent(ctx context.Context, ownerID, eventType, payload string) error
+
+	// GetLastSeq returns the sequence of the owner's last event, 0 if the owner has none.
+	GetLastSeq(ctx context.Context, ownerID string) (int64, error)
+
+	// GetEvents returns the events of the owner after the sequence, oldest first, at most limit.
+	GetEvents(ctx context.Context, ownerID string, afterSeq int64, limit int) ([]*Event, error)
+
+	// DeleteEvents deletes the events created before, of all the owners.
+	// Returns the number of deleted events.
+	DeleteEvents(ctx context.Context, before time.Time) (int64, error)
+}
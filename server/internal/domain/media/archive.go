@@ -0,0 +1,444 @@
+package media
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"skyvault/pkg/apperror"
+	"strings"
+	"time"
+)
+
+// Limits of the archive entries, they guard the server against decompression bombs
+// and archives crafted to exhaust the database.
+const (
+	maxArchiveEntries    = 10000
+	maxArchivePathLength = 1024
+	maxArchivePathDepth  = 64
+	// Maximum ratio between the extracted bytes and the size of the archive
+	maxArchiveRatio = 100
+)
+
+type archiveFormat string
+
+const (
+	archiveFormatZip     archiveFormat = "zip"
+	archiveFormatTar     archiveFormat = "tar"
+	archiveFormatTarGzip archiveFormat = "tar.gz"
+)
+
+// getArchiveFormat returns the format of the archive, or an empty format if the file is not a supported archive.
+// Gzip files are only supported when they contain a tar.
+func getArchiveFormat(info *FileInfo) archiveFormat {
+	name := strings.ToLower(info.Name)
+	mimeType, _, _ := strings.Cut(info.MimeType, ";")
+
+	switch {
+	case mimeType == "application/zip" || mimeType == "application/x-zip-compressed" || strings.HasSuffix(name, ".zip"):
+		return archiveFormatZip
+	case mimeType == "application/x-tar" || strings.HasSuffix(name, ".tar"):
+		return archiveFormatTar
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		return archiveFormatTarGzip
+	}
+	return ""
+}
+
+// ArchiveEntry is a file or a folder inside an archive.
+type ArchiveEntry struct {
+	Path     string // Cleaned slash separated path, relative to the archive root, without trailing slash
+	Size     int64  // Uncompressed size declared by the archive, 0 for folders
+	Modified time.Time
+	IsDir    bool
+}
+
+// walkArchive calls fn with every file and folder of the archive, in the order of the archive.
+// The content reader is only valid during the call and is nil for folders.
+// Symlinks, hard links and special files are skipped, they could point outside of the extracted tree.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+// - ErrMediaUnsafeArchive
+func walkArchive(file io.ReadSeeker, size int64, format archiveFormat, fn func(entry *ArchiveEntry, content io.Reader) error) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return apperror.NewAppError(err, "media.walkArchive:Seek")
+	}
+
+	switch format {
+	case archiveFormatZip:
+		return walkZip(file, size, fn)
+	case archiveFormatTar:
+		return walkTar(file, fn)
+	case archiveFormatTarGzip:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "media.walkArchive:gzip.NewReader")
+		}
+		defer gz.Close()
+		return walkTar(gz, fn)
+	default:
+		return apperror.NewAppError(fmt.Errorf("%w: unsupported archive format", apperror.ErrCommonInvalidValue), "media.walkArchive:format")
+	}
+}
+
+func walkZip(file io.ReadSeeker, size int64, fn func(entry *ArchiveEntry, content io.Reader) error) error {
+	zr, err := newZipReader(file, size)
+	if err != nil {
+		return apperror.NewAppError(err, "media.walkZip:newZipReader")
+	}
+
+	for _, f := range zr.File {
+		entry, err := zipEntry(f)
+		if err != nil {
+			return apperror.NewAppError(err, "media.walkZip:zipEntry")
+		}
+		if entry == nil {
+			continue
+		}
+
+		if entry.IsDir {
+			err = fn(entry, nil)
+		} else {
+			err = walkZipFile(f, entry, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func walkZipFile(f *zip.File, entry *ArchiveEntry, fn func(entry *ArchiveEntry, content io.Reader) error) error {
+	// Listing the entries doesn't read them, the local headers are only read when the content is
+	content := &zipFileReader{file: f}
+	defer content.Close()
+
+	return fn(entry, content)
+}
+
+// App Errors:
+// - ErrCommonInvalidValue
+// - ErrMediaUnsafeArchive
+func newZipReader(file io.ReadSeeker, size int64) (*zip.Reader, error) {
+	zr, err := zip.NewReader(&readerAt{file: file}, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err)
+	}
+
+	if len(zr.File) > maxArchiveEntries {
+		return nil, fmt.Errorf("%w: more than %d entries", apperror.ErrMediaUnsafeArchive, maxArchiveEntries)
+	}
+
+	return zr, nil
+}
+
+// zipEntry returns the entry of the zip file, or nil if it is not a regular file or a folder.
+//
+// App Errors:
+// - ErrMediaUnsafeArchive
+func zipEntry(f *zip.File) (*ArchiveEntry, error) {
+	mode := f.Mode()
+	if !mode.IsDir() && !mode.IsRegular() {
+		return nil, nil
+	}
+
+	p, err := cleanArchivePath(f.Name)
+	if err != nil || p == "" {
+		return nil, err
+	}
+
+	entry := &ArchiveEntry{Path: p, Modified: f.Modified, IsDir: mode.IsDir()}
+	if !entry.IsDir {
+		entry.Size = int64(f.UncompressedSize64)
+		if entry.Size < 0 {
+			return nil, fmt.Errorf("%w: invalid entry size", apperror.ErrMediaUnsafeArchive)
+		}
+	}
+
+	return entry, nil
+}
+
+// zipFileReader opens the zip file on the first read.
+type zipFileReader struct {
+	file *zip.File
+	rc   io.ReadCloser
+}
+
+func (r *zipFileReader) Read(p []byte) (int, error) {
+	if r.rc == nil {
+		rc, err := r.file.Open()
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err)
+		}
+		r.rc = rc
+	}
+	return r.rc.Read(p)
+}
+
+func (r *zipFileReader) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	return r.rc.Close()
+}
+
+func walkTar(r io.Reader, fn func(entry *ArchiveEntry, content io.Reader) error) error {
+	tr := tar.NewReader(r)
+
+	for count := 0; ; count++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "media.walkTar:Next")
+		}
+
+		// Tar has no central directory, the entries are counted while reading
+		if count >= maxArchiveEntries {
+			return apperror.NewAppError(fmt.Errorf("%w: more than %d entries", apperror.ErrMediaUnsafeArchive, maxArchiveEntries), "media.walkTar:Entries")
+		}
+
+		entry, err := tarEntry(header)
+		if err != nil {
+			return apperror.NewAppError(err, "media.walkTar:tarEntry")
+		}
+		if entry == nil {
+			continue
+		}
+
+		// The content not read is skipped by tar.Reader, with a seek if the archive is not compressed
+		if entry.IsDir {
+			err = fn(entry, nil)
+		} else {
+			err = fn(entry, tr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// tarEntry returns the entry of the tar header, or nil if it is not a regular file or a folder.
+//
+// App Errors:
+// - ErrMediaUnsafeArchive
+func tarEntry(header *tar.Header) (*ArchiveEntry, error) {
+	if header.Typeflag != tar.TypeDir && header.Typeflag != tar.TypeReg {
+		return nil, nil
+	}
+
+	p, err := cleanArchivePath(header.Name)
+	if err != nil || p == "" {
+		return nil, err
+	}
+
+	entry := &ArchiveEntry{Path: p, Modified: header.ModTime, IsDir: header.Typeflag == tar.TypeDir}
+	if !entry.IsDir {
+		entry.Size = header.Size
+	}
+	return entry, nil
+}
+
+// listArchive returns the files and folders of the archive without reading their content.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+// - ErrMediaUnsafeArchive
+func listArchive(file io.ReadSeeker, size int64, format archiveFormat) ([]*ArchiveEntry, error) {
+	entries := []*ArchiveEntry{}
+	err := walkArchive(file, size, format, func(entry *ArchiveEntry, _ io.Reader) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.listArchive:walkArchive")
+	}
+	return entries, nil
+}
+
+// openArchiveEntry returns the content of the file at entryPath, read from the archive without copying it.
+// The content is an io.ReadSeeker when the file is stored without compression, i.e. stored zip entries
+// and tar archives that are not compressed. It is only valid until the archive file is closed.
+//
+// App Errors:
+// - ErrCommonNoData
+// - ErrCommonInvalidValue
+// - ErrMediaUnsafeArchive
+func openArchiveEntry(file io.ReadSeeker, size int64, format archiveFormat, entryPath string) (*ArchiveEntry, io.Reader, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, apperror.NewAppError(err, "media.openArchiveEntry:Seek")
+	}
+
+	switch format {
+	case archiveFormatZip:
+		return openZipEntry(file, size, entryPath)
+	case archiveFormatTar:
+		return openTarEntry(tar.NewReader(file), file, entryPath)
+	case archiveFormatTarGzip:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "media.openArchiveEntry:gzip.NewReader")
+		}
+		return openTarEntry(tar.NewReader(gz), nil, entryPath)
+	default:
+		return nil, nil, apperror.NewAppError(fmt.Errorf("%w: unsupported archive format", apperror.ErrCommonInvalidValue), "media.openArchiveEntry:format")
+	}
+}
+
+func openZipEntry(file io.ReadSeeker, size int64, entryPath string) (*ArchiveEntry, io.Reader, error) {
+	zr, err := newZipReader(file, size)
+	if err != nil {
+		return nil, nil, apperror.NewAppError(err, "media.openZipEntry:newZipReader")
+	}
+
+	for _, f := range zr.File {
+		entry, err := zipEntry(f)
+		if err != nil {
+			return nil, nil, apperror.NewAppError(err, "media.openZipEntry:zipEntry")
+		}
+		if entry == nil || entry.IsDir || entry.Path != entryPath {
+			continue
+		}
+
+		// The stored entries are read in place, so that they can be downloaded by ranges
+		if f.Method == zip.Store && f.CompressedSize64 == f.UncompressedSize64 {
+			offset, err := f.DataOffset()
+			if err != nil {
+				return nil, nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "media.openZipEntry:DataOffset")
+			}
+			return entry, io.NewSectionReader(&readerAt{file: file}, offset, entry.Size), nil
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "media.openZipEntry:Open")
+		}
+		return entry, rc, nil
+	}
+
+	return nil, nil, apperror.NewAppError(apperror.ErrCommonNoData, "media.openZipEntry:entryPath").WithMetadata("path", entryPath)
+}
+
+// openTarEntry reads the tar until the entry, file is nil when the tar is compressed.
+func openTarEntry(tr *tar.Reader, file io.ReadSeeker, entryPath string) (*ArchiveEntry, io.Reader, error) {
+	for count := 0; ; count++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil, apperror.NewAppError(apperror.ErrCommonNoData, "media.openTarEntry:entryPath").WithMetadata("path", entryPath)
+		}
+		if err != nil {
+			return nil, nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "media.openTarEntry:Next")
+		}
+
+		if count >= maxArchiveEntries {
+			return nil, nil, apperror.NewAppError(fmt.Errorf("%w: more than %d entries", apperror.ErrMediaUnsafeArchive, maxArchiveEntries), "media.openTarEntry:Entries")
+		}
+
+		entry, err := tarEntry(header)
+		if err != nil {
+			return nil, nil, apperror.NewAppError(err, "media.openTarEntry:tarEntry")
+		}
+		if entry == nil || entry.IsDir || entry.Path != entryPath {
+			continue
+		}
+
+		if file == nil {
+			return entry, tr, nil
+		}
+
+		// tar.Reader doesn't buffer, the content starts at the current offset of the file
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, apperror.NewAppError(err, "media.openTarEntry:Seek")
+		}
+		return entry, io.NewSectionReader(&readerAt{file: file}, offset, entry.Size), nil
+	}
+}
+
+// cleanArchivePath returns the path of the entry relative to the extraction folder, or an empty path
+// for the root itself. Paths escaping the folder ("zip slip") make the whole archive unsafe.
+//
+// App Errors:
+// - ErrMediaUnsafeArchive
+func cleanArchivePath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.ContainsRune(name, 0) {
+		return "", fmt.Errorf("%w: null byte in path", apperror.ErrMediaUnsafeArchive)
+	}
+
+	// Absolute paths, including Windows drive letters, are rejected instead of made relative
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return "", fmt.Errorf("%w: absolute path %q", apperror.ErrMediaUnsafeArchive, name)
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: path %q escapes the archive", apperror.ErrMediaUnsafeArchive, name)
+		}
+	}
+
+	p := path.Clean(name)
+	if p == "." {
+		return "", nil
+	}
+
+	if len(p) > maxArchivePathLength || strings.Count(p, "/") >= maxArchivePathDepth {
+		return "", fmt.Errorf("%w: path too long", apperror.ErrMediaUnsafeArchive)
+	}
+
+	return p, nil
+}
+
+// archiveEntryContent closes the archive file with the content of the entry.
+type archiveEntryContent struct {
+	io.Reader
+	archive io.Closer
+}
+
+func (c *archiveEntryContent) Close() error {
+	if closer, ok := c.Reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return c.archive.Close()
+}
+
+// seekableArchiveEntryContent keeps the io.Seeker of the entries read in place.
+type seekableArchiveEntryContent struct {
+	*io.SectionReader
+	archive io.Closer
+}
+
+func (c *seekableArchiveEntryContent) Close() error {
+	return c.archive.Close()
+}
+
+func newArchiveEntryContent(content io.Reader, archive io.Closer) io.ReadCloser {
+	if section, ok := content.(*io.SectionReader); ok {
+		return &seekableArchiveEntryContent{SectionReader: section, archive: archive}
+	}
+	return &archiveEntryContent{Reader: content, archive: archive}
+}
+
+// readerAt gives random access over a file of the storage, for the zip reader and the entries read in place.
+// The entries are read one at a time, the seek and read are not synchronized.
+type readerAt struct {
+	file io.ReadSeeker
+}
+
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := r.file.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r.file, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		// ReaderAt reports a short read at the end of the file with io.EOF
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,171 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/utils"
+	"strings"
+	"time"
+)
+
+type ExtractionStatus string
+
+const (
+	ExtractionStatusPending ExtractionStatus = "pending"
+	ExtractionStatusRunning ExtractionStatus = "running"
+	ExtractionStatusDone    ExtractionStatus = "done"
+	ExtractionStatusFailed  ExtractionStatus = "failed"
+)
+
+// ExtractConflict tells what to do with an archive file whose name is already used in its folder.
+// Folders with the same name are always merged.
+type ExtractConflict string
+
+const (
+	ExtractConflictRename ExtractConflict = "rename" // Saved as "report (2).pdf"
+	ExtractConflictSkip   ExtractConflict = "skip"
+)
+
+// ArchiveExtraction is the extraction of an uploaded archive into a folder, run by a background job.
+// A failed extraction keeps the files extracted before the failure.
+type ArchiveExtraction struct {
+	ID             string
+	OwnerID        string
+	FileID         string  // The archive
+	FolderID       *string // Target folder, nil is the root folder
+	Conflict       ExtractConflict
+	Status         ExtractionStatus
+	FileCount      int64
+	FolderCount    int64 // Folders created, the merged ones are not counted
+	SkippedCount   int64 // Name conflicts, links and files of a type not allowed
+	ExtractedBytes int64
+	ErrorCode      *string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+var (
+	// errSkipArchiveEntry marks an entry which can't be recreated in the vault, e.g. with a blank name.
+	// It is skipped instead of failing the whole archive, unlike a corrupt entry.
+	errSkipArchiveEntry = errors.New("archive entry skipped")
+
+	// errExtractionInterrupted fails the extractions left running when the server stopped.
+	errExtractionInterrupted = errors.New("archive extraction interrupted")
+)
+
+// App Errors:
+// - ErrCommonInvalidValue
+// - ErrCommonNoAccess
+// - ErrMediaFileInfected
+func NewArchiveExtraction(ownerID string, archive *FileInfo, folder *FolderInfo, conflict ExtractConflict) (*ArchiveExtraction, error) {
+	if err := archive.ValidateAccess(ownerID); err != nil {
+		return nil, apperror.NewAppError(err, "media.NewArchiveExtraction:ValidateArchiveAccess")
+	}
+
+	if err := archive.ValidateNotQuarantined(); err != nil {
+		return nil, apperror.NewAppError(err, "media.NewArchiveExtraction:ValidateNotQuarantined")
+	}
+
+	if getArchiveFormat(archive) == "" {
+		return nil, apperror.NewAppError(fmt.Errorf("%w: not a zip or tar archive", apperror.ErrCommonInvalidValue), "media.NewArchiveExtraction:getArchiveFormat").WithMetadata("mime_type", archive.MimeType)
+	}
+
+	var folderID *string
+	if folder != nil {
+		if err := folder.ValidateAccess(ownerID); err != nil {
+			return nil, apperror.NewAppError(err, "media.NewArchiveExtraction:ValidateFolderAccess")
+		}
+		folderID = &folder.ID
+	}
+
+	if conflict == "" {
+		conflict = ExtractConflictRename
+	}
+	if conflict != ExtractConflictRename && conflict != ExtractConflictSkip {
+		return nil, apperror.NewAppError(fmt.Errorf("%w: unknown conflict %q", apperror.ErrCommonInvalidValue, conflict), "media.NewArchiveExtraction:Conflict")
+	}
+
+	id, err := utils.ID()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.NewArchiveExtraction:ID")
+	}
+
+	now := time.Now().UTC()
+	return &ArchiveExtraction{
+		ID:        id,
+		OwnerID:   ownerID,
+		FileID:    archive.ID,
+		FolderID:  folderID,
+		Conflict:  conflict,
+		Status:    ExtractionStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (e *ArchiveExtraction) Start() {
+	e.Status = ExtractionStatusRunning
+	e.UpdatedAt = time.Now().UTC()
+}
+
+// Finish marks the extraction as done, or as failed with the public code of err.
+func (e *ArchiveExtraction) Finish(err error) {
+	e.Status = ExtractionStatusDone
+	if err != nil {
+		code := apperror.GetPublicError(err).Code
+		e.Status = ExtractionStatusFailed
+		e.ErrorCode = &code
+	}
+	e.UpdatedAt = time.Now().UTC()
+}
+
+// extractionBudget limits the bytes extracted from an archive to a ratio of its size. The bytes are counted
+// while reading, the sizes declared by the archive are not trusted.
+type extractionBudget struct {
+	maxBytes  int64
+	usedBytes int64
+}
+
+func newExtractionBudget(archiveSize int64) *extractionBudget {
+	return &extractionBudget{maxBytes: max(archiveSize, 1) * maxArchiveRatio}
+}
+
+// fits tells if size more bytes are within the budget, for the sizes declared by the archive.
+func (b *extractionBudget) fits(size int64) bool {
+	return b.usedBytes+size <= b.maxBytes
+}
+
+// reader reads the content of an entry of the declared size. Reading more than the declared size
+// or than the budget fails with ErrMediaUnsafeArchive.
+func (b *extractionBudget) reader(content io.Reader, size int64) io.Reader {
+	return &budgetReader{budget: b, r: content, left: size}
+}
+
+type budgetReader struct {
+	budget *extractionBudget
+	r      io.Reader
+	left   int64
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.left -= int64(n)
+	r.budget.usedBytes += int64(n)
+
+	if r.left < 0 {
+		return n, fmt.Errorf("%w: entry larger than its declared size", apperror.ErrMediaUnsafeArchive)
+	}
+	if r.budget.usedBytes > r.budget.maxBytes {
+		return n, fmt.Errorf("%w: more than %d bytes extracted", apperror.ErrMediaUnsafeArchive, r.budget.maxBytes)
+	}
+	return n, err
+}
+
+// numberedName returns the name with the number before its extension, e.g. "report (2).pdf".
+func numberedName(name string, n int) string {
+	ext := path.Ext(name)
+	return fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+}
@@ -0,0 +1,352 @@
+package media
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"skyvault/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanArchivePath(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "docs/report.pdf", want: "docs/report.pdf"},
+		{name: "docs/", want: "docs"},
+		{name: "./docs//work/./notes.txt", want: "docs/work/notes.txt"},
+		{name: "docs\\work\\notes.txt", want: "docs/work/notes.txt"},
+		{name: "./", want: ""},
+		{name: "../etc/passwd", wantErr: true},
+		{name: "docs/../../etc/passwd", wantErr: true},
+		{name: "docs/..", wantErr: true},
+		{name: "..\\windows\\system.ini", wantErr: true},
+		{name: "/etc/passwd", wantErr: true},
+		{name: "C:/windows/system.ini", wantErr: true},
+		{name: "docs/\x00.txt", wantErr: true},
+		{name: strings.Repeat("a/", maxArchivePathDepth) + "b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := cleanArchivePath(tt.name)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, apperror.ErrMediaUnsafeArchive)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetArchiveFormat(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		mimeType string
+		want     archiveFormat
+	}{
+		{name: "backup.zip", mimeType: "application/zip", want: archiveFormatZip},
+		{name: "backup", mimeType: "application/zip", want: archiveFormatZip},
+		{name: "backup.tar", mimeType: "application/octet-stream", want: archiveFormatTar},
+		{name: "Backup.TAR.GZ", mimeType: "application/gzip", want: archiveFormatTarGzip},
+		{name: "backup.tgz", mimeType: "application/gzip", want: archiveFormatTarGzip},
+		{name: "notes.txt.gz", mimeType: "application/gzip", want: ""},
+		{name: "photo.jpg", mimeType: "image/jpeg", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, getArchiveFormat(&FileInfo{Name: tt.name, MimeType: tt.mimeType}))
+		})
+	}
+}
+
+type testArchiveEntry struct {
+	name    string
+	content string
+	link    bool
+}
+
+func newTestZip(t *testing.T, entries []testArchiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.link {
+			header.SetMode(fs.ModeSymlink | 0o777)
+		}
+		w, err := zw.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func newTestTarGzip(t *testing.T, entries []testArchiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(newTestTar(t, entries))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func newTestTar(t *testing.T, entries []testArchiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(entry.name, "/") {
+			header.Typeflag = tar.TypeDir
+		}
+		if entry.link {
+			header = &tar.Header{Name: entry.name, Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+// walkTestArchive returns the walked entries, with the content of the files after the path.
+func walkTestArchive(archive []byte, format archiveFormat) ([]string, error) {
+	var got []string
+	err := walkArchive(bytes.NewReader(archive), int64(len(archive)), format, func(entry *ArchiveEntry, content io.Reader) error {
+		if entry.IsDir {
+			got = append(got, entry.Path+"/")
+			return nil
+		}
+		b, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		got = append(got, entry.Path+"="+string(b))
+		return nil
+	})
+	return got, err
+}
+
+func TestWalkArchive(t *testing.T) {
+	t.Parallel()
+	entries := []testArchiveEntry{
+		{name: "docs/"},
+		{name: "docs/notes.txt", content: "notes"},
+		{name: "./readme.md", content: "readme"},
+		{name: "docs/passwd", link: true},
+	}
+	want := []string{"docs/", "docs/notes.txt=notes", "readme.md=readme"}
+
+	t.Run("zip", func(t *testing.T) {
+		t.Parallel()
+		got, err := walkTestArchive(newTestZip(t, entries), archiveFormatZip)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("tar.gz", func(t *testing.T) {
+		t.Parallel()
+		got, err := walkTestArchive(newTestTarGzip(t, entries), archiveFormatTarGzip)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("zip slip", func(t *testing.T) {
+		t.Parallel()
+		archive := newTestZip(t, []testArchiveEntry{{name: "ok.txt"}, {name: "../../evil.sh", content: "rm -rf /"}})
+		_, err := walkTestArchive(archive, archiveFormatZip)
+		assert.ErrorIs(t, err, apperror.ErrMediaUnsafeArchive)
+	})
+
+	t.Run("tar slip", func(t *testing.T) {
+		t.Parallel()
+		archive := newTestTarGzip(t, []testArchiveEntry{{name: "/etc/cron.d/evil", content: "* * * * * root sh"}})
+		_, err := walkTestArchive(archive, archiveFormatTarGzip)
+		assert.ErrorIs(t, err, apperror.ErrMediaUnsafeArchive)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		t.Parallel()
+		_, err := walkTestArchive([]byte("not a zip"), archiveFormatZip)
+		assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+	})
+}
+
+func TestListArchive(t *testing.T) {
+	t.Parallel()
+	archive := newTestTar(t, []testArchiveEntry{
+		{name: "backup/"},
+		{name: "backup/db.sql", content: strings.Repeat("insert;", 100)},
+		{name: "backup/passwd", link: true},
+	})
+
+	entries, err := listArchive(bytes.NewReader(archive), int64(len(archive)), archiveFormatTar)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "backup", entries[0].Path)
+	assert.True(t, entries[0].IsDir)
+	assert.Equal(t, "backup/db.sql", entries[1].Path)
+	assert.Equal(t, int64(700), entries[1].Size)
+}
+
+func TestOpenArchiveEntry(t *testing.T) {
+	t.Parallel()
+	entries := []testArchiveEntry{
+		{name: "docs/"},
+		{name: "docs/notes.txt", content: "first notes"},
+		{name: "docs/report.txt", content: "the report"},
+	}
+
+	storedZip := func(t *testing.T) []byte {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for _, entry := range entries[1:] {
+			w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Store})
+			require.NoError(t, err)
+			_, err = w.Write([]byte(entry.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		archive  func(t *testing.T) []byte
+		format   archiveFormat
+		seekable bool
+	}{
+		{name: "deflated zip", archive: func(t *testing.T) []byte { return newTestZip(t, entries) }, format: archiveFormatZip},
+		{name: "stored zip", archive: storedZip, format: archiveFormatZip, seekable: true},
+		{name: "tar", archive: func(t *testing.T) []byte { return newTestTar(t, entries) }, format: archiveFormatTar, seekable: true},
+		{name: "tar.gz", archive: func(t *testing.T) []byte { return newTestTarGzip(t, entries) }, format: archiveFormatTarGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			archive := tt.archive(t)
+			file := bytes.NewReader(archive)
+
+			entry, content, err := openArchiveEntry(file, int64(len(archive)), tt.format, "docs/report.txt")
+			require.NoError(t, err)
+			assert.Equal(t, int64(len("the report")), entry.Size)
+
+			seeker, ok := content.(io.ReadSeeker)
+			require.Equal(t, tt.seekable, ok)
+			if ok {
+				// A range of the entry, as http.ServeContent reads it
+				_, err = seeker.Seek(4, io.SeekStart)
+				require.NoError(t, err)
+			}
+
+			b, err := io.ReadAll(content)
+			require.NoError(t, err)
+			if tt.seekable {
+				assert.Equal(t, "report", string(b))
+			} else {
+				assert.Equal(t, "the report", string(b))
+			}
+
+			_, _, err = openArchiveEntry(file, int64(len(archive)), tt.format, "docs/missing.txt")
+			assert.ErrorIs(t, err, apperror.ErrCommonNoData)
+		})
+	}
+}
+
+func TestExtractionBudget(t *testing.T) {
+	t.Parallel()
+
+	t.Run("within the ratio", func(t *testing.T) {
+		t.Parallel()
+		budget := newExtractionBudget(10)
+		assert.True(t, budget.fits(10*maxArchiveRatio))
+
+		n, err := io.Copy(io.Discard, budget.reader(strings.NewReader("hello"), 5))
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n)
+		assert.False(t, budget.fits(10*maxArchiveRatio))
+	})
+
+	t.Run("bomb", func(t *testing.T) {
+		t.Parallel()
+		// A tiny archive expanding to much more than the ratio allows
+		budget := newExtractionBudget(1)
+		content := strings.NewReader(strings.Repeat("0", 2*maxArchiveRatio))
+		_, err := io.Copy(io.Discard, budget.reader(content, 2*maxArchiveRatio))
+		assert.ErrorIs(t, err, apperror.ErrMediaUnsafeArchive)
+	})
+
+	t.Run("larger than declared", func(t *testing.T) {
+		t.Parallel()
+		budget := newExtractionBudget(1000)
+		_, err := io.Copy(io.Discard, budget.reader(strings.NewReader("hello"), 2))
+		assert.ErrorIs(t, err, apperror.ErrMediaUnsafeArchive)
+	})
+}
+
+func TestArchiveExtractorSkip(t *testing.T) {
+	t.Parallel()
+	x := &archiveExtractor{
+		ctx:        context.Background(),
+		extraction: &ArchiveExtraction{},
+		folders:    map[string]*FolderInfo{"": nil},
+	}
+
+	// Names not valid in the vault are skipped, not the archive
+	require.NoError(t, x.extractEntry(&ArchiveEntry{Path: " ", IsDir: true}, nil))
+	require.NoError(t, x.extractEntry(&ArchiveEntry{Path: "docs/ "}, strings.NewReader("")))
+	assert.Equal(t, int64(2), x.extraction.SkippedCount)
+
+	// Unlike the corrupt entries, also invalid values
+	corrupt := fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, zip.ErrChecksum)
+	assert.NotErrorIs(t, corrupt, errSkipArchiveEntry)
+}
+
+func TestNewArchiveExtraction(t *testing.T) {
+	t.Parallel()
+	archive := &FileInfo{ID: "archive", OwnerID: "owner", Name: "backup.zip", MimeType: "application/zip"}
+
+	extraction, err := NewArchiveExtraction("owner", archive, &FolderInfo{ID: "folder", OwnerID: "owner"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, ExtractConflictRename, extraction.Conflict)
+	assert.Equal(t, ExtractionStatusPending, extraction.Status)
+	assert.Equal(t, "folder", *extraction.FolderID)
+
+	_, err = NewArchiveExtraction("owner", archive, nil, "overwrite")
+	assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+
+	_, err = NewArchiveExtraction("owner", &FileInfo{ID: "photo", OwnerID: "owner", Name: "photo.jpg", MimeType: "image/jpeg"}, nil, "")
+	assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+
+	_, err = NewArchiveExtraction("other", archive, nil, "")
+	assert.ErrorIs(t, err, apperror.ErrCommonNoAccess)
+
+	extraction.Finish(apperror.NewAppError(apperror.ErrMediaQuotaExceeded, "test"))
+	assert.Equal(t, ExtractionStatusFailed, extraction.Status)
+	assert.Equal(t, apperror.ErrMediaQuotaExceeded.Code, *extraction.ErrorCode)
+}
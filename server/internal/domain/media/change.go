@@ -0,0 +1,93 @@
+package media
+
+import (
+	"fmt"
+	"skyvault/pkg/apperror"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultChangesLimit = 500
+	maxChangesLimit     = 1000
+
+	// Deletes are kept this long when the config doesn't set a retention,
+	// a sync client offline for longer must resync
+	defaultChangeRetentionDays = 30
+)
+
+type ChangeItemType string
+
+const (
+	ChangeItemTypeFile   ChangeItemType = "file"
+	ChangeItemTypeFolder ChangeItemType = "folder"
+)
+
+type ChangeType string
+
+const (
+	ChangeTypeCreated  ChangeType = "created"
+	ChangeTypeRenamed  ChangeType = "renamed"
+	ChangeTypeMoved    ChangeType = "moved"
+	ChangeTypeUpdated  ChangeType = "updated" // The content or the type of a file
+	ChangeTypeTrashed  ChangeType = "trashed"
+	ChangeTypeRestored ChangeType = "restored"
+	ChangeTypeDeleted  ChangeType = "deleted"
+)
+
+// Change is the last change of a file or folder, recorded by the repository in the transaction of the change.
+// Only the last change of an item is kept, so a change has the state of the item after it and the sync
+// clients apply every change but the deletes as an upsert of the item.
+type Change struct {
+	OwnerID        string
+	Seq            int64 // Increases with every change of the owner, in the commit order
+	ItemType       ChangeItemType
+	ItemID         string
+	ChangeType     ChangeType
+	Name           string
+	ParentFolderID *string // nil if the item is in the root folder
+	Size           int64   // 0 for the folders
+	Trashed        bool
+	ChangedAt      time.Time
+}
+
+// ChangeLogState is the sequence of the owner's changes.
+type ChangeLogState struct {
+	OwnerID string
+	LastSeq int64
+
+	// The deletes up to it may be purged by the compaction, the cursors before it can't be followed anymore
+	CompactedSeq int64
+}
+
+// ValidateCursor checks that the changes since the sequence of a cursor are all kept.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+// - ErrMediaChangeCursorExpired
+func (s *ChangeLogState) ValidateCursor(seq int64) error {
+	if seq < 0 || seq > s.LastSeq {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.ChangeLogState.ValidateCursor:Unknown").WithMetadata("seq", seq)
+	}
+
+	if seq < s.CompactedSeq {
+		return apperror.NewAppError(apperror.ErrMediaChangeCursorExpired, "media.ChangeLogState.ValidateCursor:Compacted").WithMetadata("seq", seq)
+	}
+
+	return nil
+}
+
+// The cursors are opaque to the clients, they only pass them back
+func encodeChangeCursor(seq int64) string {
+	return strconv.FormatInt(seq, 10)
+}
+
+// App Errors:
+// - ErrCommonInvalidValue
+func decodeChangeCursor(cursor string) (int64, error) {
+	seq, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid change cursor: %w", apperror.ErrCommonInvalidValue, err)
+	}
+	return seq, nil
+}
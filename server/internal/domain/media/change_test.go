@@ -0,0 +1,56 @@
+package media
+
+import (
+	"testing"
+
+	"skyvault/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeLogStateValidateCursor(t *testing.T) {
+	t.Parallel()
+	state := &ChangeLogState{LastSeq: 100, CompactedSeq: 40}
+
+	tests := []struct {
+		name    string
+		seq     int64
+		wantErr error
+	}{
+		{"last", 100, nil},
+		{"compacted", 40, nil},
+		{"between", 70, nil},
+		{"before compacted", 39, apperror.ErrMediaChangeCursorExpired},
+		{"after last", 101, apperror.ErrCommonInvalidValue},
+		{"negative", -1, apperror.ErrCommonInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := state.ValidateCursor(tt.seq)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("empty state", func(t *testing.T) {
+		t.Parallel()
+		assert.NoError(t, (&ChangeLogState{}).ValidateCursor(0))
+	})
+}
+
+func TestChangeCursor(t *testing.T) {
+	t.Parallel()
+
+	seq, err := decodeChangeCursor(encodeChangeCursor(1234))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1234), seq)
+
+	_, err = decodeChangeCursor("not-a-cursor")
+	assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+}
@@ -1,12 +1,22 @@
 package media
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
+	"io"
 	"math"
+	"os"
+	"path"
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
+	"skyvault/pkg/applog"
+	"skyvault/pkg/jobs"
+	"skyvault/pkg/utils"
+	"skyvault/pkg/validate"
+	"strings"
+	"time"
 )
 
 var _ Commands = (*CommandHandlers)(nil)
@@ -15,10 +25,12 @@ type CommandHandlers struct {
 	app        *appconfig.App
 	repository Repository
 	storage    Storage
+	scanner    Scanner
+	jobs       jobs.Queue
 }
 
-func NewCommandHandlers(app *appconfig.App, repository Repository, storage Storage) Commands {
-	return &CommandHandlers{app: app, repository: repository, storage: storage}
+func NewCommandHandlers(app *appconfig.App, repository Repository, storage Storage, scanner Scanner, jobs jobs.Queue) Commands {
+	return &CommandHandlers{app: app, repository: repository, storage: storage, scanner: scanner, jobs: jobs}
 }
 
 //--------------------------------
@@ -36,34 +48,103 @@ func (h *CommandHandlers) UploadFile(ctx context.Context, cmd *UploadFileCommand
 	}
 
 	fileConfig := FileConfig{
-		MaxSizeMB: h.app.Config.Media.MaxDirectUploadSizeMB,
+		MaxSizeMB:        h.app.Config.Media.MaxDirectUploadSizeMB,
+		AllowedMimeTypes: h.app.Config.Media.AllowedMimeTypes,
+		DeniedMimeTypes:  h.app.Config.Media.DeniedMimeTypes,
 	}
 
-	info, err := NewFileInfo(fileConfig, cmd.OwnerID, parentFolderInfo, cmd.Name, cmd.Size, cmd.MimeType)
+	// The Content-Type sent by the client is not trusted
+	mimeType, err := utils.DetectMimeType(cmd.File, cmd.Name)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.UploadFile:DetectMimeType")
+	}
+
+	info, err := NewFileInfo(fileConfig, cmd.OwnerID, parentFolderInfo, cmd.Name, cmd.Size, mimeType)
 	if err != nil {
 		return nil, apperror.NewAppError(err, "media.CommandHandlers.UploadFile:NewFileInfo")
 	}
 
+	err = h.validateQuota(ctx, cmd.OwnerID, info.Size)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.UploadFile:validateQuota")
+	}
+
 	// Saving to storage first to validate the file size once again, when actually reading and writing the file
 	err = h.storage.SaveFile(ctx, cmd.File, info.ID, cmd.OwnerID)
 	if err != nil {
 		return nil, apperror.NewAppError(err, "media.CommandHandlers.UploadFile:SaveFile").WithMetadata("file_id", info.ID)
 	}
 
-	// TODO: Generate previews asynchronously via background job
-	info, err = info.WithPreview(cmd.File)
+	created, err := h.createFileInfo(ctx, info)
 	if err != nil {
-		return nil, apperror.NewAppError(err, "media.CommandHandlers.UploadFile:WithPreview")
+		// Cleanup the file from storage
+		h.storage.DeleteFile(ctx, info.ID, cmd.OwnerID)
+
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.UploadFile:createFileInfo").WithMetadata("file_id", info.ID)
 	}
+	info = created
+
+	h.recordActivity(ctx, info, FileActivityUploaded)
+	h.enqueueFileJobs(ctx, info)
+
+	return info, nil
+}
 
-	info, err = h.repository.CreateFileInfo(ctx, info)
+func (h *CommandHandlers) ReplaceFileContent(ctx context.Context, cmd *ReplaceFileContentCommand) (*FileInfo, error) {
+	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
 	if err != nil {
-		// Cleanup the file from storage
-		h.storage.DeleteFile(ctx, info.ID, cmd.OwnerID)
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ReplaceFileContent:GetFileInfo")
+	}
+
+	if err := info.ValidateAccess(cmd.OwnerID); err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ReplaceFileContent:ValidateAccess")
+	}
+
+	fileConfig := FileConfig{
+		MaxSizeMB:        h.app.Config.Media.MaxDirectUploadSizeMB,
+		AllowedMimeTypes: h.app.Config.Media.AllowedMimeTypes,
+		DeniedMimeTypes:  h.app.Config.Media.DeniedMimeTypes,
+	}
+
+	// The Content-Type sent by the client is not trusted
+	mimeType, err := utils.DetectMimeType(cmd.File, info.Name)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ReplaceFileContent:DetectMimeType")
+	}
+
+	previousSize := info.Size
+	err = info.ReplaceContent(fileConfig, cmd.Size, mimeType)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ReplaceFileContent:ReplaceContent")
+	}
+
+	// The new content is saved aside, the file keeps its previous content until the info is saved
+	stagingName, err := utils.ID()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ReplaceFileContent:ID")
+	}
 
-		return nil, apperror.NewAppError(err, "media.CommandHandlers.UploadFile:CreateFileInfo")
+	err = h.storage.SaveFile(ctx, cmd.File, stagingName, cmd.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ReplaceFileContent:SaveFile").WithMetadata("file_id", info.ID)
+	}
+
+	err = h.replaceFileContent(ctx, info, previousSize, stagingName)
+	if err != nil {
+		// Cleanup the new content from storage, it is already moved if only the commit failed
+		h.storage.DeleteFile(ctx, stagingName, cmd.OwnerID)
+
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ReplaceFileContent:replaceFileContent").WithMetadata("file_id", info.ID)
+	}
+
+	// The previews of the previous content, the new ones are generated by the jobs
+	if err := h.storage.DeletePreviews(ctx, info.ID, info.OwnerID); err != nil {
+		applog.GetLoggerFromContext(ctx).Warn().Err(err).Str("file_id", info.ID).Msg("failed to delete previews")
 	}
 
+	h.recordActivity(ctx, info, FileActivityModified)
+	h.enqueueFileJobs(ctx, info)
+
 	return info, nil
 }
 
@@ -97,32 +178,62 @@ func (h *CommandHandlers) FinalizeChunkedUpload(ctx context.Context, cmd *Finali
 	}
 
 	fileConfig := FileConfig{
-		MaxSizeMB: h.app.Config.Media.MaxUploadSizeMB,
+		MaxSizeMB:        h.app.Config.Media.MaxUploadSizeMB,
+		AllowedMimeTypes: h.app.Config.Media.AllowedMimeTypes,
+		DeniedMimeTypes:  h.app.Config.Media.DeniedMimeTypes,
 	}
 
-	info, err := NewFileInfo(fileConfig, cmd.OwnerID, parentFolderInfo, cmd.FileName, cmd.FileSize, cmd.MimeType)
+	// The first chunk holds the bytes needed for the detection
+	mimeType, err := h.detectChunkedMimeType(ctx, cmd)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.FinalizeChunkedUpload:DetectMimeType")
+	}
+
+	info, err := NewFileInfo(fileConfig, cmd.OwnerID, parentFolderInfo, cmd.FileName, cmd.FileSize, mimeType)
 	if err != nil {
 		return nil, apperror.NewAppError(err, "media.CommandHandlers.FinalizeChunkedUpload:NewFileInfo")
 	}
 
+	err = h.validateQuota(ctx, cmd.OwnerID, info.Size)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.FinalizeChunkedUpload:validateQuota")
+	}
+
 	// Finalize the chunked upload by combining chunks
 	err = h.storage.FinalizeChunkedUpload(ctx, cmd.UploadID, info.ID, cmd.OwnerID)
 	if err != nil {
 		return nil, apperror.NewAppError(err, "media.CommandHandlers.FinalizeChunkedUpload:FinalizeChunkedUpload").WithMetadata("file_id", info.ID)
 	}
 
-	// Skip preview generation for chunked uploads (will be done asynchronously via background job)
-	info, err = h.repository.CreateFileInfo(ctx, info)
+	created, err := h.createFileInfo(ctx, info)
 	if err != nil {
 		// Cleanup the file from storage
 		h.storage.DeleteFile(ctx, info.ID, cmd.OwnerID)
 
-		return nil, apperror.NewAppError(err, "media.CommandHandlers.FinalizeChunkedUpload:CreateFileInfo").WithMetadata("file_id", info.ID)
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.FinalizeChunkedUpload:createFileInfo").WithMetadata("file_id", info.ID)
 	}
+	info = created
+
+	h.recordActivity(ctx, info, FileActivityUploaded)
+	h.enqueueFileJobs(ctx, info)
 
 	return info, nil
 }
 
+func (h *CommandHandlers) detectChunkedMimeType(ctx context.Context, cmd *FinalizeChunkedUploadCommand) (string, error) {
+	chunk, err := h.storage.OpenChunk(ctx, cmd.UploadID, 0, cmd.OwnerID)
+	if err != nil {
+		return "", apperror.NewAppError(err, "media.CommandHandlers.detectChunkedMimeType:OpenChunk").WithMetadata("upload_id", cmd.UploadID)
+	}
+	defer chunk.Close()
+
+	mimeType, err := utils.DetectMimeType(chunk, cmd.FileName)
+	if err != nil {
+		return "", apperror.NewAppError(err, "media.CommandHandlers.detectChunkedMimeType:DetectMimeType").WithMetadata("upload_id", cmd.UploadID)
+	}
+	return mimeType, nil
+}
+
 func (h *CommandHandlers) RenameFile(ctx context.Context, cmd *RenameFileCommand) error {
 	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
 	if err != nil {
@@ -140,6 +251,8 @@ func (h *CommandHandlers) RenameFile(ctx context.Context, cmd *RenameFileCommand
 		return apperror.NewAppError(err, "media.CommandHandlers.RenameFile:UpdateFileInfo")
 	}
 
+	h.recordActivity(ctx, info, FileActivityModified)
+
 	return nil
 }
 
@@ -170,6 +283,8 @@ func (h *CommandHandlers) MoveFile(ctx context.Context, cmd *MoveFileCommand) er
 		return apperror.NewAppError(err, "media.CommandHandlers.MoveFile:UpdateFileInfo")
 	}
 
+	h.recordActivity(ctx, info, FileActivityModified)
+
 	return nil
 }
 
@@ -207,141 +322,1333 @@ func (h *CommandHandlers) RestoreFile(ctx context.Context, cmd *RestoreFileComma
 	return nil
 }
 
-//--------------------------------
-// Folders
-//--------------------------------
+// createFileInfo saves the info of a new file. The quota is checked again under the lock of the usage,
+// in the transaction of the creation, so the concurrent uploads of the owner can't exceed it.
+//
+// App Errors:
+// - ErrCommonDuplicateData
+// - ErrMediaQuotaExceeded
+func (h *CommandHandlers) createFileInfo(ctx context.Context, info *FileInfo) (*FileInfo, error) {
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.createFileInfo:BeginTx")
+	}
+	defer tx.Rollback()
 
-func (h *CommandHandlers) CreateFolder(ctx context.Context, cmd *CreateFolderCommand) (*FolderInfo, error) {
-	var parentFolder *FolderInfo
-	if cmd.ParentFolderID != nil {
-		var err error
-		parentFolder, err = h.repository.GetFolderInfo(ctx, cmd.OwnerID, *cmd.ParentFolderID)
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	usage, err := repoTx.LockStorageUsage(ctx, info.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.createFileInfo:LockStorageUsage")
+	}
+
+	err = usage.ValidateWrite(h.app.Config.Media.DefaultQuotaMB, info.Size)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.createFileInfo:ValidateWrite").WithMetadata("owner_id", info.OwnerID)
+	}
+
+	created, err := repoTx.CreateFileInfo(ctx, info)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.createFileInfo:CreateFileInfo")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.createFileInfo:Commit")
+	}
+
+	return created, nil
+}
+
+// replaceFileContent saves the info of the file for its new content,
+// and moves the content from stagingName over the previous one, in a transaction.
+// The quota is checked under the lock of the storage usage, as for the uploads.
+//
+// App Errors:
+// - ErrCommonNoData
+// - ErrMediaQuotaExceeded
+func (h *CommandHandlers) replaceFileContent(ctx context.Context, info *FileInfo, previousSize int64, stagingName string) error {
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.replaceFileContent:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	usage, err := repoTx.LockStorageUsage(ctx, info.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.replaceFileContent:LockStorageUsage")
+	}
+
+	// A smaller content frees space, it is always accepted
+	if grown := info.Size - previousSize; grown > 0 {
+		err = usage.ValidateWrite(h.app.Config.Media.DefaultQuotaMB, grown)
 		if err != nil {
-			return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolder:GetParentFolderInfo")
+			return apperror.NewAppError(err, "media.CommandHandlers.replaceFileContent:ValidateWrite").WithMetadata("owner_id", info.OwnerID)
 		}
 	}
 
-	info, err := NewFolderInfo(cmd.OwnerID, cmd.Name, parentFolder)
+	err = repoTx.ReplaceFileInfoContent(ctx, info)
 	if err != nil {
-		return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolder:NewFolderInfo")
+		return apperror.NewAppError(err, "media.CommandHandlers.replaceFileContent:ReplaceFileInfoContent")
 	}
 
-	info, err = h.repository.CreateFolderInfo(ctx, info)
+	// Swapped last, so that a failure of the swap rolls the info back
+	err = h.storage.ReplaceFile(ctx, stagingName, info.ID, info.OwnerID)
 	if err != nil {
-		return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolder:CreateFolderInfo")
+		return apperror.NewAppError(err, "media.CommandHandlers.replaceFileContent:ReplaceFile")
 	}
 
-	return info, nil
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.replaceFileContent:Commit")
+	}
+
+	return nil
 }
 
-func (h *CommandHandlers) RenameFolder(ctx context.Context, cmd *RenameFolderCommand) error {
-	info, err := h.repository.GetFolderInfo(ctx, cmd.OwnerID, cmd.FolderID)
+//--------------------------------
+// Archives
+//--------------------------------
+
+func (h *CommandHandlers) ExtractArchive(ctx context.Context, cmd *ExtractArchiveCommand) (*ArchiveExtraction, error) {
+	archive, err := h.repository.GetFileInfo(ctx, cmd.FileID)
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.RenameFolder:GetFolderInfo")
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ExtractArchive:GetFileInfo")
 	}
 
-	err = info.ValidateAccess(cmd.OwnerID)
+	var folder *FolderInfo
+	if cmd.FolderID != nil {
+		folder, err = h.repository.GetFolderInfo(ctx, cmd.OwnerID, *cmd.FolderID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "media.CommandHandlers.ExtractArchive:GetFolderInfo")
+		}
+	}
+
+	extraction, err := NewArchiveExtraction(cmd.OwnerID, archive, folder, cmd.Conflict)
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.RenameFolder:ValidateAccess")
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ExtractArchive:NewArchiveExtraction")
 	}
 
-	info.Rename(cmd.Name)
+	extraction, err = h.repository.CreateArchiveExtraction(ctx, extraction)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ExtractArchive:CreateArchiveExtraction")
+	}
 
-	err = h.repository.UpdateFolderInfo(ctx, info)
+	err = h.enqueueArchiveExtraction(ctx, extraction)
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.RenameFolder:UpdateFolderInfo")
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.ExtractArchive:enqueueArchiveExtraction")
+	}
+
+	return extraction, nil
+}
+
+// enqueueArchiveExtraction runs the pending extraction in the background.
+// Unlike the scans, a failed extraction is not picked up later, it is saved as failed if it can't be enqueued.
+func (h *CommandHandlers) enqueueArchiveExtraction(ctx context.Context, extraction *ArchiveExtraction) error {
+	runCmd := &RunArchiveExtractionCommand{OwnerID: extraction.OwnerID, ExtractionID: extraction.ID}
+	err := h.jobs.Enqueue("media.ExtractArchive", func(ctx context.Context) error {
+		return h.RunArchiveExtraction(ctx, runCmd)
+	})
+	if err != nil {
+		extraction.Finish(err)
+		if updateErr := h.repository.UpdateArchiveExtraction(ctx, extraction); updateErr != nil {
+			applog.GetLoggerFromContext(ctx).Warn().Err(updateErr).Str("extraction_id", extraction.ID).Msg("failed to save the extraction as failed")
+		}
+		return apperror.NewAppError(err, "media.CommandHandlers.enqueueArchiveExtraction:Enqueue")
 	}
 
 	return nil
 }
 
-func (h *CommandHandlers) MoveFolder(ctx context.Context, cmd *MoveFolderCommand) error {
-	info, err := h.repository.GetFolderInfo(ctx, cmd.OwnerID, cmd.FolderID)
+func (h *CommandHandlers) ResumeArchiveExtractions(ctx context.Context, cmd *ResumeArchiveExtractionsCommand) (int, error) {
+	// The running ones first, the pending ones enqueued below may start running meanwhile
+	failed, err := h.eachArchiveExtraction(ctx, ExtractionStatusRunning, cmd.BatchSize, func(extraction *ArchiveExtraction) error {
+		extraction.Finish(errExtractionInterrupted)
+		return h.repository.UpdateArchiveExtraction(ctx, extraction)
+	})
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:GetFolderInfo")
+		return failed, apperror.NewAppError(err, "media.CommandHandlers.ResumeArchiveExtractions:Running")
 	}
 
-	err = info.ValidateAccess(cmd.OwnerID)
+	enqueued, err := h.eachArchiveExtraction(ctx, ExtractionStatusPending, cmd.BatchSize, func(extraction *ArchiveExtraction) error {
+		// The extraction is saved as failed, the others can still be enqueued
+		if err := h.enqueueArchiveExtraction(ctx, extraction); err != nil {
+			applog.GetLoggerFromContext(ctx).Warn().Err(err).Str("extraction_id", extraction.ID).Msg("failed to enqueue the extraction")
+		}
+		return nil
+	})
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:ValidateAccess")
+		return failed + enqueued, apperror.NewAppError(err, "media.CommandHandlers.ResumeArchiveExtractions:Pending")
 	}
 
-	var destFolderInfo *FolderInfo
-	if cmd.ParentFolderID != nil {
-		destFolderInfo, err = h.repository.GetFolderInfo(ctx, cmd.OwnerID, *cmd.ParentFolderID)
+	return failed + enqueued, nil
+}
+
+// eachArchiveExtraction runs fn on the extractions with the status, batch by batch in the order of their IDs.
+func (h *CommandHandlers) eachArchiveExtraction(ctx context.Context, status ExtractionStatus, batchSize int, fn func(extraction *ArchiveExtraction) error) (int, error) {
+	count := 0
+	afterID := ""
+	for {
+		extractions, err := h.repository.GetArchiveExtractionsByStatus(ctx, status, afterID, batchSize)
 		if err != nil {
-			return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:GetParentFolderInfo")
+			return count, apperror.NewAppError(err, "media.CommandHandlers.eachArchiveExtraction:GetArchiveExtractionsByStatus")
+		}
+
+		for _, extraction := range extractions {
+			if err := fn(extraction); err != nil {
+				return count, apperror.NewAppError(err, "media.CommandHandlers.eachArchiveExtraction:fn").WithMetadata("extraction_id", extraction.ID)
+			}
+			count++
 		}
+
+		if len(extractions) < batchSize {
+			return count, nil
+		}
+		afterID = extractions[len(extractions)-1].ID
 	}
+}
 
-	descendantFolderIDs, err := h.repository.GetDescendantFolderIDs(ctx, cmd.OwnerID, cmd.FolderID)
+func (h *CommandHandlers) RunArchiveExtraction(ctx context.Context, cmd *RunArchiveExtractionCommand) error {
+	extraction, err := h.repository.GetArchiveExtraction(ctx, cmd.OwnerID, cmd.ExtractionID)
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:GetDescendantFolderIDs")
+		return apperror.NewAppError(err, "media.CommandHandlers.RunArchiveExtraction:GetArchiveExtraction")
 	}
 
-	if err := info.MoveTo(destFolderInfo, descendantFolderIDs); err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:MoveTo")
+	if extraction.Status != ExtractionStatusPending {
+		return nil
 	}
 
-	err = h.repository.UpdateFolderInfo(ctx, info)
+	extraction.Start()
+	err = h.repository.UpdateArchiveExtraction(ctx, extraction)
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:UpdateFolderInfo")
+		return apperror.NewAppError(err, "media.CommandHandlers.RunArchiveExtraction:UpdateArchiveExtraction:Start")
+	}
+
+	runErr := h.extractArchive(ctx, extraction)
+	extraction.Finish(runErr)
+
+	// The job timeout may have cancelled ctx, the final status must be saved anyway
+	err = h.repository.UpdateArchiveExtraction(context.WithoutCancel(ctx), extraction)
+	if runErr != nil {
+		return apperror.NewAppError(runErr, "media.CommandHandlers.RunArchiveExtraction:extractArchive").WithMetadata("extraction_id", extraction.ID)
+	}
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RunArchiveExtraction:UpdateArchiveExtraction:Finish")
 	}
 
 	return nil
 }
 
-func (h *CommandHandlers) TrashFolders(ctx context.Context, cmd *TrashFoldersCommand) error {
-	err := h.repository.TrashFolderInfos(ctx, cmd.OwnerID, cmd.FolderIDs)
+func (h *CommandHandlers) extractArchive(ctx context.Context, extraction *ArchiveExtraction) error {
+	archive, err := h.repository.GetFileInfo(ctx, extraction.FileID)
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.TrashFolders:TrashFolderInfos")
+		return apperror.NewAppError(err, "media.CommandHandlers.extractArchive:GetFileInfo")
+	}
+
+	// The archive may have been quarantined since the extraction was requested
+	if err := archive.ValidateNotQuarantined(); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.extractArchive:ValidateNotQuarantined")
+	}
+
+	var folder *FolderInfo
+	if extraction.FolderID != nil {
+		folder, err = h.repository.GetFolderInfo(ctx, extraction.OwnerID, *extraction.FolderID)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.extractArchive:GetFolderInfo")
+		}
+	}
+
+	file, err := h.storage.OpenFile(ctx, archive.ID, extraction.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.extractArchive:OpenFile")
+	}
+	defer file.Close()
+
+	x := &archiveExtractor{
+		h:          h,
+		ctx:        ctx,
+		extraction: extraction,
+		folders:    map[string]*FolderInfo{"": folder},
+		budget:     newExtractionBudget(archive.Size),
+		fileConfig: FileConfig{
+			// The storage doesn't save larger files without chunks
+			MaxSizeMB:        h.app.Config.Media.MaxDirectUploadSizeMB,
+			AllowedMimeTypes: h.app.Config.Media.AllowedMimeTypes,
+			DeniedMimeTypes:  h.app.Config.Media.DeniedMimeTypes,
+		},
+	}
+
+	err = walkArchive(file, archive.Size, getArchiveFormat(archive), x.extractEntry)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.extractArchive:walkArchive")
 	}
 
 	return nil
 }
 
-func (h *CommandHandlers) RestoreFolder(ctx context.Context, cmd *RestoreFolderCommand) error {
-	info, err := h.repository.GetFolderInfoTrashed(ctx, cmd.OwnerID, cmd.FolderID)
+// Number of entries after which the progress of the extraction is saved
+const extractionProgressInterval = 100
+
+// archiveExtractor recreates the entries of an archive, see CommandHandlers.RunArchiveExtraction.
+type archiveExtractor struct {
+	h          *CommandHandlers
+	ctx        context.Context
+	extraction *ArchiveExtraction
+	folders    map[string]*FolderInfo // By path in the archive, "" is the target folder
+	budget     *extractionBudget
+	fileConfig FileConfig
+	entries    int
+}
+
+func (x *archiveExtractor) extractEntry(entry *ArchiveEntry, content io.Reader) error {
+	if err := x.ctx.Err(); err != nil {
+		return apperror.NewAppError(err, "media.archiveExtractor.extractEntry:ctx")
+	}
+
+	x.entries++
+	if x.entries%extractionProgressInterval == 0 {
+		x.extraction.UpdatedAt = time.Now().UTC()
+		if err := x.h.repository.UpdateArchiveExtraction(x.ctx, x.extraction); err != nil {
+			applog.GetLoggerFromContext(x.ctx).Warn().Err(err).Str("extraction_id", x.extraction.ID).Msg("failed to save the extraction progress")
+		}
+	}
+
+	var err error
+	if entry.IsDir {
+		_, err = x.folder(entry.Path)
+	} else {
+		err = x.extractFile(entry, content)
+	}
+
+	if errors.Is(err, errSkipArchiveEntry) {
+		x.extraction.SkippedCount++
+		return nil
+	}
+	return err
+}
+
+func (x *archiveExtractor) extractFile(entry *ArchiveEntry, content io.Reader) error {
+	dir, name := path.Split(entry.Path)
+	name, err := validate.FileName(name)
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:GetFolderInfo")
+		return apperror.NewAppError(fmt.Errorf("%w: %w", errSkipArchiveEntry, err), "media.archiveExtractor.extractFile:FileName")
 	}
 
-	parentFolderIsTrashed, err := h.isParentFolderTrashed(ctx, cmd.OwnerID, info.ParentFolderID)
+	if entry.Size > x.fileConfig.MaxSizeMB*bytesPerMB {
+		x.extraction.SkippedCount++
+		return nil
+	}
+
+	if !x.budget.fits(entry.Size) {
+		return apperror.NewAppError(fmt.Errorf("%w: more than %d bytes declared", apperror.ErrMediaUnsafeArchive, x.budget.maxBytes), "media.archiveExtractor.extractFile:fits")
+	}
+
+	if err := x.h.validateQuota(x.ctx, x.extraction.OwnerID, entry.Size); err != nil {
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:validateQuota")
+	}
+
+	parent, err := x.folder(strings.TrimSuffix(dir, "/"))
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:IsParentFolderTrashed")
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:folder")
 	}
 
-	var tx *sql.Tx
-	repoTx := h.repository
-	if parentFolderIsTrashed {
-		// Make root the new parent folder, since the original parent folder is trashed
-		tx, err = h.repository.BeginTx(ctx)
-		if err != nil {
-			return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:BeginTx")
+	// The storage needs to seek the content, the archive entries are only read once
+	tmp, err := os.CreateTemp("", "skyvault-extract-*")
+	if err != nil {
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:CreateTemp")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, x.budget.reader(content, entry.Size))
+	if err != nil {
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:Copy").WithMetadata("path", entry.Path)
+	}
+
+	mimeType, err := utils.DetectMimeType(tmp, name)
+	if err != nil {
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:DetectMimeType")
+	}
+
+	info, err := NewFileInfo(x.fileConfig, x.extraction.OwnerID, parent, name, size, mimeType)
+	if errors.Is(err, apperror.ErrMediaFileTypeNotAllowed) {
+		x.extraction.SkippedCount++
+		return nil
+	}
+	if err != nil {
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:NewFileInfo")
+	}
+
+	err = x.h.storage.SaveFile(x.ctx, tmp, info.ID, x.extraction.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:SaveFile").WithMetadata("file_id", info.ID)
+	}
+
+	info, err = x.createFileInfo(info)
+	if err != nil {
+		x.h.storage.DeleteFile(x.ctx, info.ID, x.extraction.OwnerID)
+
+		if errors.Is(err, apperror.ErrCommonDuplicateData) {
+			x.extraction.SkippedCount++
+			return nil
 		}
+		return apperror.NewAppError(err, "media.archiveExtractor.extractFile:createFileInfo")
+	}
 
-		repoTx = h.repository.WithTx(ctx, tx)
-		defer tx.Rollback()
+	x.extraction.FileCount++
+	x.extraction.ExtractedBytes += info.Size
 
-		info.ParentFolderID = nil
+	x.h.recordActivity(x.ctx, info, FileActivityUploaded)
+	x.h.enqueueFileJobs(x.ctx, info)
 
-		err = repoTx.UpdateFolderInfo(ctx, info)
-		if err != nil {
-			return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:UpdateFolderInfo")
+	return nil
+}
+
+// Max. number of "name (n)" tried for a file whose name is already used
+const maxConflictRenames = 100
+
+// createFileInfo applies the conflict policy of the extraction when the name is already used in the folder.
+//
+// App Errors:
+// - ErrCommonDuplicateData (skipped)
+func (x *archiveExtractor) createFileInfo(info *FileInfo) (*FileInfo, error) {
+	name := info.Name
+	for n := 2; ; n++ {
+		created, err := x.h.createFileInfo(x.ctx, info)
+		if err == nil {
+			return created, nil
+		}
+
+		if !errors.Is(err, apperror.ErrCommonDuplicateData) || x.extraction.Conflict == ExtractConflictSkip || n > maxConflictRenames {
+			return info, err
 		}
+		info.Rename(numberedName(name, n))
 	}
+}
 
-	// Restore the main folder and all nested items
-	err = repoTx.RestoreFolderInfo(ctx, cmd.OwnerID, cmd.FolderID)
+// folder returns the folder of the path in the archive, the missing folders are created and the existing ones merged.
+// A folder whose name is not valid in the vault fails with errSkipArchiveEntry, along with its entries.
+func (x *archiveExtractor) folder(dirPath string) (*FolderInfo, error) {
+	if folder, ok := x.folders[dirPath]; ok {
+		return folder, nil
+	}
+
+	parentPath, name := path.Split(dirPath)
+	parent, err := x.folder(strings.TrimSuffix(parentPath, "/"))
 	if err != nil {
-		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:RestoreFolderInfos")
+		return nil, err
 	}
 
-	if parentFolderIsTrashed {
-		err = tx.Commit()
+	name, err = validate.FileName(name)
+	if err != nil {
+		return nil, apperror.NewAppError(fmt.Errorf("%w: %w", errSkipArchiveEntry, err), "media.archiveExtractor.folder:FileName")
+	}
+
+	var parentID *string
+	if parent != nil {
+		parentID = &parent.ID
+	}
+
+	folder, err := x.h.repository.GetFolderInfoByName(x.ctx, x.extraction.OwnerID, parentID, name)
+	if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+		return nil, apperror.NewAppError(err, "media.archiveExtractor.folder:GetFolderInfoByName")
+	}
+
+	if folder == nil {
+		folder, err = NewFolderInfo(x.extraction.OwnerID, name, parent)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "media.archiveExtractor.folder:NewFolderInfo")
+		}
+
+		folder, err = x.h.repository.CreateFolderInfo(x.ctx, folder)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "media.archiveExtractor.folder:CreateFolderInfo")
+		}
+		x.extraction.FolderCount++
+	}
+
+	x.folders[dirPath] = folder
+	return folder, nil
+}
+
+//--------------------------------
+// Background jobs
+//--------------------------------
+
+// enqueueFileJobs schedules the background processing of a newly created file.
+// Failing to enqueue is not fatal for the upload, the file is picked up by the Backfill commands.
+func (h *CommandHandlers) enqueueFileJobs(ctx context.Context, info *FileInfo) {
+	h.enqueueFileJob(ctx, "media.ScanFile", info, func(ctx context.Context) error {
+		return h.ScanFile(ctx, &ScanFileCommand{OwnerID: info.OwnerID, FileID: info.ID})
+	})
+
+	if info.PreviewStatus == PreviewStatusPending {
+		h.enqueueFileJob(ctx, "media.GeneratePreviews", info, func(ctx context.Context) error {
+			return h.GeneratePreviews(ctx, &GeneratePreviewsCommand{OwnerID: info.OwnerID, FileID: info.ID})
+		})
+	}
+
+	if info.HasMetadata() {
+		h.enqueueFileJob(ctx, "media.ExtractMetadata", info, func(ctx context.Context) error {
+			return h.ExtractMetadata(ctx, &ExtractMetadataCommand{OwnerID: info.OwnerID, FileID: info.ID})
+		})
+	}
+
+	if info.HasSearchableText() {
+		h.enqueueFileJob(ctx, "media.IndexFileText", info, func(ctx context.Context) error {
+			return h.IndexFileText(ctx, &IndexFileTextCommand{OwnerID: info.OwnerID, FileID: info.ID})
+		})
+	}
+}
+
+func (h *CommandHandlers) enqueueFileJob(ctx context.Context, name string, info *FileInfo, job func(ctx context.Context) error) {
+	if err := h.jobs.Enqueue(name, job); err != nil {
+		applog.GetLoggerFromContext(ctx).Warn().Err(err).Str("job", name).Str("file_id", info.ID).Msg("failed to enqueue file job")
+	}
+}
+
+// backfillFiles runs process on the files returned by next, batch by batch in the order of their IDs.
+// A file failing to process doesn't stop the backfill, process saves the outcome of the file anyway.
+// process reports whether the file needed processing, the count only includes those.
+func (h *CommandHandlers) backfillFiles(
+	ctx context.Context,
+	batchSize int,
+	next func(ctx context.Context, afterID string, limit int) ([]*FileInfo, error),
+	process func(ctx context.Context, info *FileInfo) (bool, error),
+) (int, error) {
+	logger := applog.GetLoggerFromContext(ctx)
+
+	count := 0
+	afterID := ""
+	for {
+		infos, err := next(ctx, afterID, batchSize)
 		if err != nil {
-			return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:Commit")
+			return count, apperror.NewAppError(err, "media.CommandHandlers.backfillFiles:next").WithMetadata("after_id", afterID)
 		}
+
+		for _, info := range infos {
+			processed, err := process(ctx, info)
+			if err != nil {
+				logger.Warn().Err(err).Str("file_id", info.ID).Msg("failed to backfill file")
+			}
+			if processed {
+				count++
+			}
+		}
+
+		if len(infos) < batchSize {
+			return count, nil
+		}
+		afterID = infos[len(infos)-1].ID
+	}
+}
+
+//--------------------------------
+// Scans
+//--------------------------------
+
+func (h *CommandHandlers) ScanFile(ctx context.Context, cmd *ScanFileCommand) error {
+	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ScanFile:GetFileInfo")
+	}
+
+	if err := info.ValidateAccess(cmd.OwnerID); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ScanFile:ValidateAccess")
+	}
+
+	res, scanErr := h.scanFile(ctx, info)
+	info.SetScanResult(res, scanErr)
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ScanFile:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.UpdateFileInfoScanStatus(ctx, info.ID, info.ScanStatus, info.ScanSignature)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ScanFile:UpdateFileInfoScanStatus")
+	}
+
+	if info.IsQuarantined() {
+		// The owner is notified through the event, and sees the file in the quarantine, see Queries.GetQuarantinedFiles
+		err = repoTx.AddDomainEvents(ctx, FileQuarantined{
+			OwnerID:   info.OwnerID,
+			FileID:    info.ID,
+			Name:      info.Name,
+			Signature: *info.ScanSignature,
+		})
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.ScanFile:AddDomainEvents")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ScanFile:Commit")
+	}
+
+	if info.IsQuarantined() {
+		applog.GetLoggerFromContext(ctx).Warn().Str("file_id", info.ID).Str("owner_id", info.OwnerID).Str("signature", *info.ScanSignature).Msg("infected file quarantined")
+	}
+
+	if info.ScanStatus == ScanStatusFailed {
+		return apperror.NewAppError(scanErr, "media.CommandHandlers.ScanFile:scanFile").WithMetadata("file_id", info.ID)
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) scanFile(ctx context.Context, info *FileInfo) (*ScanResult, error) {
+	file, err := h.storage.OpenFile(ctx, info.ID, info.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.scanFile:OpenFile")
+	}
+	defer file.Close()
+
+	res, err := h.scanner.Scan(ctx, file)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.scanFile:Scan")
+	}
+	return res, nil
+}
+
+func (h *CommandHandlers) BackfillScans(ctx context.Context, cmd *BackfillScansCommand) (int, error) {
+	count, err := h.backfillFiles(ctx, cmd.BatchSize, h.repository.GetFileInfosPendingScan, func(ctx context.Context, info *FileInfo) (bool, error) {
+		return true, h.ScanFile(ctx, &ScanFileCommand{OwnerID: info.OwnerID, FileID: info.ID})
+	})
+	if err != nil {
+		return count, apperror.NewAppError(err, "media.CommandHandlers.BackfillScans:backfillFiles")
+	}
+	return count, nil
+}
+
+//--------------------------------
+// Previews
+//--------------------------------
+
+func (h *CommandHandlers) GeneratePreviews(ctx context.Context, cmd *GeneratePreviewsCommand) error {
+	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.GeneratePreviews:GetFileInfo")
+	}
+
+	if err := info.ValidateAccess(cmd.OwnerID); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.GeneratePreviews:ValidateAccess")
+	}
+
+	genErr := h.generatePreviews(ctx, info)
+	info.SetPreviewStatus(genErr)
+	if info.PreviewStatus != PreviewStatusReady {
+		// Don't leave previews of some sizes behind
+		if err := h.storage.DeletePreviews(ctx, info.ID, info.OwnerID); err != nil {
+			applog.GetLoggerFromContext(ctx).Warn().Err(err).Str("file_id", info.ID).Msg("failed to delete previews")
+		}
+	}
+
+	err = h.repository.UpdateFileInfoPreviewStatus(ctx, info.ID, info.PreviewStatus)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.GeneratePreviews:UpdateFileInfoPreviewStatus")
+	}
+
+	if info.PreviewStatus == PreviewStatusFailed {
+		return apperror.NewAppError(genErr, "media.CommandHandlers.GeneratePreviews:generatePreviews").WithMetadata("file_id", info.ID)
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) generatePreviews(ctx context.Context, info *FileInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, previewTimeout)
+	defer cancel()
+
+	file, err := h.storage.OpenFile(ctx, info.ID, info.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.generatePreviews:OpenFile")
+	}
+	defer file.Close()
+
+	previews, err := info.GeneratePreviews(ctx, file, PreviewSizes)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.generatePreviews:GeneratePreviews")
+	}
+
+	for i, size := range PreviewSizes {
+		err = h.storage.SavePreview(ctx, bytes.NewReader(previews[i]), info.ID, size, info.OwnerID)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.generatePreviews:SavePreview").WithMetadata("size", size)
+		}
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) BackfillPreviews(ctx context.Context, cmd *BackfillPreviewsCommand) (int, error) {
+	count, err := h.backfillFiles(ctx, cmd.BatchSize, h.repository.GetFileInfosPendingPreview, func(ctx context.Context, info *FileInfo) (bool, error) {
+		return true, h.GeneratePreviews(ctx, &GeneratePreviewsCommand{OwnerID: info.OwnerID, FileID: info.ID})
+	})
+	if err != nil {
+		return count, apperror.NewAppError(err, "media.CommandHandlers.BackfillPreviews:backfillFiles")
+	}
+	return count, nil
+}
+
+//--------------------------------
+// Metadata
+//--------------------------------
+
+func (h *CommandHandlers) ExtractMetadata(ctx context.Context, cmd *ExtractMetadataCommand) error {
+	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ExtractMetadata:GetFileInfo")
+	}
+
+	if err := info.ValidateAccess(cmd.OwnerID); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ExtractMetadata:ValidateAccess")
+	}
+
+	file, err := h.storage.OpenFile(ctx, info.ID, info.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ExtractMetadata:OpenFile")
+	}
+	defer file.Close()
+
+	meta, extractErr := NewFileMetadata(info, file)
+
+	err = h.repository.UpsertFileMetadata(ctx, meta)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.ExtractMetadata:UpsertFileMetadata")
+	}
+
+	if extractErr != nil {
+		return apperror.NewAppError(extractErr, "media.CommandHandlers.ExtractMetadata:NewFileMetadata").WithMetadata("file_id", info.ID)
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) BackfillMetadata(ctx context.Context, cmd *BackfillMetadataCommand) (int, error) {
+	count, err := h.backfillFiles(ctx, cmd.BatchSize, h.repository.GetFileInfosWithoutMetadata, func(ctx context.Context, info *FileInfo) (bool, error) {
+		return true, h.ExtractMetadata(ctx, &ExtractMetadataCommand{OwnerID: info.OwnerID, FileID: info.ID})
+	})
+	if err != nil {
+		return count, apperror.NewAppError(err, "media.CommandHandlers.BackfillMetadata:backfillFiles")
+	}
+	return count, nil
+}
+
+//--------------------------------
+// Search
+//--------------------------------
+
+func (h *CommandHandlers) IndexFileText(ctx context.Context, cmd *IndexFileTextCommand) error {
+	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.IndexFileText:GetFileInfo")
+	}
+
+	if err := info.ValidateAccess(cmd.OwnerID); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.IndexFileText:ValidateAccess")
+	}
+
+	file, err := h.storage.OpenFile(ctx, info.ID, info.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.IndexFileText:OpenFile")
+	}
+	defer file.Close()
+
+	text, readErr := NewFileText(info, file)
+	if readErr != nil {
+		// Save an empty text, the file would be retried forever otherwise
+		text = &FileText{FileID: info.ID, OwnerID: info.OwnerID}
+	}
+
+	err = h.repository.UpsertFileText(ctx, text)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.IndexFileText:UpsertFileText")
+	}
+
+	if readErr != nil {
+		return apperror.NewAppError(readErr, "media.CommandHandlers.IndexFileText:NewFileText").WithMetadata("file_id", info.ID)
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) BackfillFileTexts(ctx context.Context, cmd *BackfillFileTextsCommand) (int, error) {
+	count, err := h.backfillFiles(ctx, cmd.BatchSize, h.repository.GetFileInfosWithoutText, func(ctx context.Context, info *FileInfo) (bool, error) {
+		// Some files of the "other" category are text, e.g. JSON, see FileInfo.HasSearchableText
+		if !info.HasSearchableText() {
+			return false, nil
+		}
+		return true, h.IndexFileText(ctx, &IndexFileTextCommand{OwnerID: info.OwnerID, FileID: info.ID})
+	})
+	if err != nil {
+		return count, apperror.NewAppError(err, "media.CommandHandlers.BackfillFileTexts:backfillFiles")
+	}
+	return count, nil
+}
+
+//--------------------------------
+// Tags
+//--------------------------------
+
+func (h *CommandHandlers) CreateTag(ctx context.Context, cmd *CreateTagCommand) (*Tag, error) {
+	tag, err := NewTag(cmd.OwnerID, cmd.Name, cmd.Color)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateTag:NewTag")
+	}
+
+	tag, err = h.repository.CreateTag(ctx, tag)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateTag:CreateTag")
+	}
+
+	return tag, nil
+}
+
+func (h *CommandHandlers) UpdateTag(ctx context.Context, cmd *UpdateTagCommand) error {
+	tag, err := h.repository.GetTag(ctx, cmd.OwnerID, cmd.TagID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UpdateTag:GetTag")
+	}
+
+	if err := tag.Update(cmd.Name, cmd.Color); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UpdateTag:Update")
+	}
+
+	err = h.repository.UpdateTag(ctx, tag)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UpdateTag:UpdateTag")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) DeleteTag(ctx context.Context, cmd *DeleteTagCommand) error {
+	err := h.repository.DeleteTag(ctx, cmd.OwnerID, cmd.TagID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.DeleteTag:DeleteTag")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) TagItems(ctx context.Context, cmd *TagItemsCommand) error {
+	_, err := h.repository.GetTag(ctx, cmd.OwnerID, cmd.TagID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.TagItems:GetTag")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.TagItems:BeginTx")
+	}
+	defer tx.Rollback()
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	if len(cmd.FileIDs) > 0 {
+		err = repoTx.TagFileInfos(ctx, cmd.OwnerID, cmd.TagID, cmd.FileIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.TagItems:TagFileInfos")
+		}
+	}
+
+	if len(cmd.FolderIDs) > 0 {
+		err = repoTx.TagFolderInfos(ctx, cmd.OwnerID, cmd.TagID, cmd.FolderIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.TagItems:TagFolderInfos")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.TagItems:Commit")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) UntagItems(ctx context.Context, cmd *UntagItemsCommand) error {
+	_, err := h.repository.GetTag(ctx, cmd.OwnerID, cmd.TagID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UntagItems:GetTag")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UntagItems:BeginTx")
+	}
+	defer tx.Rollback()
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	if len(cmd.FileIDs) > 0 {
+		err = repoTx.UntagFileInfos(ctx, cmd.OwnerID, cmd.TagID, cmd.FileIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.UntagItems:UntagFileInfos")
+		}
+	}
+
+	if len(cmd.FolderIDs) > 0 {
+		err = repoTx.UntagFolderInfos(ctx, cmd.OwnerID, cmd.TagID, cmd.FolderIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.UntagItems:UntagFolderInfos")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UntagItems:Commit")
+	}
+
+	return nil
+}
+
+//--------------------------------
+// Starred & Recent
+//--------------------------------
+
+func (h *CommandHandlers) StarItems(ctx context.Context, cmd *StarItemsCommand) error {
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.StarItems:BeginTx")
+	}
+	defer tx.Rollback()
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	if len(cmd.FileIDs) > 0 {
+		err = repoTx.StarFileInfos(ctx, cmd.OwnerID, cmd.FileIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.StarItems:StarFileInfos")
+		}
+	}
+
+	if len(cmd.FolderIDs) > 0 {
+		err = repoTx.StarFolderInfos(ctx, cmd.OwnerID, cmd.FolderIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.StarItems:StarFolderInfos")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.StarItems:Commit")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) UnstarItems(ctx context.Context, cmd *UnstarItemsCommand) error {
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UnstarItems:BeginTx")
+	}
+	defer tx.Rollback()
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	if len(cmd.FileIDs) > 0 {
+		err = repoTx.UnstarFileInfos(ctx, cmd.OwnerID, cmd.FileIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.UnstarItems:UnstarFileInfos")
+		}
+	}
+
+	if len(cmd.FolderIDs) > 0 {
+		err = repoTx.UnstarFolderInfos(ctx, cmd.OwnerID, cmd.FolderIDs)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.UnstarItems:UnstarFolderInfos")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.UnstarItems:Commit")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) RecordFileDownload(ctx context.Context, cmd *RecordFileDownloadCommand) error {
+	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RecordFileDownload:GetFileInfo")
+	}
+
+	if err := info.ValidateAccess(cmd.OwnerID); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RecordFileDownload:ValidateAccess")
+	}
+
+	err = h.repository.UpsertRecentFile(ctx, NewRecentFile(info, FileActivityDownloaded))
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RecordFileDownload:UpsertRecentFile")
+	}
+
+	return nil
+}
+
+// recordActivity adds the activity to the recent files of the owner.
+// The recent files are a convenience, failing to record is not fatal for the command.
+func (h *CommandHandlers) recordActivity(ctx context.Context, info *FileInfo, activity FileActivity) {
+	err := h.repository.UpsertRecentFile(ctx, NewRecentFile(info, activity))
+	if err != nil {
+		applog.GetLoggerFromContext(ctx).Warn().Err(err).Str("file_id", info.ID).Str("activity", string(activity)).Msg("failed to record file activity")
+	}
+}
+
+//--------------------------------
+// Storage Usage
+//--------------------------------
+
+// validateQuota checks that size more bytes fit in the storage quota of the owner, before the file is stored.
+// It is checked again when the file is created, see createFileInfo.
+func (h *CommandHandlers) validateQuota(ctx context.Context, ownerID string, size int64) error {
+	usage, err := h.repository.GetStorageUsage(ctx, ownerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.validateQuota:GetStorageUsage")
+	}
+
+	err = usage.ValidateWrite(h.app.Config.Media.DefaultQuotaMB, size)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.validateQuota:ValidateWrite").WithMetadata("owner_id", ownerID)
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) SetStorageQuota(ctx context.Context, cmd *SetStorageQuotaCommand) error {
+	var quotaBytes *int64
+	if cmd.QuotaMB != nil {
+		quota := *cmd.QuotaMB * bytesPerMB
+		quotaBytes = &quota
+	}
+
+	err := h.repository.SetStorageQuota(ctx, cmd.OwnerID, quotaBytes)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetStorageQuota:SetStorageQuota")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) PurgeTrash(ctx context.Context, cmd *PurgeTrashCommand) (int, error) {
+	days := h.app.Config.Media.TrashRetentionDays
+	if days <= 0 {
+		days = defaultTrashRetentionDays
+	}
+	before := time.Now().UTC().AddDate(0, 0, -days)
+
+	// The folders are purged after their files, deleting a folder would delete its files without their storage
+	count := 0
+	for {
+		infos, err := h.repository.GetFileInfosTrashedBefore(ctx, before, cmd.BatchSize)
+		if err != nil {
+			return count, apperror.NewAppError(err, "media.CommandHandlers.PurgeTrash:GetFileInfosTrashedBefore")
+		}
+
+		for _, info := range infos {
+			err = h.purgeFile(ctx, info)
+			if err != nil {
+				return count, apperror.NewAppError(err, "media.CommandHandlers.PurgeTrash:purgeFile").WithMetadata("file_id", info.ID)
+			}
+			count++
+		}
+
+		if len(infos) < cmd.BatchSize {
+			break
+		}
+	}
+
+	folders, err := h.repository.DeleteFolderInfosTrashedBefore(ctx, before)
+	if err != nil {
+		return count, apperror.NewAppError(err, "media.CommandHandlers.PurgeTrash:DeleteFolderInfosTrashedBefore")
+	}
+
+	return count + int(folders), nil
+}
+
+// purgeFile deletes the file for good. The info is deleted first, a failed delete of the storage leaves
+// an unused file rather than an info without its content.
+func (h *CommandHandlers) purgeFile(ctx context.Context, info *FileInfo) error {
+	err := h.repository.DeleteFileInfo(ctx, info.ID)
+	if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+		return apperror.NewAppError(err, "media.CommandHandlers.purgeFile:DeleteFileInfo")
+	}
+
+	logger := applog.GetLoggerFromContext(ctx)
+	err = h.storage.DeleteFile(ctx, info.ID, info.OwnerID)
+	if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+		logger.Warn().Err(err).Str("file_id", info.ID).Msg("failed to delete the purged file from storage")
+	}
+
+	err = h.storage.DeletePreviews(ctx, info.ID, info.OwnerID)
+	if err != nil {
+		logger.Warn().Err(err).Str("file_id", info.ID).Msg("failed to delete the previews of the purged file")
+	}
+
+	return nil
+}
+
+//--------------------------------
+// Changes
+//--------------------------------
+
+func (h *CommandHandlers) CompactChangeLog(ctx context.Context, cmd *CompactChangeLogCommand) (int, error) {
+	days := h.app.Config.Media.ChangeRetentionDays
+	if days <= 0 {
+		days = defaultChangeRetentionDays
+	}
+
+	count, err := h.repository.CompactChangeLog(ctx, time.Now().UTC().AddDate(0, 0, -days))
+	if err != nil {
+		return 0, apperror.NewAppError(err, "media.CommandHandlers.CompactChangeLog:CompactChangeLog").WithMetadata("retention_days", days)
+	}
+
+	return int(count), nil
+}
+
+//--------------------------------
+// Properties
+//--------------------------------
+
+func (h *CommandHandlers) SetFileProperties(ctx context.Context, cmd *SetFilePropertiesCommand) error {
+	// Properties can be set on trashed files too, like tags
+	info, err := h.repository.GetFileInfo(ctx, cmd.FileID)
+	if errors.Is(err, apperror.ErrCommonNoData) {
+		info, err = h.repository.GetFileInfoTrashed(ctx, cmd.FileID)
+	}
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFileProperties:GetFileInfo")
+	}
+
+	if err := info.ValidateAccess(cmd.OwnerID); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFileProperties:ValidateAccess")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFileProperties:BeginTx")
+	}
+	defer tx.Rollback()
+
+	err = h.repository.WithTx(ctx, tx).ReplaceFileProperties(ctx, info.ID, cmd.Properties)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFileProperties:ReplaceFileProperties")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFileProperties:Commit")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) SetFolderProperties(ctx context.Context, cmd *SetFolderPropertiesCommand) error {
+	// GetFolderInfo checks the owner
+	info, err := h.repository.GetFolderInfo(ctx, cmd.OwnerID, cmd.FolderID)
+	if errors.Is(err, apperror.ErrCommonNoData) {
+		info, err = h.repository.GetFolderInfoTrashed(ctx, cmd.OwnerID, cmd.FolderID)
+	}
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFolderProperties:GetFolderInfo")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFolderProperties:BeginTx")
+	}
+	defer tx.Rollback()
+
+	err = h.repository.WithTx(ctx, tx).ReplaceFolderProperties(ctx, info.ID, cmd.Properties)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFolderProperties:ReplaceFolderProperties")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.SetFolderProperties:Commit")
+	}
+
+	return nil
+}
+
+//--------------------------------
+// Folders
+//--------------------------------
+
+func (h *CommandHandlers) CreateFolder(ctx context.Context, cmd *CreateFolderCommand) (*FolderInfo, error) {
+	var parentFolder *FolderInfo
+	if cmd.ParentFolderID != nil {
+		var err error
+		parentFolder, err = h.repository.GetFolderInfo(ctx, cmd.OwnerID, *cmd.ParentFolderID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolder:GetParentFolderInfo")
+		}
+	}
+
+	info, err := NewFolderInfo(cmd.OwnerID, cmd.Name, parentFolder)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolder:NewFolderInfo")
+	}
+
+	info, err = h.repository.CreateFolderInfo(ctx, info)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolder:CreateFolderInfo")
+	}
+
+	return info, nil
+}
+
+func (h *CommandHandlers) CreateFolderPath(ctx context.Context, cmd *CreateFolderPathCommand) (*FolderInfo, error) {
+	names, err := splitPath(cmd.Path)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolderPath:splitPath").WithMetadata("path", cmd.Path)
+	}
+
+	var folder *FolderInfo
+	for _, name := range names {
+		var parentFolderID *string
+		if folder != nil {
+			parentFolderID = &folder.ID
+		}
+
+		existing, err := h.repository.GetFolderInfoByName(ctx, cmd.OwnerID, parentFolderID, name)
+		if err == nil {
+			folder = existing
+			continue
+		}
+		if !errors.Is(err, apperror.ErrCommonNoData) {
+			return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolderPath:GetFolderInfoByName").WithMetadata("name", name)
+		}
+
+		info, err := NewFolderInfo(cmd.OwnerID, name, folder)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolderPath:NewFolderInfo").WithMetadata("name", name)
+		}
+
+		created, err := h.repository.CreateFolderInfo(ctx, info)
+		if errors.Is(err, apperror.ErrCommonDuplicateData) {
+			// Created by a concurrent request meanwhile
+			created, err = h.repository.GetFolderInfoByName(ctx, cmd.OwnerID, parentFolderID, name)
+		}
+		if err != nil {
+			return nil, apperror.NewAppError(err, "media.CommandHandlers.CreateFolderPath:CreateFolderInfo").WithMetadata("name", name)
+		}
+		folder = created
+	}
+
+	return folder, nil
+}
+
+func (h *CommandHandlers) RenameFolder(ctx context.Context, cmd *RenameFolderCommand) error {
+	info, err := h.repository.GetFolderInfo(ctx, cmd.OwnerID, cmd.FolderID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RenameFolder:GetFolderInfo")
+	}
+
+	err = info.ValidateAccess(cmd.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RenameFolder:ValidateAccess")
+	}
+
+	info.Rename(cmd.Name)
+
+	err = h.repository.UpdateFolderInfo(ctx, info)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RenameFolder:UpdateFolderInfo")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) MoveFolder(ctx context.Context, cmd *MoveFolderCommand) error {
+	info, err := h.repository.GetFolderInfo(ctx, cmd.OwnerID, cmd.FolderID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:GetFolderInfo")
+	}
+
+	err = info.ValidateAccess(cmd.OwnerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:ValidateAccess")
+	}
+
+	var destFolderInfo *FolderInfo
+	if cmd.ParentFolderID != nil {
+		destFolderInfo, err = h.repository.GetFolderInfo(ctx, cmd.OwnerID, *cmd.ParentFolderID)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:GetParentFolderInfo")
+		}
+	}
+
+	descendantFolderIDs, err := h.repository.GetDescendantFolderIDs(ctx, cmd.OwnerID, cmd.FolderID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:GetDescendantFolderIDs")
+	}
+
+	if err := info.MoveTo(destFolderInfo, descendantFolderIDs); err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:MoveTo")
+	}
+
+	err = h.repository.UpdateFolderInfo(ctx, info)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.MoveFolder:UpdateFolderInfo")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) TrashFolders(ctx context.Context, cmd *TrashFoldersCommand) error {
+	err := h.repository.TrashFolderInfos(ctx, cmd.OwnerID, cmd.FolderIDs)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.TrashFolders:TrashFolderInfos")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) RestoreFolder(ctx context.Context, cmd *RestoreFolderCommand) error {
+	info, err := h.repository.GetFolderInfoTrashed(ctx, cmd.OwnerID, cmd.FolderID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:GetFolderInfo")
+	}
+
+	parentFolderIsTrashed, err := h.isParentFolderTrashed(ctx, cmd.OwnerID, info.ParentFolderID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:IsParentFolderTrashed")
+	}
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	if parentFolderIsTrashed {
+		// Make root the new parent folder, since the original parent folder is trashed
+		info.ParentFolderID = nil
+
+		err = repoTx.UpdateFolderInfo(ctx, info)
+		if err != nil {
+			return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:UpdateFolderInfo")
+		}
+	}
+
+	// Restore the main folder and all nested items
+	err = repoTx.RestoreFolderInfo(ctx, cmd.OwnerID, cmd.FolderID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:RestoreFolderInfos")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "media.CommandHandlers.RestoreFolder:Commit")
 	}
 
 	return nil
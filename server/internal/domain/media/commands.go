@@ -19,9 +19,22 @@ type Commands interface {
 	// - ErrCommonNoAccess
 	// - ErrCommonDuplicateData
 	// - ErrMediaFileSizeLimitExceeded
+	// - ErrMediaFileTypeNotAllowed
+	// - ErrMediaQuotaExceeded
 	// - ErrCommonInvalidValue
 	UploadFile(ctx context.Context, cmd *UploadFileCommand) (*FileInfo, error)
 
+	// ReplaceFileContent overwrites the content of a file in place. The file keeps its ID,
+	// so its shares, tags, stars and properties, and its scan and previews are done again.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	// - ErrMediaFileTypeNotAllowed
+	// - ErrMediaQuotaExceeded
+	ReplaceFileContent(ctx context.Context, cmd *ReplaceFileContentCommand) (*FileInfo, error)
+
 	// UploadChunk uploads a single chunk of a file for chunked uploads
 	// Does not finalize the upload - use FinalizeChunkedUpload for that
 	// App Errors:
@@ -35,6 +48,8 @@ type Commands interface {
 	// - ErrCommonNoAccess
 	// - ErrCommonDuplicateData
 	// - ErrMediaFileSizeLimitExceeded
+	// - ErrMediaFileTypeNotAllowed
+	// - ErrMediaQuotaExceeded
 	FinalizeChunkedUpload(ctx context.Context, cmd *FinalizeChunkedUploadCommand) (*FileInfo, error)
 
 	// App Errors:
@@ -60,6 +75,218 @@ type Commands interface {
 	// - ErrCommonNoAccess
 	RestoreFile(ctx context.Context, cmd *RestoreFileCommand) error
 
+	//--------------------------------
+	// Archives
+	//--------------------------------
+
+	// ExtractArchive schedules the extraction of a zip or tar archive into the folder.
+	// The extraction runs in the background, its progress is returned by Queries.GetArchiveExtraction.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	// - ErrMediaFileInfected
+	ExtractArchive(ctx context.Context, cmd *ExtractArchiveCommand) (*ArchiveExtraction, error)
+
+	// RunArchiveExtraction recreates the folders and files of the archive, it is run in the background by ExtractArchive.
+	// The extraction is saved as failed if the archive is unsafe, e.g. paths escaping the folder or a decompression bomb,
+	// or if it doesn't fit in the storage quota. The files extracted before the failure are kept.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	// - ErrCommonInvalidValue
+	// - ErrMediaUnsafeArchive
+	// - ErrMediaQuotaExceeded
+	RunArchiveExtraction(ctx context.Context, cmd *RunArchiveExtractionCommand) error
+
+	// ResumeArchiveExtractions picks up the extractions whose job was lost when the server stopped, it is run at startup.
+	// The pending extractions are enqueued again. The running ones are saved as failed, they are not run again
+	// since their files already extracted would be extracted twice with ExtractConflictRename.
+	// Returns the number of resumed or failed extractions.
+	ResumeArchiveExtractions(ctx context.Context, cmd *ResumeArchiveExtractionsCommand) (int, error)
+
+	//--------------------------------
+	// Scans
+	//--------------------------------
+
+	// ScanFile scans the file for malware and updates its scan status.
+	// It is run in the background after upload. Infected files are kept in quarantine.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	ScanFile(ctx context.Context, cmd *ScanFileCommand) error
+
+	// BackfillScans scans all the files still pending or whose scan failed,
+	// e.g. files uploaded before scanning was enabled or whose job was lost.
+	// Returns the number of processed files.
+	BackfillScans(ctx context.Context, cmd *BackfillScansCommand) (int, error)
+
+	//--------------------------------
+	// Previews
+	//--------------------------------
+
+	// GeneratePreviews generates the previews of all sizes and updates the preview status of the file.
+	// It is run in the background after upload.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GeneratePreviews(ctx context.Context, cmd *GeneratePreviewsCommand) error
+
+	// BackfillPreviews generates the previews of all the files still pending,
+	// e.g. files uploaded before previews were generated or whose job was lost.
+	// Returns the number of processed files.
+	BackfillPreviews(ctx context.Context, cmd *BackfillPreviewsCommand) (int, error)
+
+	//--------------------------------
+	// Metadata
+	//--------------------------------
+
+	// ExtractMetadata extracts and saves the metadata of an image or audio file.
+	// It is run in the background after upload. A metadata row is saved even if the extraction fails,
+	// so that the file is not picked up again by BackfillMetadata.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	ExtractMetadata(ctx context.Context, cmd *ExtractMetadataCommand) error
+
+	// BackfillMetadata extracts the metadata of all the image and audio files without metadata,
+	// e.g. files uploaded before metadata was extracted or whose job was lost.
+	// Returns the number of processed files.
+	BackfillMetadata(ctx context.Context, cmd *BackfillMetadataCommand) (int, error)
+
+	//--------------------------------
+	// Search
+	//--------------------------------
+
+	// IndexFileText indexes the content of a text file for the full-text search.
+	// It is run in the background after upload. A row is saved even if the file can't be read,
+	// so that the file is not picked up again by BackfillFileTexts.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	IndexFileText(ctx context.Context, cmd *IndexFileTextCommand) error
+
+	// BackfillFileTexts indexes the content of all the text files not indexed yet,
+	// e.g. files uploaded before the full-text search existed or whose job was lost.
+	// Returns the number of processed files.
+	BackfillFileTexts(ctx context.Context, cmd *BackfillFileTextsCommand) (int, error)
+
+	//--------------------------------
+	// Tags
+	//--------------------------------
+
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonDuplicateData
+	CreateTag(ctx context.Context, cmd *CreateTagCommand) (*Tag, error)
+
+	// UpdateTag renames and recolors the tag.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonDuplicateData
+	UpdateTag(ctx context.Context, cmd *UpdateTagCommand) error
+
+	// DeleteTag deletes the tag and removes it from all the items.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	DeleteTag(ctx context.Context, cmd *DeleteTagCommand) error
+
+	// TagItems adds the tag to the files and folders.
+	// Items not owned by the owner or already tagged are skipped.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	TagItems(ctx context.Context, cmd *TagItemsCommand) error
+
+	// UntagItems removes the tag from the files and folders. Items not tagged are skipped.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	UntagItems(ctx context.Context, cmd *UntagItemsCommand) error
+
+	//--------------------------------
+	// Starred & Recent
+	//--------------------------------
+
+	// StarItems stars the files and folders for the owner.
+	// Items not owned by the owner or already starred are skipped.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	StarItems(ctx context.Context, cmd *StarItemsCommand) error
+
+	// UnstarItems unstars the files and folders. Items not starred are skipped.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	UnstarItems(ctx context.Context, cmd *UnstarItemsCommand) error
+
+	// RecordFileDownload adds the download of the file to the recent files of the owner.
+	// Uploads and modifications are recorded by the commands themselves.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	RecordFileDownload(ctx context.Context, cmd *RecordFileDownloadCommand) error
+
+	//--------------------------------
+	// Storage Usage
+	//--------------------------------
+
+	// SetStorageQuota overrides the default storage quota of the owner, a nil quota resets it to the default.
+	// A quota lower than the current usage only blocks new writes, the existing files are kept.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	SetStorageQuota(ctx context.Context, cmd *SetStorageQuotaCommand) error
+
+	// PurgeTrash deletes for good the files and folders trashed for longer than the trash retention of the
+	// config, with their stored content. Returns the number of purged files and folders.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	PurgeTrash(ctx context.Context, cmd *PurgeTrashCommand) (int, error)
+
+	//--------------------------------
+	// Changes
+	//--------------------------------
+
+	// CompactChangeLog purges the deletes older than the change retention of the config.
+	// The sync clients whose cursor is before a purged delete must resync.
+	// Returns the number of purged changes.
+	CompactChangeLog(ctx context.Context, cmd *CompactChangeLogCommand) (int, error)
+
+	//--------------------------------
+	// Properties
+	//--------------------------------
+
+	// SetFileProperties replaces the custom properties of the file.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	SetFileProperties(ctx context.Context, cmd *SetFilePropertiesCommand) error
+
+	// SetFolderProperties replaces the custom properties of the folder.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	SetFolderProperties(ctx context.Context, cmd *SetFolderPropertiesCommand) error
+
 	//--------------------------------
 	// Folders
 	//--------------------------------
@@ -69,6 +296,13 @@ type Commands interface {
 	// - ErrCommonDuplicateData
 	CreateFolder(ctx context.Context, cmd *CreateFolderCommand) (*FolderInfo, error)
 
+	// CreateFolderPath creates the missing folders of a slash separated path, like mkdir -p,
+	// and returns the last one. The existing folders are reused.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	CreateFolderPath(ctx context.Context, cmd *CreateFolderPathCommand) (*FolderInfo, error)
+
 	// App Errors:
 	// - ErrCommonInvalidValue
 	// - ErrCommonNoData
@@ -105,10 +339,16 @@ type UploadFileCommand struct {
 	FolderID *string
 	Name     string
 	Size     int64
-	MimeType string
 	File     io.ReadSeeker
 }
 
+type ReplaceFileContentCommand struct {
+	OwnerID string
+	FileID  string
+	Size    int64
+	File    io.ReadSeeker
+}
+
 type UploadChunkCommand struct {
 	OwnerID     string
 	UploadID    string
@@ -123,7 +363,6 @@ type FinalizeChunkedUploadCommand struct {
 	UploadID    string
 	FileName    string
 	FileSize    int64
-	MimeType    string
 	TotalChunks int64
 }
 
@@ -149,6 +388,170 @@ type RestoreFileCommand struct {
 	FileID  string
 }
 
+//--------------------------------
+// Archives
+//--------------------------------
+
+type ExtractArchiveCommand struct {
+	OwnerID  string
+	FileID   string
+	FolderID *string
+	Conflict ExtractConflict
+}
+
+type RunArchiveExtractionCommand struct {
+	OwnerID      string
+	ExtractionID string
+}
+
+type ResumeArchiveExtractionsCommand struct {
+	BatchSize int
+}
+
+//--------------------------------
+// Scans
+//--------------------------------
+
+type ScanFileCommand struct {
+	OwnerID string
+	FileID  string
+}
+
+type BackfillScansCommand struct {
+	BatchSize int
+}
+
+//--------------------------------
+// Previews
+//--------------------------------
+
+type GeneratePreviewsCommand struct {
+	OwnerID string
+	FileID  string
+}
+
+type BackfillPreviewsCommand struct {
+	BatchSize int
+}
+
+//--------------------------------
+// Metadata
+//--------------------------------
+
+type ExtractMetadataCommand struct {
+	OwnerID string
+	FileID  string
+}
+
+type BackfillMetadataCommand struct {
+	BatchSize int
+}
+
+//--------------------------------
+// Search
+//--------------------------------
+
+type IndexFileTextCommand struct {
+	OwnerID string
+	FileID  string
+}
+
+type BackfillFileTextsCommand struct {
+	BatchSize int
+}
+
+//--------------------------------
+// Tags
+//--------------------------------
+
+type CreateTagCommand struct {
+	OwnerID string
+	Name    string
+	Color   string // Hex color like "#ff9800", DefaultTagColor if empty
+}
+
+type UpdateTagCommand struct {
+	OwnerID string
+	TagID   string
+	Name    string
+	Color   string
+}
+
+type DeleteTagCommand struct {
+	OwnerID string
+	TagID   string
+}
+
+type TagItemsCommand struct {
+	OwnerID   string
+	TagID     string
+	FileIDs   []string
+	FolderIDs []string
+}
+
+type UntagItemsCommand struct {
+	OwnerID   string
+	TagID     string
+	FileIDs   []string
+	FolderIDs []string
+}
+
+//--------------------------------
+// Starred & Recent
+//--------------------------------
+
+type StarItemsCommand struct {
+	OwnerID   string
+	FileIDs   []string
+	FolderIDs []string
+}
+
+type UnstarItemsCommand struct {
+	OwnerID   string
+	FileIDs   []string
+	FolderIDs []string
+}
+
+type RecordFileDownloadCommand struct {
+	OwnerID string
+	FileID  string
+}
+
+//--------------------------------
+// Storage Usage
+//--------------------------------
+
+type SetStorageQuotaCommand struct {
+	OwnerID string
+	QuotaMB *int64 // 0 is unlimited, nil uses the default quota
+}
+
+type PurgeTrashCommand struct {
+	BatchSize int
+}
+
+//--------------------------------
+// Changes
+//--------------------------------
+
+type CompactChangeLogCommand struct{}
+
+//--------------------------------
+// Properties
+//--------------------------------
+
+type SetFilePropertiesCommand struct {
+	OwnerID    string
+	FileID     string
+	Properties map[string]string
+}
+
+type SetFolderPropertiesCommand struct {
+	OwnerID    string
+	FolderID   string
+	Properties map[string]string
+}
+
 //--------------------------------
 // Folders
 //--------------------------------
@@ -159,6 +562,11 @@ type CreateFolderCommand struct {
 	ParentFolderID *string
 }
 
+type CreateFolderPathCommand struct {
+	OwnerID string
+	Path    string // e.g. "/Projects/2026"
+}
+
 type TrashFoldersCommand struct {
 	OwnerID   string
 	FolderIDs []string
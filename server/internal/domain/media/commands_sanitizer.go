@@ -4,6 +4,7 @@ import (
 	"context"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/validate"
+	"strings"
 )
 
 var _ Commands = (*CommandsSanitizer)(nil)
@@ -34,6 +35,22 @@ func (s *CommandsSanitizer) UploadFile(ctx context.Context, cmd *UploadFileComma
 	return s.Commands.UploadFile(ctx, cmd)
 }
 
+func (s *CommandsSanitizer) ReplaceFileContent(ctx context.Context, cmd *ReplaceFileContentCommand) (*FileInfo, error) {
+	if !validate.UUID(cmd.FileID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ReplaceFileContent:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	if cmd.File == nil {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ReplaceFileContent:File")
+	}
+
+	if cmd.Size <= 0 {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ReplaceFileContent:Size")
+	}
+
+	return s.Commands.ReplaceFileContent(ctx, cmd)
+}
+
 func (s *CommandsSanitizer) UploadChunk(ctx context.Context, cmd *UploadChunkCommand) error {
 	if cmd.Chunk == nil {
 		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.UploadChunk:Chunk")
@@ -82,6 +99,246 @@ func (s *CommandsSanitizer) RenameFile(ctx context.Context, cmd *RenameFileComma
 	return s.Commands.RenameFile(ctx, cmd)
 }
 
+func (s *CommandsSanitizer) ExtractArchive(ctx context.Context, cmd *ExtractArchiveCommand) (*ArchiveExtraction, error) {
+	if !validate.UUID(cmd.FileID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ExtractArchive:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	if cmd.FolderID != nil && !validate.UUID(*cmd.FolderID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ExtractArchive:FolderID").WithMetadata("folder_id", *cmd.FolderID)
+	}
+
+	cmd.Conflict = ExtractConflict(strings.ToLower(strings.TrimSpace(string(cmd.Conflict))))
+
+	return s.Commands.ExtractArchive(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) RunArchiveExtraction(ctx context.Context, cmd *RunArchiveExtractionCommand) error {
+	if !validate.UUID(cmd.ExtractionID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.RunArchiveExtraction:ExtractionID").WithMetadata("extraction_id", cmd.ExtractionID)
+	}
+
+	return s.Commands.RunArchiveExtraction(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) ResumeArchiveExtractions(ctx context.Context, cmd *ResumeArchiveExtractionsCommand) (int, error) {
+	if cmd.BatchSize <= 0 {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ResumeArchiveExtractions:BatchSize").WithMetadata("batch_size", cmd.BatchSize)
+	}
+
+	return s.Commands.ResumeArchiveExtractions(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) ScanFile(ctx context.Context, cmd *ScanFileCommand) error {
+	if !validate.UUID(cmd.FileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ScanFile:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	return s.Commands.ScanFile(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) BackfillScans(ctx context.Context, cmd *BackfillScansCommand) (int, error) {
+	if cmd.BatchSize <= 0 {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.BackfillScans:BatchSize").WithMetadata("batch_size", cmd.BatchSize)
+	}
+
+	return s.Commands.BackfillScans(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) GeneratePreviews(ctx context.Context, cmd *GeneratePreviewsCommand) error {
+	if !validate.UUID(cmd.FileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.GeneratePreviews:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	return s.Commands.GeneratePreviews(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) BackfillPreviews(ctx context.Context, cmd *BackfillPreviewsCommand) (int, error) {
+	if cmd.BatchSize <= 0 {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.BackfillPreviews:BatchSize").WithMetadata("batch_size", cmd.BatchSize)
+	}
+
+	return s.Commands.BackfillPreviews(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) ExtractMetadata(ctx context.Context, cmd *ExtractMetadataCommand) error {
+	if !validate.UUID(cmd.FileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.ExtractMetadata:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	return s.Commands.ExtractMetadata(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) BackfillMetadata(ctx context.Context, cmd *BackfillMetadataCommand) (int, error) {
+	if cmd.BatchSize <= 0 {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.BackfillMetadata:BatchSize").WithMetadata("batch_size", cmd.BatchSize)
+	}
+
+	return s.Commands.BackfillMetadata(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) IndexFileText(ctx context.Context, cmd *IndexFileTextCommand) error {
+	if !validate.UUID(cmd.FileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.IndexFileText:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	return s.Commands.IndexFileText(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) BackfillFileTexts(ctx context.Context, cmd *BackfillFileTextsCommand) (int, error) {
+	if cmd.BatchSize <= 0 {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.BackfillFileTexts:BatchSize").WithMetadata("batch_size", cmd.BatchSize)
+	}
+
+	return s.Commands.BackfillFileTexts(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) CreateTag(ctx context.Context, cmd *CreateTagCommand) (*Tag, error) {
+	if n, err := validate.Name(cmd.Name); err != nil {
+		return nil, apperror.NewAppError(err, "media.CommandsSanitizer.CreateTag:Name")
+	} else {
+		cmd.Name = n
+	}
+
+	return s.Commands.CreateTag(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) UpdateTag(ctx context.Context, cmd *UpdateTagCommand) error {
+	if !validate.UUID(cmd.TagID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.UpdateTag:TagID").WithMetadata("tag_id", cmd.TagID)
+	}
+
+	if n, err := validate.Name(cmd.Name); err != nil {
+		return apperror.NewAppError(err, "media.CommandsSanitizer.UpdateTag:Name")
+	} else {
+		cmd.Name = n
+	}
+
+	return s.Commands.UpdateTag(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) DeleteTag(ctx context.Context, cmd *DeleteTagCommand) error {
+	if !validate.UUID(cmd.TagID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.DeleteTag:TagID").WithMetadata("tag_id", cmd.TagID)
+	}
+
+	return s.Commands.DeleteTag(ctx, cmd)
+}
+
+// validateItemIDs checks the files and folders of the bulk operations, at least one item is required.
+func validateItemIDs(fileIDs, folderIDs []string) error {
+	if len(fileIDs) == 0 && len(folderIDs) == 0 {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.validateItemIDs:NoItems")
+	}
+
+	if _, invalid := validate.UUIDs(fileIDs); len(invalid) > 0 {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.validateItemIDs:FileIDs").WithMetadata("file_ids", invalid)
+	}
+
+	if _, invalid := validate.UUIDs(folderIDs); len(invalid) > 0 {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.validateItemIDs:FolderIDs").WithMetadata("folder_ids", invalid)
+	}
+
+	return nil
+}
+
+// validateTaggedItems checks the tag and item IDs of TagItems and UntagItems.
+func validateTaggedItems(tagID string, fileIDs, folderIDs []string) error {
+	if !validate.UUID(tagID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.validateTaggedItems:TagID").WithMetadata("tag_id", tagID)
+	}
+
+	return validateItemIDs(fileIDs, folderIDs)
+}
+
+func (s *CommandsSanitizer) TagItems(ctx context.Context, cmd *TagItemsCommand) error {
+	if err := validateTaggedItems(cmd.TagID, cmd.FileIDs, cmd.FolderIDs); err != nil {
+		return apperror.NewAppError(err, "media.CommandsSanitizer.TagItems:validateTaggedItems")
+	}
+
+	return s.Commands.TagItems(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) UntagItems(ctx context.Context, cmd *UntagItemsCommand) error {
+	if err := validateTaggedItems(cmd.TagID, cmd.FileIDs, cmd.FolderIDs); err != nil {
+		return apperror.NewAppError(err, "media.CommandsSanitizer.UntagItems:validateTaggedItems")
+	}
+
+	return s.Commands.UntagItems(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) StarItems(ctx context.Context, cmd *StarItemsCommand) error {
+	if err := validateItemIDs(cmd.FileIDs, cmd.FolderIDs); err != nil {
+		return apperror.NewAppError(err, "media.CommandsSanitizer.StarItems:validateItemIDs")
+	}
+
+	return s.Commands.StarItems(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) UnstarItems(ctx context.Context, cmd *UnstarItemsCommand) error {
+	if err := validateItemIDs(cmd.FileIDs, cmd.FolderIDs); err != nil {
+		return apperror.NewAppError(err, "media.CommandsSanitizer.UnstarItems:validateItemIDs")
+	}
+
+	return s.Commands.UnstarItems(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) RecordFileDownload(ctx context.Context, cmd *RecordFileDownloadCommand) error {
+	if !validate.UUID(cmd.FileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.RecordFileDownload:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	return s.Commands.RecordFileDownload(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) SetStorageQuota(ctx context.Context, cmd *SetStorageQuotaCommand) error {
+	if !validate.UUID(cmd.OwnerID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.SetStorageQuota:OwnerID").WithMetadata("owner_id", cmd.OwnerID)
+	}
+
+	if cmd.QuotaMB != nil && (*cmd.QuotaMB < 0 || *cmd.QuotaMB > MaxQuotaMB) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.SetStorageQuota:QuotaMB").WithMetadata("quota_mb", *cmd.QuotaMB)
+	}
+
+	return s.Commands.SetStorageQuota(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) PurgeTrash(ctx context.Context, cmd *PurgeTrashCommand) (int, error) {
+	if cmd.BatchSize <= 0 {
+		return 0, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.PurgeTrash:BatchSize").WithMetadata("batch_size", cmd.BatchSize)
+	}
+
+	return s.Commands.PurgeTrash(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) SetFileProperties(ctx context.Context, cmd *SetFilePropertiesCommand) error {
+	if !validate.UUID(cmd.FileID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.SetFileProperties:FileID").WithMetadata("file_id", cmd.FileID)
+	}
+
+	if props, err := ValidateProperties(cmd.Properties); err != nil {
+		return apperror.NewAppError(err, "media.CommandsSanitizer.SetFileProperties:Properties")
+	} else {
+		cmd.Properties = props
+	}
+
+	return s.Commands.SetFileProperties(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) SetFolderProperties(ctx context.Context, cmd *SetFolderPropertiesCommand) error {
+	if !validate.UUID(cmd.FolderID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.SetFolderProperties:FolderID").WithMetadata("folder_id", cmd.FolderID)
+	}
+
+	if props, err := ValidateProperties(cmd.Properties); err != nil {
+		return apperror.NewAppError(err, "media.CommandsSanitizer.SetFolderProperties:Properties")
+	} else {
+		cmd.Properties = props
+	}
+
+	return s.Commands.SetFolderProperties(ctx, cmd)
+}
+
 func (s *CommandsSanitizer) CreateFolder(ctx context.Context, cmd *CreateFolderCommand) (*FolderInfo, error) {
 	if n, err := validate.FileName(cmd.Name); err != nil {
 		return nil, apperror.NewAppError(err, "media.CommandsSanitizer.CreateFolder:FileName")
@@ -92,6 +349,15 @@ func (s *CommandsSanitizer) CreateFolder(ctx context.Context, cmd *CreateFolderC
 	return s.Commands.CreateFolder(ctx, cmd)
 }
 
+func (s *CommandsSanitizer) CreateFolderPath(ctx context.Context, cmd *CreateFolderPathCommand) (*FolderInfo, error) {
+	// The root folder always exists
+	if names, err := splitPath(cmd.Path); err != nil || len(names) == 0 {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.CommandsSanitizer.CreateFolderPath:Path").WithMetadata("path", cmd.Path)
+	}
+
+	return s.Commands.CreateFolderPath(ctx, cmd)
+}
+
 func (s *CommandsSanitizer) RenameFolder(ctx context.Context, cmd *RenameFolderCommand) error {
 	if n, err := validate.FileName(cmd.Name); err != nil {
 		return apperror.NewAppError(err, "media.CommandsSanitizer.RenameFolder:FileName")
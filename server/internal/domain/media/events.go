@@ -0,0 +1,18 @@
+package media
+
+// Domain events of the media, written to the outbox in the transaction of the change
+// and published to the subscribers of the event bus once committed.
+//
+// The changes of the files and folders have no events here: the triggers of file_info and folder_info
+// record them in change_log, which feeds the event stream of the owner (SSE) and the webhook deliveries,
+// in the transaction of the change and whatever the write path. Only what the triggers can't see is published here.
+
+// FileQuarantined is published when malware is found in a file, the file can't be downloaded anymore.
+type FileQuarantined struct {
+	OwnerID   string
+	FileID    string
+	Name      string
+	Signature string
+}
+
+func (FileQuarantined) EventType() string { return "media.file.quarantined" }
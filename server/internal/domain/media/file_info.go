@@ -2,7 +2,6 @@ package media
 
 import (
 	"fmt"
-	"io"
 	"path/filepath"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
@@ -20,28 +19,34 @@ const (
 )
 
 type FileConfig struct {
-	MaxSizeMB int64
+	MaxSizeMB        int64
+	AllowedMimeTypes []string // All types are allowed if empty
+	DeniedMimeTypes  []string
 }
 
-// TODO: Generate preview asynchronously via worker
 type FileInfo struct {
-	ID        string
-	OwnerID   string
-	FolderID  *string // null if file is in root folder
-	Name      string
-	Size      int64 // bytes
-	Extension *string
-	MimeType  string
-	Category  Category
-	Preview   []byte
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	TrashedAt *time.Time
+	ID            string
+	OwnerID       string
+	FolderID      *string // null if file is in root folder
+	Name          string
+	Size          int64 // bytes
+	Extension     *string
+	MimeType      string
+	Category      Category
+	PreviewStatus PreviewStatus // empty if the file has no preview
+	ScanStatus    ScanStatus    // empty for files uploaded before scanning existed, same as pending
+	ScanSignature *string       // name of the detected malware, if infected
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	TrashedAt     *time.Time
 }
 
+// The mime type must be detected from the content of the file, see utils.DetectMimeType.
+//
 // App Errors:
 // - ErrCommonNoAccess
 // - ErrCommonInvalidValue
+// - ErrMediaFileTypeNotAllowed
 func NewFileInfo(config FileConfig, ownerID string, parentFolder *FolderInfo, name string, size int64, mimeType string) (*FileInfo, error) {
 	var folderID *string
 	if parentFolder != nil {
@@ -51,12 +56,9 @@ func NewFileInfo(config FileConfig, ownerID string, parentFolder *FolderInfo, na
 		folderID = &parentFolder.ID
 	}
 
-	if size > (config.MaxSizeMB * common.BytesPerMB) {
-		return nil, apperror.NewAppError(fmt.Errorf("%w: file size limit exceeded", apperror.ErrCommonInvalidValue), "media.NewFileInfo:FileSizeLimitExceeded").WithMetadata("max_size_mb", config.MaxSizeMB).WithMetadata("file_size_mb", size/common.BytesPerMB)
-	}
-
-	if mimeType == "" {
-		mimeType = "application/octet-stream"
+	mimeType, err := config.validateContent(size, mimeType)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.NewFileInfo:validateContent")
 	}
 
 	var ext *string
@@ -70,18 +72,70 @@ func NewFileInfo(config FileConfig, ownerID string, parentFolder *FolderInfo, na
 	}
 
 	now := time.Now().UTC()
-	return &FileInfo{
-		ID:        id,
-		OwnerID:   ownerID,
-		FolderID:  folderID,
-		Name:      name,
-		Size:      size,
-		Extension: ext,
-		MimeType:  mimeType,
-		Category:  getCategory(mimeType),
-		CreatedAt: now,
-		UpdatedAt: now,
-	}, nil
+	info := &FileInfo{
+		ID:         id,
+		OwnerID:    ownerID,
+		FolderID:   folderID,
+		Name:       name,
+		Size:       size,
+		Extension:  ext,
+		MimeType:   mimeType,
+		Category:   getCategory(mimeType),
+		ScanStatus: ScanStatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if info.NeedsPreview() {
+		info.PreviewStatus = PreviewStatusPending
+	}
+
+	return info, nil
+}
+
+// validateContent checks the size and the type of the content of a file, and returns the mime type to save.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+// - ErrMediaFileTypeNotAllowed
+func (c FileConfig) validateContent(size int64, mimeType string) (string, error) {
+	if size > (c.MaxSizeMB * common.BytesPerMB) {
+		return "", apperror.NewAppError(fmt.Errorf("%w: file size limit exceeded", apperror.ErrCommonInvalidValue), "media.FileConfig.validateContent:FileSizeLimitExceeded").WithMetadata("max_size_mb", c.MaxSizeMB).WithMetadata("file_size_mb", size/common.BytesPerMB)
+	}
+
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	if !c.isMimeTypeAllowed(mimeType) {
+		return "", apperror.NewAppError(apperror.ErrMediaFileTypeNotAllowed, "media.FileConfig.validateContent:MimeTypeNotAllowed").WithMetadata("mime_type", mimeType)
+	}
+
+	return mimeType, nil
+}
+
+// Denied types take precedence over allowed types.
+func (c FileConfig) isMimeTypeAllowed(mimeType string) bool {
+	if matchesMimeType(c.DeniedMimeTypes, mimeType) {
+		return false
+	}
+	return len(c.AllowedMimeTypes) == 0 || matchesMimeType(c.AllowedMimeTypes, mimeType)
+}
+
+// matchesMimeType matches the type without its parameters against exact patterns such as "image/png",
+// or wildcard patterns such as "image/*".
+func matchesMimeType(patterns []string, mimeType string) bool {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*/*" || pattern == mimeType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 type Category string
@@ -104,26 +158,6 @@ func getCategory(mimeType string) Category {
 	return category
 }
 
-func (f *FileInfo) WithPreview(file io.ReadSeeker) (*FileInfo, error) {
-	if f.Category != CategoryImage {
-		return f, nil
-	}
-
-	format := strings.Split(f.MimeType, "/")[1]
-	preview, err := utils.ScaleDownImageTo(format, file, 100, 100)
-	if err != nil {
-		// In case of unsupported image format, no need to set the preview
-		if err == utils.ErrUnsupportedImageFormat {
-			return f, nil
-		}
-
-		return nil, err
-	}
-
-	f.Preview = preview
-	return f, nil
-}
-
 // Restore to original parent folder if it's not trashed.
 // Otherwise, restore to root folder.
 func (f *FileInfo) Restore(parentFolderIsTrashed bool) {
@@ -149,6 +183,32 @@ func (f *FileInfo) Rename(newName string) {
 	f.UpdatedAt = time.Now().UTC()
 }
 
+// ReplaceContent updates the file for a new content, keeping its ID and so its shares, tags and stars.
+// The scan and the previews of the previous content are outdated, they are done again.
+// The mime type must be detected from the new content, see utils.DetectMimeType.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+// - ErrMediaFileTypeNotAllowed
+func (f *FileInfo) ReplaceContent(config FileConfig, size int64, mimeType string) error {
+	mimeType, err := config.validateContent(size, mimeType)
+	if err != nil {
+		return apperror.NewAppError(err, "media.FileInfo.ReplaceContent:validateContent")
+	}
+
+	f.Size = size
+	f.MimeType = mimeType
+	f.Category = getCategory(mimeType)
+	f.ScanStatus = ScanStatusPending
+	f.ScanSignature = nil
+	f.PreviewStatus = ""
+	if f.NeedsPreview() {
+		f.PreviewStatus = PreviewStatusPending
+	}
+	f.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
 // App Errors:
 // - ErrCommonNoAccess
 // - ErrCommonInvalidValue
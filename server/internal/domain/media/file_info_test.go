@@ -2,7 +2,10 @@ package media
 
 import (
 	"bytes"
+	"context"
+	"image/jpeg"
 	"io"
+	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
 	"skyvault/pkg/utils"
 	"testing"
@@ -70,6 +73,33 @@ func TestNewFileInfo(t *testing.T) {
 			mimeType:    "text/plain",
 			expectError: true,
 		},
+		{
+			name:        "allowed mime type",
+			config:      FileConfig{MaxSizeMB: 10, AllowedMimeTypes: []string{"image/*", "text/plain"}},
+			ownerID:     "100",
+			fileName:    "test.txt",
+			size:        1024,
+			mimeType:    "text/plain; charset=utf-8",
+			expectError: false,
+		},
+		{
+			name:        "mime type not in allowlist",
+			config:      FileConfig{MaxSizeMB: 10, AllowedMimeTypes: []string{"image/*"}},
+			ownerID:     "100",
+			fileName:    "test.txt",
+			size:        1024,
+			mimeType:    "text/plain",
+			expectError: true,
+		},
+		{
+			name:        "denied mime type wins over allowlist",
+			config:      FileConfig{MaxSizeMB: 10, AllowedMimeTypes: []string{"*/*"}, DeniedMimeTypes: []string{"application/x-executable"}},
+			ownerID:     "100",
+			fileName:    "app",
+			size:        1024,
+			mimeType:    "application/x-executable",
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -85,6 +115,7 @@ func TestNewFileInfo(t *testing.T) {
 				assert.NotEmpty(t, fileInfo.ID)
 				assert.NotEmpty(t, fileInfo.MimeType)
 				assert.NotEmpty(t, fileInfo.Category)
+				assert.Equal(t, ScanStatusPending, fileInfo.ScanStatus)
 			}
 		})
 	}
@@ -260,22 +291,50 @@ func TestFileInfo_Restore(t *testing.T) {
 	}
 }
 
-func TestFileInfo_WithPreview(t *testing.T) {
+func TestFileInfo_ReplaceContent(t *testing.T) {
+	t.Parallel()
+	config := FileConfig{MaxSizeMB: 1, DeniedMimeTypes: []string{"application/x-msdownload"}}
+	signature := "Eicar-Test-Signature"
+	file := &FileInfo{
+		ID:            "1",
+		Name:          "photo.png",
+		Size:          10,
+		MimeType:      "image/png",
+		Category:      CategoryImage,
+		PreviewStatus: PreviewStatusReady,
+		ScanStatus:    ScanStatusInfected,
+		ScanSignature: &signature,
+	}
+
+	require.NoError(t, file.ReplaceContent(config, 20, "text/plain"))
+	assert.Equal(t, "1", file.ID, "should keep the ID")
+	assert.Equal(t, int64(20), file.Size)
+	assert.Equal(t, "text/plain", file.MimeType)
+	assert.Equal(t, Category(CategoryText), file.Category)
+	assert.Equal(t, PreviewStatusPending, file.PreviewStatus, "should generate the previews again")
+	assert.Equal(t, ScanStatusPending, file.ScanStatus, "should scan the new content")
+	assert.Nil(t, file.ScanSignature)
+
+	require.NoError(t, file.ReplaceContent(config, 20, "application/zip"))
+	assert.Empty(t, file.PreviewStatus, "should have no preview")
+
+	assert.Error(t, file.ReplaceContent(config, 2*common.BytesPerMB, "text/plain"), "should reject a content over the size limit")
+	assert.ErrorIs(t, file.ReplaceContent(config, 20, "application/x-msdownload"), apperror.ErrMediaFileTypeNotAllowed)
+}
+
+func TestFileInfo_GeneratePreviews(t *testing.T) {
 	t.Parallel()
 	buf := new(bytes.Buffer)
 	err := utils.SampleImage(buf)
 	require.NoError(t, err)
 
-	buf1 := new(bytes.Buffer)
-	err = utils.SampleImage(buf1)
-	require.NoError(t, err)
-
 	tests := []struct {
-		name          string
-		file          FileInfo
-		reader        io.ReadSeeker
-		expectPreview bool
-		expectError   bool
+		name         string
+		file         FileInfo
+		reader       io.ReadSeeker
+		size         PreviewSize
+		expectStatus PreviewStatus
+		expectPixels int
 	}{
 		{
 			name: "generate preview for image",
@@ -283,46 +342,164 @@ func TestFileInfo_WithPreview(t *testing.T) {
 				Category: CategoryImage,
 				MimeType: "image/png",
 			},
-			reader:        bytes.NewReader(buf.Bytes()),
-			expectPreview: true,
-			expectError:   false,
+			reader:       bytes.NewReader(buf.Bytes()),
+			size:         PreviewSizeSmall,
+			expectStatus: PreviewStatusReady,
+			expectPixels: 100, // sample image is smaller than the preview size, so not upscaled
+		},
+		{
+			name: "generate preview for text",
+			file: FileInfo{
+				Name:     "main.go",
+				Category: CategoryText,
+				MimeType: "text/plain",
+			},
+			reader:       bytes.NewReader([]byte("package main")),
+			size:         PreviewSizeSmall,
+			expectStatus: PreviewStatusReady,
+		},
+		{
+			name: "generate preview for markdown",
+			file: FileInfo{
+				Name:     "README.md",
+				Category: CategoryText,
+				MimeType: "text/markdown",
+			},
+			reader:       bytes.NewReader([]byte("# Title")),
+			size:         PreviewSizeSmall,
+			expectStatus: PreviewStatusReady,
 		},
 		{
-			name: "skip preview for non-image",
+			name: "skip preview for binary text",
 			file: FileInfo{
+				Name:     "data.txt",
 				Category: CategoryText,
 				MimeType: "text/plain",
 			},
-			reader:        bytes.NewReader([]byte("test")),
-			expectPreview: false,
-			expectError:   false,
+			reader:       bytes.NewReader([]byte{0x00, 0x01, 0xFF}),
+			size:         PreviewSizeSmall,
+			expectStatus: PreviewStatusUnsupported,
+		},
+		{
+			name: "skip preview for other files",
+			file: FileInfo{
+				Category: CategoryOther,
+				MimeType: "application/zip",
+			},
+			reader:       bytes.NewReader([]byte("test")),
+			size:         PreviewSizeSmall,
+			expectStatus: PreviewStatusUnsupported,
 		},
 		{
 			name: "skip preview for unsupported image format",
 			file: FileInfo{
 				Category: CategoryImage,
-				MimeType: "image/webp",
+				MimeType: "image/x-unknown",
 			},
-			reader:        bytes.NewReader(buf1.Bytes()),
-			expectPreview: false,
-			expectError:   false,
+			reader:       bytes.NewReader(buf.Bytes()),
+			size:         PreviewSizeSmall,
+			expectStatus: PreviewStatusUnsupported,
+		},
+		{
+			name: "fail on corrupted image",
+			file: FileInfo{
+				Category: CategoryImage,
+				MimeType: "image/png",
+			},
+			reader:       bytes.NewReader([]byte("not an image")),
+			size:         PreviewSizeSmall,
+			expectStatus: PreviewStatusFailed,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			fileInfo, err := tt.file.WithPreview(tt.reader)
-			if tt.expectError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				if tt.expectPreview {
-					assert.NotNil(t, fileInfo.Preview)
-				} else {
-					assert.Nil(t, fileInfo.Preview)
+			previews, err := tt.file.GeneratePreviews(context.Background(), tt.reader, []PreviewSize{tt.size})
+			tt.file.SetPreviewStatus(err)
+			assert.Equal(t, tt.expectStatus, tt.file.PreviewStatus)
+			if tt.expectStatus == PreviewStatusReady {
+				require.NoError(t, err)
+				require.Len(t, previews, 1)
+				assert.NotEmpty(t, previews[0])
+				if tt.file.PreviewKind() == PreviewKindImage {
+					img, err := jpeg.Decode(bytes.NewReader(previews[0]))
+					require.NoError(t, err)
+					assert.Equal(t, tt.expectPixels, img.Bounds().Dx())
 				}
+			} else {
+				assert.Nil(t, previews)
 			}
 		})
 	}
 }
+
+func TestFileInfo_PreviewKind(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		file       FileInfo
+		expectKind PreviewKind
+		expectMime string
+	}{
+		{
+			name:       "supported image",
+			file:       FileInfo{Name: "a.webp", Category: CategoryImage, MimeType: "image/webp"},
+			expectKind: PreviewKindImage,
+			expectMime: "image/jpeg",
+		},
+		{
+			name:       "unsupported image",
+			file:       FileInfo{Name: "a.heic", Category: CategoryImage, MimeType: "image/heic"},
+			expectKind: PreviewKindNone,
+		},
+		{
+			name:       "source file with application mime type",
+			file:       FileInfo{Name: "a.json", Category: CategoryOther, MimeType: "application/json"},
+			expectKind: PreviewKindText,
+			expectMime: "text/html; charset=utf-8",
+		},
+		{
+			name:       "markdown by extension",
+			file:       FileInfo{Name: "notes.MD", Category: CategoryText, MimeType: "text/plain; charset=utf-8"},
+			expectKind: PreviewKindMarkdown,
+			expectMime: "text/html; charset=utf-8",
+		},
+		{
+			name:       "pdf",
+			file:       FileInfo{Name: "a.pdf", Category: CategoryOther, MimeType: "application/pdf"},
+			expectKind: PreviewKindPDF,
+			expectMime: "application/json",
+		},
+		{
+			name:       "other",
+			file:       FileInfo{Name: "a.zip", Category: CategoryOther, MimeType: "application/zip"},
+			expectKind: PreviewKindNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expectKind, tt.file.PreviewKind())
+			assert.Equal(t, tt.expectKind != PreviewKindNone, tt.file.NeedsPreview())
+			if tt.expectMime != "" {
+				assert.Equal(t, tt.expectMime, tt.file.PreviewMimeType())
+			}
+		})
+	}
+}
+
+func TestValidatePreviewSize(t *testing.T) {
+	t.Parallel()
+	size, err := validatePreviewSize("")
+	require.NoError(t, err)
+	assert.Equal(t, PreviewSizeSmall, size)
+
+	size, err = validatePreviewSize(" large ")
+	require.NoError(t, err)
+	assert.Equal(t, PreviewSizeLarge, size)
+
+	_, err = validatePreviewSize("huge")
+	assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+}
@@ -0,0 +1,149 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"skyvault/pkg/utils"
+	"strings"
+	"time"
+)
+
+// FileMetadata is extracted from the content of image and audio files. Unknown fields are nil.
+type FileMetadata struct {
+	FileID  string
+	OwnerID string
+
+	// Images, as displayed after applying the EXIF orientation
+	Width       *int
+	Height      *int
+	CapturedAt  *time.Time // Local time of the camera
+	CameraMake  *string
+	CameraModel *string
+	Latitude    *float64
+	Longitude   *float64
+
+	// Audio
+	DurationMs *int64
+	Title      *string
+	Artist     *string
+	Album      *string
+	Genre      *string
+	Year       *int
+	Track      *int
+
+	// TimelineAt is the capture time if known, the upload time otherwise.
+	// Truncated to seconds, as it is part of the paging cursor of the timeline.
+	TimelineAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type TimelineItem struct {
+	File     *FileInfo
+	Metadata *FileMetadata
+}
+
+// TimelineGroup holds the timeline items of a day. A day may be split across pages.
+type TimelineGroup struct {
+	Date  string // YYYY-MM-DD
+	Items []*TimelineItem
+}
+
+// GroupTimeline groups the consecutive items of the same day, keeping their order.
+func GroupTimeline(items []*TimelineItem) []*TimelineGroup {
+	groups := []*TimelineGroup{}
+	for _, item := range items {
+		date := item.Metadata.TimelineAt.Format(time.DateOnly)
+		if len(groups) == 0 || groups[len(groups)-1].Date != date {
+			groups = append(groups, &TimelineGroup{Date: date})
+		}
+		last := groups[len(groups)-1]
+		last.Items = append(last.Items, item)
+	}
+	return groups
+}
+
+// HasMetadata tells if metadata should be extracted from the file.
+func (f *FileInfo) HasMetadata() bool {
+	return f.Category == CategoryImage || f.Category == CategoryAudio
+}
+
+// NewFileMetadata extracts the metadata of the file. The extraction is best effort:
+// unsupported formats leave the fields empty, and on error the fields found so far are still returned.
+func NewFileMetadata(info *FileInfo, file io.ReadSeeker) (*FileMetadata, error) {
+	now := time.Now().UTC()
+	meta := &FileMetadata{
+		FileID:     info.ID,
+		OwnerID:    info.OwnerID,
+		TimelineAt: info.CreatedAt.UTC().Truncate(time.Second),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	_, format, _ := strings.Cut(info.MimeType, "/")
+	format, _, _ = strings.Cut(format, ";")
+	format = strings.TrimSpace(format)
+
+	var err error
+	switch info.Category {
+	case CategoryImage:
+		err = meta.readImage(format, file)
+	case CategoryAudio:
+		err = meta.readAudio(format, file)
+	}
+
+	if errors.Is(err, utils.ErrUnsupportedFormat) {
+		err = nil
+	}
+	return meta, err
+}
+
+func (m *FileMetadata) readImage(format string, file io.ReadSeeker) error {
+	if exif, err := utils.ReadExif(format, file); err == nil {
+		m.CameraMake = optional(exif.CameraMake)
+		m.CameraModel = optional(exif.CameraModel)
+		m.Latitude = exif.Latitude
+		m.Longitude = exif.Longitude
+
+		if exif.CapturedAt != nil {
+			capturedAt := exif.CapturedAt.Truncate(time.Second)
+			m.CapturedAt = &capturedAt
+			m.TimelineAt = capturedAt
+		}
+	}
+
+	width, height, err := utils.ImageDimensions(format, file)
+	if err != nil {
+		return err
+	}
+	m.Width = optional(width)
+	m.Height = optional(height)
+
+	return nil
+}
+
+func (m *FileMetadata) readAudio(format string, file io.ReadSeeker) error {
+	audio, err := utils.ReadAudioMetadata(format, file)
+	if err != nil {
+		return err
+	}
+
+	m.Title = optional(audio.Title)
+	m.Artist = optional(audio.Artist)
+	m.Album = optional(audio.Album)
+	m.Genre = optional(audio.Genre)
+	m.Year = optional(audio.Year)
+	m.Track = optional(audio.Track)
+	m.DurationMs = optional(audio.Duration.Milliseconds())
+
+	return nil
+}
+
+// optional returns nil for the zero value, which means unknown for metadata.
+func optional[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
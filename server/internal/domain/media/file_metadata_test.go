@@ -0,0 +1,86 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileMetadata(t *testing.T) {
+	t.Parallel()
+	createdAt := time.Date(2024, 3, 1, 10, 20, 30, 123456789, time.UTC)
+
+	t.Run("image without exif", func(t *testing.T) {
+		t.Parallel()
+		buf := new(bytes.Buffer)
+		require.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 30, 10))))
+
+		info := &FileInfo{ID: "1", OwnerID: "100", MimeType: "image/png", Category: CategoryImage, CreatedAt: createdAt}
+		meta, err := NewFileMetadata(info, bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, "1", meta.FileID)
+		assert.Equal(t, "100", meta.OwnerID)
+		require.NotNil(t, meta.Width)
+		require.NotNil(t, meta.Height)
+		assert.Equal(t, 30, *meta.Width)
+		assert.Equal(t, 10, *meta.Height)
+		assert.Nil(t, meta.CapturedAt)
+		assert.Equal(t, createdAt.Truncate(time.Second), meta.TimelineAt, "falls back to the upload time")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		t.Parallel()
+		info := &FileInfo{ID: "1", OwnerID: "100", MimeType: "audio/aac", Category: CategoryAudio, CreatedAt: createdAt}
+		meta, err := NewFileMetadata(info, bytes.NewReader([]byte("data")))
+		require.NoError(t, err)
+		assert.Nil(t, meta.DurationMs)
+		assert.Nil(t, meta.Title)
+	})
+
+	t.Run("corrupted image", func(t *testing.T) {
+		t.Parallel()
+		info := &FileInfo{ID: "1", OwnerID: "100", MimeType: "image/png", Category: CategoryImage, CreatedAt: createdAt}
+		meta, err := NewFileMetadata(info, bytes.NewReader([]byte("not a png")))
+		require.Error(t, err)
+		require.NotNil(t, meta, "still saved so that the file is not retried")
+		assert.Nil(t, meta.Width)
+	})
+}
+
+func TestGroupTimeline(t *testing.T) {
+	t.Parallel()
+	item := func(timelineAt string) *TimelineItem {
+		at, err := time.Parse(time.DateTime, timelineAt)
+		require.NoError(t, err)
+		return &TimelineItem{File: &FileInfo{}, Metadata: &FileMetadata{TimelineAt: at}}
+	}
+
+	items := []*TimelineItem{
+		item("2024-05-02 18:00:00"),
+		item("2024-05-02 08:00:00"),
+		item("2024-04-30 23:59:59"),
+		item("2023-04-30 12:00:00"),
+	}
+
+	groups := GroupTimeline(items)
+	require.Len(t, groups, 3)
+	assert.Equal(t, "2024-05-02", groups[0].Date)
+	assert.Equal(t, items[:2], groups[0].Items)
+	assert.Equal(t, "2024-04-30", groups[1].Date)
+	assert.Equal(t, "2023-04-30", groups[2].Date)
+
+	assert.Empty(t, GroupTimeline(nil))
+}
+
+func TestFileInfo_HasMetadata(t *testing.T) {
+	t.Parallel()
+	assert.True(t, (&FileInfo{Category: CategoryImage}).HasMetadata())
+	assert.True(t, (&FileInfo{Category: CategoryAudio}).HasMetadata())
+	assert.False(t, (&FileInfo{Category: CategoryVideo}).HasMetadata())
+	assert.False(t, (&FileInfo{Category: CategoryText}).HasMetadata())
+}
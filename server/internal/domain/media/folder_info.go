@@ -15,6 +15,11 @@ type FolderInfo struct {
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	TrashedAt      *time.Time
+
+	// Recursive stats of the non-trashed content, maintained by the repository
+	Size        int64
+	FileCount   int64
+	FolderCount int64
 }
 
 // App Errors:
@@ -0,0 +1,72 @@
+package media
+
+const (
+	// Levels of folders loaded by GetFolderTree when no depth is given, enough to open a navigation pane
+	defaultFolderTreeDepth = 2
+	maxFolderTreeDepth     = 10
+
+	// Max. number of folders in a tree, the deepest levels are left to be expanded lazily above it
+	maxFolderTreeNodes = 5000
+)
+
+// FolderTreeNode is a folder in the folder-only tree of a navigation pane.
+type FolderTreeNode struct {
+	Folder      *FolderInfo
+	HasChildren bool // Whether the folder has non-trashed subfolders, loaded or not
+
+	// nil if the subfolders are not loaded, they are loaded with a tree rooted at the folder
+	Children []*FolderTreeNode
+}
+
+// newFolderTree nests the folders returned by Repository.GetFolderTree under the root folder, nil is
+// the root folder. The folders are ordered by depth, the parents before their children.
+//
+// The folders of the levels above the depth have their children loaded. If there are more than
+// limit folders, only the complete levels are kept, and truncated is true.
+func newFolderTree(rootFolderID *string, folders []*FolderInfo, depth int, limit int) (nodes []*FolderTreeNode, truncated bool) {
+	// Depth of each folder, the children of the root folder are 1
+	depths := make(map[string]int, len(folders))
+	for _, f := range folders {
+		if f.ParentFolderID == nil || (rootFolderID != nil && *f.ParentFolderID == *rootFolderID) {
+			depths[f.ID] = 1
+		} else if d, ok := depths[*f.ParentFolderID]; ok {
+			depths[f.ID] = d + 1
+		}
+	}
+
+	if len(folders) > limit {
+		truncated = true
+		// The last level is incomplete, drop it, unless the root folder alone has too many children
+		depth = depths[folders[limit].ID] - 1
+		if depth < 1 {
+			depth = 1
+			folders = folders[:limit]
+		}
+	}
+
+	nodes = []*FolderTreeNode{}
+	byID := make(map[string]*FolderTreeNode, len(folders))
+	for _, f := range folders {
+		d, ok := depths[f.ID]
+		if !ok || d > depth {
+			continue
+		}
+
+		node := &FolderTreeNode{
+			Folder:      f,
+			HasChildren: f.FolderCount > 0,
+		}
+		if d < depth {
+			node.Children = []*FolderTreeNode{}
+		}
+		byID[f.ID] = node
+
+		if d == 1 {
+			nodes = append(nodes, node)
+		} else if parent := byID[*f.ParentFolderID]; parent != nil {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	return nodes, truncated
+}
@@ -0,0 +1,82 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFolderTree(t *testing.T) {
+	t.Parallel()
+	root := "root"
+	folder := func(id string, parentID *string, folderCount int64) *FolderInfo {
+		return &FolderInfo{ID: id, Name: id, ParentFolderID: parentID, FolderCount: folderCount}
+	}
+	a, b := "a", "b"
+
+	// Ordered by depth, as returned by the repository
+	folders := []*FolderInfo{
+		folder("a", &root, 2),
+		folder("b", &root, 1),
+		folder("c", &root, 0),
+		folder("a1", &a, 1),
+		folder("a2", &a, 0),
+		folder("b1", &b, 0),
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		t.Parallel()
+		nodes, truncated := newFolderTree(&root, folders, 2, 10)
+		assert.False(t, truncated)
+		require.Len(t, nodes, 3)
+
+		assert.Equal(t, "a", nodes[0].Folder.ID)
+		assert.True(t, nodes[0].HasChildren)
+		require.Len(t, nodes[0].Children, 2)
+		assert.Equal(t, "a1", nodes[0].Children[0].Folder.ID)
+		assert.True(t, nodes[0].Children[0].HasChildren)
+		assert.Nil(t, nodes[0].Children[0].Children, "below the depth, not loaded")
+
+		require.Len(t, nodes[1].Children, 1)
+		assert.Equal(t, "b1", nodes[1].Children[0].Folder.ID)
+
+		assert.False(t, nodes[2].HasChildren)
+		assert.NotNil(t, nodes[2].Children, "loaded, without children")
+		assert.Empty(t, nodes[2].Children)
+	})
+
+	t.Run("truncated drops the incomplete level", func(t *testing.T) {
+		t.Parallel()
+		nodes, truncated := newFolderTree(&root, folders[:5], 2, 4)
+		assert.True(t, truncated)
+		require.Len(t, nodes, 3)
+		for _, n := range nodes {
+			assert.Nil(t, n.Children)
+		}
+	})
+
+	t.Run("truncated first level", func(t *testing.T) {
+		t.Parallel()
+		nodes, truncated := newFolderTree(&root, folders[:3], 2, 2)
+		assert.True(t, truncated)
+		require.Len(t, nodes, 2)
+		assert.Nil(t, nodes[0].Children)
+	})
+
+	t.Run("root folder", func(t *testing.T) {
+		t.Parallel()
+		nodes, truncated := newFolderTree(nil, []*FolderInfo{folder("a", nil, 1), folder("a1", &a, 0)}, 1, 10)
+		assert.False(t, truncated)
+		require.Len(t, nodes, 1)
+		assert.Nil(t, nodes[0].Children)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		nodes, truncated := newFolderTree(&root, []*FolderInfo{}, 2, 10)
+		assert.False(t, truncated)
+		assert.NotNil(t, nodes)
+		assert.Empty(t, nodes)
+	})
+}
@@ -0,0 +1,54 @@
+package media
+
+import (
+	"fmt"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/common"
+	"skyvault/pkg/validate"
+	"strings"
+)
+
+// Max. number of folders and file in a path, e.g. "/a/b/c.txt" has 3
+const maxPathDepth = 64
+
+// splitPath returns the names in a slash separated path of the owner's folders, relative to the root folder.
+// Empty names are ignored, so "/a//b/" is "a/b". The root folder is an empty slice.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+func splitPath(p string) ([]string, error) {
+	names := []string{}
+	for _, name := range strings.Split(p, "/") {
+		if name == "" {
+			continue
+		}
+
+		if name == "." || name == ".." || strings.ContainsAny(name, "\\\x00") {
+			return nil, fmt.Errorf("%w: invalid name %q in path", apperror.ErrCommonInvalidValue, name)
+		}
+
+		n, err := validate.Name(name)
+		if err != nil || n != name {
+			return nil, fmt.Errorf("%w: invalid name %q in path", apperror.ErrCommonInvalidValue, name)
+		}
+
+		names = append(names, name)
+	}
+
+	if len(names) > maxPathDepth {
+		return nil, fmt.Errorf("%w: more than %d names in path", apperror.ErrCommonInvalidValue, maxPathDepth)
+	}
+
+	return names, nil
+}
+
+// joinPath returns the path of the name in the folder of the ancestors, the parent folder first
+// as returned by Repository.GetAncestors.
+func joinPath(ancestors []*common.BaseInfo, name string) string {
+	names := make([]string, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		names = append(names, ancestors[i].Name)
+	}
+	names = append(names, name)
+	return "/" + strings.Join(names, "/")
+}
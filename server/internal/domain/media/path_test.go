@@ -0,0 +1,64 @@
+package media
+
+import (
+	"strings"
+	"testing"
+
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPath(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{"root", "/", []string{}, false},
+		{"empty", "", []string{}, false},
+		{"file", "/docs/report.pdf", []string{"docs", "report.pdf"}, false},
+		{"relative", "docs/2024", []string{"docs", "2024"}, false},
+		{"empty names", "//docs///2024/", []string{"docs", "2024"}, false},
+		{"names with spaces", "/My Docs/a b.txt", []string{"My Docs", "a b.txt"}, false},
+		{"dot", "/docs/./a.txt", nil, true},
+		{"dot dot", "/docs/../a.txt", nil, true},
+		{"backslash", "/docs\\a.txt", nil, true},
+		{"null byte", "/docs/a\x00.txt", nil, true},
+		{"padded name", "/ docs/a.txt", nil, true},
+		{"too long name", "/" + strings.Repeat("a", 256), nil, true},
+		{"too deep", strings.Repeat("/a", maxPathDepth+1), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			names, err := splitPath(tt.path)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/spec.pdf", joinPath(nil, "spec.pdf"))
+
+	// The ancestors come parent first
+	ancestors := []*common.BaseInfo{{ID: "2", Name: "2026"}, {ID: "1", Name: "Projects"}}
+	assert.Equal(t, "/Projects/2026/spec.pdf", joinPath(ancestors, "spec.pdf"))
+
+	names, err := splitPath(joinPath(ancestors, "spec.pdf"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Projects", "2026", "spec.pdf"}, names)
+}
@@ -0,0 +1,202 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"skyvault/pkg/utils"
+	"strings"
+	"time"
+)
+
+type PreviewStatus string
+
+const (
+	PreviewStatusPending     PreviewStatus = "pending"
+	PreviewStatusReady       PreviewStatus = "ready"
+	PreviewStatusUnsupported PreviewStatus = "unsupported"
+	PreviewStatusFailed      PreviewStatus = "failed"
+)
+
+type PreviewSize string
+
+const (
+	PreviewSizeSmall  PreviewSize = "small"
+	PreviewSizeMedium PreviewSize = "medium"
+	PreviewSizeLarge  PreviewSize = "large"
+)
+
+// PreviewSizes are generated for every file that supports previews.
+var PreviewSizes = []PreviewSize{PreviewSizeSmall, PreviewSizeMedium, PreviewSizeLarge}
+
+// Max. duration of the generation of all the previews of a file, malformed files can take long to parse
+const previewTimeout = time.Minute
+
+// MaxPixels returns the max. width and height of image previews, in pixels.
+func (s PreviewSize) MaxPixels() int {
+	switch s {
+	case PreviewSizeSmall:
+		return 256
+	case PreviewSizeMedium:
+		return 512
+	default:
+		return 1024
+	}
+}
+
+// MaxLines returns the max. number of lines of text previews.
+func (s PreviewSize) MaxLines() int {
+	switch s {
+	case PreviewSizeSmall:
+		return 20
+	case PreviewSizeMedium:
+		return 50
+	default:
+		return 200
+	}
+}
+
+// MaxBytes returns the max. number of bytes of the source read for markdown previews.
+func (s PreviewSize) MaxBytes() int64 {
+	switch s {
+	case PreviewSizeSmall:
+		return 4 * 1024
+	case PreviewSizeMedium:
+		return 16 * 1024
+	default:
+		return 64 * 1024
+	}
+}
+
+// MaxTextLen returns the max. number of characters of the text extracted for pdf previews.
+func (s PreviewSize) MaxTextLen() int {
+	switch s {
+	case PreviewSizeSmall:
+		return 200
+	case PreviewSizeMedium:
+		return 1000
+	default:
+		return 4000
+	}
+}
+
+// PreviewKind tells how the preview of a file is generated and served.
+type PreviewKind string
+
+const (
+	PreviewKindNone     PreviewKind = ""
+	PreviewKindImage    PreviewKind = "image"    // Scaled down jpeg
+	PreviewKindText     PreviewKind = "text"     // First lines, highlighted as html
+	PreviewKindMarkdown PreviewKind = "markdown" // Sanitized html
+	PreviewKindPDF      PreviewKind = "pdf"      // Json summary, see utils.PDFSummary
+)
+
+// Text files outside of the text category, e.g. source files with an application/* mime type
+var textMimeTypes = map[string]bool{
+	"application/json":       true,
+	"application/xml":        true,
+	"application/javascript": true,
+	"application/typescript": true,
+	"application/x-sh":       true,
+	"application/x-yaml":     true,
+	"application/yaml":       true,
+	"application/toml":       true,
+	"application/sql":        true,
+}
+
+func (f *FileInfo) PreviewKind() PreviewKind {
+	mimeType, _, _ := strings.Cut(f.MimeType, ";")
+	mimeType = strings.TrimSpace(mimeType)
+
+	switch {
+	case f.Category == CategoryImage:
+		if utils.SupportsImageFormat(f.PreviewFormat()) {
+			return PreviewKindImage
+		}
+		return PreviewKindNone
+	case mimeType == "text/markdown" || mimeType == "text/x-markdown" || strings.EqualFold(filepath.Ext(f.Name), ".md"):
+		return PreviewKindMarkdown
+	case f.Category == CategoryText || textMimeTypes[mimeType]:
+		return PreviewKindText
+	case mimeType == "application/pdf":
+		return PreviewKindPDF
+	default:
+		return PreviewKindNone
+	}
+}
+
+// PreviewMimeType returns the content type of the generated previews.
+func (f *FileInfo) PreviewMimeType() string {
+	switch f.PreviewKind() {
+	case PreviewKindText, PreviewKindMarkdown:
+		return "text/html; charset=utf-8"
+	case PreviewKindPDF:
+		return "application/json"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// NeedsPreview tells if a preview should be generated for the file.
+func (f *FileInfo) NeedsPreview() bool {
+	return f.PreviewKind() != PreviewKindNone
+}
+
+// PreviewFormat returns the image format used by the preview generator.
+func (f *FileInfo) PreviewFormat() string {
+	_, format, _ := strings.Cut(f.MimeType, "/")
+	return format
+}
+
+// GeneratePreviews returns the previews of the file for the given sizes, in their order,
+// see PreviewMimeType for their format. Images and pdf files are decoded only once for all the sizes.
+//
+// Errors:
+// - utils.ErrUnsupportedFormat
+func (f *FileInfo) GeneratePreviews(ctx context.Context, file io.ReadSeeker, sizes []PreviewSize) ([][]byte, error) {
+	kind := f.PreviewKind()
+	switch kind {
+	case PreviewKindImage:
+		maxPixels := make([]int, len(sizes))
+		for i, size := range sizes {
+			maxPixels[i] = size.MaxPixels()
+		}
+		return utils.ScaleDownImageToFitSizes(f.PreviewFormat(), file, maxPixels)
+	case PreviewKindPDF:
+		maxTextLens := make([]int, len(sizes))
+		for i, size := range sizes {
+			maxTextLens[i] = size.MaxTextLen()
+		}
+		return utils.SummarizePDFSizes(ctx, file, maxTextLens)
+	case PreviewKindText, PreviewKindMarkdown:
+		// Only the beginning of the file is read, once per size
+		previews := make([][]byte, len(sizes))
+		for i, size := range sizes {
+			var err error
+			if kind == PreviewKindText {
+				previews[i], err = utils.HighlightText(file, f.Name, f.MimeType, size.MaxLines())
+			} else {
+				previews[i], err = utils.RenderMarkdown(file, size.MaxBytes())
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return previews, nil
+	default:
+		return nil, utils.ErrUnsupportedFormat
+	}
+}
+
+// SetPreviewStatus sets the status from the result of the preview generation.
+func (f *FileInfo) SetPreviewStatus(err error) {
+	switch {
+	case err == nil:
+		f.PreviewStatus = PreviewStatusReady
+	case errors.Is(err, utils.ErrUnsupportedFormat):
+		f.PreviewStatus = PreviewStatusUnsupported
+	default:
+		f.PreviewStatus = PreviewStatusFailed
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"skyvault/pkg/common"
 	"skyvault/pkg/paging"
+	"time"
 )
 
 type Queries interface {
@@ -12,16 +13,164 @@ type Queries interface {
 
 	GetFolderInfo(ctx context.Context, query *GetFolderInfoQuery) (*FolderInfo, error)
 
+	// GetAncestors returns the ancestors of the folder, the parent folder first.
 	GetAncestors(ctx context.Context, ownerID, folderID string) ([]*common.BaseInfo, error)
 
 	GetFolderContent(ctx context.Context, query *GetFolderContentQuery) (*GetFolderContentRes, error)
 
+	// GetFolderTree returns the folder-only tree under the folder, nil is the root folder, down to the depth.
+	// The deeper folders are expanded lazily with a tree rooted at them.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GetFolderTree(ctx context.Context, query *GetFolderTreeQuery) (*GetFolderTreeRes, error)
+
+	// ResolvePath returns the folder or file at a slash separated path of the owner's folders, e.g. "/docs/report.pdf".
+	// A folder wins over a file of the same name.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	ResolvePath(ctx context.Context, query *ResolvePathQuery) (*ResolvePathRes, error)
+
+	// GetFilePath returns the slash separated path of the file from the root folder, the reverse of ResolvePath.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GetFilePath(ctx context.Context, query *GetFilePathQuery) (string, error)
+
+	// GetFolderPath is GetFilePath for folders.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GetFolderPath(ctx context.Context, query *GetFolderPathQuery) (string, error)
+
 	// The file MUST be CLOSED after use by the caller.
 	//
 	// App Errors:
 	// - ErrCommonNoData
 	// - ErrCommonNoAccess
+	// - ErrMediaFileInfected
 	GetFile(ctx context.Context, query *GetFileQuery) (*GetFileRes, error)
+
+	// GetZipArchive prepares the download of the files and folders as one archive, the folders with
+	// their whole subtree. The archive is written by ZipArchive.Write.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	// - ErrCommonInvalidValue
+	// - ErrMediaFileInfected
+	GetZipArchive(ctx context.Context, query *GetZipArchiveQuery) (*ZipArchive, error)
+
+	// GetArchiveEntries lists the files and folders of a zip or tar archive, read in place from the storage.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	// - ErrCommonInvalidValue
+	// - ErrMediaFileInfected
+	// - ErrMediaUnsafeArchive
+	GetArchiveEntries(ctx context.Context, query *GetArchiveEntriesQuery) ([]*ArchiveEntry, error)
+
+	// GetArchiveEntry opens one file of a zip or tar archive without extracting the archive.
+	// The content MUST be CLOSED after use by the caller, it is an io.ReadSeeker when the file is not compressed.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	// - ErrCommonInvalidValue
+	// - ErrMediaFileInfected
+	// - ErrMediaUnsafeArchive
+	GetArchiveEntry(ctx context.Context, query *GetArchiveEntryQuery) (*GetArchiveEntryRes, error)
+
+	// GetArchiveExtraction returns the progress of an extraction started by Commands.ExtractArchive.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	GetArchiveExtraction(ctx context.Context, query *GetArchiveExtractionQuery) (*ArchiveExtraction, error)
+
+	// The preview MUST be CLOSED after use by the caller.
+	//
+	// App Errors:
+	// - ErrCommonNoData (also if the preview is not generated yet)
+	// - ErrCommonNoAccess
+	// - ErrCommonInvalidValue
+	// - ErrMediaFileInfected
+	GetPreview(ctx context.Context, query *GetPreviewQuery) (*GetPreviewRes, error)
+
+	// App Errors:
+	// - ErrCommonNoData (also if the metadata is not extracted yet)
+	// - ErrCommonNoAccess
+	GetFileMetadata(ctx context.Context, query *GetFileMetadataQuery) (*FileMetadata, error)
+
+	// GetTimeline returns the images of the owner grouped by day, newest first by default.
+	// The paging options are always sorted by the timeline time.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	GetTimeline(ctx context.Context, query *GetTimelineQuery) (*GetTimelineRes, error)
+
+	// GetQuarantinedFiles returns the files of the owner in which malware was found.
+	GetQuarantinedFiles(ctx context.Context, query *GetQuarantinedFilesQuery) (*paging.Page[*FileInfo], error)
+
+	// Search returns the files and folders of the owner matching the query, see SearchFilter.
+	// The folder page is empty if the query has file only filters.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData (the folder of the query doesn't exist)
+	Search(ctx context.Context, query *SearchQuery) (*SearchRes, error)
+
+	GetTags(ctx context.Context, query *GetTagsQuery) (*paging.Page[*Tag], error)
+
+	// GetTaggedItems returns the non-trashed files and folders with the tag.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	GetTaggedItems(ctx context.Context, query *GetTaggedItemsQuery) (*GetTaggedItemsRes, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GetFileTags(ctx context.Context, query *GetFileTagsQuery) ([]*Tag, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetFolderTags(ctx context.Context, query *GetFolderTagsQuery) ([]*Tag, error)
+
+	// GetStarredItems returns the non-trashed starred files and folders of the owner.
+	GetStarredItems(ctx context.Context, query *GetStarredItemsQuery) (*GetStarredItemsRes, error)
+
+	// GetRecentFiles returns the files with the last activity of the owner, newest first by default.
+	// The paging options are always sorted by the activity time.
+	GetRecentFiles(ctx context.Context, query *GetRecentFilesQuery) (*paging.Page[*RecentItem], error)
+
+	// GetStorageUsage returns the storage used by the owner, with the quota and the usage per category.
+	GetStorageUsage(ctx context.Context, query *GetStorageUsageQuery) (*GetStorageUsageRes, error)
+
+	// GetChanges returns the changes of the owner's files and folders since the cursor, for the sync clients.
+	// Without a cursor, it returns no changes and the cursor of the last change, to be taken before a full sync.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrMediaChangeCursorExpired
+	GetChanges(ctx context.Context, query *GetChangesQuery) (*GetChangesRes, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GetFileProperties(ctx context.Context, query *GetFilePropertiesQuery) (map[string]string, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetFolderProperties(ctx context.Context, query *GetFolderPropertiesQuery) (map[string]string, error)
 }
 
 type GetFileInfosByCategoryQuery struct {
@@ -47,6 +196,41 @@ type GetFolderContentRes struct {
 	FolderPage *paging.Page[*FolderInfo]
 }
 
+type GetFolderTreeQuery struct {
+	OwnerID  string
+	FolderID *string
+	Depth    int // Levels of folders to load, 1 for the subfolders only, 0 for the default
+}
+
+type GetFolderTreeRes struct {
+	Folder    *FolderInfo        // nil for the root folder
+	Ancestors []*common.BaseInfo // The ancestors of the folder, the parent folder first, to open the tree to a deep link
+	Nodes     []*FolderTreeNode  // The subfolders of the folder
+	Truncated bool               // Whether the deepest levels were left out for being too many folders
+}
+
+type ResolvePathQuery struct {
+	OwnerID string
+	Path    string
+}
+
+// ResolvePathRes has either the folder or the file at the path, both are nil for the root folder.
+type ResolvePathRes struct {
+	Folder    *FolderInfo
+	File      *FileInfo
+	Ancestors []*FolderInfo // The folders the item is in, the root folder's child first
+}
+
+type GetFilePathQuery struct {
+	OwnerID string
+	FileID  string
+}
+
+type GetFolderPathQuery struct {
+	OwnerID  string
+	FolderID string
+}
+
 type GetFileQuery struct {
 	OwnerID string
 	FileID  string
@@ -56,3 +240,161 @@ type GetFileRes struct {
 	Info *FileInfo
 	File io.ReadSeekCloser
 }
+
+type GetZipArchiveQuery struct {
+	OwnerID   string
+	FileIDs   []string
+	FolderIDs []string
+}
+
+type GetArchiveEntriesQuery struct {
+	OwnerID string
+	FileID  string
+}
+
+type GetArchiveEntryQuery struct {
+	OwnerID string
+	FileID  string
+	Path    string
+}
+
+type GetArchiveEntryRes struct {
+	Entry   *ArchiveEntry
+	Content io.ReadCloser
+}
+
+type GetArchiveExtractionQuery struct {
+	OwnerID      string
+	ExtractionID string
+}
+
+type GetPreviewQuery struct {
+	OwnerID string
+	FileID  string
+	Size    PreviewSize
+}
+
+type GetPreviewRes struct {
+	Info    *FileInfo
+	Preview io.ReadSeekCloser
+}
+
+type GetFileMetadataQuery struct {
+	OwnerID string
+	FileID  string
+}
+
+type GetTimelineQuery struct {
+	OwnerID   string
+	PagingOpt *paging.Options
+}
+
+type GetQuarantinedFilesQuery struct {
+	OwnerID   string
+	PagingOpt *paging.Options
+}
+
+type SearchQuery struct {
+	OwnerID     string
+	Term        string
+	Category    Category
+	Extension   string
+	MinSize     *int64
+	MaxSize     *int64
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	// FolderID restricts the search to the subtree of the folder, nil searches the whole vault
+	FolderID        *string
+	FilePagingOpt   *paging.Options
+	FolderPagingOpt *paging.Options
+}
+
+type SearchRes struct {
+	FilePage   *paging.Page[*FileInfo]
+	FolderPage *paging.Page[*FolderInfo]
+}
+
+type GetTagsQuery struct {
+	OwnerID   string
+	PagingOpt *paging.Options
+}
+
+type GetTaggedItemsQuery struct {
+	OwnerID         string
+	TagID           string
+	FilePagingOpt   *paging.Options
+	FolderPagingOpt *paging.Options
+}
+
+type GetTaggedItemsRes struct {
+	FilePage   *paging.Page[*FileInfo]
+	FolderPage *paging.Page[*FolderInfo]
+}
+
+type GetFileTagsQuery struct {
+	OwnerID string
+	FileID  string
+}
+
+type GetFolderTagsQuery struct {
+	OwnerID  string
+	FolderID string
+}
+
+type GetStarredItemsQuery struct {
+	OwnerID         string
+	FilePagingOpt   *paging.Options
+	FolderPagingOpt *paging.Options
+}
+
+type GetStarredItemsRes struct {
+	FilePage   *paging.Page[*FileInfo]
+	FolderPage *paging.Page[*FolderInfo]
+}
+
+type GetRecentFilesQuery struct {
+	OwnerID   string
+	PagingOpt *paging.Options
+}
+
+type GetStorageUsageQuery struct {
+	OwnerID string
+}
+
+type GetStorageUsageRes struct {
+	UsedBytes    int64 // Non-trashed files
+	TrashedBytes int64 // Also counted in the quota, until purged
+	QuotaBytes   int64 // 0 is unlimited
+	Categories   []*CategoryUsage
+}
+
+type GetChangesQuery struct {
+	OwnerID string
+	Cursor  string
+	Limit   int
+}
+
+type GetChangesRes struct {
+	Changes []*Change
+	Cursor  string // The cursor to get the next changes with, the same one if there are none
+	HasMore bool
+}
+
+type GetFilePropertiesQuery struct {
+	OwnerID string
+	FileID  string
+}
+
+type GetFolderPropertiesQuery struct {
+	OwnerID  string
+	FolderID string
+}
+
+type GetTimelineRes struct {
+	Groups         []*TimelineGroup
+	PrevCursor     string
+	NextCursor     string
+	HasMore        bool
+	Total          *int64
+	TotalEstimated bool
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/paging"
+	"skyvault/pkg/validate"
+	"slices"
 	"strings"
+	"unicode/utf8"
 )
 
 var _ Queries = (*QueriesSanitizer)(nil)
@@ -27,6 +30,18 @@ func validateCategory(category Category) (Category, error) {
 	}
 }
 
+func validatePreviewSize(size PreviewSize) (PreviewSize, error) {
+	size = PreviewSize(strings.TrimSpace(string(size)))
+	switch size {
+	case PreviewSizeSmall, PreviewSizeMedium, PreviewSizeLarge:
+		return size, nil
+	case "":
+		return PreviewSizeSmall, nil
+	default:
+		return "", apperror.ErrCommonInvalidValue
+	}
+}
+
 func (s *QueriesSanitizer) GetFileInfosByCategory(ctx context.Context, query *GetFileInfosByCategoryQuery) (*paging.Page[*FileInfo], error) {
 	if c, err := validateCategory(query.Category); err != nil {
 		return nil, err
@@ -36,3 +51,151 @@ func (s *QueriesSanitizer) GetFileInfosByCategory(ctx context.Context, query *Ge
 
 	return s.Queries.GetFileInfosByCategory(ctx, query)
 }
+
+func (s *QueriesSanitizer) GetZipArchive(ctx context.Context, query *GetZipArchiveQuery) (*ZipArchive, error) {
+	query.FileIDs = slices.Compact(slices.Sorted(slices.Values(query.FileIDs)))
+	query.FolderIDs = slices.Compact(slices.Sorted(slices.Values(query.FolderIDs)))
+	if err := validateItemIDs(query.FileIDs, query.FolderIDs); err != nil {
+		return nil, apperror.NewAppError(err, "media.QueriesSanitizer.GetZipArchive:validateItemIDs")
+	}
+
+	return s.Queries.GetZipArchive(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetArchiveEntries(ctx context.Context, query *GetArchiveEntriesQuery) ([]*ArchiveEntry, error) {
+	if !validate.UUID(query.FileID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetArchiveEntries:FileID").WithMetadata("file_id", query.FileID)
+	}
+
+	return s.Queries.GetArchiveEntries(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetArchiveEntry(ctx context.Context, query *GetArchiveEntryQuery) (*GetArchiveEntryRes, error) {
+	if !validate.UUID(query.FileID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetArchiveEntry:FileID").WithMetadata("file_id", query.FileID)
+	}
+
+	// The entries are matched by their cleaned path, see ArchiveEntry
+	p, err := cleanArchivePath(query.Path)
+	if err != nil || p == "" {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetArchiveEntry:Path").WithMetadata("path", query.Path)
+	}
+	query.Path = p
+
+	return s.Queries.GetArchiveEntry(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetArchiveExtraction(ctx context.Context, query *GetArchiveExtractionQuery) (*ArchiveExtraction, error) {
+	if !validate.UUID(query.ExtractionID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetArchiveExtraction:ExtractionID").WithMetadata("extraction_id", query.ExtractionID)
+	}
+
+	return s.Queries.GetArchiveExtraction(ctx, query)
+}
+
+func (s *QueriesSanitizer) ResolvePath(ctx context.Context, query *ResolvePathQuery) (*ResolvePathRes, error) {
+	if _, err := splitPath(query.Path); err != nil {
+		return nil, apperror.NewAppError(err, "media.QueriesSanitizer.ResolvePath:Path").WithMetadata("path", query.Path)
+	}
+
+	return s.Queries.ResolvePath(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetFilePath(ctx context.Context, query *GetFilePathQuery) (string, error) {
+	if !validate.UUID(query.FileID) {
+		return "", apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetFilePath:FileID").WithMetadata("file_id", query.FileID)
+	}
+
+	return s.Queries.GetFilePath(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetFolderPath(ctx context.Context, query *GetFolderPathQuery) (string, error) {
+	if !validate.UUID(query.FolderID) {
+		return "", apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetFolderPath:FolderID").WithMetadata("folder_id", query.FolderID)
+	}
+
+	return s.Queries.GetFolderPath(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetFolderTree(ctx context.Context, query *GetFolderTreeQuery) (*GetFolderTreeRes, error) {
+	if query.FolderID != nil && !validate.UUID(*query.FolderID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetFolderTree:FolderID").WithMetadata("folder_id", *query.FolderID)
+	}
+
+	if query.Depth == 0 {
+		query.Depth = defaultFolderTreeDepth
+	}
+	if query.Depth < 1 || query.Depth > maxFolderTreeDepth {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetFolderTree:Depth").WithMetadata("depth", query.Depth)
+	}
+
+	return s.Queries.GetFolderTree(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetChanges(ctx context.Context, query *GetChangesQuery) (*GetChangesRes, error) {
+	if query.Limit == 0 {
+		query.Limit = defaultChangesLimit
+	}
+	if query.Limit < 1 || query.Limit > maxChangesLimit {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetChanges:Limit").WithMetadata("limit", query.Limit)
+	}
+
+	return s.Queries.GetChanges(ctx, query)
+}
+
+// Max. length of a search term, in characters
+const maxSearchTermLength = 200
+
+func (s *QueriesSanitizer) Search(ctx context.Context, query *SearchQuery) (*SearchRes, error) {
+	query.Term = strings.TrimSpace(query.Term)
+	if utf8.RuneCountInString(query.Term) > maxSearchTermLength {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.Search:Term").WithMetadata("term_length", len(query.Term))
+	}
+
+	if query.Category != "" {
+		if c, err := validateCategory(query.Category); err != nil {
+			return nil, apperror.NewAppError(err, "media.QueriesSanitizer.Search:Category").WithMetadata("category", query.Category)
+		} else {
+			query.Category = c
+		}
+	}
+
+	// Extensions are stored with the leading dot and compared case-insensitively, see NewFileInfo
+	query.Extension = strings.ToLower(strings.TrimSpace(query.Extension))
+	if query.Extension != "" && !strings.HasPrefix(query.Extension, ".") {
+		query.Extension = "." + query.Extension
+	}
+
+	if (query.MinSize != nil && *query.MinSize < 0) || (query.MaxSize != nil && *query.MaxSize < 0) ||
+		(query.MinSize != nil && query.MaxSize != nil && *query.MinSize > *query.MaxSize) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.Search:Size")
+	}
+
+	if query.UpdatedFrom != nil && query.UpdatedTo != nil && query.UpdatedFrom.After(*query.UpdatedTo) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.Search:Updated")
+	}
+
+	if query.FolderID != nil && !validate.UUID(*query.FolderID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.Search:FolderID").WithMetadata("folder_id", *query.FolderID)
+	}
+
+	return s.Queries.Search(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetTaggedItems(ctx context.Context, query *GetTaggedItemsQuery) (*GetTaggedItemsRes, error) {
+	if !validate.UUID(query.TagID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "media.QueriesSanitizer.GetTaggedItems:TagID").WithMetadata("tag_id", query.TagID)
+	}
+
+	return s.Queries.GetTaggedItems(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetPreview(ctx context.Context, query *GetPreviewQuery) (*GetPreviewRes, error) {
+	if size, err := validatePreviewSize(query.Size); err != nil {
+		return nil, apperror.NewAppError(err, "media.QueriesSanitizer.GetPreview:Size").WithMetadata("size", query.Size)
+	} else {
+		query.Size = size
+	}
+
+	return s.Queries.GetPreview(ctx, query)
+}
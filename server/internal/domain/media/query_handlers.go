@@ -2,6 +2,10 @@ package media
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
 	"skyvault/pkg/paging"
@@ -10,12 +14,13 @@ import (
 var _ Queries = (*QueryHandlers)(nil)
 
 type QueryHandlers struct {
+	app        *appconfig.App
 	repository Repository
 	storage    Storage
 }
 
-func NewQueryHandlers(repository Repository, storage Storage) Queries {
-	return &QueryHandlers{repository: repository, storage: storage}
+func NewQueryHandlers(app *appconfig.App, repository Repository, storage Storage) Queries {
+	return &QueryHandlers{app: app, repository: repository, storage: storage}
 }
 
 func (h *QueryHandlers) GetFile(ctx context.Context, query *GetFileQuery) (*GetFileRes, error) {
@@ -29,6 +34,11 @@ func (h *QueryHandlers) GetFile(ctx context.Context, query *GetFileQuery) (*GetF
 		return nil, apperror.NewAppError(err, "QueryHandlers.GetFile:ValidateAccess")
 	}
 
+	err = info.ValidateNotQuarantined()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFile:ValidateNotQuarantined")
+	}
+
 	file, err := h.storage.OpenFile(ctx, info.ID, query.OwnerID)
 	if err != nil {
 		return nil, apperror.NewAppError(err, "QueryHandlers.GetFile:OpenFile")
@@ -39,6 +49,183 @@ func (h *QueryHandlers) GetFile(ctx context.Context, query *GetFileQuery) (*GetF
 	}, nil
 }
 
+func (h *QueryHandlers) GetZipArchive(ctx context.Context, query *GetZipArchiveQuery) (*ZipArchive, error) {
+	files := make([]*FileInfo, 0, len(query.FileIDs))
+	for _, fileID := range query.FileIDs {
+		info, err := h.repository.GetFileInfo(ctx, fileID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetZipArchive:GetFileInfo").WithMetadata("file_id", fileID)
+		}
+
+		err = info.ValidateAccess(query.OwnerID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetZipArchive:ValidateAccess").WithMetadata("file_id", fileID)
+		}
+
+		err = info.ValidateNotQuarantined()
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetZipArchive:ValidateNotQuarantined").WithMetadata("file_id", fileID)
+		}
+
+		files = append(files, info)
+	}
+
+	if len(query.FolderIDs) == 0 {
+		return newZipArchive(query.OwnerID, h.storage, files, nil, nil, nil), nil
+	}
+
+	for _, folderID := range query.FolderIDs {
+		_, err := h.repository.GetFolderInfo(ctx, query.OwnerID, folderID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetZipArchive:GetFolderInfo").WithMetadata("folder_id", folderID)
+		}
+	}
+
+	folders, err := h.repository.GetSubtreeFolderInfos(ctx, query.OwnerID, query.FolderIDs)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetZipArchive:GetSubtreeFolderInfos")
+	}
+
+	folderFiles, err := h.repository.GetSubtreeFileInfos(ctx, query.OwnerID, query.FolderIDs)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetZipArchive:GetSubtreeFileInfos")
+	}
+
+	return newZipArchive(query.OwnerID, h.storage, files, query.FolderIDs, folders, folderFiles), nil
+}
+
+func (h *QueryHandlers) GetArchiveEntries(ctx context.Context, query *GetArchiveEntriesQuery) ([]*ArchiveEntry, error) {
+	info, file, err := h.openArchive(ctx, query.OwnerID, query.FileID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetArchiveEntries:openArchive")
+	}
+	defer file.Close()
+
+	entries, err := listArchive(file, info.Size, getArchiveFormat(info))
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetArchiveEntries:listArchive").WithMetadata("file_id", info.ID)
+	}
+
+	return entries, nil
+}
+
+func (h *QueryHandlers) GetArchiveEntry(ctx context.Context, query *GetArchiveEntryQuery) (*GetArchiveEntryRes, error) {
+	info, file, err := h.openArchive(ctx, query.OwnerID, query.FileID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetArchiveEntry:openArchive")
+	}
+
+	entry, content, err := openArchiveEntry(file, info.Size, getArchiveFormat(info), query.Path)
+	if err != nil {
+		file.Close()
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetArchiveEntry:openArchiveEntry").WithMetadata("file_id", info.ID)
+	}
+
+	return &GetArchiveEntryRes{Entry: entry, Content: newArchiveEntryContent(content, file)}, nil
+}
+
+// openArchive opens the archive file for reading, the file must be closed by the caller.
+func (h *QueryHandlers) openArchive(ctx context.Context, ownerID, fileID string) (*FileInfo, io.ReadSeekCloser, error) {
+	info, err := h.repository.GetFileInfo(ctx, fileID)
+	if err != nil {
+		return nil, nil, apperror.NewAppError(err, "QueryHandlers.openArchive:GetFileInfo")
+	}
+
+	err = info.ValidateAccess(ownerID)
+	if err != nil {
+		return nil, nil, apperror.NewAppError(err, "QueryHandlers.openArchive:ValidateAccess")
+	}
+
+	err = info.ValidateNotQuarantined()
+	if err != nil {
+		return nil, nil, apperror.NewAppError(err, "QueryHandlers.openArchive:ValidateNotQuarantined")
+	}
+
+	if getArchiveFormat(info) == "" {
+		return nil, nil, apperror.NewAppError(fmt.Errorf("%w: not a zip or tar archive", apperror.ErrCommonInvalidValue), "QueryHandlers.openArchive:getArchiveFormat").WithMetadata("mime_type", info.MimeType)
+	}
+
+	file, err := h.storage.OpenFile(ctx, info.ID, ownerID)
+	if err != nil {
+		return nil, nil, apperror.NewAppError(err, "QueryHandlers.openArchive:OpenFile")
+	}
+
+	return info, file, nil
+}
+
+func (h *QueryHandlers) GetArchiveExtraction(ctx context.Context, query *GetArchiveExtractionQuery) (*ArchiveExtraction, error) {
+	extraction, err := h.repository.GetArchiveExtraction(ctx, query.OwnerID, query.ExtractionID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetArchiveExtraction:GetArchiveExtraction")
+	}
+
+	return extraction, nil
+}
+
+func (h *QueryHandlers) GetPreview(ctx context.Context, query *GetPreviewQuery) (*GetPreviewRes, error) {
+	info, err := h.repository.GetFileInfo(ctx, query.FileID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetPreview:GetFileInfo")
+	}
+
+	err = info.ValidateAccess(query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetPreview:ValidateAccess")
+	}
+
+	err = info.ValidateNotQuarantined()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetPreview:ValidateNotQuarantined")
+	}
+
+	if info.PreviewStatus != PreviewStatusReady {
+		return nil, apperror.NewAppError(apperror.ErrCommonNoData, "QueryHandlers.GetPreview:PreviewStatus").WithMetadata("preview_status", info.PreviewStatus)
+	}
+
+	preview, err := h.storage.OpenPreview(ctx, info.ID, query.Size, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetPreview:OpenPreview")
+	}
+	return &GetPreviewRes{
+		Info:    info,
+		Preview: preview,
+	}, nil
+}
+
+func (h *QueryHandlers) GetFileMetadata(ctx context.Context, query *GetFileMetadataQuery) (*FileMetadata, error) {
+	info, err := h.repository.GetFileInfo(ctx, query.FileID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileMetadata:GetFileInfo")
+	}
+
+	err = info.ValidateAccess(query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileMetadata:ValidateAccess")
+	}
+
+	meta, err := h.repository.GetFileMetadata(ctx, info.ID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileMetadata:GetFileMetadata")
+	}
+	return meta, nil
+}
+
+func (h *QueryHandlers) GetTimeline(ctx context.Context, query *GetTimelineQuery) (*GetTimelineRes, error) {
+	page, err := h.repository.GetTimeline(ctx, query.PagingOpt, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetTimeline:GetTimeline")
+	}
+
+	return &GetTimelineRes{
+		Groups:         GroupTimeline(page.Items),
+		PrevCursor:     page.PrevCursor,
+		NextCursor:     page.NextCursor,
+		HasMore:        page.HasMore,
+		Total:          page.Total,
+		TotalEstimated: page.TotalEstimated,
+	}, nil
+}
+
 func (h *QueryHandlers) GetFileInfosByCategory(ctx context.Context, query *GetFileInfosByCategoryQuery) (*paging.Page[*FileInfo], error) {
 	files, err := h.repository.GetFileInfosByCategory(ctx, query.PagingOpt, query.OwnerID, query.Category)
 	if err != nil {
@@ -48,6 +235,239 @@ func (h *QueryHandlers) GetFileInfosByCategory(ctx context.Context, query *GetFi
 	return files, nil
 }
 
+func (h *QueryHandlers) GetQuarantinedFiles(ctx context.Context, query *GetQuarantinedFilesQuery) (*paging.Page[*FileInfo], error) {
+	files, err := h.repository.GetQuarantinedFileInfos(ctx, query.PagingOpt, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetQuarantinedFiles:GetQuarantinedFileInfos")
+	}
+
+	return files, nil
+}
+
+func (h *QueryHandlers) Search(ctx context.Context, query *SearchQuery) (*SearchRes, error) {
+	filter := &SearchFilter{
+		Term:        query.Term,
+		Category:    query.Category,
+		Extension:   query.Extension,
+		MinSize:     query.MinSize,
+		MaxSize:     query.MaxSize,
+		UpdatedFrom: query.UpdatedFrom,
+		UpdatedTo:   query.UpdatedTo,
+	}
+
+	if query.FolderID != nil {
+		_, err := h.repository.GetFolderInfo(ctx, query.OwnerID, *query.FolderID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.Search:GetFolderInfo")
+		}
+
+		descendantIDs, err := h.repository.GetDescendantFolderIDs(ctx, query.OwnerID, *query.FolderID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.Search:GetDescendantFolderIDs")
+		}
+		filter.FolderIDs = append(descendantIDs, *query.FolderID)
+	}
+
+	files, err := h.repository.SearchFileInfos(ctx, query.FilePagingOpt, query.OwnerID, filter)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.Search:SearchFileInfos")
+	}
+
+	folders := &paging.Page[*FolderInfo]{Items: []*FolderInfo{}}
+	if !filter.HasFileOnlyFilters() {
+		folders, err = h.repository.SearchFolderInfos(ctx, query.FolderPagingOpt, query.OwnerID, filter)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.Search:SearchFolderInfos")
+		}
+	}
+
+	return &SearchRes{
+		FilePage:   files,
+		FolderPage: folders,
+	}, nil
+}
+
+func (h *QueryHandlers) GetTags(ctx context.Context, query *GetTagsQuery) (*paging.Page[*Tag], error) {
+	tags, err := h.repository.GetTags(ctx, query.PagingOpt, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetTags:GetTags")
+	}
+
+	return tags, nil
+}
+
+func (h *QueryHandlers) GetTaggedItems(ctx context.Context, query *GetTaggedItemsQuery) (*GetTaggedItemsRes, error) {
+	_, err := h.repository.GetTag(ctx, query.OwnerID, query.TagID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetTaggedItems:GetTag")
+	}
+
+	files, err := h.repository.GetFileInfosByTag(ctx, query.FilePagingOpt, query.OwnerID, query.TagID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetTaggedItems:GetFileInfosByTag")
+	}
+
+	folders, err := h.repository.GetFolderInfosByTag(ctx, query.FolderPagingOpt, query.OwnerID, query.TagID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetTaggedItems:GetFolderInfosByTag")
+	}
+
+	return &GetTaggedItemsRes{
+		FilePage:   files,
+		FolderPage: folders,
+	}, nil
+}
+
+func (h *QueryHandlers) GetFileTags(ctx context.Context, query *GetFileTagsQuery) ([]*Tag, error) {
+	info, err := h.repository.GetFileInfo(ctx, query.FileID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileTags:GetFileInfo")
+	}
+
+	err = info.ValidateAccess(query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileTags:ValidateAccess")
+	}
+
+	tags, err := h.repository.GetFileTags(ctx, info.ID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileTags:GetFileTags")
+	}
+	return tags, nil
+}
+
+func (h *QueryHandlers) GetFolderTags(ctx context.Context, query *GetFolderTagsQuery) ([]*Tag, error) {
+	info, err := h.repository.GetFolderInfo(ctx, query.OwnerID, query.FolderID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderTags:GetFolderInfo")
+	}
+
+	tags, err := h.repository.GetFolderTags(ctx, info.ID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderTags:GetFolderTags")
+	}
+	return tags, nil
+}
+
+func (h *QueryHandlers) GetStarredItems(ctx context.Context, query *GetStarredItemsQuery) (*GetStarredItemsRes, error) {
+	files, err := h.repository.GetStarredFileInfos(ctx, query.FilePagingOpt, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetStarredItems:GetStarredFileInfos")
+	}
+
+	folders, err := h.repository.GetStarredFolderInfos(ctx, query.FolderPagingOpt, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetStarredItems:GetStarredFolderInfos")
+	}
+
+	return &GetStarredItemsRes{
+		FilePage:   files,
+		FolderPage: folders,
+	}, nil
+}
+
+func (h *QueryHandlers) GetRecentFiles(ctx context.Context, query *GetRecentFilesQuery) (*paging.Page[*RecentItem], error) {
+	res, err := h.repository.GetRecentFiles(ctx, query.PagingOpt, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetRecentFiles:GetRecentFiles")
+	}
+	return res, nil
+}
+
+func (h *QueryHandlers) GetStorageUsage(ctx context.Context, query *GetStorageUsageQuery) (*GetStorageUsageRes, error) {
+	usage, err := h.repository.GetStorageUsage(ctx, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetStorageUsage:GetStorageUsage")
+	}
+
+	categories, err := h.repository.GetCategoryUsages(ctx, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetStorageUsage:GetCategoryUsages")
+	}
+
+	return &GetStorageUsageRes{
+		UsedBytes:    usage.UsedBytes,
+		TrashedBytes: usage.TrashedBytes,
+		QuotaBytes:   usage.Quota(h.app.Config.Media.DefaultQuotaMB),
+		Categories:   categories,
+	}, nil
+}
+
+func (h *QueryHandlers) GetChanges(ctx context.Context, query *GetChangesQuery) (*GetChangesRes, error) {
+	if query.Cursor == "" {
+		state, err := h.repository.GetChangeLogState(ctx, query.OwnerID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetChanges:GetChangeLogState")
+		}
+
+		return &GetChangesRes{Changes: []*Change{}, Cursor: encodeChangeCursor(state.LastSeq)}, nil
+	}
+
+	seq, err := decodeChangeCursor(query.Cursor)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetChanges:decodeChangeCursor").WithMetadata("cursor", query.Cursor)
+	}
+
+	// One more than the limit tells whether there are more
+	changes, err := h.repository.GetChanges(ctx, query.OwnerID, seq, query.Limit+1)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetChanges:GetChanges")
+	}
+
+	// The state is read after the changes, so a compaction in between is not missed
+	state, err := h.repository.GetChangeLogState(ctx, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetChanges:GetChangeLogState")
+	}
+
+	err = state.ValidateCursor(seq)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetChanges:ValidateCursor").WithMetadata("cursor", query.Cursor)
+	}
+
+	res := &GetChangesRes{Changes: changes, Cursor: query.Cursor}
+	if len(changes) > query.Limit {
+		res.Changes = changes[:query.Limit]
+		res.HasMore = true
+	}
+	if len(res.Changes) > 0 {
+		res.Cursor = encodeChangeCursor(res.Changes[len(res.Changes)-1].Seq)
+	}
+
+	return res, nil
+}
+
+func (h *QueryHandlers) GetFileProperties(ctx context.Context, query *GetFilePropertiesQuery) (map[string]string, error) {
+	info, err := h.repository.GetFileInfo(ctx, query.FileID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileProperties:GetFileInfo")
+	}
+
+	err = info.ValidateAccess(query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileProperties:ValidateAccess")
+	}
+
+	props, err := h.repository.GetFileProperties(ctx, info.ID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFileProperties:GetFileProperties")
+	}
+	return props, nil
+}
+
+func (h *QueryHandlers) GetFolderProperties(ctx context.Context, query *GetFolderPropertiesQuery) (map[string]string, error) {
+	info, err := h.repository.GetFolderInfo(ctx, query.OwnerID, query.FolderID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderProperties:GetFolderInfo")
+	}
+
+	props, err := h.repository.GetFolderProperties(ctx, info.ID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderProperties:GetFolderProperties")
+	}
+	return props, nil
+}
+
 func (h *QueryHandlers) GetFolderInfo(ctx context.Context, query *GetFolderInfoQuery) (*FolderInfo, error) {
 	info, err := h.repository.GetFolderInfo(ctx, query.OwnerID, query.FolderID)
 	if err != nil {
@@ -64,6 +484,114 @@ func (h *QueryHandlers) GetAncestors(ctx context.Context, ownerID, folderID stri
 	return ancestors, nil
 }
 
+func (h *QueryHandlers) ResolvePath(ctx context.Context, query *ResolvePathQuery) (*ResolvePathRes, error) {
+	names, err := splitPath(query.Path)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.ResolvePath:splitPath").WithMetadata("path", query.Path)
+	}
+
+	res := &ResolvePathRes{Ancestors: []*FolderInfo{}}
+	for i, name := range names {
+		var parentFolderID *string
+		if res.Folder != nil {
+			parentFolderID = &res.Folder.ID
+			res.Ancestors = append(res.Ancestors, res.Folder)
+		}
+
+		folder, err := h.repository.GetFolderInfoByName(ctx, query.OwnerID, parentFolderID, name)
+		if err == nil {
+			res.Folder = folder
+			continue
+		}
+		if !errors.Is(err, apperror.ErrCommonNoData) || i < len(names)-1 {
+			return nil, apperror.NewAppError(err, "QueryHandlers.ResolvePath:GetFolderInfoByName").WithMetadata("path", query.Path)
+		}
+
+		file, err := h.repository.GetFileInfoByName(ctx, query.OwnerID, parentFolderID, name)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.ResolvePath:GetFileInfoByName").WithMetadata("path", query.Path)
+		}
+		res.Folder = nil
+		res.File = file
+	}
+
+	return res, nil
+}
+
+func (h *QueryHandlers) GetFilePath(ctx context.Context, query *GetFilePathQuery) (string, error) {
+	info, err := h.repository.GetFileInfo(ctx, query.FileID)
+	if err != nil {
+		return "", apperror.NewAppError(err, "QueryHandlers.GetFilePath:GetFileInfo")
+	}
+
+	err = info.ValidateAccess(query.OwnerID)
+	if err != nil {
+		return "", apperror.NewAppError(err, "QueryHandlers.GetFilePath:ValidateAccess")
+	}
+
+	if info.FolderID == nil {
+		return joinPath(nil, info.Name), nil
+	}
+
+	folderPath, err := h.GetFolderPath(ctx, &GetFolderPathQuery{OwnerID: query.OwnerID, FolderID: *info.FolderID})
+	if err != nil {
+		return "", apperror.NewAppError(err, "QueryHandlers.GetFilePath:GetFolderPath")
+	}
+	return folderPath + "/" + info.Name, nil
+}
+
+func (h *QueryHandlers) GetFolderPath(ctx context.Context, query *GetFolderPathQuery) (string, error) {
+	info, err := h.repository.GetFolderInfo(ctx, query.OwnerID, query.FolderID)
+	if err != nil {
+		return "", apperror.NewAppError(err, "QueryHandlers.GetFolderPath:GetFolderInfo")
+	}
+
+	err = info.ValidateAccess(query.OwnerID)
+	if err != nil {
+		return "", apperror.NewAppError(err, "QueryHandlers.GetFolderPath:ValidateAccess")
+	}
+
+	ancestors, err := h.repository.GetAncestors(ctx, query.OwnerID, query.FolderID)
+	if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+		return "", apperror.NewAppError(err, "QueryHandlers.GetFolderPath:GetAncestors")
+	}
+
+	return joinPath(ancestors, info.Name), nil
+}
+
+func (h *QueryHandlers) GetFolderTree(ctx context.Context, query *GetFolderTreeQuery) (*GetFolderTreeRes, error) {
+	res := &GetFolderTreeRes{Ancestors: []*common.BaseInfo{}}
+	if query.FolderID != nil {
+		info, err := h.repository.GetFolderInfo(ctx, query.OwnerID, *query.FolderID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderTree:GetFolderInfo")
+		}
+
+		err = info.ValidateAccess(query.OwnerID)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderTree:ValidateAccess")
+		}
+
+		ancestors, err := h.repository.GetAncestors(ctx, query.OwnerID, *query.FolderID)
+		if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+			return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderTree:GetAncestors")
+		}
+		if ancestors != nil {
+			res.Ancestors = ancestors
+		}
+		res.Folder = info
+	}
+
+	// One more than the limit tells whether the tree is truncated
+	folders, err := h.repository.GetFolderTree(ctx, query.OwnerID, query.FolderID, query.Depth, maxFolderTreeNodes+1)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "QueryHandlers.GetFolderTree:GetFolderTree")
+	}
+
+	res.Nodes, res.Truncated = newFolderTree(query.FolderID, folders, query.Depth, maxFolderTreeNodes)
+	return res, nil
+}
+
 func (h *QueryHandlers) GetFolderContent(ctx context.Context, query *GetFolderContentQuery) (*GetFolderContentRes, error) {
 	files, err := h.repository.GetFileInfos(ctx, query.FilePagingOpt, query.OwnerID, query.FolderID)
 	if err != nil {
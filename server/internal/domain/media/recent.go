@@ -0,0 +1,33 @@
+package media
+
+import "time"
+
+type FileActivity string
+
+const (
+	FileActivityUploaded   FileActivity = "uploaded"
+	FileActivityModified   FileActivity = "modified" // Renamed, moved or its content replaced
+	FileActivityDownloaded FileActivity = "downloaded"
+)
+
+// RecentFile is the last activity of the owner on a file, only the last one is kept.
+type RecentFile struct {
+	OwnerID    string
+	FileID     string
+	Activity   FileActivity
+	ActivityAt time.Time
+}
+
+func NewRecentFile(info *FileInfo, activity FileActivity) *RecentFile {
+	return &RecentFile{
+		OwnerID:    info.OwnerID,
+		FileID:     info.ID,
+		Activity:   activity,
+		ActivityAt: time.Now().UTC(),
+	}
+}
+
+type RecentItem struct {
+	File       *FileInfo
+	RecentFile *RecentFile
+}
@@ -0,0 +1,43 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecentFile(t *testing.T) {
+	t.Parallel()
+	info := &FileInfo{ID: "file-id", OwnerID: "owner-id"}
+
+	recent := NewRecentFile(info, FileActivityDownloaded)
+	assert.Equal(t, "owner-id", recent.OwnerID)
+	assert.Equal(t, "file-id", recent.FileID)
+	assert.Equal(t, FileActivityDownloaded, recent.Activity)
+	assert.False(t, recent.ActivityAt.IsZero())
+}
+
+func TestValidateItemIDs(t *testing.T) {
+	t.Parallel()
+	id := "0195f1b0-8d5c-7c3a-9a45-4c1c5d9e2f10"
+	tests := []struct {
+		name      string
+		fileIDs   []string
+		folderIDs []string
+		wantErr   bool
+	}{
+		{"files only", []string{id}, nil, false},
+		{"folders only", nil, []string{id}, false},
+		{"no items", nil, nil, true},
+		{"invalid file", []string{"nope"}, []string{id}, true},
+		{"invalid folder", []string{id}, []string{"nope"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateItemIDs(tt.fileIDs, tt.folderIDs)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"skyvault/internal/domain/internal"
 	"skyvault/pkg/common"
+	"skyvault/pkg/eventbus"
 	"skyvault/pkg/paging"
+	"time"
 )
 
 type Repository interface {
@@ -30,14 +32,54 @@ type Repository interface {
 
 	GetFileInfosByCategory(ctx context.Context, pagingOpt *paging.Options, ownerID string, category Category) (*paging.Page[*FileInfo], error)
 
+	// GetFileInfoByName returns the non-trashed file with the name in the folder, nil is the root folder.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	GetFileInfoByName(ctx context.Context, ownerID string, folderID *string, name string) (*FileInfo, error)
+
 	// App Errors:
 	// - ErrCommonNoData
 	UpdateFileInfo(ctx context.Context, info *FileInfo) error
 
+	// ReplaceFileInfoContent saves the content fields of the non-trashed file, see FileInfo.ReplaceContent,
+	// and deletes the metadata and the indexed text of its previous content.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	ReplaceFileInfoContent(ctx context.Context, info *FileInfo) error
+
+	// UpdateFileInfoPreviewStatus only updates the preview status,
+	// so that background jobs don't overwrite concurrent changes of the file.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	UpdateFileInfoPreviewStatus(ctx context.Context, fileID string, status PreviewStatus) error
+
+	// GetFileInfosPendingPreview returns the non-trashed files whose previews are not generated yet,
+	// ordered by ID and starting after afterID.
+	GetFileInfosPendingPreview(ctx context.Context, afterID string, limit int) ([]*FileInfo, error)
+
+	// UpdateFileInfoScanStatus only updates the scan status and signature, see UpdateFileInfoPreviewStatus.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	UpdateFileInfoScanStatus(ctx context.Context, fileID string, status ScanStatus, signature *string) error
+
+	// GetFileInfosPendingScan returns the non-trashed files not scanned yet or whose scan failed,
+	// ordered by ID and starting after afterID.
+	GetFileInfosPendingScan(ctx context.Context, afterID string, limit int) ([]*FileInfo, error)
+
+	// GetQuarantinedFileInfos returns the non-trashed infected files of the owner.
+	GetQuarantinedFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*FileInfo], error)
+
 	// App Errors:
 	// - ErrCommonNoData
 	DeleteFileInfo(ctx context.Context, fileID string) error
 
+	// GetFileInfosTrashedBefore returns the files of all the owners trashed before the time, ordered by ID.
+	GetFileInfosTrashedBefore(ctx context.Context, before time.Time, limit int) ([]*FileInfo, error)
+
 	// TODO: Once, sharing/permissions feature is implemented,
 	// replace the ownerID param with deletableBy to check appropriate permissions.
 	// Permissions: CanDelete, CanEditFile, CanUploadToFolder
@@ -46,6 +88,175 @@ type Repository interface {
 	// - ErrCommonNoData
 	TrashFileInfos(ctx context.Context, ownerID string, fileIDs []string) error
 
+	//--------------------------------
+	// Metadata
+	//--------------------------------
+
+	// UpsertFileMetadata replaces the metadata of the file, if already extracted.
+	UpsertFileMetadata(ctx context.Context, meta *FileMetadata) error
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetFileMetadata(ctx context.Context, fileID string) (*FileMetadata, error)
+
+	// GetFileInfosWithoutMetadata returns the non-trashed image and audio files whose metadata is not extracted yet,
+	// ordered by ID and starting after afterID.
+	GetFileInfosWithoutMetadata(ctx context.Context, afterID string, limit int) ([]*FileInfo, error)
+
+	// GetTimeline returns the non-trashed images of the owner with their metadata, sorted by FileMetadata.TimelineAt.
+	// The paging options are always sorted by "updated", which stands for the timeline time.
+	GetTimeline(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*TimelineItem], error)
+
+	//--------------------------------
+	// Search
+	//--------------------------------
+
+	// UpsertFileText replaces the indexed text of the file, if already indexed.
+	UpsertFileText(ctx context.Context, text *FileText) error
+
+	// GetFileInfosWithoutText returns the non-trashed text and other files whose text is not indexed yet,
+	// ordered by ID and starting after afterID.
+	GetFileInfosWithoutText(ctx context.Context, afterID string, limit int) ([]*FileInfo, error)
+
+	// SearchFileInfos returns the non-trashed files of the owner whose name contains the term,
+	// or whose indexed text matches it.
+	SearchFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string, filter *SearchFilter) (*paging.Page[*FileInfo], error)
+
+	// SearchFolderInfos returns the non-trashed folders of the owner whose name contains the term.
+	// The file only fields of the filter are ignored.
+	SearchFolderInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string, filter *SearchFilter) (*paging.Page[*FolderInfo], error)
+
+	//--------------------------------
+	// Tags
+	//--------------------------------
+
+	// App Errors:
+	// - ErrCommonDuplicateData
+	CreateTag(ctx context.Context, tag *Tag) (*Tag, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetTag(ctx context.Context, ownerID, tagID string) (*Tag, error)
+
+	GetTags(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*Tag], error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonDuplicateData
+	UpdateTag(ctx context.Context, tag *Tag) error
+
+	// DeleteTag also removes the tag from all the items.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	DeleteTag(ctx context.Context, ownerID, tagID string) error
+
+	// TagFileInfos adds the tag to the files of the owner, trashed or not.
+	// The files not owned by the owner and those already tagged are skipped.
+	TagFileInfos(ctx context.Context, ownerID, tagID string, fileIDs []string) error
+
+	// TagFolderInfos is the same as TagFileInfos for folders.
+	TagFolderInfos(ctx context.Context, ownerID, tagID string, folderIDs []string) error
+
+	// UntagFileInfos removes the tag from the files, the files not tagged are skipped.
+	UntagFileInfos(ctx context.Context, ownerID, tagID string, fileIDs []string) error
+
+	// UntagFolderInfos is the same as UntagFileInfos for folders.
+	UntagFolderInfos(ctx context.Context, ownerID, tagID string, folderIDs []string) error
+
+	// GetFileInfosByTag returns the non-trashed files of the owner with the tag.
+	GetFileInfosByTag(ctx context.Context, pagingOpt *paging.Options, ownerID, tagID string) (*paging.Page[*FileInfo], error)
+
+	// GetFolderInfosByTag returns the non-trashed folders of the owner with the tag.
+	GetFolderInfosByTag(ctx context.Context, pagingOpt *paging.Options, ownerID, tagID string) (*paging.Page[*FolderInfo], error)
+
+	// GetFileTags returns the tags of the file, sorted by name.
+	GetFileTags(ctx context.Context, fileID string) ([]*Tag, error)
+
+	// GetFolderTags returns the tags of the folder, sorted by name.
+	GetFolderTags(ctx context.Context, folderID string) ([]*Tag, error)
+
+	//--------------------------------
+	// Properties
+	//--------------------------------
+
+	GetFileProperties(ctx context.Context, fileID string) (map[string]string, error)
+
+	GetFolderProperties(ctx context.Context, folderID string) (map[string]string, error)
+
+	// ReplaceFileProperties deletes the properties of the file and saves the new ones.
+	// It should be run in a transaction.
+	ReplaceFileProperties(ctx context.Context, fileID string, props map[string]string) error
+
+	// ReplaceFolderProperties is the same as ReplaceFileProperties for folders.
+	ReplaceFolderProperties(ctx context.Context, folderID string, props map[string]string) error
+
+	//--------------------------------
+	// Starred & Recent
+	//--------------------------------
+
+	// StarFileInfos stars the files of the owner, trashed or not.
+	// The files not owned by the owner and those already starred are skipped.
+	StarFileInfos(ctx context.Context, ownerID string, fileIDs []string) error
+
+	// StarFolderInfos is the same as StarFileInfos for folders.
+	StarFolderInfos(ctx context.Context, ownerID string, folderIDs []string) error
+
+	// UnstarFileInfos unstars the files, the files not starred are skipped.
+	UnstarFileInfos(ctx context.Context, ownerID string, fileIDs []string) error
+
+	// UnstarFolderInfos is the same as UnstarFileInfos for folders.
+	UnstarFolderInfos(ctx context.Context, ownerID string, folderIDs []string) error
+
+	// GetStarredFileInfos returns the non-trashed starred files of the owner.
+	GetStarredFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*FileInfo], error)
+
+	// GetStarredFolderInfos returns the non-trashed starred folders of the owner.
+	GetStarredFolderInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*FolderInfo], error)
+
+	// UpsertRecentFile replaces the last activity on the file.
+	UpsertRecentFile(ctx context.Context, recent *RecentFile) error
+
+	// GetRecentFiles returns the non-trashed files of the owner with their last activity, sorted by RecentFile.ActivityAt.
+	// The paging options are always sorted by "updated", which stands for the activity time.
+	GetRecentFiles(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*RecentItem], error)
+
+	//--------------------------------
+	// Storage Usage
+	//--------------------------------
+
+	// GetStorageUsage returns the storage used by the owner, an owner without files has an empty usage.
+	GetStorageUsage(ctx context.Context, ownerID string) (*StorageUsage, error)
+
+	// GetCategoryUsages returns the storage used by the non-trashed files of the owner per category.
+	GetCategoryUsages(ctx context.Context, ownerID string) ([]*CategoryUsage, error)
+
+	// LockStorageUsage returns the storage used by the owner, locked until the end of the transaction so that
+	// the concurrent writes of the owner check the quota one after another. Only for a repository in a transaction.
+	LockStorageUsage(ctx context.Context, ownerID string) (*StorageUsage, error)
+
+	// SetStorageQuota overrides the default quota of the owner, nil resets it to the default.
+	//
+	// App Errors:
+	// - ErrCommonNoData (the owner doesn't exist)
+	SetStorageQuota(ctx context.Context, ownerID string, quotaBytes *int64) error
+
+	//--------------------------------
+	// Changes
+	//--------------------------------
+
+	// The changes are recorded by the repository itself, in the transaction of every write of the files and folders.
+
+	// GetChangeLogState returns the sequence of the owner's changes, an owner without changes has an empty state.
+	GetChangeLogState(ctx context.Context, ownerID string) (*ChangeLogState, error)
+
+	// GetChanges returns the changes of the owner after the sequence, oldest first, at most limit.
+	GetChanges(ctx context.Context, ownerID string, afterSeq int64, limit int) ([]*Change, error)
+
+	// CompactChangeLog purges the deletes older than before, of all the owners.
+	// Returns the number of purged changes.
+	CompactChangeLog(ctx context.Context, before time.Time) (int64, error)
+
 	//--------------------------------
 	// Folders
 	//--------------------------------
@@ -64,6 +275,12 @@ type Repository interface {
 
 	GetFolderInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string, parentFolderID *string) (*paging.Page[*FolderInfo], error)
 
+	// GetFolderInfoByName returns the non-trashed folder with the name in the parent folder, nil is the root folder.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	GetFolderInfoByName(ctx context.Context, ownerID string, parentFolderID *string, name string) (*FolderInfo, error)
+
 	// App Errors:
 	// - ErrCommonNoData
 	UpdateFolderInfo(ctx context.Context, folder *FolderInfo) error
@@ -72,6 +289,11 @@ type Repository interface {
 	// - ErrCommonNoData
 	DeleteFolderInfo(ctx context.Context, folderID string) error
 
+	// DeleteFolderInfosTrashedBefore deletes the folders of all the owners trashed before the time, with their
+	// sub-folders. A folder still holding files is kept, their deletes would skip the deletes of the stored files.
+	// Returns the number of deleted folders.
+	DeleteFolderInfosTrashedBefore(ctx context.Context, before time.Time) (int64, error)
+
 	// Recursively trash all files and sub-folders.
 	//
 	// App Errors:
@@ -90,9 +312,47 @@ type Repository interface {
 	// - ErrCommonNoData
 	GetDescendantFolderIDs(ctx context.Context, ownerID string, folderID string) ([]string, error)
 
+	// GetSubtreeFolderInfos returns the given folders and all their descendants, trashed folders excluded.
+	GetSubtreeFolderInfos(ctx context.Context, ownerID string, folderIDs []string) ([]*FolderInfo, error)
+
+	// GetSubtreeFileInfos returns the files of the given folders and of all their descendants, trashed files excluded.
+	GetSubtreeFileInfos(ctx context.Context, ownerID string, folderIDs []string) ([]*FileInfo, error)
+
+	// GetFolderTree returns the non-trashed descendant folders of the folder, nil is the root folder,
+	// down to the depth (1 for the children only), with their stats. The folders are ordered by depth and name,
+	// so the parents come before their children, and at most limit are returned.
+	GetFolderTree(ctx context.Context, ownerID string, folderID *string, depth int, limit int) ([]*FolderInfo, error)
+
 	// GetAncestors returns the ancestor folders (basic info) of the given folder ID, excluding the folder itself.
+	// The parent folder comes first.
 	//
 	// App Errors:
 	// - ErrCommonNoData
 	GetAncestors(ctx context.Context, ownerID string, folderID string) ([]*common.BaseInfo, error)
+
+	//--------------------------------
+	// Archives
+	//--------------------------------
+
+	CreateArchiveExtraction(ctx context.Context, extraction *ArchiveExtraction) (*ArchiveExtraction, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetArchiveExtraction(ctx context.Context, ownerID, extractionID string) (*ArchiveExtraction, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	UpdateArchiveExtraction(ctx context.Context, extraction *ArchiveExtraction) error
+
+	// GetArchiveExtractionsByStatus returns the extractions of all owners with the status,
+	// ordered by ID and starting after afterID.
+	GetArchiveExtractionsByStatus(ctx context.Context, status ExtractionStatus, afterID string, limit int) ([]*ArchiveExtraction, error)
+
+	//--------------------------------
+	// Domain events
+	//--------------------------------
+
+	// AddDomainEvents writes the events to the outbox. With the repository of a transaction,
+	// they are published only once it commits.
+	AddDomainEvents(ctx context.Context, events ...eventbus.Event) error
 }
@@ -0,0 +1,62 @@
+package media
+
+import (
+	"context"
+	"io"
+	"skyvault/pkg/apperror"
+)
+
+type Scanner interface {
+	// Scan scans the content of a file for malware.
+	// An error means the file could not be scanned, not that it is infected.
+	Scan(ctx context.Context, file io.Reader) (*ScanResult, error)
+}
+
+type ScanResult struct {
+	Infected  bool
+	Signature string // Name of the detected malware, empty if not infected
+}
+
+type ScanStatus string
+
+const (
+	ScanStatusPending  ScanStatus = "pending"
+	ScanStatusClean    ScanStatus = "clean"
+	ScanStatusInfected ScanStatus = "infected"
+	ScanStatusFailed   ScanStatus = "failed" // Retried by BackfillScans
+)
+
+// SetScanResult sets the scan status from the outcome of the scan.
+func (f *FileInfo) SetScanResult(res *ScanResult, scanErr error) {
+	switch {
+	case scanErr != nil:
+		f.ScanStatus = ScanStatusFailed
+		f.ScanSignature = nil
+	case res.Infected:
+		f.ScanStatus = ScanStatusInfected
+		f.ScanSignature = &res.Signature
+	default:
+		f.ScanStatus = ScanStatusClean
+		f.ScanSignature = nil
+	}
+}
+
+// IsQuarantined tells if the file is infected.
+// Files waiting for a scan, or whose scan failed, are not quarantined and stay downloadable and shareable:
+// the scan is asynchronous and the scanner is optional, blocking them would break reading a file right after
+// writing it (e.g. WebDAV clients) and every download while the scanner is down.
+func (f *FileInfo) IsQuarantined() bool {
+	return f.ScanStatus == ScanStatusInfected
+}
+
+// ValidateNotQuarantined must be checked before the content of the file leaves the server,
+// e.g. on download or sharing.
+//
+// App Errors:
+// - ErrMediaFileInfected
+func (f *FileInfo) ValidateNotQuarantined() error {
+	if f.IsQuarantined() {
+		return apperror.NewAppError(apperror.ErrMediaFileInfected, "media.FileInfo.ValidateNotQuarantined").WithMetadata("file_id", f.ID)
+	}
+	return nil
+}
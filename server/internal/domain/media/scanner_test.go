@@ -0,0 +1,47 @@
+package media
+
+import (
+	"errors"
+	"testing"
+
+	"skyvault/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileInfo_SetScanResult(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		res           *ScanResult
+		scanErr       error
+		wantStatus    ScanStatus
+		wantSignature *string
+	}{
+		{"clean", &ScanResult{}, nil, ScanStatusClean, nil},
+		{"infected", &ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}, nil, ScanStatusInfected, optional("Eicar-Test-Signature")},
+		{"failed", nil, errors.New("clamd down"), ScanStatusFailed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			info := &FileInfo{ScanStatus: ScanStatusPending, ScanSignature: optional("previous")}
+			info.SetScanResult(tt.res, tt.scanErr)
+			assert.Equal(t, tt.wantStatus, info.ScanStatus)
+			assert.Equal(t, tt.wantSignature, info.ScanSignature)
+		})
+	}
+}
+
+func TestFileInfo_ValidateNotQuarantined(t *testing.T) {
+	t.Parallel()
+	// Files not scanned yet are served, see FileInfo.IsQuarantined
+	for _, status := range []ScanStatus{"", ScanStatusPending, ScanStatusClean, ScanStatusFailed} {
+		require.NoError(t, (&FileInfo{ScanStatus: status}).ValidateNotQuarantined(), status)
+	}
+
+	err := (&FileInfo{ScanStatus: ScanStatusInfected}).ValidateNotQuarantined()
+	require.ErrorIs(t, err, apperror.ErrMediaFileInfected)
+}
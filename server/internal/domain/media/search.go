@@ -0,0 +1,68 @@
+package media
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// Max. number of bytes of a file indexed for the full-text search
+const maxSearchableTextBytes = 256 * 1024
+
+// SearchFilter selects the files and folders of a search. Empty fields match everything.
+type SearchFilter struct {
+	Term string // Part of the name, or words of the content of text files
+
+	// Files only, folders are not returned when set
+	Category  Category
+	Extension string // Lowercase, e.g. ".pdf"
+	MinSize   *int64 // bytes
+	MaxSize   *int64 // bytes
+
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	// FolderIDs restricts the search to the content of these folders, nil searches the whole vault
+	FolderIDs []string
+}
+
+// HasFileOnlyFilters tells if the filter can only match files.
+func (f *SearchFilter) HasFileOnlyFilters() bool {
+	return f.Category != "" || f.Extension != "" || f.MinSize != nil || f.MaxSize != nil
+}
+
+// FileText is the text content of a file, indexed for the full-text search.
+type FileText struct {
+	FileID    string
+	OwnerID   string
+	Text      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// HasSearchableText tells if the content of the file should be indexed for the full-text search.
+func (f *FileInfo) HasSearchableText() bool {
+	kind := f.PreviewKind()
+	return kind == PreviewKindText || kind == PreviewKindMarkdown
+}
+
+// NewFileText reads the beginning of the file, see maxSearchableTextBytes.
+func NewFileText(info *FileInfo, file io.Reader) (*FileText, error) {
+	data, err := io.ReadAll(io.LimitReader(file, maxSearchableTextBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// The limit may cut a multi-byte character, and postgres rejects invalid utf-8 and null bytes
+	text := strings.ToValidUTF8(string(data), "")
+	text = strings.ReplaceAll(text, "\x00", "")
+
+	now := time.Now().UTC()
+	return &FileText{
+		FileID:    info.ID,
+		OwnerID:   info.OwnerID,
+		Text:      text,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package media
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchFilter_HasFileOnlyFilters(t *testing.T) {
+	t.Parallel()
+	size := int64(10)
+	tests := []struct {
+		name   string
+		filter SearchFilter
+		want   bool
+	}{
+		{"empty", SearchFilter{}, false},
+		{"term and folders", SearchFilter{Term: "report", FolderIDs: []string{"id"}}, false},
+		{"category", SearchFilter{Category: CategoryImage}, true},
+		{"extension", SearchFilter{Extension: ".pdf"}, true},
+		{"min size", SearchFilter{MinSize: &size}, true},
+		{"max size", SearchFilter{MaxSize: &size}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.filter.HasFileOnlyFilters())
+		})
+	}
+}
+
+func TestFileInfo_HasSearchableText(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		fileName string
+		mimeType string
+		want     bool
+	}{
+		{"plain text", "notes.txt", "text/plain; charset=utf-8", true},
+		{"markdown", "README.md", "text/markdown", true},
+		{"json", "data.json", "application/json", true},
+		{"pdf", "doc.pdf", "application/pdf", false},
+		{"image", "photo.jpg", "image/jpeg", false},
+		{"binary", "app.exe", "application/octet-stream", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			info := &FileInfo{Name: tt.fileName, MimeType: tt.mimeType, Category: getCategory(tt.mimeType)}
+			assert.Equal(t, tt.want, info.HasSearchableText())
+		})
+	}
+}
+
+func TestNewFileText(t *testing.T) {
+	t.Parallel()
+	info := &FileInfo{ID: "file-id", OwnerID: "owner-id"}
+
+	t.Run("keeps the text", func(t *testing.T) {
+		t.Parallel()
+		text, err := NewFileText(info, strings.NewReader("annual report 2026"))
+		require.NoError(t, err)
+		assert.Equal(t, "file-id", text.FileID)
+		assert.Equal(t, "owner-id", text.OwnerID)
+		assert.Equal(t, "annual report 2026", text.Text)
+		assert.False(t, text.CreatedAt.IsZero())
+	})
+
+	t.Run("removes invalid utf-8 and null bytes", func(t *testing.T) {
+		t.Parallel()
+		text, err := NewFileText(info, strings.NewReader("a\x00b\xffc"))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", text.Text)
+	})
+
+	t.Run("limits the size", func(t *testing.T) {
+		t.Parallel()
+		// The multi-byte character is cut by the limit
+		content := strings.Repeat("a", maxSearchableTextBytes-1) + "é"
+		text, err := NewFileText(info, strings.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("a", maxSearchableTextBytes-1), text.Text)
+	})
+}
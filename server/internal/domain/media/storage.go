@@ -23,6 +23,13 @@ type Storage interface {
 	// - ErrCommonInvalidValue
 	FinalizeChunkedUpload(ctx context.Context, uploadID string, fileName string, ownerID string) error
 
+	// OpenChunk opens a chunk of a chunked upload which is not finalized yet.
+	// The chunk must be closed after use by the caller.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	OpenChunk(ctx context.Context, uploadID string, chunkIndex int64, ownerID string) (io.ReadSeekCloser, error)
+
 	// CleanupChunks removes temporary chunk files
 	CleanupChunks(ctx context.Context, uploadID string, ownerID string) error
 
@@ -35,4 +42,23 @@ type Storage interface {
 	// App Errors:
 	// - ErrCommonNoData
 	DeleteFile(ctx context.Context, name string, ownerID string) error
+
+	// ReplaceFile moves the file fromName over the file name in one step,
+	// the readers see either the previous or the new content.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	ReplaceFile(ctx context.Context, fromName string, name string, ownerID string) error
+
+	// SavePreview overwrites the existing preview of the same size, if any.
+	SavePreview(ctx context.Context, preview io.Reader, fileID string, size PreviewSize, ownerID string) error
+
+	// The preview must be closed after use by the caller.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	OpenPreview(ctx context.Context, fileID string, size PreviewSize, ownerID string) (io.ReadSeekCloser, error)
+
+	// DeletePreviews deletes the previews of all sizes. Missing previews are ignored.
+	DeletePreviews(ctx context.Context, fileID string, ownerID string) error
 }
@@ -0,0 +1,55 @@
+package media
+
+import (
+	"fmt"
+	"math"
+	"skyvault/pkg/apperror"
+	"time"
+)
+
+const (
+	bytesPerMB = 1024 * 1024
+	// MaxQuotaMB is the largest quota whose size in bytes fits in an int64
+	MaxQuotaMB = math.MaxInt64 / bytesPerMB
+
+	// The trash is purged after this many days if the config doesn't set it
+	defaultTrashRetentionDays = 30
+)
+
+// StorageUsage is the storage used by the owner, kept up to date by the database on every file change.
+// Trashed files count towards the quota until the trash retention purges them, so trashing doesn't free space.
+type StorageUsage struct {
+	OwnerID      string
+	UsedBytes    int64
+	TrashedBytes int64
+	QuotaBytes   *int64 // Overrides the default quota of the config, nil uses the default
+	UpdatedAt    time.Time
+}
+
+// CategoryUsage is the storage used by the non-trashed files of a category.
+type CategoryUsage struct {
+	Category Category
+	Bytes    int64
+	Files    int64
+}
+
+// Quota returns the quota of the owner in bytes, 0 is unlimited.
+func (u *StorageUsage) Quota(defaultQuotaMB int64) int64 {
+	if u.QuotaBytes != nil {
+		return *u.QuotaBytes
+	}
+	return defaultQuotaMB * bytesPerMB
+}
+
+// ValidateWrite checks that size more bytes fit in the quota of the owner.
+//
+// App Errors:
+// - ErrMediaQuotaExceeded
+func (u *StorageUsage) ValidateWrite(defaultQuotaMB int64, size int64) error {
+	quota := u.Quota(defaultQuotaMB)
+	used := u.UsedBytes + u.TrashedBytes
+	if quota > 0 && used+size > quota {
+		return fmt.Errorf("%w: %d bytes used of %d, %d more requested", apperror.ErrMediaQuotaExceeded, used, quota, size)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package media
+
+import (
+	"testing"
+
+	"skyvault/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageUsage_Quota(t *testing.T) {
+	t.Parallel()
+	override := int64(5 * bytesPerMB)
+	unlimited := int64(0)
+
+	assert.Equal(t, int64(10*bytesPerMB), (&StorageUsage{}).Quota(10))
+	assert.Equal(t, int64(0), (&StorageUsage{}).Quota(0))
+	assert.Equal(t, override, (&StorageUsage{QuotaBytes: &override}).Quota(10))
+	assert.Equal(t, int64(0), (&StorageUsage{QuotaBytes: &unlimited}).Quota(10))
+}
+
+func TestStorageUsage_ValidateWrite(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		used           int64
+		trashed        int64
+		defaultQuotaMB int64
+		size           int64
+		wantErr        bool
+	}{
+		{"unlimited", 100 * bytesPerMB, 0, 0, bytesPerMB, false},
+		{"fits", 9 * bytesPerMB, 0, 10, bytesPerMB, false},
+		{"exceeds", 9 * bytesPerMB, 0, 10, bytesPerMB + 1, true},
+		{"trash is counted", 5 * bytesPerMB, 5 * bytesPerMB, 10, 1, true},
+		{"already over quota", 11 * bytesPerMB, 0, 10, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			usage := &StorageUsage{UsedBytes: tt.used, TrashedBytes: tt.trashed}
+			err := usage.ValidateWrite(tt.defaultQuotaMB, tt.size)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, apperror.ErrMediaQuotaExceeded)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
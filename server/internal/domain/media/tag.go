@@ -0,0 +1,123 @@
+package media
+
+import (
+	"fmt"
+	"regexp"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/utils"
+	"skyvault/pkg/validate"
+	"strings"
+	"time"
+)
+
+// Color of the tags created without one
+const DefaultTagColor = "#9e9e9e"
+
+// Limits of the custom properties of a single file or folder
+const (
+	maxPropertiesPerItem = 50
+	maxPropertyKeyLen    = 64
+	maxPropertyValueLen  = 1024
+)
+
+var tagColorRegex = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type Tag struct {
+	ID        string
+	OwnerID   string
+	Name      string
+	Color     string // e.g. "#ff9800"
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// App Errors:
+// - ErrCommonInvalidValue
+func NewTag(ownerID string, name string, color string) (*Tag, error) {
+	color, err := validateTagColor(color)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.NewTag:Color")
+	}
+
+	id, err := utils.ID()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "media.NewTag:ID")
+	}
+
+	now := time.Now().UTC()
+	return &Tag{
+		ID:        id,
+		OwnerID:   ownerID,
+		Name:      name,
+		Color:     color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// App Errors:
+// - ErrCommonNoAccess
+func (t *Tag) ValidateAccess(ownerID string) error {
+	if t.OwnerID != ownerID {
+		return apperror.NewAppError(apperror.ErrCommonNoAccess, "media.Tag.ValidateAccess").WithMetadata("owner_id", ownerID).WithMetadata("tag_owner_id", t.OwnerID)
+	}
+	return nil
+}
+
+// Update changes the name and color of the tag. The items keep the tag.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+func (t *Tag) Update(name string, color string) error {
+	color, err := validateTagColor(color)
+	if err != nil {
+		return apperror.NewAppError(err, "media.Tag.Update:Color")
+	}
+
+	t.Name = name
+	t.Color = color
+	t.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// validateTagColor accepts hex colors like "#FF9800", an empty color is the default one.
+func validateTagColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" {
+		return DefaultTagColor, nil
+	}
+
+	if !tagColorRegex.MatchString(color) {
+		return "", fmt.Errorf("%w: invalid color %q", apperror.ErrCommonInvalidValue, color)
+	}
+	return color, nil
+}
+
+// ValidateProperties trims the keys and checks the limits of the custom properties of an item.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+func ValidateProperties(props map[string]string) (map[string]string, error) {
+	if len(props) > maxPropertiesPerItem {
+		return nil, fmt.Errorf("%w: more than %d properties", apperror.ErrCommonInvalidValue, maxPropertiesPerItem)
+	}
+
+	res := make(map[string]string, len(props))
+	for key, value := range props {
+		k, err := validate.Name(key)
+		if err != nil || len(k) > maxPropertyKeyLen {
+			return nil, fmt.Errorf("%w: invalid property key %q", apperror.ErrCommonInvalidValue, key)
+		}
+
+		if len(value) > maxPropertyValueLen {
+			return nil, fmt.Errorf("%w: value of property %q is too long", apperror.ErrCommonInvalidValue, k)
+		}
+
+		if _, ok := res[k]; ok {
+			return nil, fmt.Errorf("%w: duplicate property key %q", apperror.ErrCommonInvalidValue, k)
+		}
+		res[k] = value
+	}
+
+	return res, nil
+}
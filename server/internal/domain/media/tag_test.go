@@ -0,0 +1,106 @@
+package media
+
+import (
+	"strings"
+	"testing"
+
+	"skyvault/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTag(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		color     string
+		wantColor string
+		wantErr   bool
+	}{
+		{"default color", "", DefaultTagColor, false},
+		{"lowercased", " #FF9800 ", "#ff9800", false},
+		{"short hex", "#f90", "", true},
+		{"named color", "orange", "", true},
+		{"missing hash", "ff9800", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tag, err := NewTag("owner-id", "Work", tt.color)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, tag.ID)
+			assert.Equal(t, "owner-id", tag.OwnerID)
+			assert.Equal(t, "Work", tag.Name)
+			assert.Equal(t, tt.wantColor, tag.Color)
+		})
+	}
+}
+
+func TestTag_Update(t *testing.T) {
+	t.Parallel()
+	tag, err := NewTag("owner-id", "Work", "#ff9800")
+	require.NoError(t, err)
+	createdAt := tag.CreatedAt
+
+	err = tag.Update("Personal", "nope")
+	assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+	assert.Equal(t, "Work", tag.Name)
+
+	require.NoError(t, tag.Update("Personal", "#2196F3"))
+	assert.Equal(t, "Personal", tag.Name)
+	assert.Equal(t, "#2196f3", tag.Color)
+	assert.Equal(t, createdAt, tag.CreatedAt)
+	assert.False(t, tag.UpdatedAt.Before(createdAt))
+}
+
+func TestTag_ValidateAccess(t *testing.T) {
+	t.Parallel()
+	tag := &Tag{OwnerID: "owner-id"}
+	assert.NoError(t, tag.ValidateAccess("owner-id"))
+	assert.ErrorIs(t, tag.ValidateAccess("other-id"), apperror.ErrCommonNoAccess)
+}
+
+func TestValidateProperties(t *testing.T) {
+	t.Parallel()
+
+	t.Run("trims the keys", func(t *testing.T) {
+		t.Parallel()
+		props, err := ValidateProperties(map[string]string{" project ": "skyvault", "empty": ""})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"project": "skyvault", "empty": ""}, props)
+	})
+
+	t.Run("nil is no properties", func(t *testing.T) {
+		t.Parallel()
+		props, err := ValidateProperties(nil)
+		require.NoError(t, err)
+		assert.Empty(t, props)
+	})
+
+	tooMany := map[string]string{}
+	for i := range maxPropertiesPerItem + 1 {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	invalid := map[string]map[string]string{
+		"empty key":       {" ": "v"},
+		"long key":        {strings.Repeat("k", maxPropertyKeyLen+1): "v"},
+		"long value":      {"k": strings.Repeat("v", maxPropertyValueLen+1)},
+		"duplicate key":   {"k": "1", " k": "2"},
+		"too many values": tooMany,
+	}
+	for name, props := range invalid {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ValidateProperties(props)
+			assert.ErrorIs(t, err, apperror.ErrCommonInvalidValue)
+		})
+	}
+}
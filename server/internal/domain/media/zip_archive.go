@@ -0,0 +1,232 @@
+package media
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"io/fs"
+	"skyvault/pkg/apperror"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Name of the archive when the download is not a single folder
+const defaultZipName = "download.zip"
+
+// ZipEntry is a file or a folder of a ZipArchive, folders are kept so that the empty ones are not lost.
+type ZipEntry struct {
+	Path     string    // Slash separated, relative to the archive root, folders end with a slash
+	File     *FileInfo // nil for folders
+	Modified time.Time
+}
+
+// ZipArchive is a download of several files and folders.
+// It is written on the fly from the storage, nothing is buffered besides the current chunk.
+type ZipArchive struct {
+	Name    string
+	Entries []*ZipEntry
+
+	ownerID string
+	storage Storage
+}
+
+// newZipArchive lays out the selected files at the root of the archive and the selected folders with
+// their whole subtree. subtreeFolders must contain the selected folders, the quarantined files are left out.
+func newZipArchive(ownerID string, storage Storage, files []*FileInfo, folderIDs []string, subtreeFolders []*FolderInfo, subtreeFiles []*FileInfo) *ZipArchive {
+	z := &ZipArchive{
+		Name:    defaultZipName,
+		Entries: []*ZipEntry{},
+		ownerID: ownerID,
+		storage: storage,
+	}
+
+	names := zipNames{}
+
+	foldersByID := make(map[string]*FolderInfo, len(subtreeFolders))
+	for _, folder := range subtreeFolders {
+		foldersByID[folder.ID] = folder
+	}
+
+	folderPaths := make(map[string]string, len(subtreeFolders))
+	var folderPath func(folder *FolderInfo) string
+	folderPath = func(folder *FolderInfo) string {
+		if p, ok := folderPaths[folder.ID]; ok {
+			return p
+		}
+
+		// The selected folders are at the root, even if one is inside another
+		dir := ""
+		if folder.ParentFolderID != nil && !slices.Contains(folderIDs, folder.ID) {
+			if parent, ok := foldersByID[*folder.ParentFolderID]; ok {
+				dir = folderPath(parent)
+			}
+		}
+
+		p := names.unique(dir, folder.Name) + "/"
+		folderPaths[folder.ID] = p
+		z.Entries = append(z.Entries, &ZipEntry{Path: p, Modified: folder.UpdatedAt})
+		return p
+	}
+
+	for _, folder := range subtreeFolders {
+		folderPath(folder)
+	}
+
+	for _, file := range files {
+		z.addFile("", file, names)
+	}
+
+	for _, file := range subtreeFiles {
+		if file.FolderID == nil || file.IsQuarantined() {
+			continue
+		}
+
+		folder, ok := foldersByID[*file.FolderID]
+		if !ok {
+			continue
+		}
+		z.addFile(folderPath(folder), file, names)
+	}
+
+	// The folders come before their content
+	slices.SortFunc(z.Entries, func(a, b *ZipEntry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if len(files) == 0 && len(folderIDs) == 1 {
+		if folder, ok := foldersByID[folderIDs[0]]; ok {
+			z.Name = zipEntryName(folder.Name) + ".zip"
+		}
+	}
+
+	return z
+}
+
+func (z *ZipArchive) addFile(dir string, file *FileInfo, names zipNames) {
+	z.Entries = append(z.Entries, &ZipEntry{
+		Path:     names.unique(dir, file.Name),
+		File:     file,
+		Modified: file.UpdatedAt,
+	})
+}
+
+// Write streams the archive to w. The archive/zip writer switches to the Zip64 format on its own
+// for the files and the archives larger than 4 GiB.
+//
+// If it fails midway, w has a truncated archive, the caller can't report the error to the client anymore.
+//
+// App Errors:
+// - ErrCommonNoData
+func (z *ZipArchive) Write(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	for _, entry := range z.Entries {
+		if err := ctx.Err(); err != nil {
+			return apperror.NewAppError(err, "media.ZipArchive.Write:ctx")
+		}
+
+		header := &zip.FileHeader{
+			Name:     entry.Path,
+			Modified: entry.Modified,
+			Method:   zip.Deflate,
+		}
+
+		if entry.File == nil {
+			header.Method = zip.Store
+			header.SetMode(fs.ModeDir | 0o755)
+			if _, err := zw.CreateHeader(header); err != nil {
+				return apperror.NewAppError(err, "media.ZipArchive.Write:CreateHeader:Folder")
+			}
+			continue
+		}
+
+		// Compressing them again only costs CPU
+		if isCompressed(entry.File) {
+			header.Method = zip.Store
+		}
+		header.SetMode(0o644)
+
+		err := z.writeFile(ctx, zw, header, entry.File)
+		if err != nil {
+			return apperror.NewAppError(err, "media.ZipArchive.Write:writeFile").WithMetadata("file_id", entry.File.ID)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return apperror.NewAppError(err, "media.ZipArchive.Write:Close")
+	}
+
+	return nil
+}
+
+func (z *ZipArchive) writeFile(ctx context.Context, zw *zip.Writer, header *zip.FileHeader, info *FileInfo) error {
+	file, err := z.storage.OpenFile(ctx, info.ID, z.ownerID)
+	if err != nil {
+		return apperror.NewAppError(err, "media.ZipArchive.writeFile:OpenFile")
+	}
+	defer file.Close()
+
+	fw, err := zw.CreateHeader(header)
+	if err != nil {
+		return apperror.NewAppError(err, "media.ZipArchive.writeFile:CreateHeader")
+	}
+
+	_, err = io.Copy(fw, file)
+	if err != nil {
+		return apperror.NewAppError(err, "media.ZipArchive.writeFile:Copy")
+	}
+
+	return nil
+}
+
+func isCompressed(info *FileInfo) bool {
+	switch info.Category {
+	case CategoryImage, CategoryVideo, CategoryAudio:
+		return info.MimeType != "image/bmp" && info.MimeType != "audio/wav"
+	}
+
+	return slices.Contains([]string{
+		"application/zip",
+		"application/gzip",
+		"application/x-7z-compressed",
+		"application/x-rar-compressed",
+		"application/x-bzip2",
+		"application/x-xz",
+		"application/zstd",
+	}, info.MimeType)
+}
+
+// zipNames keeps the names used in each folder of the archive, two selected items may have the same
+// name, e.g. a file and a folder.
+type zipNames map[string]map[string]struct{}
+
+// unique returns the path of the name in dir, "report (2).pdf" if "report.pdf" is already used.
+func (n zipNames) unique(dir, name string) string {
+	name = zipEntryName(name)
+
+	used, ok := n[dir]
+	if !ok {
+		used = map[string]struct{}{}
+		n[dir] = used
+	}
+
+	candidate := name
+	for i := 2; ; i++ {
+		key := strings.ToLower(candidate)
+		if _, taken := used[key]; !taken {
+			used[key] = struct{}{}
+			return dir + candidate
+		}
+		candidate = numberedName(name, i)
+	}
+}
+
+// zipEntryName keeps the name inside its folder when the archive is extracted.
+func zipEntryName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
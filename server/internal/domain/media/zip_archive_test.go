@@ -0,0 +1,147 @@
+package media
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"skyvault/pkg/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zipTestStorage serves the content of the files by ID, the other methods are not used.
+type zipTestStorage struct {
+	Storage
+	files map[string]string
+}
+
+type nopReadSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopReadSeekCloser) Close() error { return nil }
+
+func (s *zipTestStorage) OpenFile(_ context.Context, name string, _ string) (io.ReadSeekCloser, error) {
+	content, ok := s.files[name]
+	if !ok {
+		return nil, apperror.ErrCommonNoData
+	}
+	return nopReadSeekCloser{strings.NewReader(content)}, nil
+}
+
+func zipPaths(z *ZipArchive) []string {
+	paths := make([]string, 0, len(z.Entries))
+	for _, entry := range z.Entries {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+func TestNewZipArchive(t *testing.T) {
+	t.Parallel()
+	strPtr := func(s string) *string { return &s }
+
+	docs := &FolderInfo{ID: "docs", Name: "Docs"}
+	work := &FolderInfo{ID: "work", Name: "Work", ParentFolderID: strPtr("docs")}
+	empty := &FolderInfo{ID: "empty", Name: "Empty", ParentFolderID: strPtr("work")}
+	photos := &FolderInfo{ID: "photos", Name: "Photos", ParentFolderID: strPtr("elsewhere")}
+
+	readme := &FileInfo{ID: "readme", Name: "readme.txt"}
+	docsFile := &FileInfo{ID: "docs-file", Name: "Docs", FolderID: nil}
+	report := &FileInfo{ID: "report", Name: "report.pdf", FolderID: strPtr("work")}
+	reportUpper := &FileInfo{ID: "report-upper", Name: "REPORT.pdf", FolderID: strPtr("work")}
+	virus := &FileInfo{ID: "virus", Name: "virus.exe", FolderID: strPtr("docs"), ScanStatus: ScanStatusInfected}
+	photo := &FileInfo{ID: "photo", Name: "a/b.jpg", FolderID: strPtr("photos")}
+
+	t.Run("single folder", func(t *testing.T) {
+		t.Parallel()
+		z := newZipArchive("owner", nil, nil, []string{"docs"}, []*FolderInfo{empty, work, docs}, []*FileInfo{report, reportUpper, virus})
+
+		assert.Equal(t, "Docs.zip", z.Name)
+		assert.Equal(t, []string{
+			"Docs/",
+			"Docs/Work/",
+			"Docs/Work/Empty/",
+			"Docs/Work/REPORT (2).pdf",
+			"Docs/Work/report.pdf",
+		}, zipPaths(z))
+	})
+
+	t.Run("mixed selection", func(t *testing.T) {
+		t.Parallel()
+		// The photos folder is selected without its parent, it is at the root of the archive
+		z := newZipArchive("owner", nil, []*FileInfo{readme, docsFile}, []string{"docs", "photos"}, []*FolderInfo{docs, photos}, []*FileInfo{photo})
+
+		assert.Equal(t, defaultZipName, z.Name)
+		assert.Equal(t, []string{
+			"Docs (2)",
+			"Docs/",
+			"Photos/",
+			"Photos/a_b.jpg",
+			"readme.txt",
+		}, zipPaths(z))
+	})
+}
+
+func TestZipArchive_Write(t *testing.T) {
+	t.Parallel()
+	folderID := "docs"
+	storage := &zipTestStorage{files: map[string]string{
+		"notes": strings.Repeat("notes ", 100),
+		"photo": "jpeg bytes",
+	}}
+
+	z := newZipArchive("owner", storage,
+		[]*FileInfo{{ID: "photo", Name: "photo.jpg", Category: CategoryImage, MimeType: "image/jpeg"}},
+		[]string{folderID},
+		[]*FolderInfo{{ID: folderID, Name: "Docs"}},
+		[]*FileInfo{{ID: "notes", Name: "notes.txt", FolderID: &folderID, Category: CategoryText, MimeType: "text/plain"}},
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, z.Write(context.Background(), &buf))
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, r.File, 3)
+
+	contents := map[string]string{}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			contents[f.Name] = ""
+			continue
+		}
+
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		contents[f.Name] = string(b)
+
+		if f.Name == "photo.jpg" {
+			assert.Equal(t, zip.Store, f.Method, "images are already compressed")
+		} else {
+			assert.Equal(t, zip.Deflate, f.Method)
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"Docs/":          "",
+		"Docs/notes.txt": storage.files["notes"],
+		"photo.jpg":      storage.files["photo"],
+	}, contents)
+}
+
+func TestZipArchive_WriteMissingFile(t *testing.T) {
+	t.Parallel()
+	z := newZipArchive("owner", &zipTestStorage{}, []*FileInfo{{ID: "gone", Name: "gone.txt"}}, nil, nil, nil)
+
+	err := z.Write(context.Background(), io.Discard)
+	assert.ErrorIs(t, err, apperror.ErrCommonNoData)
+}
@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/applog"
+	"time"
+)
+
+var _ Commands = (*CommandHandlers)(nil)
+
+type CommandHandlers struct {
+	repository Repository
+	publisher  Publisher
+}
+
+func NewCommandHandlers(repository Repository, publisher Publisher) Commands {
+	return &CommandHandlers{repository: repository, publisher: publisher}
+}
+
+func (h *CommandHandlers) DispatchMessages(ctx context.Context, cmd *DispatchMessagesCommand) (int, error) {
+	logger := applog.GetLoggerFromContext(ctx)
+
+	messages, err := h.repository.ClaimMessages(ctx, time.Now().UTC(), dispatchClaimLease, dispatchBatchSize)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "outbox.CommandHandlers.DispatchMessages:ClaimMessages")
+	}
+
+	// Published one after the other, so the subscribers mostly see the events in the commit order
+	published := 0
+	for _, m := range messages {
+		err := h.publisher.Publish(ctx, m.EventType, []byte(m.Payload))
+		if err != nil {
+			m.recordFailure(time.Now().UTC(), err)
+			logger.Warn().Err(err).Int64("message_id", m.ID).Str("event_type", m.EventType).Int("attempts", m.Attempts).Msg("failed to publish domain event")
+
+			err = h.repository.UpdateMessage(ctx, m)
+			if err != nil {
+				return published, apperror.NewAppError(err, "outbox.CommandHandlers.DispatchMessages:UpdateMessage").WithMetadata("message_id", m.ID)
+			}
+			continue
+		}
+
+		err = h.repository.DeleteMessage(ctx, m.ID)
+		if err != nil {
+			return published, apperror.NewAppError(err, "outbox.CommandHandlers.DispatchMessages:DeleteMessage").WithMetadata("message_id", m.ID)
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+func (h *CommandHandlers) PurgeMessages(ctx context.Context, cmd *PurgeMessagesCommand) (int, error) {
+	count, err := h.repository.DeleteFailedMessages(ctx, time.Now().UTC().Add(-failedRetention))
+	if err != nil {
+		return 0, apperror.NewAppError(err, "outbox.CommandHandlers.PurgeMessages:DeleteFailedMessages")
+	}
+
+	return int(count), nil
+}
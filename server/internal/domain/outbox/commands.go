@@ -0,0 +1,17 @@
+package outbox
+
+import "context"
+
+type Commands interface {
+	// DispatchMessages publishes the due messages of the outbox, it is run periodically by every server.
+	// Returns the number of published messages.
+	DispatchMessages(ctx context.Context, cmd *DispatchMessagesCommand) (int, error)
+
+	// PurgeMessages deletes the messages failed for longer than the retention.
+	// Returns the number of deleted messages.
+	PurgeMessages(ctx context.Context, cmd *PurgeMessagesCommand) (int, error)
+}
+
+type DispatchMessagesCommand struct{}
+
+type PurgeMessagesCommand struct{}
@@ -0,0 +1,12 @@
+package outbox
+
+var _ Commands = (*CommandsSanitizer)(nil)
+
+// CommandsSanitizer has nothing to validate, the commands are run by the server itself.
+type CommandsSanitizer struct {
+	Commands
+}
+
+func NewCommandsSanitizer(commands Commands) Commands {
+	return &CommandsSanitizer{Commands: commands}
+}
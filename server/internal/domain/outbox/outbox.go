@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"time"
+)
+
+const (
+	// The attempts are spaced by a doubling delay, 10 attempts span about 4 hours
+	maxDispatchAttempts = 10
+	minRetryDelay       = 15 * time.Second
+	maxRetryDelay       = time.Hour
+
+	// The claim of a message lasts longer than the subscribers take, so it is not dispatched twice at once
+	dispatchClaimLease = 5 * time.Minute
+
+	// Messages claimed at once by DispatchMessages
+	dispatchBatchSize = 50
+
+	// The failed messages are kept this long to be looked into
+	failedRetention = 30 * 24 * time.Hour
+
+	maxErrorLen = 1000
+)
+
+// Message is a domain event written to the outbox by the repository of its domain, in the transaction
+// of the change, and published to the event bus once committed. It is deleted once published.
+type Message struct {
+	ID            int64 // In the commit order, mostly
+	EventType     string
+	Payload       string // JSON object, the event
+	OccurredAt    time.Time
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	FailedAt      *time.Time // Set once the attempts are exhausted, the message is not published anymore
+}
+
+// recordFailure records a failed publishing, it is retried later until the attempts are exhausted.
+func (m *Message) recordFailure(now time.Time, err error) {
+	m.Attempts++
+
+	msg := err.Error()
+	if len(msg) > maxErrorLen {
+		msg = msg[:maxErrorLen]
+	}
+	m.LastError = &msg
+
+	if m.Attempts >= maxDispatchAttempts {
+		m.FailedAt = &now
+		return
+	}
+
+	m.NextAttemptAt = now.Add(retryDelay(m.Attempts))
+}
+
+// retryDelay is the delay after the failed attempts, doubling from minRetryDelay up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_recordFailure(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &Message{ID: 1, EventType: "test.created", Payload: "{}"}
+
+	m.recordFailure(now, errors.New("boom"))
+	assert.Equal(t, 1, m.Attempts)
+	require.NotNil(t, m.LastError)
+	assert.Equal(t, "boom", *m.LastError)
+	assert.Equal(t, now.Add(minRetryDelay), m.NextAttemptAt)
+	assert.Nil(t, m.FailedAt)
+
+	m.recordFailure(now, errors.New(strings.Repeat("x", maxErrorLen+10)))
+	assert.Equal(t, now.Add(2*minRetryDelay), m.NextAttemptAt)
+	assert.Len(t, *m.LastError, maxErrorLen)
+
+	for m.Attempts < maxDispatchAttempts {
+		m.recordFailure(now, errors.New("boom"))
+	}
+	require.NotNil(t, m.FailedAt, "exhausted")
+	assert.Equal(t, now, *m.FailedAt)
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, minRetryDelay, retryDelay(1))
+	assert.Equal(t, 4*minRetryDelay, retryDelay(3))
+	assert.Equal(t, maxRetryDelay, retryDelay(maxDispatchAttempts))
+}
@@ -0,0 +1,9 @@
+package outbox
+
+import "context"
+
+// Publisher delivers the events to their subscribers, e.g. eventbus.Bus.
+type Publisher interface {
+	// Publish returns an error if any subscriber failed, the event is published again later.
+	Publish(ctx context.Context, eventType string, payload []byte) error
+}
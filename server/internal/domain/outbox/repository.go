@@ -0,0 +1,32 @@
+package outbox
+
+import (
+	"context"
+	"skyvault/internal/domain/internal"
+	"time"
+)
+
+// The messages are written by the repositories of the other domains, see their AddDomainEvents.
+type Repository interface {
+	internal.RepositoryTx[Repository]
+
+	// ClaimMessages returns the messages due at now and not failed, at most limit, oldest first.
+	// They are postponed by the lease, so that the other servers don't claim them while they are published.
+	ClaimMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Message, error)
+
+	// UpdateMessage saves the failure of a publishing.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	UpdateMessage(ctx context.Context, message *Message) error
+
+	// DeleteMessage deletes a published message.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	DeleteMessage(ctx context.Context, messageID int64) error
+
+	// DeleteFailedMessages deletes the messages failed before.
+	// Returns the number of deleted messages.
+	DeleteFailedMessages(ctx context.Context, before time.Time) (int64, error)
+}
@@ -31,6 +31,12 @@ func (h *CommandHandlers) Create(ctx context.Context, cmd *CreateCommand) (*Prof
 		return nil, apperror.NewAppError(err, "profile.CommandHandlers.Create:Create")
 	}
 
+	// The repository is the one of the SignUpFlow transaction, the event is committed with the auth
+	err = h.repository.AddDomainEvents(ctx, Created{ProfileID: pro.ID, Email: pro.Email, FullName: pro.FullName})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "profile.CommandHandlers.Create:AddDomainEvents")
+	}
+
 	return pro, nil
 }
 
@@ -47,10 +53,28 @@ func (h *CommandHandlers) Delete(ctx context.Context, cmd *DeleteCommand) error
 		return apperror.NewAppError(err, "profile.CommandHandlers.Delete:ValidateAccess")
 	}
 
-	err = h.repository.Delete(ctx, cmd.ID)
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "profile.CommandHandlers.Delete:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.Delete(ctx, cmd.ID)
 	if err != nil {
 		return apperror.NewAppError(err, "profile.CommandHandlers.Delete:Delete")
 	}
 
+	err = repoTx.AddDomainEvents(ctx, Deleted{ProfileID: pro.ID})
+	if err != nil {
+		return apperror.NewAppError(err, "profile.CommandHandlers.Delete:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "profile.CommandHandlers.Delete:Commit")
+	}
+
 	return nil
 }
@@ -0,0 +1,18 @@
+package profile
+
+// Domain events of the profiles, written to the outbox in the transaction of the change
+// and published to the subscribers of the event bus once committed.
+
+type Created struct {
+	ProfileID string
+	Email     string
+	FullName  string
+}
+
+func (Created) EventType() string { return "profile.created" }
+
+type Deleted struct {
+	ProfileID string
+}
+
+func (Deleted) EventType() string { return "profile.deleted" }
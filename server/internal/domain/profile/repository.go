@@ -3,6 +3,7 @@ package profile
 import (
 	"context"
 	"skyvault/internal/domain/internal"
+	"skyvault/pkg/eventbus"
 )
 
 type Repository interface {
@@ -27,4 +28,8 @@ type Repository interface {
 	// App Errors:
 	// - ErrCommonNoData
 	Delete(ctx context.Context, id string) error
+
+	// AddDomainEvents writes the events to the outbox. With the repository of a transaction,
+	// they are published only once it commits.
+	AddDomainEvents(ctx context.Context, events ...eventbus.Event) error
 }
@@ -2,7 +2,7 @@ package sharing
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"skyvault/internal/domain/media"
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
@@ -137,6 +137,11 @@ func (h *CommandHandlers) CreateShare(ctx context.Context, cmd *CreateShareComma
 		if err != nil {
 			return nil, apperror.NewAppError(err, "sharing.CommandHandlers.CreateShare:File.ValidateAccess")
 		}
+
+		err = fileInfo.ValidateNotQuarantined()
+		if err != nil {
+			return nil, apperror.NewAppError(err, "sharing.CommandHandlers.CreateShare:File.ValidateNotQuarantined")
+		}
 	}
 
 	if cmd.FolderID != nil {
@@ -186,6 +191,21 @@ func (h *CommandHandlers) CreateShare(ctx context.Context, cmd *CreateShareComma
 		config.Recipients = append(config.Recipients, recipient)
 	}
 
+	recipientIDs := make([]string, 0, len(config.Recipients))
+	for _, r := range config.Recipients {
+		recipientIDs = append(recipientIDs, r.ID)
+	}
+	err = repoTx.AddDomainEvents(ctx, ShareCreated{
+		OwnerID:      profileID,
+		ShareID:      config.ID,
+		FileID:       config.FileID,
+		FolderID:     config.FolderID,
+		RecipientIDs: recipientIDs,
+	})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "sharing.CommandHandlers.CreateShare:AddDomainEvents")
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, apperror.NewAppError(err, "sharing.CommandHandlers.CreateShare:Commit")
@@ -196,11 +216,30 @@ func (h *CommandHandlers) CreateShare(ctx context.Context, cmd *CreateShareComma
 
 func (h *CommandHandlers) UpdateShareExpiry(ctx context.Context, cmd *UpdateShareExpiryCommand) error {
 	profileID := common.GetProfileIDFromContext(ctx)
-	err := h.repository.UpdateShareExpiry(ctx, profileID, cmd.ShareID, cmd.MaxDownloads, cmd.ExpiresAt)
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateShareExpiry:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.UpdateShareExpiry(ctx, profileID, cmd.ShareID, cmd.MaxDownloads, cmd.ExpiresAt)
 	if err != nil {
 		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateShareExpiry:UpdateShareExpiry")
 	}
 
+	err = repoTx.AddDomainEvents(ctx, ShareUpdated{OwnerID: profileID, ShareID: cmd.ShareID})
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateShareExpiry:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateShareExpiry:Commit")
+	}
+
 	return nil
 }
 
@@ -211,36 +250,69 @@ func (h *CommandHandlers) UpdateSharePassword(ctx context.Context, cmd *UpdateSh
 		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateSharePassword:HashPassword")
 	}
 
-	err = h.repository.UpdateSharePassword(ctx, profileID, cmd.ShareID, &pwdHash)
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateSharePassword:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.UpdateSharePassword(ctx, profileID, cmd.ShareID, &pwdHash)
 	if err != nil {
 		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateSharePassword:UpdateSharePassword")
 	}
 
+	err = repoTx.AddDomainEvents(ctx, ShareUpdated{OwnerID: profileID, ShareID: cmd.ShareID})
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateSharePassword:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.UpdateSharePassword:Commit")
+	}
+
 	return nil
 }
 
 func (h *CommandHandlers) DeleteShare(ctx context.Context, cmd *DeleteShareCommand) error {
 	profileID := common.GetProfileIDFromContext(ctx)
-	err := h.repository.DeleteShareConfig(ctx, profileID, cmd.ShareID)
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.DeleteShare:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.DeleteShareConfig(ctx, profileID, cmd.ShareID)
 	if err != nil {
 		return apperror.NewAppError(err, "sharing.CommandHandlers.DeleteShare:DeleteShareConfig")
 	}
 
+	err = repoTx.AddDomainEvents(ctx, ShareDeleted{OwnerID: profileID, ShareID: cmd.ShareID})
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.DeleteShare:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.DeleteShare:Commit")
+	}
+
 	return nil
 }
 
 func (h *CommandHandlers) AddShareRecipient(ctx context.Context, cmd *AddShareRecipientCommand) (*ShareRecipient, error) {
-	var tx *sql.Tx
-	var err error
-	repoTx := h.repository
-	if cmd.SaveAsContact {
-		tx, err = repoTx.BeginTx(ctx)
-		if err != nil {
-			return nil, apperror.NewAppError(err, "sharing.CommandHandlers.AddShareRecipient:BeginTx")
-		}
-		defer tx.Rollback()
-		repoTx = repoTx.WithTx(ctx, tx)
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "sharing.CommandHandlers.AddShareRecipient:BeginTx")
 	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
 
 	profileID := common.GetProfileIDFromContext(ctx)
 	recipient, err := addShareRecipient(ctx, repoTx, profileID, cmd.ShareID, cmd.ShareRecipientInput)
@@ -248,11 +320,14 @@ func (h *CommandHandlers) AddShareRecipient(ctx context.Context, cmd *AddShareRe
 		return nil, apperror.NewAppError(err, "sharing.CommandHandlers.AddShareRecipient:addShareRecipient")
 	}
 
-	if tx != nil {
-		err = tx.Commit()
-		if err != nil {
-			return nil, apperror.NewAppError(err, "sharing.CommandHandlers.AddShareRecipient:Commit")
-		}
+	err = repoTx.AddDomainEvents(ctx, ShareRecipientAdded{OwnerID: profileID, ShareID: cmd.ShareID, RecipientID: recipient.ID})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "sharing.CommandHandlers.AddShareRecipient:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "sharing.CommandHandlers.AddShareRecipient:Commit")
 	}
 	return recipient, nil
 }
@@ -293,10 +368,70 @@ func addShareRecipient(ctx context.Context, repoTx Repository, profileID, shareC
 
 func (h *CommandHandlers) RemoveShareRecipient(ctx context.Context, cmd *RemoveShareRecipientCommand) error {
 	profileID := common.GetProfileIDFromContext(ctx)
-	err := h.repository.DeleteShareRecipient(ctx, profileID, cmd.ShareID, cmd.RecipientID)
+
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.RemoveShareRecipient:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	err = repoTx.DeleteShareRecipient(ctx, profileID, cmd.ShareID, cmd.RecipientID)
 	if err != nil {
 		return apperror.NewAppError(err, "sharing.CommandHandlers.RemoveShareRecipient:DeleteShareRecipient")
 	}
 
+	err = repoTx.AddDomainEvents(ctx, ShareRecipientRemoved{OwnerID: profileID, ShareID: cmd.ShareID, RecipientID: cmd.RecipientID})
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.RemoveShareRecipient:AddDomainEvents")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.RemoveShareRecipient:Commit")
+	}
+
+	return nil
+}
+
+func (h *CommandHandlers) RecordShareDownload(ctx context.Context, cmd *RecordShareDownloadCommand) error {
+	tx, err := h.repository.BeginTx(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.RecordShareDownload:BeginTx")
+	}
+	defer tx.Rollback()
+
+	repoTx := h.repository.WithTx(ctx, tx)
+
+	// The share was found while validating the access, no row is a share with its max downloads
+	err = repoTx.IncrementShareDownloads(ctx, cmd.ShareID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrCommonNoData) {
+			return apperror.NewAppError(apperror.ErrSharingMaxDownloadsReached, "sharing.CommandHandlers.RecordShareDownload:IncrementShareDownloads")
+		}
+		return apperror.NewAppError(err, "sharing.CommandHandlers.RecordShareDownload:IncrementShareDownloads")
+	}
+
+	// A share without recipients is open to anyone, whatever email the visitor gave
+	if cmd.Email != nil {
+		recipient, err := repoTx.GetShareRecipientByEmail(ctx, cmd.ShareID, *cmd.Email)
+		if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+			return apperror.NewAppError(err, "sharing.CommandHandlers.RecordShareDownload:GetShareRecipientByEmail")
+		}
+
+		if recipient != nil {
+			err = repoTx.IncrementShareRecipientDownloads(ctx, recipient.ID)
+			if err != nil {
+				return apperror.NewAppError(err, "sharing.CommandHandlers.RecordShareDownload:IncrementShareRecipientDownloads")
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.CommandHandlers.RecordShareDownload:Commit")
+	}
+
 	return nil
 }
@@ -59,6 +59,7 @@ type Commands interface {
 	// App Errors:
 	// - ErrCommonInvalidValue
 	// - ErrCommonNoData (if resource not found)
+	// - ErrMediaFileInfected
 	CreateShare(ctx context.Context, cmd *CreateShareCommand) (*ShareConfig, error)
 
 	// App Errors:
@@ -86,6 +87,14 @@ type Commands interface {
 	// - ErrCommonNoData
 	// - ErrCommonNoAccess
 	RemoveShareRecipient(ctx context.Context, cmd *RemoveShareRecipientCommand) error
+
+	// RecordShareDownload counts a download of the share by a visitor of the share link, who has
+	// access to it. The visitor is not signed in.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrSharingMaxDownloadsReached
+	RecordShareDownload(ctx context.Context, cmd *RecordShareDownloadCommand) error
 }
 
 //--------------------------------
@@ -184,3 +193,8 @@ type RemoveShareRecipientCommand struct {
 	ShareID     string
 	RecipientID string
 }
+
+type RecordShareDownloadCommand struct {
+	ShareID string
+	Email   *string // Of the recipient, if any
+}
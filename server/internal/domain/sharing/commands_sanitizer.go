@@ -190,6 +190,22 @@ func (s *CommandsSanitizer) AddShareRecipient(ctx context.Context, cmd *AddShare
 	return s.Commands.AddShareRecipient(ctx, cmd)
 }
 
+func (s *CommandsSanitizer) RecordShareDownload(ctx context.Context, cmd *RecordShareDownloadCommand) error {
+	if !validate.UUID(cmd.ShareID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "sharing.CommandsSanitizer.RecordShareDownload:ShareID")
+	}
+
+	if cmd.Email != nil {
+		if e, err := validate.Email(*cmd.Email); err != nil {
+			return apperror.NewAppError(err, "sharing.CommandsSanitizer.RecordShareDownload:Email")
+		} else {
+			cmd.Email = &e
+		}
+	}
+
+	return s.Commands.RecordShareDownload(ctx, cmd)
+}
+
 // Only one of the contactID, contactGroupID, or email can be set.
 func validShareRecipient(recipient *ShareRecipientInput) bool {
 	if recipient.SaveAsContact {
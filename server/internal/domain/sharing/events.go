@@ -0,0 +1,45 @@
+package sharing
+
+// Domain events of the shares, written to the outbox in the transaction of the change
+// and published to the subscribers of the event bus once committed.
+
+type ShareCreated struct {
+	OwnerID      string
+	ShareID      string
+	FileID       *string // Only one of file or folder is set
+	FolderID     *string
+	RecipientIDs []string
+}
+
+func (ShareCreated) EventType() string { return "sharing.share.created" }
+
+// ShareUpdated is the change of the expiry or the password of a share.
+type ShareUpdated struct {
+	OwnerID string
+	ShareID string
+}
+
+func (ShareUpdated) EventType() string { return "sharing.share.updated" }
+
+type ShareDeleted struct {
+	OwnerID string
+	ShareID string
+}
+
+func (ShareDeleted) EventType() string { return "sharing.share.deleted" }
+
+type ShareRecipientAdded struct {
+	OwnerID     string
+	ShareID     string
+	RecipientID string
+}
+
+func (ShareRecipientAdded) EventType() string { return "sharing.recipient.added" }
+
+type ShareRecipientRemoved struct {
+	OwnerID     string
+	ShareID     string
+	RecipientID string
+}
+
+func (ShareRecipientRemoved) EventType() string { return "sharing.recipient.removed" }
@@ -32,8 +32,20 @@ type Queries interface {
 	// - ErrCommonNoData
 	// - ErrCommonNoAccess
 	// - ErrSharingExpired
+	// - ErrSharingInvalidCredentials
 	// - ErrSharingMaxDownloadsReached
 	ValidateShareAccess(ctx context.Context, query *ValidateShareAccessQuery) error
+
+	// GetPublicShare returns the share to a visitor of the share link, after the same checks as
+	// ValidateShareAccess. The visitor is not signed in, the share is looked up without its owner.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	// - ErrSharingExpired
+	// - ErrSharingInvalidCredentials
+	// - ErrSharingMaxDownloadsReached
+	GetPublicShare(ctx context.Context, query *ValidateShareAccessQuery) (*ShareConfig, error)
 }
 
 //--------------------------------
@@ -42,9 +42,29 @@ func (s *QueriesSanitizer) GetContactGroups(ctx context.Context, query *GetConta
 }
 
 func (s *QueriesSanitizer) ValidateShareAccess(ctx context.Context, query *ValidateShareAccessQuery) error {
+	if err := sanitizeShareAccess(query); err != nil {
+		return apperror.NewAppError(err, "sharing.QueriesSanitizer.ValidateShareAccess:sanitizeShareAccess")
+	}
+
+	return s.Queries.ValidateShareAccess(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetPublicShare(ctx context.Context, query *ValidateShareAccessQuery) (*ShareConfig, error) {
+	if !validate.UUID(query.ShareID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "sharing.QueriesSanitizer.GetPublicShare:ShareID")
+	}
+
+	if err := sanitizeShareAccess(query); err != nil {
+		return nil, apperror.NewAppError(err, "sharing.QueriesSanitizer.GetPublicShare:sanitizeShareAccess")
+	}
+
+	return s.Queries.GetPublicShare(ctx, query)
+}
+
+func sanitizeShareAccess(query *ValidateShareAccessQuery) error {
 	if query.Email != nil {
 		if m, err := validate.Email(*query.Email); err != nil {
-			return apperror.NewAppError(err, "sharing.QueriesSanitizer.ValidateShareAccess:Email")
+			return apperror.NewAppError(err, "sharing.sanitizeShareAccess:Email")
 		} else {
 			query.Email = &m
 		}
@@ -52,11 +72,11 @@ func (s *QueriesSanitizer) ValidateShareAccess(ctx context.Context, query *Valid
 
 	if query.Password != nil {
 		if p, err := validate.PasswordLen(*query.Password); err != nil {
-			return apperror.NewAppError(err, "sharing.QueriesSanitizer.ValidateShareAccess:Password")
+			return apperror.NewAppError(err, "sharing.sanitizeShareAccess:Password")
 		} else {
 			query.Password = &p
 		}
 	}
 
-	return s.Queries.ValidateShareAccess(ctx, query)
+	return nil
 }
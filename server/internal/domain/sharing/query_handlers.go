@@ -2,6 +2,7 @@ package sharing
 
 import (
 	"context"
+	"errors"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
 	"skyvault/pkg/paging"
@@ -77,35 +78,71 @@ func (h *QueryHandlers) ValidateShareAccess(ctx context.Context, query *Validate
 		return apperror.NewAppError(err, "sharing.QueryHandlers.ValidateShareAccess:GetShareConfig")
 	}
 
+	err = h.validateShareAccess(ctx, config, query)
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.QueryHandlers.ValidateShareAccess:validateShareAccess")
+	}
+
+	return nil
+}
+
+func (h *QueryHandlers) GetPublicShare(ctx context.Context, query *ValidateShareAccessQuery) (*ShareConfig, error) {
+	config, err := h.repository.GetPublicShareConfig(ctx, query.ShareID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "sharing.QueryHandlers.GetPublicShare:GetPublicShareConfig")
+	}
+
+	err = h.validateShareAccess(ctx, config, query)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "sharing.QueryHandlers.GetPublicShare:validateShareAccess")
+	}
+
+	return config, nil
+}
+
+func (h *QueryHandlers) validateShareAccess(ctx context.Context, config *ShareConfig, query *ValidateShareAccessQuery) error {
 	// validate expiry
-	err = config.ValidateExpiry()
+	err := config.ValidateExpiry()
 	if err != nil {
-		return apperror.NewAppError(err, "sharing.QueryHandlers.ValidateShareAccess:ValidateExpiry")
+		return apperror.NewAppError(err, "sharing.QueryHandlers.validateShareAccess:ValidateExpiry")
 	}
 
 	// validate password
 	if config.PasswordHash != nil {
 		if query.Password == nil {
-			return apperror.NewAppError(apperror.ErrSharingInvalidCredentials, "sharing.QueryHandlers.ValidateShareAccess:PasswordRequired")
+			return apperror.NewAppError(apperror.ErrSharingInvalidCredentials, "sharing.QueryHandlers.validateShareAccess:PasswordRequired")
 		}
 
 		ok, err := utils.SamePassword(*config.PasswordHash, *query.Password)
 		if err != nil {
-			return apperror.NewAppError(err, "sharing.QueryHandlers.ValidateShareAccess:SamePassword")
+			return apperror.NewAppError(err, "sharing.QueryHandlers.validateShareAccess:SamePassword")
 		}
 		if !ok {
-			return apperror.NewAppError(apperror.ErrSharingInvalidCredentials, "sharing.QueryHandlers.ValidateShareAccess:InvalidPassword")
+			return apperror.NewAppError(apperror.ErrSharingInvalidCredentials, "sharing.QueryHandlers.validateShareAccess:InvalidPassword")
 		}
 	}
 
-	if query.Email == nil {
+	// validate downloads
+	err = config.ValidateDownloads()
+	if err != nil {
+		return apperror.NewAppError(err, "sharing.QueryHandlers.validateShareAccess:ValidateDownloads")
+	}
+
+	// validate recipient, a share with recipients is only for them
+	if len(config.Recipients) == 0 {
 		return nil
 	}
 
-	// validate recipient
+	if query.Email == nil {
+		return apperror.NewAppError(apperror.ErrSharingInvalidCredentials, "sharing.QueryHandlers.validateShareAccess:EmailRequired")
+	}
+
 	_, err = h.repository.GetShareRecipientByEmail(ctx, config.ID, *query.Email)
 	if err != nil {
-		return apperror.NewAppError(err, "sharing.QueryHandlers.ValidateShareAccess:GetShareRecipientByEmail")
+		if errors.Is(err, apperror.ErrCommonNoData) {
+			return apperror.NewAppError(apperror.ErrSharingInvalidCredentials, "sharing.QueryHandlers.validateShareAccess:InvalidEmail")
+		}
+		return apperror.NewAppError(err, "sharing.QueryHandlers.validateShareAccess:GetShareRecipientByEmail")
 	}
 
 	return nil
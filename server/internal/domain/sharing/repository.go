@@ -3,6 +3,7 @@ package sharing
 import (
 	"context"
 	"skyvault/internal/domain/internal"
+	"skyvault/pkg/eventbus"
 	"skyvault/pkg/paging"
 	"time"
 )
@@ -73,6 +74,12 @@ type Repository interface {
 	// - ErrCommonNoData
 	GetShareConfig(ctx context.Context, ownerID, shareID string) (*ShareConfig, error)
 
+	// GetPublicShareConfig returns the share whoever the owner is, for the visitors of a share link.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	GetPublicShareConfig(ctx context.Context, shareID string) (*ShareConfig, error)
+
 	// App Errors:
 	// - ErrCommonNoData
 	UpdateShareExpiry(ctx context.Context, ownerID, shareID string, maxDownloads *int64, expiresAt *time.Time) error
@@ -85,6 +92,12 @@ type Repository interface {
 	// - ErrCommonNoData
 	DeleteShareConfig(ctx context.Context, ownerID, shareID string) error
 
+	// IncrementShareDownloads counts a download of the share, unless it already has its max downloads.
+	//
+	// App Errors:
+	// - ErrCommonNoData (if the share is not found or has its max downloads)
+	IncrementShareDownloads(ctx context.Context, shareID string) error
+
 	// App Errors:
 	// - ErrCommonDuplicateData
 	CreateShareRecipient(ctx context.Context, ownerID string, recipient *ShareRecipient) (*ShareRecipient, error)
@@ -93,7 +106,22 @@ type Repository interface {
 	// - ErrCommonNoData
 	DeleteShareRecipient(ctx context.Context, ownerID, shareID, recipientID string) error
 
+	// GetShareRecipientByEmail returns the recipient with the email, directly or as a contact or
+	// a member of a contact group.
+	//
 	// App Errors:
 	// - ErrCommonNoData
 	GetShareRecipientByEmail(ctx context.Context, shareID string, email string) (*ShareRecipient, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	IncrementShareRecipientDownloads(ctx context.Context, recipientID string) error
+
+	//--------------------------------
+	// Domain events
+	//--------------------------------
+
+	// AddDomainEvents writes the events to the outbox. With the repository of a transaction,
+	// they are published only once it commits.
+	AddDomainEvents(ctx context.Context, events ...eventbus.Event) error
 }
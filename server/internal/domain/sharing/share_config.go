@@ -7,16 +7,17 @@ import (
 )
 
 type ShareConfig struct {
-	ID           string
-	OwnerID      string
-	FileID       *string // Only one of file or folder must be set
-	FolderID     *string
-	PasswordHash *string
-	MaxDownloads *int64
-	ExpiresAt    *time.Time
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	Recipients   []*ShareRecipient
+	ID             string
+	OwnerID        string
+	FileID         *string // Only one of file or folder must be set
+	FolderID       *string
+	PasswordHash   *string
+	MaxDownloads   *int64
+	DownloadsCount int64
+	ExpiresAt      *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Recipients     []*ShareRecipient
 }
 
 func NewShareConfig(
@@ -71,3 +72,15 @@ func (s *ShareConfig) ValidateExpiry() error {
 	}
 	return nil
 }
+
+// ValidateDownloads only reports a share already used up, the download itself is counted
+// atomically by the repository.
+//
+// App Errors:
+// - ErrSharingMaxDownloadsReached
+func (s *ShareConfig) ValidateDownloads() error {
+	if s.MaxDownloads != nil && s.DownloadsCount >= *s.MaxDownloads {
+		return apperror.NewAppError(apperror.ErrSharingMaxDownloadsReached, "sharing.ShareConfig.ValidateDownloads")
+	}
+	return nil
+}
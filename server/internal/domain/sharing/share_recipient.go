@@ -40,8 +40,3 @@ func NewShareRecipient(
 		UpdatedAt:      now,
 	}, nil
 }
-
-// func (r *ShareRecipient) IncrementDownloads() {
-// 	r.DownloadsCount++
-// 	r.UpdatedAt = time.Now().UTC()
-// }
@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"skyvault/internal/domain/media"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/applog"
+	"sync"
+	"time"
+)
+
+var _ Commands = (*CommandHandlers)(nil)
+
+type CommandHandlers struct {
+	repository      Repository
+	mediaRepository media.Repository
+	sender          Sender
+}
+
+func NewCommandHandlers(repository Repository, mediaRepository media.Repository, sender Sender) Commands {
+	return &CommandHandlers{repository: repository, mediaRepository: mediaRepository, sender: sender}
+}
+
+//--------------------------------
+// Webhooks
+//--------------------------------
+
+func (h *CommandHandlers) CreateWebhook(ctx context.Context, cmd *CreateWebhookCommand) (*Webhook, error) {
+	err := h.validateFolder(ctx, cmd.OwnerID, cmd.FolderID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.CreateWebhook:validateFolder")
+	}
+
+	webhook, err := NewWebhook(cmd.OwnerID, cmd.URL, cmd.EventTypes, cmd.FolderID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.CreateWebhook:NewWebhook")
+	}
+
+	webhook, err = h.repository.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.CreateWebhook:CreateWebhook")
+	}
+
+	return webhook, nil
+}
+
+func (h *CommandHandlers) UpdateWebhook(ctx context.Context, cmd *UpdateWebhookCommand) (*Webhook, error) {
+	webhook, err := h.getWebhook(ctx, cmd.OwnerID, cmd.WebhookID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.UpdateWebhook:getWebhook")
+	}
+
+	err = h.validateFolder(ctx, cmd.OwnerID, cmd.FolderID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.UpdateWebhook:validateFolder")
+	}
+
+	webhook.URL = cmd.URL
+	webhook.EventTypes = cmd.EventTypes
+	webhook.FolderID = cmd.FolderID
+	webhook.Enabled = cmd.Enabled
+	webhook.UpdatedAt = time.Now().UTC()
+
+	err = h.repository.UpdateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.UpdateWebhook:UpdateWebhook")
+	}
+
+	return webhook, nil
+}
+
+func (h *CommandHandlers) RotateWebhookSecret(ctx context.Context, cmd *RotateWebhookSecretCommand) (*Webhook, error) {
+	webhook, err := h.getWebhook(ctx, cmd.OwnerID, cmd.WebhookID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.RotateWebhookSecret:getWebhook")
+	}
+
+	err = webhook.RotateSecret()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.RotateWebhookSecret:RotateSecret")
+	}
+
+	err = h.repository.UpdateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.RotateWebhookSecret:UpdateWebhook")
+	}
+
+	return webhook, nil
+}
+
+func (h *CommandHandlers) DeleteWebhook(ctx context.Context, cmd *DeleteWebhookCommand) error {
+	webhook, err := h.getWebhook(ctx, cmd.OwnerID, cmd.WebhookID)
+	if err != nil {
+		return apperror.NewAppError(err, "webhook.CommandHandlers.DeleteWebhook:getWebhook")
+	}
+
+	err = h.repository.DeleteWebhook(ctx, webhook.ID)
+	if err != nil {
+		return apperror.NewAppError(err, "webhook.CommandHandlers.DeleteWebhook:DeleteWebhook")
+	}
+
+	return nil
+}
+
+// App Errors:
+// - ErrCommonNoData
+// - ErrCommonNoAccess
+func (h *CommandHandlers) getWebhook(ctx context.Context, ownerID, webhookID string) (*Webhook, error) {
+	webhook, err := h.repository.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.getWebhook:GetWebhook")
+	}
+
+	err = webhook.ValidateAccess(ownerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.getWebhook:ValidateAccess")
+	}
+
+	return webhook, nil
+}
+
+// App Errors:
+// - ErrCommonNoData
+// - ErrCommonNoAccess
+func (h *CommandHandlers) validateFolder(ctx context.Context, ownerID string, folderID *string) error {
+	if folderID == nil {
+		return nil
+	}
+
+	folderInfo, err := h.mediaRepository.GetFolderInfo(ctx, ownerID, *folderID)
+	if err != nil {
+		return apperror.NewAppError(err, "webhook.CommandHandlers.validateFolder:GetFolderInfo")
+	}
+
+	err = folderInfo.ValidateAccess(ownerID)
+	if err != nil {
+		return apperror.NewAppError(err, "webhook.CommandHandlers.validateFolder:ValidateAccess")
+	}
+
+	return nil
+}
+
+//--------------------------------
+// Deliveries
+//--------------------------------
+
+func (h *CommandHandlers) PingWebhook(ctx context.Context, cmd *PingWebhookCommand) (*Delivery, error) {
+	webhook, err := h.getWebhook(ctx, cmd.OwnerID, cmd.WebhookID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.PingWebhook:getWebhook")
+	}
+
+	delivery, err := newPingDelivery(webhook)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.PingWebhook:newPingDelivery")
+	}
+
+	delivery, err = h.repository.CreateDelivery(ctx, delivery)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.PingWebhook:CreateDelivery")
+	}
+
+	return delivery, nil
+}
+
+func (h *CommandHandlers) Redeliver(ctx context.Context, cmd *RedeliverCommand) (*Delivery, error) {
+	delivery, err := h.repository.GetDelivery(ctx, cmd.DeliveryID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.Redeliver:GetDelivery")
+	}
+
+	if delivery.OwnerID != cmd.OwnerID {
+		return nil, apperror.NewAppError(apperror.ErrCommonNoAccess, "webhook.CommandHandlers.Redeliver:OwnerID")
+	}
+
+	redelivery, err := newRedelivery(delivery)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.Redeliver:newRedelivery")
+	}
+
+	redelivery, err = h.repository.CreateDelivery(ctx, redelivery)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandHandlers.Redeliver:CreateDelivery")
+	}
+
+	return redelivery, nil
+}
+
+func (h *CommandHandlers) DeliverWebhooks(ctx context.Context, cmd *DeliverWebhooksCommand) (int, error) {
+	deliveries, err := h.repository.ClaimDeliveries(ctx, time.Now().UTC(), deliveryClaimLease, deliveryBatchSize)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "webhook.CommandHandlers.DeliverWebhooks:ClaimDeliveries")
+	}
+
+	webhooks := map[string]*Webhook{}
+	for _, d := range deliveries {
+		if _, ok := webhooks[d.WebhookID]; ok {
+			continue
+		}
+
+		webhook, err := h.repository.GetWebhook(ctx, d.WebhookID)
+		if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+			return 0, apperror.NewAppError(err, "webhook.CommandHandlers.DeliverWebhooks:GetWebhook")
+		}
+		webhooks[d.WebhookID] = webhook
+	}
+
+	// A slow receiver doesn't hold up the others
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		// The webhook was deleted since the claim, along with its deliveries
+		if webhooks[d.WebhookID] == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.deliver(ctx, webhooks[d.WebhookID], d)
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// deliver attempts the delivery and saves the result, the failures are recorded in the delivery log.
+func (h *CommandHandlers) deliver(ctx context.Context, webhook *Webhook, delivery *Delivery) {
+	logger := applog.GetLoggerFromContext(ctx).With().Str("webhook_id", webhook.ID).Str("delivery_id", delivery.ID).Logger()
+
+	now := time.Now().UTC()
+	body, headers, err := delivery.Request(webhook.Secret, now)
+	if err != nil {
+		logger.Error().Err(apperror.NewAppError(err, "webhook.CommandHandlers.deliver:Request")).Msg("failed to build the webhook request")
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	status, sendErr := h.sender.Send(sendCtx, webhook.URL, headers, body)
+	cancel()
+
+	delivery.recordAttempt(time.Now().UTC(), status, sendErr)
+	if sendErr != nil {
+		logger.Debug().Err(sendErr).Int("attempts", delivery.Attempts).Msg("webhook delivery failed")
+	}
+
+	err = h.repository.UpdateDelivery(ctx, delivery)
+	if err != nil {
+		logger.Error().Err(apperror.NewAppError(err, "webhook.CommandHandlers.deliver:UpdateDelivery")).Msg("failed to save the webhook delivery")
+	}
+}
+
+func (h *CommandHandlers) PurgeDeliveries(ctx context.Context, cmd *PurgeDeliveriesCommand) (int, error) {
+	count, err := h.repository.DeleteDeliveries(ctx, time.Now().UTC().Add(-deliveryRetention))
+	if err != nil {
+		return 0, apperror.NewAppError(err, "webhook.CommandHandlers.PurgeDeliveries:DeleteDeliveries")
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import "context"
+
+type Commands interface {
+	// CreateWebhook returns the webhook with its secret, the receiver verifies the signatures with it.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData (if the folder is not found)
+	// - ErrCommonNoAccess
+	CreateWebhook(ctx context.Context, cmd *CreateWebhookCommand) (*Webhook, error)
+
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	UpdateWebhook(ctx context.Context, cmd *UpdateWebhookCommand) (*Webhook, error)
+
+	// RotateWebhookSecret returns the webhook with a new secret, the next deliveries are signed with it.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	RotateWebhookSecret(ctx context.Context, cmd *RotateWebhookSecretCommand) (*Webhook, error)
+
+	// DeleteWebhook deletes the webhook and its deliveries.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	DeleteWebhook(ctx context.Context, cmd *DeleteWebhookCommand) error
+
+	// PingWebhook queues a delivery of a "webhook.ping" event, to check the receiver.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	PingWebhook(ctx context.Context, cmd *PingWebhookCommand) (*Delivery, error)
+
+	// Redeliver queues a new delivery of the event of a delivery, whatever the status of the delivery.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	Redeliver(ctx context.Context, cmd *RedeliverCommand) (*Delivery, error)
+
+	// DeliverWebhooks attempts the due deliveries of all the owners, it is run periodically by every server.
+	// Returns the number of attempted deliveries.
+	DeliverWebhooks(ctx context.Context, cmd *DeliverWebhooksCommand) (int, error)
+
+	// PurgeDeliveries deletes the deliveries older than the retention of the delivery log.
+	// Returns the number of deleted deliveries.
+	PurgeDeliveries(ctx context.Context, cmd *PurgeDeliveriesCommand) (int, error)
+}
+
+type CreateWebhookCommand struct {
+	OwnerID    string
+	URL        string
+	EventTypes []string
+	FolderID   *string
+}
+
+type UpdateWebhookCommand struct {
+	OwnerID    string
+	WebhookID  string
+	URL        string
+	EventTypes []string
+	FolderID   *string
+	Enabled    bool
+}
+
+type RotateWebhookSecretCommand struct {
+	OwnerID   string
+	WebhookID string
+}
+
+type DeleteWebhookCommand struct {
+	OwnerID   string
+	WebhookID string
+}
+
+type PingWebhookCommand struct {
+	OwnerID   string
+	WebhookID string
+}
+
+type RedeliverCommand struct {
+	OwnerID    string
+	DeliveryID string
+}
+
+type DeliverWebhooksCommand struct{}
+
+type PurgeDeliveriesCommand struct{}
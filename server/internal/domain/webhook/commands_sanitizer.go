@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"context"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/validate"
+)
+
+var _ Commands = (*CommandsSanitizer)(nil)
+
+type CommandsSanitizer struct {
+	Commands
+}
+
+func NewCommandsSanitizer(commands Commands) Commands {
+	return &CommandsSanitizer{Commands: commands}
+}
+
+func (s *CommandsSanitizer) CreateWebhook(ctx context.Context, cmd *CreateWebhookCommand) (*Webhook, error) {
+	if !validate.UUID(cmd.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.CreateWebhook:OwnerID")
+	}
+
+	if u, err := validateURL(cmd.URL); err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandsSanitizer.CreateWebhook:URL")
+	} else {
+		cmd.URL = u
+	}
+
+	if t, err := validateEventTypes(cmd.EventTypes); err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandsSanitizer.CreateWebhook:EventTypes")
+	} else {
+		cmd.EventTypes = t
+	}
+
+	if cmd.FolderID != nil && !validate.UUID(*cmd.FolderID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.CreateWebhook:FolderID")
+	}
+
+	return s.Commands.CreateWebhook(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) UpdateWebhook(ctx context.Context, cmd *UpdateWebhookCommand) (*Webhook, error) {
+	if !validate.UUID(cmd.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.UpdateWebhook:OwnerID")
+	}
+
+	if !validate.UUID(cmd.WebhookID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.UpdateWebhook:WebhookID")
+	}
+
+	if u, err := validateURL(cmd.URL); err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandsSanitizer.UpdateWebhook:URL")
+	} else {
+		cmd.URL = u
+	}
+
+	if t, err := validateEventTypes(cmd.EventTypes); err != nil {
+		return nil, apperror.NewAppError(err, "webhook.CommandsSanitizer.UpdateWebhook:EventTypes")
+	} else {
+		cmd.EventTypes = t
+	}
+
+	if cmd.FolderID != nil && !validate.UUID(*cmd.FolderID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.UpdateWebhook:FolderID")
+	}
+
+	return s.Commands.UpdateWebhook(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) RotateWebhookSecret(ctx context.Context, cmd *RotateWebhookSecretCommand) (*Webhook, error) {
+	if !validate.UUID(cmd.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.RotateWebhookSecret:OwnerID")
+	}
+
+	if !validate.UUID(cmd.WebhookID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.RotateWebhookSecret:WebhookID")
+	}
+
+	return s.Commands.RotateWebhookSecret(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) DeleteWebhook(ctx context.Context, cmd *DeleteWebhookCommand) error {
+	if !validate.UUID(cmd.OwnerID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.DeleteWebhook:OwnerID")
+	}
+
+	if !validate.UUID(cmd.WebhookID) {
+		return apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.DeleteWebhook:WebhookID")
+	}
+
+	return s.Commands.DeleteWebhook(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) PingWebhook(ctx context.Context, cmd *PingWebhookCommand) (*Delivery, error) {
+	if !validate.UUID(cmd.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.PingWebhook:OwnerID")
+	}
+
+	if !validate.UUID(cmd.WebhookID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.PingWebhook:WebhookID")
+	}
+
+	return s.Commands.PingWebhook(ctx, cmd)
+}
+
+func (s *CommandsSanitizer) Redeliver(ctx context.Context, cmd *RedeliverCommand) (*Delivery, error) {
+	if !validate.UUID(cmd.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.Redeliver:OwnerID")
+	}
+
+	if !validate.UUID(cmd.DeliveryID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.CommandsSanitizer.Redeliver:DeliveryID")
+	}
+
+	return s.Commands.Redeliver(ctx, cmd)
+}
@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/utils"
+	"strconv"
+	"time"
+)
+
+const (
+	// The attempts are spaced by a doubling delay, 8 attempts span about 4 hours
+	maxDeliveryAttempts = 8
+	minRetryDelay       = 2 * time.Minute
+	maxRetryDelay       = 2 * time.Hour
+
+	// Max. time of a delivery, the claim of a delivery lasts longer so it is not attempted twice at once
+	deliveryTimeout    = 10 * time.Second
+	deliveryClaimLease = time.Minute
+
+	// Deliveries claimed at once by DeliverWebhooks
+	deliveryBatchSize = 20
+
+	// The delivery log is kept this long
+	deliveryRetention = 30 * 24 * time.Hour
+
+	// Bytes of a response body kept in the log when the delivery fails
+	maxResponseErrorLen = 500
+
+	PingEventType = "webhook.ping"
+)
+
+// The headers of the requests, the receivers verify the signature with the secret of the webhook.
+const (
+	HeaderDeliveryID = "X-SkyVault-Delivery"
+	HeaderEvent      = "X-SkyVault-Event"
+	HeaderTimestamp  = "X-SkyVault-Timestamp"
+	HeaderSignature  = "X-SkyVault-Signature"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSucceeded DeliveryStatus = "succeeded"
+	DeliveryStatusFailed    DeliveryStatus = "failed" // All the attempts failed
+)
+
+// Delivery is an event to post to a webhook, queued by the repository in the transaction of the event.
+type Delivery struct {
+	ID             string
+	WebhookID      string
+	OwnerID        string
+	EventSeq       *int64 // nil for the pings
+	EventType      string
+	Payload        string // JSON object, the data of the event
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastAttemptAt  *time.Time
+	ResponseStatus *int
+	LastError      *string
+	CreatedAt      time.Time
+}
+
+func newDelivery(webhookID, ownerID string, eventSeq *int64, eventType, payload string) (*Delivery, error) {
+	id, err := utils.ID()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.newDelivery:ID")
+	}
+
+	now := time.Now().UTC()
+	return &Delivery{
+		ID:            id,
+		WebhookID:     webhookID,
+		OwnerID:       ownerID,
+		EventSeq:      eventSeq,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, nil
+}
+
+// newPingDelivery is a test delivery to check the receiver.
+func newPingDelivery(webhook *Webhook) (*Delivery, error) {
+	payload, err := json.Marshal(map[string]any{"webhookId": webhook.ID})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.newPingDelivery:Marshal")
+	}
+
+	return newDelivery(webhook.ID, webhook.OwnerID, nil, PingEventType, string(payload))
+}
+
+// newRedelivery is a new delivery of the event of the delivery, the log of the delivery is kept as is.
+func newRedelivery(d *Delivery) (*Delivery, error) {
+	return newDelivery(d.WebhookID, d.OwnerID, d.EventSeq, d.EventType, d.Payload)
+}
+
+// deliveryBody is the body of the requests
+type deliveryBody struct {
+	ID        string          `json:"id"` // The ID of the delivery, a redelivery has a new ID
+	Type      string          `json:"type"`
+	Seq       *int64          `json:"seq,omitempty"` // The sequence of the event, the same for the redeliveries
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Request returns the body and the headers of the request of the delivery, signed with the secret at the time.
+func (d *Delivery) Request(secret string, now time.Time) ([]byte, map[string]string, error) {
+	body, err := json.Marshal(&deliveryBody{
+		ID:        d.ID,
+		Type:      d.EventType,
+		Seq:       d.EventSeq,
+		CreatedAt: d.CreatedAt,
+		Data:      json.RawMessage(d.Payload),
+	})
+	if err != nil {
+		return nil, nil, apperror.NewAppError(err, "webhook.Delivery.Request:Marshal")
+	}
+
+	timestamp := now.Unix()
+	headers := map[string]string{
+		"Content-Type":   "application/json",
+		HeaderDeliveryID: d.ID,
+		HeaderEvent:      d.EventType,
+		HeaderTimestamp:  strconv.FormatInt(timestamp, 10),
+		HeaderSignature:  Sign(secret, timestamp, body),
+	}
+
+	return body, headers, nil
+}
+
+// Sign returns the signature of the body, the HMAC-SHA256 of "<timestamp>.<body>" with the secret.
+// The receivers compute it the same way and compare it to the HeaderSignature, the timestamp is the
+// HeaderTimestamp, they should reject the old ones against replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// recordAttempt records the result of an attempt, the status of the response or the error of the request.
+// A 2xx response succeeds, a failed attempt is retried later until the attempts are exhausted.
+func (d *Delivery) recordAttempt(now time.Time, responseStatus int, responseErr error) {
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.ResponseStatus = nil
+	d.LastError = nil
+	if responseStatus != 0 {
+		d.ResponseStatus = &responseStatus
+	}
+
+	if responseErr == nil && responseStatus >= 200 && responseStatus < 300 {
+		d.Status = DeliveryStatusSucceeded
+		return
+	}
+
+	if responseErr != nil {
+		msg := responseErr.Error()
+		if len(msg) > maxResponseErrorLen {
+			msg = msg[:maxResponseErrorLen]
+		}
+		d.LastError = &msg
+	}
+
+	if d.Attempts >= maxDeliveryAttempts {
+		d.Status = DeliveryStatusFailed
+		return
+	}
+
+	d.Status = DeliveryStatusPending
+	d.NextAttemptAt = now.Add(retryDelay(d.Attempts))
+}
+
+// retryDelay is the delay after the failed attempts, doubling from minRetryDelay up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
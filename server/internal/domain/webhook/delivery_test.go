@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryRequest(t *testing.T) {
+	t.Parallel()
+	seq := int64(42)
+	d, err := newDelivery("webhook", "owner", &seq, "file.created", `{"itemId":"file"}`)
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	body, headers, err := d.Request("secret", now)
+	require.NoError(t, err)
+
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(body, &res))
+	assert.Equal(t, d.ID, res["id"])
+	assert.Equal(t, "file.created", res["type"])
+	assert.EqualValues(t, 42, res["seq"])
+	assert.Equal(t, map[string]any{"itemId": "file"}, res["data"])
+
+	assert.Equal(t, d.ID, headers[HeaderDeliveryID])
+	assert.Equal(t, "file.created", headers[HeaderEvent])
+	assert.Equal(t, "1700000000", headers[HeaderTimestamp])
+
+	// As a receiver verifies it
+	timestamp, err := strconv.ParseInt(headers[HeaderTimestamp], 10, 64)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(headers[HeaderTimestamp] + "."))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), headers[HeaderSignature])
+	assert.Equal(t, headers[HeaderSignature], Sign("secret", timestamp, body))
+	assert.NotEqual(t, headers[HeaderSignature], Sign("other", timestamp, body))
+}
+
+func TestRecordAttempt(t *testing.T) {
+	t.Parallel()
+	now := time.Now().UTC()
+
+	t.Run("succeeded", func(t *testing.T) {
+		t.Parallel()
+		d := &Delivery{Status: DeliveryStatusPending}
+		d.recordAttempt(now, 204, nil)
+		assert.Equal(t, DeliveryStatusSucceeded, d.Status)
+		assert.Equal(t, 1, d.Attempts)
+		require.NotNil(t, d.ResponseStatus)
+		assert.Equal(t, 204, *d.ResponseStatus)
+		assert.Nil(t, d.LastError)
+	})
+
+	t.Run("retried until failed", func(t *testing.T) {
+		t.Parallel()
+		d := &Delivery{Status: DeliveryStatusPending}
+		for i := 1; i < maxDeliveryAttempts; i++ {
+			d.recordAttempt(now, 500, errors.New("internal server error"))
+			assert.Equal(t, DeliveryStatusPending, d.Status)
+			assert.Equal(t, now.Add(retryDelay(i)), d.NextAttemptAt)
+		}
+
+		d.recordAttempt(now, 0, errors.New("connection refused"))
+		assert.Equal(t, DeliveryStatusFailed, d.Status)
+		assert.Equal(t, maxDeliveryAttempts, d.Attempts)
+		assert.Nil(t, d.ResponseStatus)
+		require.NotNil(t, d.LastError)
+		assert.Equal(t, "connection refused", *d.LastError)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, minRetryDelay, retryDelay(1))
+	assert.Equal(t, 2*minRetryDelay, retryDelay(2))
+	assert.Equal(t, 4*minRetryDelay, retryDelay(3))
+	assert.Equal(t, maxRetryDelay, retryDelay(20))
+}
+
+func TestNewRedelivery(t *testing.T) {
+	t.Parallel()
+	seq := int64(7)
+	d, err := newDelivery("webhook", "owner", &seq, "share.accessed", `{}`)
+	require.NoError(t, err)
+	d.recordAttempt(time.Now().UTC(), 0, errors.New("timeout"))
+
+	r, err := newRedelivery(d)
+	require.NoError(t, err)
+	assert.NotEqual(t, d.ID, r.ID)
+	assert.Equal(t, d.EventSeq, r.EventSeq)
+	assert.Equal(t, DeliveryStatusPending, r.Status)
+	assert.Zero(t, r.Attempts)
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"context"
+	"skyvault/pkg/paging"
+)
+
+type Queries interface {
+	// App Errors:
+	// - ErrCommonInvalidValue
+	GetWebhooks(ctx context.Context, query *GetWebhooksQuery) ([]*Webhook, error)
+
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GetWebhook(ctx context.Context, query *GetWebhookQuery) (*Webhook, error)
+
+	// GetDeliveries returns the delivery log of the webhook, the latest first by default.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	// - ErrCommonNoData
+	// - ErrCommonNoAccess
+	GetDeliveries(ctx context.Context, query *GetDeliveriesQuery) (*paging.Page[*Delivery], error)
+}
+
+type GetWebhooksQuery struct {
+	OwnerID string
+}
+
+type GetWebhookQuery struct {
+	OwnerID   string
+	WebhookID string
+}
+
+type GetDeliveriesQuery struct {
+	OwnerID   string
+	WebhookID string
+	PagingOpt *paging.Options
+}
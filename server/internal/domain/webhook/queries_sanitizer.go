@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/paging"
+	"skyvault/pkg/validate"
+)
+
+var _ Queries = (*QueriesSanitizer)(nil)
+
+type QueriesSanitizer struct {
+	Queries
+}
+
+func NewQueriesSanitizer(queries Queries) Queries {
+	return &QueriesSanitizer{Queries: queries}
+}
+
+func (s *QueriesSanitizer) GetWebhooks(ctx context.Context, query *GetWebhooksQuery) ([]*Webhook, error) {
+	if !validate.UUID(query.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.QueriesSanitizer.GetWebhooks:OwnerID")
+	}
+
+	return s.Queries.GetWebhooks(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetWebhook(ctx context.Context, query *GetWebhookQuery) (*Webhook, error) {
+	if !validate.UUID(query.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.QueriesSanitizer.GetWebhook:OwnerID")
+	}
+
+	if !validate.UUID(query.WebhookID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.QueriesSanitizer.GetWebhook:WebhookID")
+	}
+
+	return s.Queries.GetWebhook(ctx, query)
+}
+
+func (s *QueriesSanitizer) GetDeliveries(ctx context.Context, query *GetDeliveriesQuery) (*paging.Page[*Delivery], error) {
+	if !validate.UUID(query.OwnerID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.QueriesSanitizer.GetDeliveries:OwnerID")
+	}
+
+	if !validate.UUID(query.WebhookID) {
+		return nil, apperror.NewAppError(apperror.ErrCommonInvalidValue, "webhook.QueriesSanitizer.GetDeliveries:WebhookID")
+	}
+
+	if query.PagingOpt == nil {
+		query.PagingOpt = &paging.Options{}
+	}
+
+	return s.Queries.GetDeliveries(ctx, query)
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/paging"
+)
+
+var _ Queries = (*QueryHandlers)(nil)
+
+type QueryHandlers struct {
+	repository Repository
+}
+
+func NewQueryHandlers(repository Repository) Queries {
+	return &QueryHandlers{repository: repository}
+}
+
+func (h *QueryHandlers) GetWebhooks(ctx context.Context, query *GetWebhooksQuery) ([]*Webhook, error) {
+	webhooks, err := h.repository.GetWebhooks(ctx, query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.QueryHandlers.GetWebhooks:GetWebhooks")
+	}
+
+	return webhooks, nil
+}
+
+func (h *QueryHandlers) GetWebhook(ctx context.Context, query *GetWebhookQuery) (*Webhook, error) {
+	webhook, err := h.repository.GetWebhook(ctx, query.WebhookID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.QueryHandlers.GetWebhook:GetWebhook")
+	}
+
+	err = webhook.ValidateAccess(query.OwnerID)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.QueryHandlers.GetWebhook:ValidateAccess")
+	}
+
+	return webhook, nil
+}
+
+func (h *QueryHandlers) GetDeliveries(ctx context.Context, query *GetDeliveriesQuery) (*paging.Page[*Delivery], error) {
+	webhook, err := h.GetWebhook(ctx, &GetWebhookQuery{OwnerID: query.OwnerID, WebhookID: query.WebhookID})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.QueryHandlers.GetDeliveries:GetWebhook")
+	}
+
+	page, err := h.repository.GetDeliveries(ctx, webhook.ID, query.PagingOpt)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.QueryHandlers.GetDeliveries:GetDeliveries")
+	}
+
+	return page, nil
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"skyvault/internal/domain/internal"
+	"skyvault/pkg/paging"
+	"time"
+)
+
+type Repository interface {
+	internal.RepositoryTx[Repository]
+
+	//--------------------------------
+	// Webhooks
+	//--------------------------------
+
+	CreateWebhook(ctx context.Context, webhook *Webhook) (*Webhook, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetWebhook(ctx context.Context, webhookID string) (*Webhook, error)
+
+	GetWebhooks(ctx context.Context, ownerID string) ([]*Webhook, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	UpdateWebhook(ctx context.Context, webhook *Webhook) error
+
+	// App Errors:
+	// - ErrCommonNoData
+	DeleteWebhook(ctx context.Context, webhookID string) error
+
+	//--------------------------------
+	// Deliveries
+	//--------------------------------
+
+	// The deliveries of the events are queued by the repository itself, in the transaction of every event.
+
+	CreateDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error)
+
+	// App Errors:
+	// - ErrCommonNoData
+	GetDelivery(ctx context.Context, deliveryID string) (*Delivery, error)
+
+	// GetDeliveries returns the deliveries of the webhook, sorted by creation.
+	//
+	// App Errors:
+	// - ErrCommonInvalidValue
+	GetDeliveries(ctx context.Context, webhookID string, pagingOpt *paging.Options) (*paging.Page[*Delivery], error)
+
+	// ClaimDeliveries returns the pending deliveries due at now, of the enabled webhooks, at most limit.
+	// They are postponed by the lease, so that the other servers don't claim them while they are attempted.
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+
+	// UpdateDelivery saves the result of an attempt.
+	//
+	// App Errors:
+	// - ErrCommonNoData
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+
+	// DeleteDeliveries deletes the deliveries created before, of all the owners.
+	// Returns the number of deleted deliveries.
+	DeleteDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,10 @@
+package webhook
+
+import "context"
+
+// Sender posts the requests of the deliveries.
+type Sender interface {
+	// Send posts the body to the URL, it returns the status of the response.
+	// The error is set for the failed requests and the non-2xx responses, without their body.
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (status int, err error)
+}
@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"skyvault/internal/domain/event"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/utils"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	webhookSecretBytes  = 32
+	webhookSecretPrefix = "whsec_"
+
+	maxURLLen     = 2048
+	maxEventTypes = 50
+)
+
+// Webhook posts the events of the owner to the URL, signed with the secret.
+type Webhook struct {
+	ID         string
+	OwnerID    string
+	URL        string
+	Secret     string
+	EventTypes []string // The types of the events, or patterns like "file.*", empty for all the events
+	FolderID   *string  // Only the events of the folder and its descendants, nil for all the events
+	Enabled    bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewWebhook(ownerID, url string, eventTypes []string, folderID *string) (*Webhook, error) {
+	id, err := utils.ID()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.NewWebhook:ID")
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, apperror.NewAppError(err, "webhook.NewWebhook:newSecret")
+	}
+
+	now := time.Now().UTC()
+	return &Webhook{
+		ID:         id,
+		OwnerID:    ownerID,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		FolderID:   folderID,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
+
+// App Errors:
+// - ErrCommonNoAccess
+func (w *Webhook) ValidateAccess(accessedByID string) error {
+	if w.OwnerID != accessedByID {
+		return apperror.ErrCommonNoAccess
+	}
+	return nil
+}
+
+// RotateSecret replaces the secret, the receivers must verify the signatures with the new one.
+func (w *Webhook) RotateSecret() error {
+	secret, err := newSecret()
+	if err != nil {
+		return apperror.NewAppError(err, "webhook.Webhook.RotateSecret:newSecret")
+	}
+
+	w.Secret = secret
+	w.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// validateURL accepts the http and https URLs. The private addresses are rejected by the Sender
+// once resolved, unless allowed to test a local receiver.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+func validateURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" || len(rawURL) > maxURLLen {
+		return "", fmt.Errorf("%w: url is empty or too long", apperror.ErrCommonInvalidValue)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid url: %w", apperror.ErrCommonInvalidValue, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: url must be http or https", apperror.ErrCommonInvalidValue)
+	}
+
+	if u.User != nil {
+		return "", fmt.Errorf("%w: url must not have credentials", apperror.ErrCommonInvalidValue)
+	}
+
+	return u.String(), nil
+}
+
+// validateEventTypes accepts the types of event.Types and the patterns of their prefix, e.g. "file.*".
+// The duplicates are removed, nil is no filter.
+//
+// App Errors:
+// - ErrCommonInvalidValue
+func validateEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) > maxEventTypes {
+		return nil, fmt.Errorf("%w: more than %d event types", apperror.ErrCommonInvalidValue, maxEventTypes)
+	}
+
+	res := []string{}
+	for _, t := range eventTypes {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !isEventType(t) {
+			return nil, fmt.Errorf("%w: unknown event type %q", apperror.ErrCommonInvalidValue, t)
+		}
+
+		if !slices.Contains(res, t) {
+			res = append(res, t)
+		}
+	}
+
+	return res, nil
+}
+
+func isEventType(t string) bool {
+	if prefix, ok := strings.CutSuffix(t, ".*"); ok {
+		return slices.ContainsFunc(event.Types, func(et string) bool {
+			return strings.HasPrefix(et, prefix+".")
+		})
+	}
+
+	return slices.Contains(event.Types, t)
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"fmt"
 	"skyvault/internal/infrastructure/internal/authinfra"
+	"skyvault/internal/infrastructure/internal/eventinfra"
 	"skyvault/internal/infrastructure/internal/repository"
+	"skyvault/internal/infrastructure/internal/scanner"
 	"skyvault/internal/infrastructure/internal/storage"
+	"skyvault/internal/infrastructure/internal/webhookinfra"
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
+	"skyvault/pkg/eventbus"
+	"skyvault/pkg/jobs"
 	"time"
 )
 
@@ -15,7 +20,12 @@ type Infrastructure struct {
 	app        *appconfig.App
 	Repository *repository.Repository
 	Storage    *storage.Storage
+	Scanner    *scanner.Scanner
 	Auth       *authinfra.AuthInfra
+	Events     *eventinfra.EventInfra
+	Webhooks   *webhookinfra.WebhookInfra
+	Jobs       *jobs.WorkerPool
+	Bus        *eventbus.Bus // Subscribed by bootstrap.InitSubscribers, fed by the outbox
 }
 
 // NewInfrastructure initializes all infrastructure components
@@ -26,7 +36,17 @@ func NewInfrastructure(app *appconfig.App) *Infrastructure {
 
 	instance.Repository = repository.NewRepository(app)
 	instance.Storage = storage.NewStorage(app)
+	instance.Scanner = scanner.NewScanner(app)
 	instance.Auth = authinfra.NewAuthInfra(app)
+	instance.Events = eventinfra.NewEventInfra(app)
+	instance.Webhooks = webhookinfra.NewWebhookInfra(app)
+	instance.Bus = eventbus.NewBus()
+	instance.Jobs = jobs.NewWorkerPool(jobs.Config{
+		Workers:    app.Config.Jobs.Workers,
+		QueueSize:  app.Config.Jobs.QueueSize,
+		JobTimeout: time.Duration(app.Config.Jobs.TimeoutSec) * time.Second,
+	}, app.Logger)
+	instance.Jobs.Start()
 
 	return instance
 }
@@ -39,6 +59,21 @@ func (i *Infrastructure) Cleanup(ctx context.Context) error {
 
 	var finalErr error
 
+	// Stop the jobs first, since they use the other components
+	if err := i.Jobs.Stop(ctx); err != nil {
+		finalErr = apperror.NewAppError(err, "i.Cleanup:jobs.Stop")
+	}
+
+	// Stop listening before the database goes away
+	if err := i.Events.Cleanup(); err != nil {
+		err = apperror.NewAppError(err, "i.Cleanup:events.Cleanup")
+		if finalErr == nil {
+			finalErr = err
+		} else {
+			finalErr = fmt.Errorf("%w: %w", finalErr, err)
+		}
+	}
+
 	// Run cleanup in parallel
 	errChan := make(chan error, 2)
 	go func() {
@@ -85,7 +120,7 @@ func (i *Infrastructure) Health(ctx context.Context) error {
 	var finalErr error
 
 	// Run health checks in parallel
-	errChan := make(chan error, 2)
+	errChan := make(chan error, 3)
 	go func() {
 		if err := i.Repository.Health(ctx); err != nil {
 			errChan <- apperror.NewAppError(err, "Infrastructure.Health:repository.Health")
@@ -102,6 +137,14 @@ func (i *Infrastructure) Health(ctx context.Context) error {
 		errChan <- nil
 	}()
 
+	go func() {
+		if err := i.Scanner.Health(ctx); err != nil {
+			errChan <- apperror.NewAppError(err, "Infrastructure.Health:scanner.Health")
+			return
+		}
+		errChan <- nil
+	}()
+
 	// Collect errors
 	for i := 0; i < len(errChan); i++ {
 		select {
@@ -24,6 +24,7 @@ var _ auth.Claims = (*Claims)(nil)
 
 type Claims struct {
 	ProfileID string `json:"profileId"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -31,6 +32,10 @@ func (c *Claims) GetProfileID() string {
 	return c.ProfileID
 }
 
+func (c *Claims) GetSessionID() string {
+	return c.SessionID
+}
+
 type Config struct {
 	TokenTimeoutMin int
 	Key             []byte
@@ -46,12 +51,13 @@ func NewJWTAuth(cfg Config) *JWTAuth {
 	return &JWTAuth{cfg: cfg}
 }
 
-func (a *JWTAuth) GenerateToken(ctx context.Context, profileID string) (string, error) {
+func (a *JWTAuth) GenerateToken(ctx context.Context, profileID, sessionID string) (string, error) {
 	now := time.Now().UTC()
 	expirationTime := time.Duration(a.cfg.TokenTimeoutMin) * time.Minute
 
 	claims := &Claims{
 		ProfileID: profileID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    issuer,
 			Audience:  []string{audience},
@@ -94,6 +100,10 @@ func (a *JWTAuth) ValidateToken(ctx context.Context, tokenStr string) (auth.Clai
 	if !token.Valid {
 		return nil, apperror.NewAppError(apperror.ErrAuthInvalidToken, "JWTAuth.ValidateToken:InvalidToken")
 	}
+	// Tokens issued before the sessions can't be revoked
+	if claims.SessionID == "" {
+		return nil, apperror.NewAppError(apperror.ErrAuthInvalidToken, "JWTAuth.ValidateToken:SessionID")
+	}
 	return claims, nil
 }
 
@@ -0,0 +1,23 @@
+package eventinfra
+
+import (
+	"skyvault/internal/domain/event"
+	"skyvault/pkg/appconfig"
+)
+
+type EventInfra struct {
+	Notifier event.Notifier
+	pg       *PGNotifier
+}
+
+// NewEventInfra listens to the event notifications of Postgres, once the first stream subscribes.
+func NewEventInfra(app *appconfig.App) *EventInfra {
+	pg := NewPGNotifier(app.Config.DB.DSN, app.Logger)
+	return &EventInfra{Notifier: pg, pg: pg}
+}
+
+// Cleanup stops listening, the open streams stop receiving the new events
+func (e *EventInfra) Cleanup() error {
+	e.pg.Stop()
+	return nil
+}
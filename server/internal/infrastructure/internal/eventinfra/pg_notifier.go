@@ -0,0 +1,177 @@
+package eventinfra
+
+import (
+	"context"
+	"skyvault/internal/domain/event"
+	"skyvault/pkg/applog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var _ event.Notifier = (*PGNotifier)(nil)
+
+// pgChannel is notified by the event triggers with the owner ID, see the event migration
+const pgChannel = "skyvault_event"
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// PGNotifier fans out the notifications of Postgres to the subscribed streams of this server.
+// Every server listens on its own connection, so the events reach the streams of all the servers.
+type PGNotifier struct {
+	dsn    string
+	logger applog.Logger
+
+	mu      sync.Mutex
+	subs    map[string]map[chan struct{}]struct{} // By owner ID
+	stopped bool
+
+	startOnce sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func NewPGNotifier(dsn string, logger applog.Logger) *PGNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PGNotifier{
+		dsn:    dsn,
+		logger: logger.With().Str("where", "PGNotifier").Logger(),
+		subs:   map[string]map[chan struct{}]struct{}{},
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+func (n *PGNotifier) Subscribe(ownerID string) (<-chan struct{}, func()) {
+	n.startOnce.Do(func() {
+		go n.run()
+	})
+
+	// A pending signal is enough, the stream reads all the new events at once
+	signal := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	if n.stopped {
+		n.mu.Unlock()
+		close(signal)
+		return signal, func() {}
+	}
+	if n.subs[ownerID] == nil {
+		n.subs[ownerID] = map[chan struct{}]struct{}{}
+	}
+	n.subs[ownerID][signal] = struct{}{}
+	n.mu.Unlock()
+
+	unsubscribe := func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		delete(n.subs[ownerID], signal)
+		if len(n.subs[ownerID]) == 0 {
+			delete(n.subs, ownerID)
+		}
+	}
+
+	return signal, unsubscribe
+}
+
+// Stop stops listening and closes the signals, so that the streams end before the server shuts down.
+func (n *PGNotifier) Stop() {
+	n.mu.Lock()
+	n.stopped = true
+	for _, signals := range n.subs {
+		for signal := range signals {
+			close(signal)
+		}
+	}
+	n.subs = map[string]map[chan struct{}]struct{}{}
+	n.mu.Unlock()
+
+	n.cancel()
+	started := true
+	n.startOnce.Do(func() {
+		started = false
+	})
+	if started {
+		<-n.done
+	}
+}
+
+func (n *PGNotifier) notify(ownerID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for signal := range n.subs[ownerID] {
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// notifyAll wakes up all the streams, for the notifications missed while not listening
+func (n *PGNotifier) notifyAll() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, signals := range n.subs {
+		for signal := range signals {
+			select {
+			case signal <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (n *PGNotifier) run() {
+	defer close(n.done)
+
+	delay := minReconnectDelay
+	for {
+		listening, err := n.listen()
+		if n.ctx.Err() != nil {
+			return
+		}
+
+		if listening {
+			delay = minReconnectDelay
+		}
+		n.logger.Warn().Err(err).Str("retry_in", delay.String()).Msg("stopped listening to the events, reconnecting")
+
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// listen returns when the connection fails, listening is whether it got to listen before
+func (n *PGNotifier) listen() (listening bool, err error) {
+	conn, err := pgx.Connect(n.ctx, n.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(n.ctx, "listen "+pgChannel)
+	if err != nil {
+		return false, err
+	}
+	n.notifyAll()
+
+	for {
+		notification, err := conn.WaitForNotification(n.ctx)
+		if err != nil {
+			return true, err
+		}
+		n.notify(notification.Payload)
+	}
+}
@@ -0,0 +1,83 @@
+package eventinfra
+
+import (
+	"testing"
+
+	"skyvault/pkg/applog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNotifier() *PGNotifier {
+	n := NewPGNotifier("", applog.NewLogger(&applog.Config{Level: "disabled"}))
+	// Subscribing doesn't start listening in the tests
+	n.startOnce.Do(func() { close(n.done) })
+	return n
+}
+
+func signaled(signal <-chan struct{}) bool {
+	select {
+	case <-signal:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestPGNotifierNotify(t *testing.T) {
+	t.Parallel()
+	n := newTestNotifier()
+	defer n.Stop()
+
+	a1, unsubscribeA1 := n.Subscribe("a")
+	a2, unsubscribeA2 := n.Subscribe("a")
+	b, unsubscribeB := n.Subscribe("b")
+	defer unsubscribeA2()
+	defer unsubscribeB()
+
+	n.notify("a")
+	n.notify("a") // Coalesced with the pending signal
+	assert.True(t, signaled(a1))
+	assert.False(t, signaled(a1))
+	assert.True(t, signaled(a2))
+	assert.False(t, signaled(b))
+
+	unsubscribeA1()
+	n.notify("a")
+	assert.False(t, signaled(a1))
+	assert.True(t, signaled(a2))
+
+	n.notifyAll()
+	assert.True(t, signaled(a2))
+	assert.True(t, signaled(b))
+}
+
+func TestPGNotifierUnsubscribe(t *testing.T) {
+	t.Parallel()
+	n := newTestNotifier()
+	defer n.Stop()
+
+	_, unsubscribe := n.Subscribe("a")
+	unsubscribe()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	assert.Empty(t, n.subs)
+}
+
+func TestPGNotifierStop(t *testing.T) {
+	t.Parallel()
+	n := newTestNotifier()
+
+	signal, unsubscribe := n.Subscribe("a")
+	n.Stop()
+	unsubscribe()
+
+	_, ok := <-signal
+	assert.False(t, ok, "closed")
+
+	signal, unsubscribe = n.Subscribe("a")
+	defer unsubscribe()
+	_, ok = <-signal
+	assert.False(t, ok, "closed once stopped")
+}
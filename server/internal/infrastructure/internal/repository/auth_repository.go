@@ -4,11 +4,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"skyvault/internal/domain/auth"
 	"skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/model"
 	. "skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/table"
 	"skyvault/pkg/apperror"
+	"skyvault/pkg/eventbus"
 
 	. "github.com/go-jet/jet/v2/postgres"
 	"github.com/jinzhu/copier"
@@ -32,6 +34,10 @@ func (r *AuthRepository) WithTx(ctx context.Context, tx *sql.Tx) auth.Repository
 	return &AuthRepository{repository: r.repository.withTx(ctx, tx)}
 }
 
+func (r *AuthRepository) AddDomainEvents(ctx context.Context, events ...eventbus.Event) error {
+	return r.repository.addDomainEvents(ctx, events)
+}
+
 func (r *AuthRepository) Create(ctx context.Context, au *auth.Auth) (*auth.Auth, error) {
 	dbModel := new(model.Auth)
 	err := copier.Copy(dbModel, au)
@@ -94,3 +100,172 @@ func (r *AuthRepository) Delete(ctx context.Context, id string) error {
 
 	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
 }
+
+//--------------------------------
+// App Passwords
+//--------------------------------
+
+func (r *AuthRepository) CreateAppPassword(ctx context.Context, appPassword *auth.AppPassword) (*auth.AppPassword, error) {
+	dbModel := new(model.AppPassword)
+	err := copier.Copy(dbModel, appPassword)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateAppPassword:copier.Copy")
+	}
+
+	stmt := AppPassword.INSERT(
+		AppPassword.AllColumns,
+	).MODEL(dbModel).RETURNING(AppPassword.AllColumns)
+
+	return runInsert[model.AppPassword, auth.AppPassword](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) GetAppPassword(ctx context.Context, id string) (*auth.AppPassword, error) {
+	stmt := SELECT(AppPassword.AllColumns).
+		FROM(AppPassword).
+		WHERE(AppPassword.ID.EQ(UUID(UUIDStr(id))))
+
+	return runSelect[model.AppPassword, auth.AppPassword](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) GetAppPasswordBySecretHash(ctx context.Context, secretHash string) (*auth.AppPassword, error) {
+	stmt := SELECT(AppPassword.AllColumns).
+		FROM(AppPassword).
+		WHERE(AppPassword.SecretHash.EQ(String(secretHash)))
+
+	return runSelect[model.AppPassword, auth.AppPassword](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) GetAppPasswords(ctx context.Context, profileID string) ([]*auth.AppPassword, error) {
+	stmt := SELECT(AppPassword.AllColumns).
+		FROM(AppPassword).
+		WHERE(AppPassword.ProfileID.EQ(UUID(UUIDStr(profileID)))).
+		ORDER_BY(AppPassword.CreatedAt.DESC())
+
+	return runSelectSliceAll[model.AppPassword, auth.AppPassword](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) DeleteAppPassword(ctx context.Context, id string) error {
+	stmt := AppPassword.DELETE().
+		WHERE(AppPassword.ID.EQ(UUID(UUIDStr(id))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+//--------------------------------
+// Sessions
+//--------------------------------
+
+func (r *AuthRepository) CreateSession(ctx context.Context, session *auth.Session) (*auth.Session, error) {
+	dbModel := new(model.AuthSession)
+	err := copier.Copy(dbModel, session)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateSession:copier.Copy")
+	}
+
+	stmt := AuthSession.INSERT(
+		AuthSession.AllColumns,
+	).MODEL(dbModel).RETURNING(AuthSession.AllColumns)
+
+	return runInsert[model.AuthSession, auth.Session](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) GetSession(ctx context.Context, id string) (*auth.Session, error) {
+	stmt := SELECT(AuthSession.AllColumns).
+		FROM(AuthSession).
+		WHERE(AuthSession.ID.EQ(UUID(UUIDStr(id))))
+
+	return runSelect[model.AuthSession, auth.Session](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) GetActiveSessions(ctx context.Context, profileID string, now time.Time) ([]*auth.Session, error) {
+	stmt := SELECT(AuthSession.AllColumns).
+		FROM(AuthSession).
+		WHERE(
+			AuthSession.ProfileID.EQ(UUID(UUIDStr(profileID))).
+				AND(AuthSession.RevokedAt.IS_NULL()).
+				AND(AuthSession.ExpiresAt.GT(TimestampT(now))),
+		).
+		ORDER_BY(AuthSession.LastSeenAt.DESC())
+
+	return runSelectSliceAll[model.AuthSession, auth.Session](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) RotateSession(ctx context.Context, session *auth.Session) error {
+	stmt := AuthSession.UPDATE(
+		AuthSession.RefreshTokenHash,
+		AuthSession.PreviousRefreshTokenHash,
+		AuthSession.ExpiresAt,
+		AuthSession.LastSeenAt,
+		AuthSession.UpdatedAt,
+	).SET(
+		String(session.RefreshTokenHash),
+		String(session.PreviousRefreshTokenHash),
+		TimestampT(session.ExpiresAt),
+		TimestampT(session.LastSeenAt),
+		TimestampT(session.UpdatedAt),
+	).WHERE(
+		AuthSession.ID.EQ(UUID(UUIDStr(session.ID))).
+			AND(AuthSession.RefreshTokenHash.EQ(String(session.PreviousRefreshTokenHash))).
+			AND(AuthSession.RevokedAt.IS_NULL()),
+	)
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) UpdateSession(ctx context.Context, session *auth.Session) error {
+	dbModel := new(model.AuthSession)
+	err := copier.Copy(dbModel, session)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UpdateSession:copier.Copy")
+	}
+
+	stmt := AuthSession.UPDATE(AuthSession.MutableColumns).
+		MODEL(dbModel).
+		WHERE(AuthSession.ID.EQ(UUID(UUIDStr(session.ID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) TouchSession(ctx context.Context, id string, lastSeenAt time.Time) error {
+	stmt := AuthSession.UPDATE(AuthSession.LastSeenAt).
+		SET(TimestampT(lastSeenAt)).
+		WHERE(AuthSession.ID.EQ(UUID(UUIDStr(id))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) RevokeSessions(ctx context.Context, profileID, exceptSessionID string, revokedAt time.Time) ([]*auth.Session, error) {
+	condition := AuthSession.ProfileID.EQ(UUID(UUIDStr(profileID))).
+		AND(AuthSession.RevokedAt.IS_NULL()).
+		AND(AuthSession.ExpiresAt.GT(TimestampT(revokedAt)))
+	if exceptSessionID != "" {
+		condition = condition.AND(AuthSession.ID.NOT_EQ(UUID(UUIDStr(exceptSessionID))))
+	}
+
+	stmt := AuthSession.UPDATE(AuthSession.RevokedAt, AuthSession.UpdatedAt).
+		SET(TimestampT(revokedAt), TimestampT(revokedAt)).
+		WHERE(condition).
+		RETURNING(AuthSession.AllColumns)
+
+	return runSelectSliceAll[model.AuthSession, auth.Session](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *AuthRepository) DeleteSessions(ctx context.Context, before time.Time) (int64, error) {
+	stmt := AuthSession.DELETE().
+		WHERE(
+			AuthSession.ExpiresAt.LT(TimestampT(before)).
+				OR(AuthSession.RevokedAt.LT(TimestampT(before))),
+		)
+
+	res, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteSessions:ExecContext")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteSessions:RowsAffected")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,97 @@
+//lint:file-ignore ST1001 Using dot import to make SQL queries more readable
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"skyvault/internal/domain/event"
+	"skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/model"
+	. "skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/table"
+	"skyvault/pkg/apperror"
+
+	. "github.com/go-jet/jet/v2/postgres"
+)
+
+var _ event.Repository = (*EventRepository)(nil)
+
+// EventRepository reads the events recorded by the triggers, see the event migration
+type EventRepository struct {
+	repository *Repository
+}
+
+func NewEventRepository(repo *Repository) *EventRepository {
+	return &EventRepository{repository: repo}
+}
+
+func (r *EventRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.repository.db.BeginTx(ctx, nil)
+}
+
+func (r *EventRepository) WithTx(ctx context.Context, tx *sql.Tx) event.Repository {
+	return &EventRepository{repository: r.repository.withTx(ctx, tx)}
+}
+
+func (r *EventRepository) AddEvent(ctx context.Context, ownerID, eventType, payload string) error {
+	// The function takes the sequence and notifies the streams, as for the recorded events
+	stmt := SELECT(
+		Raw("event_add(#owner_id::uuid, #type, #payload::jsonb)", RawArgs{"#owner_id": ownerID, "#type": eventType, "#payload": payload}),
+	)
+
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.AddEvent:ExecContext")
+	}
+
+	return nil
+}
+
+func (r *EventRepository) GetLastSeq(ctx context.Context, ownerID string) (int64, error) {
+	// The events take the sequence of the media change log
+	stmt := SELECT(ChangeLogState.LastSeq).
+		FROM(ChangeLogState).
+		WHERE(ChangeLogState.OwnerID.EQ(UUID(UUIDStr(ownerID))))
+
+	state, err := runSelect[model.ChangeLogState, model.ChangeLogState](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		// The state is created with the first event of the owner
+		if errors.Is(err, apperror.ErrCommonNoData) {
+			return 0, nil
+		}
+		return 0, apperror.NewAppError(err, "repository.GetLastSeq:runSelect")
+	}
+
+	return state.LastSeq, nil
+}
+
+func (r *EventRepository) GetEvents(ctx context.Context, ownerID string, afterSeq int64, limit int) ([]*event.Event, error) {
+	stmt := SELECT(Event.AllColumns).
+		FROM(Event).
+		WHERE(
+			Event.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+				AND(Event.Seq.GT(Int64(afterSeq))),
+		).
+		ORDER_BY(Event.Seq.ASC()).
+		LIMIT(int64(limit))
+
+	return runSelectSliceAll[model.Event, event.Event](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *EventRepository) DeleteEvents(ctx context.Context, before time.Time) (int64, error) {
+	stmt := Event.DELETE().
+		WHERE(Event.CreatedAt.LT(TimestampT(before)))
+
+	res, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteEvents:ExecContext")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteEvents:RowsAffected")
+	}
+
+	return count, nil
+}
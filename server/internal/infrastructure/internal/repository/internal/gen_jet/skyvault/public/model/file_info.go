@@ -13,16 +13,18 @@ import (
 )
 
 type FileInfo struct {
-	ID        uuid.UUID `sql:"primary_key"`
-	OwnerID   uuid.UUID
-	FolderID  *uuid.UUID
-	Name      string
-	Size      int64
-	Extension *string
-	MimeType  string
-	Category  *string
-	Preview   *[]byte
-	TrashedAt *time.Time
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID            uuid.UUID `sql:"primary_key"`
+	OwnerID       uuid.UUID
+	FolderID      *uuid.UUID
+	Name          string
+	Size          int64
+	Extension     *string
+	MimeType      string
+	Category      *string
+	TrashedAt     *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	PreviewStatus *string
+	ScanStatus    *string
+	ScanSignature *string
 }
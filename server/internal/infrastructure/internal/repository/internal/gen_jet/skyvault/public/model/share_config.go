@@ -13,13 +13,14 @@ import (
 )
 
 type ShareConfig struct {
-	ID           uuid.UUID `sql:"primary_key"`
-	OwnerID      uuid.UUID
-	FileID       *uuid.UUID
-	FolderID     *uuid.UUID
-	PasswordHash *string
-	MaxDownloads *int64
-	ExpiresAt    *time.Time
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID             uuid.UUID `sql:"primary_key"`
+	OwnerID        uuid.UUID
+	FileID         *uuid.UUID
+	FolderID       *uuid.UUID
+	PasswordHash   *string
+	MaxDownloads   *int64
+	ExpiresAt      *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DownloadsCount int64
 }
@@ -17,18 +17,20 @@ type fileInfoTable struct {
 	postgres.Table
 
 	// Columns
-	ID        postgres.ColumnString
-	OwnerID   postgres.ColumnString
-	FolderID  postgres.ColumnString
-	Name      postgres.ColumnString
-	Size      postgres.ColumnInteger
-	Extension postgres.ColumnString
-	MimeType  postgres.ColumnString
-	Category  postgres.ColumnString
-	Preview   postgres.ColumnString
-	TrashedAt postgres.ColumnTimestamp
-	CreatedAt postgres.ColumnTimestamp
-	UpdatedAt postgres.ColumnTimestamp
+	ID            postgres.ColumnString
+	OwnerID       postgres.ColumnString
+	FolderID      postgres.ColumnString
+	Name          postgres.ColumnString
+	Size          postgres.ColumnInteger
+	Extension     postgres.ColumnString
+	MimeType      postgres.ColumnString
+	Category      postgres.ColumnString
+	TrashedAt     postgres.ColumnTimestamp
+	CreatedAt     postgres.ColumnTimestamp
+	UpdatedAt     postgres.ColumnTimestamp
+	PreviewStatus postgres.ColumnString
+	ScanStatus    postgres.ColumnString
+	ScanSignature postgres.ColumnString
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -69,38 +71,42 @@ func newFileInfoTable(schemaName, tableName, alias string) *FileInfoTable {
 
 func newFileInfoTableImpl(schemaName, tableName, alias string) fileInfoTable {
 	var (
-		IDColumn        = postgres.StringColumn("id")
-		OwnerIDColumn   = postgres.StringColumn("owner_id")
-		FolderIDColumn  = postgres.StringColumn("folder_id")
-		NameColumn      = postgres.StringColumn("name")
-		SizeColumn      = postgres.IntegerColumn("size")
-		ExtensionColumn = postgres.StringColumn("extension")
-		MimeTypeColumn  = postgres.StringColumn("mime_type")
-		CategoryColumn  = postgres.StringColumn("category")
-		PreviewColumn   = postgres.StringColumn("preview")
-		TrashedAtColumn = postgres.TimestampColumn("trashed_at")
-		CreatedAtColumn = postgres.TimestampColumn("created_at")
-		UpdatedAtColumn = postgres.TimestampColumn("updated_at")
-		allColumns      = postgres.ColumnList{IDColumn, OwnerIDColumn, FolderIDColumn, NameColumn, SizeColumn, ExtensionColumn, MimeTypeColumn, CategoryColumn, PreviewColumn, TrashedAtColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns  = postgres.ColumnList{OwnerIDColumn, FolderIDColumn, NameColumn, SizeColumn, ExtensionColumn, MimeTypeColumn, CategoryColumn, PreviewColumn, TrashedAtColumn, CreatedAtColumn, UpdatedAtColumn}
+		IDColumn            = postgres.StringColumn("id")
+		OwnerIDColumn       = postgres.StringColumn("owner_id")
+		FolderIDColumn      = postgres.StringColumn("folder_id")
+		NameColumn          = postgres.StringColumn("name")
+		SizeColumn          = postgres.IntegerColumn("size")
+		ExtensionColumn     = postgres.StringColumn("extension")
+		MimeTypeColumn      = postgres.StringColumn("mime_type")
+		CategoryColumn      = postgres.StringColumn("category")
+		TrashedAtColumn     = postgres.TimestampColumn("trashed_at")
+		CreatedAtColumn     = postgres.TimestampColumn("created_at")
+		UpdatedAtColumn     = postgres.TimestampColumn("updated_at")
+		PreviewStatusColumn = postgres.StringColumn("preview_status")
+		ScanStatusColumn    = postgres.StringColumn("scan_status")
+		ScanSignatureColumn = postgres.StringColumn("scan_signature")
+		allColumns          = postgres.ColumnList{IDColumn, OwnerIDColumn, FolderIDColumn, NameColumn, SizeColumn, ExtensionColumn, MimeTypeColumn, CategoryColumn, TrashedAtColumn, CreatedAtColumn, UpdatedAtColumn, PreviewStatusColumn, ScanStatusColumn, ScanSignatureColumn}
+		mutableColumns      = postgres.ColumnList{OwnerIDColumn, FolderIDColumn, NameColumn, SizeColumn, ExtensionColumn, MimeTypeColumn, CategoryColumn, TrashedAtColumn, CreatedAtColumn, UpdatedAtColumn, PreviewStatusColumn, ScanStatusColumn, ScanSignatureColumn}
 	)
 
 	return fileInfoTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:        IDColumn,
-		OwnerID:   OwnerIDColumn,
-		FolderID:  FolderIDColumn,
-		Name:      NameColumn,
-		Size:      SizeColumn,
-		Extension: ExtensionColumn,
-		MimeType:  MimeTypeColumn,
-		Category:  CategoryColumn,
-		Preview:   PreviewColumn,
-		TrashedAt: TrashedAtColumn,
-		CreatedAt: CreatedAtColumn,
-		UpdatedAt: UpdatedAtColumn,
+		ID:            IDColumn,
+		OwnerID:       OwnerIDColumn,
+		FolderID:      FolderIDColumn,
+		Name:          NameColumn,
+		Size:          SizeColumn,
+		Extension:     ExtensionColumn,
+		MimeType:      MimeTypeColumn,
+		Category:      CategoryColumn,
+		TrashedAt:     TrashedAtColumn,
+		CreatedAt:     CreatedAtColumn,
+		UpdatedAt:     UpdatedAtColumn,
+		PreviewStatus: PreviewStatusColumn,
+		ScanStatus:    ScanStatusColumn,
+		ScanSignature: ScanSignatureColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
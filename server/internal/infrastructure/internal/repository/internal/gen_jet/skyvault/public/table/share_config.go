@@ -17,15 +17,16 @@ type shareConfigTable struct {
 	postgres.Table
 
 	// Columns
-	ID           postgres.ColumnString
-	OwnerID      postgres.ColumnString
-	FileID       postgres.ColumnString
-	FolderID     postgres.ColumnString
-	PasswordHash postgres.ColumnString
-	MaxDownloads postgres.ColumnInteger
-	ExpiresAt    postgres.ColumnTimestamp
-	CreatedAt    postgres.ColumnTimestamp
-	UpdatedAt    postgres.ColumnTimestamp
+	ID             postgres.ColumnString
+	OwnerID        postgres.ColumnString
+	FileID         postgres.ColumnString
+	FolderID       postgres.ColumnString
+	PasswordHash   postgres.ColumnString
+	MaxDownloads   postgres.ColumnInteger
+	ExpiresAt      postgres.ColumnTimestamp
+	CreatedAt      postgres.ColumnTimestamp
+	UpdatedAt      postgres.ColumnTimestamp
+	DownloadsCount postgres.ColumnInteger
 
 	AllColumns     postgres.ColumnList
 	MutableColumns postgres.ColumnList
@@ -66,32 +67,34 @@ func newShareConfigTable(schemaName, tableName, alias string) *ShareConfigTable
 
 func newShareConfigTableImpl(schemaName, tableName, alias string) shareConfigTable {
 	var (
-		IDColumn           = postgres.StringColumn("id")
-		OwnerIDColumn      = postgres.StringColumn("owner_id")
-		FileIDColumn       = postgres.StringColumn("file_id")
-		FolderIDColumn     = postgres.StringColumn("folder_id")
-		PasswordHashColumn = postgres.StringColumn("password_hash")
-		MaxDownloadsColumn = postgres.IntegerColumn("max_downloads")
-		ExpiresAtColumn    = postgres.TimestampColumn("expires_at")
-		CreatedAtColumn    = postgres.TimestampColumn("created_at")
-		UpdatedAtColumn    = postgres.TimestampColumn("updated_at")
-		allColumns         = postgres.ColumnList{IDColumn, OwnerIDColumn, FileIDColumn, FolderIDColumn, PasswordHashColumn, MaxDownloadsColumn, ExpiresAtColumn, CreatedAtColumn, UpdatedAtColumn}
-		mutableColumns     = postgres.ColumnList{OwnerIDColumn, FileIDColumn, FolderIDColumn, PasswordHashColumn, MaxDownloadsColumn, ExpiresAtColumn, CreatedAtColumn, UpdatedAtColumn}
+		IDColumn             = postgres.StringColumn("id")
+		OwnerIDColumn        = postgres.StringColumn("owner_id")
+		FileIDColumn         = postgres.StringColumn("file_id")
+		FolderIDColumn       = postgres.StringColumn("folder_id")
+		PasswordHashColumn   = postgres.StringColumn("password_hash")
+		MaxDownloadsColumn   = postgres.IntegerColumn("max_downloads")
+		ExpiresAtColumn      = postgres.TimestampColumn("expires_at")
+		CreatedAtColumn      = postgres.TimestampColumn("created_at")
+		UpdatedAtColumn      = postgres.TimestampColumn("updated_at")
+		DownloadsCountColumn = postgres.IntegerColumn("downloads_count")
+		allColumns           = postgres.ColumnList{IDColumn, OwnerIDColumn, FileIDColumn, FolderIDColumn, PasswordHashColumn, MaxDownloadsColumn, ExpiresAtColumn, CreatedAtColumn, UpdatedAtColumn, DownloadsCountColumn}
+		mutableColumns       = postgres.ColumnList{OwnerIDColumn, FileIDColumn, FolderIDColumn, PasswordHashColumn, MaxDownloadsColumn, ExpiresAtColumn, CreatedAtColumn, UpdatedAtColumn, DownloadsCountColumn}
 	)
 
 	return shareConfigTable{
 		Table: postgres.NewTable(schemaName, tableName, alias, allColumns...),
 
 		//Columns
-		ID:           IDColumn,
-		OwnerID:      OwnerIDColumn,
-		FileID:       FileIDColumn,
-		FolderID:     FolderIDColumn,
-		PasswordHash: PasswordHashColumn,
-		MaxDownloads: MaxDownloadsColumn,
-		ExpiresAt:    ExpiresAtColumn,
-		CreatedAt:    CreatedAtColumn,
-		UpdatedAt:    UpdatedAtColumn,
+		ID:             IDColumn,
+		OwnerID:        OwnerIDColumn,
+		FileID:         FileIDColumn,
+		FolderID:       FolderIDColumn,
+		PasswordHash:   PasswordHashColumn,
+		MaxDownloads:   MaxDownloadsColumn,
+		ExpiresAt:      ExpiresAtColumn,
+		CreatedAt:      CreatedAtColumn,
+		UpdatedAt:      UpdatedAtColumn,
+		DownloadsCount: DownloadsCountColumn,
 
 		AllColumns:     allColumns,
 		MutableColumns: mutableColumns,
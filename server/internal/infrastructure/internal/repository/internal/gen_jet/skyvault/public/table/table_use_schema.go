@@ -10,14 +10,35 @@ package table
 // UseSchema sets a new schema name for all generated table SQL builder types. It is recommended to invoke
 // this method only once at the beginning of the program.
 func UseSchema(schema string) {
+	AppPassword = AppPassword.FromSchema(schema)
+	ArchiveExtraction = ArchiveExtraction.FromSchema(schema)
 	Auth = Auth.FromSchema(schema)
+	AuthSession = AuthSession.FromSchema(schema)
+	ChangeLog = ChangeLog.FromSchema(schema)
+	ChangeLogState = ChangeLogState.FromSchema(schema)
 	Contact = Contact.FromSchema(schema)
 	ContactGroup = ContactGroup.FromSchema(schema)
 	ContactGroupMember = ContactGroupMember.FromSchema(schema)
+	Event = Event.FromSchema(schema)
 	FileInfo = FileInfo.FromSchema(schema)
+	FileMetadata = FileMetadata.FromSchema(schema)
+	FileProperty = FileProperty.FromSchema(schema)
+	FileTag = FileTag.FromSchema(schema)
+	FileText = FileText.FromSchema(schema)
 	FolderInfo = FolderInfo.FromSchema(schema)
+	FolderProperty = FolderProperty.FromSchema(schema)
+	FolderStats = FolderStats.FromSchema(schema)
+	FolderTag = FolderTag.FromSchema(schema)
+	Outbox = Outbox.FromSchema(schema)
 	Profile = Profile.FromSchema(schema)
+	RecentFile = RecentFile.FromSchema(schema)
 	SchemaMigrations = SchemaMigrations.FromSchema(schema)
 	ShareConfig = ShareConfig.FromSchema(schema)
 	ShareRecipient = ShareRecipient.FromSchema(schema)
+	StarredFile = StarredFile.FromSchema(schema)
+	StarredFolder = StarredFolder.FromSchema(schema)
+	StorageUsage = StorageUsage.FromSchema(schema)
+	Tag = Tag.FromSchema(schema)
+	Webhook = Webhook.FromSchema(schema)
+	WebhookDelivery = WebhookDelivery.FromSchema(schema)
 }
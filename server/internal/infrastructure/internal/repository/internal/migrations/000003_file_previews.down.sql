@@ -0,0 +1,4 @@
+drop index if exists file_info_idx_preview_pending;
+
+alter table file_info drop column if exists preview_status;
+alter table file_info add column if not exists preview bytea;
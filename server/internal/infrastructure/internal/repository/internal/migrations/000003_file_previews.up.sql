@@ -0,0 +1,6 @@
+-- Previews are stored in the file storage and generated in the background
+alter table file_info drop column if exists preview;
+alter table file_info add column if not exists preview_status text;
+
+create index if not exists file_info_idx_preview_pending
+on file_info(id) where preview_status is null or preview_status = 'pending';
@@ -0,0 +1,3 @@
+drop index if exists file_metadata_idx_timeline;
+
+drop table if exists file_metadata;
@@ -0,0 +1,27 @@
+-- Metadata extracted from the content of image and audio files in the background.
+-- A row is inserted for every processed file, even if nothing could be extracted.
+create table if not exists file_metadata (
+	file_id uuid primary key references file_info(id) on delete cascade,
+	owner_id uuid not null references profile(id) on delete cascade,
+	width integer,
+	height integer,
+	captured_at timestamp,
+	camera_make text,
+	camera_model text,
+	latitude double precision,
+	longitude double precision,
+	duration_ms bigint,
+	title text,
+	artist text,
+	album text,
+	genre text,
+	year integer,
+	track integer,
+	-- Capture time if known, upload time otherwise
+	timeline_at timestamp not null,
+	created_at timestamp not null default (timezone('utc', now())),
+	updated_at timestamp not null default (timezone('utc', now()))
+);
+
+create index if not exists file_metadata_idx_timeline
+on file_metadata(owner_id, timeline_at desc, file_id desc);
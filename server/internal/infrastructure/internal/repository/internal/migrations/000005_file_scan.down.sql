@@ -0,0 +1,5 @@
+drop index if exists file_info_idx_scan_infected;
+drop index if exists file_info_idx_scan_pending;
+
+alter table file_info drop column if exists scan_signature;
+alter table file_info drop column if exists scan_status;
@@ -0,0 +1,9 @@
+-- Files are scanned for malware in the background, infected files stay in quarantine
+alter table file_info add column if not exists scan_status text;
+alter table file_info add column if not exists scan_signature text;
+
+create index if not exists file_info_idx_scan_pending
+on file_info(id) where scan_status is null or scan_status in ('pending', 'failed');
+
+create index if not exists file_info_idx_scan_infected
+on file_info(owner_id, id) where scan_status = 'infected';
@@ -0,0 +1,4 @@
+drop table if exists file_text;
+
+drop index if exists folder_info_idx_name_trgm;
+drop index if exists file_info_idx_name_trgm;
@@ -0,0 +1,21 @@
+-- Trigram indexes make the ILIKE search on names fast, wherever the term is in the name
+create extension if not exists pg_trgm;
+
+create index if not exists file_info_idx_name_trgm
+on file_info using gin (name gin_trgm_ops);
+
+create index if not exists folder_info_idx_name_trgm
+on folder_info using gin (name gin_trgm_ops);
+
+-- Text extracted from the content of text files in the background, for the full-text search.
+-- A row is inserted for every processed file, even if nothing could be extracted.
+create table if not exists file_text (
+	file_id uuid primary key references file_info(id) on delete cascade,
+	owner_id uuid not null references profile(id) on delete cascade,
+	search_vector tsvector not null,
+	created_at timestamp not null default (timezone('utc', now())),
+	updated_at timestamp not null default (timezone('utc', now()))
+);
+
+create index if not exists file_text_idx_search_vector
+on file_text using gin (search_vector);
@@ -0,0 +1,5 @@
+drop table if exists folder_property;
+drop table if exists file_property;
+drop table if exists folder_tag;
+drop table if exists file_tag;
+drop table if exists tag;
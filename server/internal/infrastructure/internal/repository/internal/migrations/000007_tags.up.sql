@@ -0,0 +1,47 @@
+create table if not exists tag (
+    id uuid primary key,
+    owner_id uuid not null references profile(id) on delete cascade,
+    name text not null,
+    color text not null,
+    created_at timestamp not null default (timezone('utc', now())),
+    updated_at timestamp not null default (timezone('utc', now()))
+);
+
+create unique index if not exists tag_idx_unq_name
+on tag(owner_id, name);
+
+-- Tags reference the items by ID, so they survive renames, moves and trash/restore
+create table if not exists file_tag (
+    file_id uuid not null references file_info(id) on delete cascade,
+    tag_id uuid not null references tag(id) on delete cascade,
+    created_at timestamp not null default (timezone('utc', now())),
+    primary key (file_id, tag_id)
+);
+
+create index if not exists file_tag_idx_tag
+on file_tag(tag_id, file_id);
+
+create table if not exists folder_tag (
+    folder_id uuid not null references folder_info(id) on delete cascade,
+    tag_id uuid not null references tag(id) on delete cascade,
+    created_at timestamp not null default (timezone('utc', now())),
+    primary key (folder_id, tag_id)
+);
+
+create index if not exists folder_tag_idx_tag
+on folder_tag(tag_id, folder_id);
+
+-- Free-form key/value metadata set by the users
+create table if not exists file_property (
+    file_id uuid not null references file_info(id) on delete cascade,
+    key text not null,
+    value text not null,
+    primary key (file_id, key)
+);
+
+create table if not exists folder_property (
+    folder_id uuid not null references folder_info(id) on delete cascade,
+    key text not null,
+    value text not null,
+    primary key (folder_id, key)
+);
@@ -0,0 +1,3 @@
+drop table if exists recent_file;
+drop table if exists starred_folder;
+drop table if exists starred_file;
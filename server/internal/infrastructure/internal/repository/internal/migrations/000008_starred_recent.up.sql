@@ -0,0 +1,25 @@
+create table if not exists starred_file (
+    owner_id uuid not null references profile(id) on delete cascade,
+    file_id uuid not null references file_info(id) on delete cascade,
+    created_at timestamp not null default (timezone('utc', now())),
+    primary key (owner_id, file_id)
+);
+
+create table if not exists starred_folder (
+    owner_id uuid not null references profile(id) on delete cascade,
+    folder_id uuid not null references folder_info(id) on delete cascade,
+    created_at timestamp not null default (timezone('utc', now())),
+    primary key (owner_id, folder_id)
+);
+
+-- Last activity of the users on their files, a single row per file keeps the feed bounded
+create table if not exists recent_file (
+    owner_id uuid not null references profile(id) on delete cascade,
+    file_id uuid not null references file_info(id) on delete cascade,
+    activity text not null, -- uploaded, modified or downloaded
+    activity_at timestamp not null default (timezone('utc', now())),
+    primary key (owner_id, file_id)
+);
+
+create index if not exists recent_file_idx_activity_at
+on recent_file(owner_id, activity_at, file_id);
@@ -0,0 +1,3 @@
+drop trigger if exists file_info_storage_usage on file_info;
+drop function if exists storage_usage_on_file_info_change();
+drop table if exists storage_usage;
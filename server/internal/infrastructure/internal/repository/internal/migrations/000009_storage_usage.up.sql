@@ -0,0 +1,50 @@
+-- Storage used by the users, maintained by a trigger on file_info so every write path
+-- (uploads, bulk trash/restore of folders, cascading deletes) keeps it accurate
+create table if not exists storage_usage (
+    owner_id uuid primary key references profile(id) on delete cascade,
+    used_bytes bigint not null default 0, -- non-trashed files, counted in the quota
+    trashed_bytes bigint not null default 0,
+    quota_bytes bigint, -- overrides the default quota of the config, null uses the default
+    updated_at timestamp not null default (timezone('utc', now()))
+);
+
+create or replace function storage_usage_on_file_info_change() returns trigger as $$
+begin
+    if tg_op in ('UPDATE', 'DELETE') then
+        update storage_usage
+        set used_bytes = used_bytes - case when old.trashed_at is null then old.size else 0 end,
+            trashed_bytes = trashed_bytes - case when old.trashed_at is not null then old.size else 0 end,
+            updated_at = timezone('utc', now())
+        where owner_id = old.owner_id;
+    end if;
+
+    if tg_op in ('INSERT', 'UPDATE') then
+        insert into storage_usage (owner_id, used_bytes, trashed_bytes)
+        values (
+            new.owner_id,
+            case when new.trashed_at is null then new.size else 0 end,
+            case when new.trashed_at is not null then new.size else 0 end
+        )
+        on conflict (owner_id) do update
+        set used_bytes = storage_usage.used_bytes + excluded.used_bytes,
+            trashed_bytes = storage_usage.trashed_bytes + excluded.trashed_bytes,
+            updated_at = timezone('utc', now());
+    end if;
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger file_info_storage_usage
+after insert or delete or update of owner_id, size, trashed_at on file_info
+for each row execute function storage_usage_on_file_info_change();
+
+-- Existing files
+insert into storage_usage (owner_id, used_bytes, trashed_bytes)
+select
+    owner_id,
+    coalesce(sum(size) filter (where trashed_at is null), 0),
+    coalesce(sum(size) filter (where trashed_at is not null), 0)
+from file_info
+group by owner_id
+on conflict (owner_id) do nothing;
@@ -0,0 +1,6 @@
+drop trigger if exists folder_info_folder_stats on folder_info;
+drop function if exists folder_stats_on_folder_info_change();
+drop trigger if exists file_info_folder_stats on file_info;
+drop function if exists folder_stats_on_file_info_change();
+drop function if exists folder_stats_add(uuid, bigint, bigint, bigint);
+drop table if exists folder_stats;
@@ -0,0 +1,129 @@
+-- Recursive size and item counts of the folders, only non-trashed items are counted.
+-- Maintained by the triggers below, each item adds itself to all its ancestors.
+-- No foreign key, the rows are removed by the trigger after being used to update the ancestors.
+create table if not exists folder_stats (
+    folder_id uuid primary key,
+    size bigint not null default 0,
+    file_count bigint not null default 0,
+    folder_count bigint not null default 0
+);
+
+create index if not exists folder_stats_idx_size on folder_stats(size, folder_id);
+
+-- Adds the deltas to the folder and all its ancestors
+create or replace function folder_stats_add(p_folder_id uuid, p_size bigint, p_files bigint, p_folders bigint) returns void as $$
+begin
+    if p_folder_id is null or (p_size = 0 and p_files = 0 and p_folders = 0) then
+        return;
+    end if;
+
+    with recursive ancestors as (
+        select id, parent_folder_id from folder_info where id = p_folder_id
+        union all
+        select f.id, f.parent_folder_id from folder_info f inner join ancestors a on f.id = a.parent_folder_id
+    )
+    insert into folder_stats (folder_id, size, file_count, folder_count)
+    select id, p_size, p_files, p_folders from ancestors
+    on conflict (folder_id) do update
+    set size = folder_stats.size + excluded.size,
+        file_count = folder_stats.file_count + excluded.file_count,
+        folder_count = folder_stats.folder_count + excluded.folder_count;
+end;
+$$ language plpgsql;
+
+create or replace function folder_stats_on_file_info_change() returns trigger as $$
+begin
+    if tg_op = 'UPDATE'
+        and old.folder_id is not distinct from new.folder_id
+        and old.size = new.size
+        and (old.trashed_at is null) = (new.trashed_at is null) then
+        return null;
+    end if;
+
+    if tg_op in ('UPDATE', 'DELETE') and old.trashed_at is null then
+        perform folder_stats_add(old.folder_id, -old.size, -1, 0);
+    end if;
+
+    if tg_op in ('INSERT', 'UPDATE') and new.trashed_at is null then
+        perform folder_stats_add(new.folder_id, new.size, 1, 0);
+    end if;
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger file_info_folder_stats
+after insert or delete or update of folder_id, size, trashed_at on file_info
+for each row execute function folder_stats_on_file_info_change();
+
+create or replace function folder_stats_on_folder_info_change() returns trigger as $$
+declare
+    stats folder_stats%rowtype;
+begin
+    if tg_op = 'INSERT' then
+        insert into folder_stats (folder_id) values (new.id) on conflict do nothing;
+        if new.trashed_at is null then
+            perform folder_stats_add(new.parent_folder_id, 0, 0, 1);
+        end if;
+        return null;
+    end if;
+
+    -- Trashing or restoring a folder only counts the folder itself,
+    -- its items are trashed and restored along with it and count themselves
+    if tg_op = 'UPDATE' and old.parent_folder_id is not distinct from new.parent_folder_id then
+        if (old.trashed_at is null) <> (new.trashed_at is null) then
+            perform folder_stats_add(new.parent_folder_id, 0, 0, case when new.trashed_at is null then 1 else -1 end);
+        end if;
+        return null;
+    end if;
+
+    -- Moving or deleting a folder takes its whole content along
+    select * into stats from folder_stats where folder_id = old.id;
+    perform folder_stats_add(
+        old.parent_folder_id,
+        -coalesce(stats.size, 0),
+        -coalesce(stats.file_count, 0),
+        -coalesce(stats.folder_count, 0) - case when old.trashed_at is null then 1 else 0 end
+    );
+
+    if tg_op = 'DELETE' then
+        delete from folder_stats where folder_id = old.id;
+        return null;
+    end if;
+
+    perform folder_stats_add(
+        new.parent_folder_id,
+        coalesce(stats.size, 0),
+        coalesce(stats.file_count, 0),
+        coalesce(stats.folder_count, 0) + case when new.trashed_at is null then 1 else 0 end
+    );
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger folder_info_folder_stats
+after insert or delete or update of parent_folder_id, trashed_at on folder_info
+for each row execute function folder_stats_on_folder_info_change();
+
+-- Existing folders
+with recursive tree as (
+    select id as folder_id, id as descendant_id from folder_info
+    union all
+    select t.folder_id, f.id from tree t inner join folder_info f on f.parent_folder_id = t.descendant_id
+)
+insert into folder_stats (folder_id, size, file_count, folder_count)
+select
+    t.folder_id,
+    coalesce(sum(files.size), 0),
+    coalesce(sum(files.file_count), 0),
+    count(*) filter (where t.descendant_id <> t.folder_id and d.trashed_at is null)
+from tree t
+inner join folder_info d on d.id = t.descendant_id
+left join lateral (
+    select sum(size) as size, count(*) as file_count
+    from file_info
+    where folder_id = t.descendant_id and trashed_at is null
+) files on true
+group by t.folder_id
+on conflict (folder_id) do nothing;
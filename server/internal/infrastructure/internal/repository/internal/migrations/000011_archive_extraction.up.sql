@@ -0,0 +1,19 @@
+-- Extractions of uploaded archives into a folder, run by background jobs
+create table if not exists archive_extraction (
+    id uuid primary key,
+    owner_id uuid not null references profile(id) on delete cascade,
+    file_id uuid not null references file_info(id) on delete cascade, -- the archive
+    folder_id uuid references folder_info(id) on delete cascade, -- target folder, null is the root folder
+    conflict text not null, -- rename or skip the entries whose name is already used
+    status text not null, -- pending, running, done, failed
+    file_count bigint not null default 0,
+    folder_count bigint not null default 0,
+    skipped_count bigint not null default 0,
+    extracted_bytes bigint not null default 0,
+    error_code text, -- app error code when failed
+    created_at timestamp not null default (timezone('utc', now())),
+    updated_at timestamp not null default (timezone('utc', now()))
+);
+
+create index if not exists archive_extraction_idx_owner
+on archive_extraction(owner_id, created_at);
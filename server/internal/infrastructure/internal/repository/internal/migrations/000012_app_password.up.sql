@@ -0,0 +1,14 @@
+-- App passwords sign in clients that can't use tokens, e.g. WebDAV drives. Only the hash of the secret is kept.
+create table if not exists app_password (
+    id uuid primary key,
+    profile_id uuid not null references profile(id) on delete cascade,
+    name text not null,
+    secret_hash text not null, -- sha256 hex of the secret, the secret is random so it needs no salt
+    created_at timestamp not null default (timezone('utc', now()))
+);
+
+create unique index if not exists app_password_idx_unq_secret_hash
+on app_password(secret_hash);
+
+create index if not exists app_password_idx_profile
+on app_password(profile_id, created_at);
@@ -0,0 +1,8 @@
+drop function if exists change_log_compact(timestamp);
+drop trigger if exists folder_info_change_log on folder_info;
+drop function if exists change_log_on_folder_info_change();
+drop trigger if exists file_info_change_log on file_info;
+drop function if exists change_log_on_file_info_change();
+drop function if exists change_log_add(uuid, text, uuid, text, text, uuid, bigint, boolean);
+drop table if exists change_log;
+drop table if exists change_log_state;
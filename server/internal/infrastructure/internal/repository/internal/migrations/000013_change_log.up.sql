@@ -0,0 +1,145 @@
+-- Per-owner sequence of the changes, the last change of each item is kept in change_log
+create table if not exists change_log_state (
+    owner_id uuid primary key references profile(id) on delete cascade,
+    last_seq bigint not null default 0,
+    compacted_seq bigint not null default 0 -- The deletes up to it may be purged, older cursors must resync
+);
+
+-- Changes of the files and folders for the sync clients, recorded by the triggers below in the
+-- transaction of the change. Only the last change of an item is kept, with the state of the item after it.
+create table if not exists change_log (
+    owner_id uuid not null references profile(id) on delete cascade,
+    seq bigint not null,
+    item_type text not null, -- file or folder
+    item_id uuid not null, -- No foreign key, the deleted items keep their delete
+    change_type text not null, -- created, renamed, moved, updated, trashed, restored or deleted
+    name text not null,
+    parent_folder_id uuid, -- The folder of the item, null for the root folder
+    size bigint not null default 0, -- 0 for the folders
+    trashed boolean not null default false,
+    changed_at timestamp not null default (timezone('utc', now())),
+    primary key (owner_id, seq)
+);
+
+create unique index if not exists change_log_idx_unq_item
+on change_log(owner_id, item_id);
+
+create index if not exists change_log_idx_deleted
+on change_log(changed_at) where change_type = 'deleted';
+
+create or replace function change_log_add(
+    p_owner_id uuid, p_item_type text, p_item_id uuid, p_change_type text,
+    p_name text, p_parent_folder_id uuid, p_size bigint, p_trashed boolean
+) returns void as $$
+declare
+    v_seq bigint;
+begin
+    -- The owner's row stays locked until the commit, so the sequence follows the commit order
+    -- and a client never reads a sequence before a smaller one is committed
+    insert into change_log_state (owner_id, last_seq)
+    select id, 1 from profile where id = p_owner_id
+    on conflict (owner_id) do update set last_seq = change_log_state.last_seq + 1
+    returning last_seq into v_seq;
+
+    -- The owner is being deleted along with the items
+    if v_seq is null then
+        return;
+    end if;
+
+    delete from change_log where owner_id = p_owner_id and item_id = p_item_id;
+
+    insert into change_log (owner_id, seq, item_type, item_id, change_type, name, parent_folder_id, size, trashed)
+    values (p_owner_id, v_seq, p_item_type, p_item_id, p_change_type, p_name, p_parent_folder_id, p_size, p_trashed);
+end;
+$$ language plpgsql;
+
+create or replace function change_log_on_file_info_change() returns trigger as $$
+declare
+    v_change_type text;
+begin
+    if tg_op = 'DELETE' then
+        perform change_log_add(old.owner_id, 'file', old.id, 'deleted', old.name, old.folder_id, old.size, old.trashed_at is not null);
+        return null;
+    end if;
+
+    if tg_op = 'INSERT' then
+        v_change_type := 'created';
+    elsif old.trashed_at is null and new.trashed_at is not null then
+        v_change_type := 'trashed';
+    elsif old.trashed_at is not null and new.trashed_at is null then
+        v_change_type := 'restored';
+    elsif old.folder_id is distinct from new.folder_id then
+        v_change_type := 'moved';
+    elsif old.name <> new.name then
+        v_change_type := 'renamed';
+    elsif old.size <> new.size or old.mime_type <> new.mime_type or old.updated_at <> new.updated_at then
+        v_change_type := 'updated';
+    else
+        -- e.g. the preview or scan status
+        return null;
+    end if;
+
+    perform change_log_add(new.owner_id, 'file', new.id, v_change_type, new.name, new.folder_id, new.size, new.trashed_at is not null);
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger file_info_change_log
+after insert or delete or update of folder_id, name, size, mime_type, trashed_at, updated_at on file_info
+for each row execute function change_log_on_file_info_change();
+
+create or replace function change_log_on_folder_info_change() returns trigger as $$
+declare
+    v_change_type text;
+begin
+    if tg_op = 'DELETE' then
+        perform change_log_add(old.owner_id, 'folder', old.id, 'deleted', old.name, old.parent_folder_id, 0, old.trashed_at is not null);
+        return null;
+    end if;
+
+    if tg_op = 'INSERT' then
+        v_change_type := 'created';
+    elsif old.trashed_at is null and new.trashed_at is not null then
+        v_change_type := 'trashed';
+    elsif old.trashed_at is not null and new.trashed_at is null then
+        v_change_type := 'restored';
+    elsif old.parent_folder_id is distinct from new.parent_folder_id then
+        v_change_type := 'moved';
+    elsif old.name <> new.name then
+        v_change_type := 'renamed';
+    elsif old.updated_at <> new.updated_at then
+        v_change_type := 'updated';
+    else
+        return null;
+    end if;
+
+    perform change_log_add(new.owner_id, 'folder', new.id, v_change_type, new.name, new.parent_folder_id, 0, new.trashed_at is not null);
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger folder_info_change_log
+after insert or delete or update of parent_folder_id, name, trashed_at, updated_at on folder_info
+for each row execute function change_log_on_folder_info_change();
+
+-- Purges the deletes older than p_before and moves the compacted sequence of their owners past them.
+-- The other changes are only replaced by the next change of their item, so they never expire.
+create or replace function change_log_compact(p_before timestamp) returns bigint as $$
+declare
+    v_count bigint;
+begin
+    with purged as (
+        delete from change_log
+        where change_type = 'deleted' and changed_at < p_before
+        returning owner_id, seq
+    ), compacted as (
+        update change_log_state s
+        set compacted_seq = greatest(s.compacted_seq, p.max_seq)
+        from (select owner_id, max(seq) as max_seq from purged group by owner_id) p
+        where s.owner_id = p.owner_id
+    )
+    select count(*) into v_count from purged;
+
+    return v_count;
+end;
+$$ language plpgsql;
@@ -0,0 +1,9 @@
+drop trigger if exists share_recipient_event on share_recipient;
+drop function if exists event_on_share_recipient_change();
+drop trigger if exists share_config_event on share_config;
+drop function if exists event_on_share_config_change();
+drop function if exists event_add(uuid, text, jsonb);
+drop trigger if exists change_log_event on change_log;
+drop function if exists event_on_change_log_insert();
+drop function if exists event_notify(uuid);
+drop table if exists event;
@@ -0,0 +1,129 @@
+-- Events of the owners for the live streams of the clients, kept for a day so the clients can resume.
+-- The events take the owner's sequence of change_log_state, so the ID of a media event is also a change cursor.
+create table if not exists event (
+    owner_id uuid not null references profile(id) on delete cascade,
+    seq bigint not null,
+    type text not null, -- e.g. file.created, folder.moved or share.created
+    payload jsonb not null,
+    created_at timestamp not null default (timezone('utc', now())),
+    primary key (owner_id, seq)
+);
+
+create index if not exists event_idx_created_at
+on event(created_at);
+
+-- Wakes up the streams of the owner on all the servers. The notifications are sent on commit
+-- and the same payload is sent once per transaction, however many events it has.
+create or replace function event_notify(p_owner_id uuid) returns void as $$
+begin
+    perform pg_notify('skyvault_event', p_owner_id::text);
+end;
+$$ language plpgsql;
+
+-- Every change of the files and folders is an event
+create or replace function event_on_change_log_insert() returns trigger as $$
+begin
+    insert into event (owner_id, seq, type, payload)
+    values (
+        new.owner_id,
+        new.seq,
+        new.item_type || '.' || new.change_type,
+        jsonb_build_object(
+            'itemType', new.item_type,
+            'itemId', new.item_id,
+            'name', new.name,
+            'parentFolderId', new.parent_folder_id,
+            'size', new.size,
+            'trashed', new.trashed
+        )
+    );
+    perform event_notify(new.owner_id);
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger change_log_event
+after insert on change_log
+for each row execute function event_on_change_log_insert();
+
+-- Adds an event that is not a change of the files and folders, e.g. of the shares
+create or replace function event_add(p_owner_id uuid, p_type text, p_payload jsonb) returns void as $$
+declare
+    v_seq bigint;
+begin
+    -- Same sequence as change_log_add
+    insert into change_log_state (owner_id, last_seq)
+    select id, 1 from profile where id = p_owner_id
+    on conflict (owner_id) do update set last_seq = change_log_state.last_seq + 1
+    returning last_seq into v_seq;
+
+    -- The owner is being deleted
+    if v_seq is null then
+        return;
+    end if;
+
+    insert into event (owner_id, seq, type, payload) values (p_owner_id, v_seq, p_type, p_payload);
+    perform event_notify(p_owner_id);
+end;
+$$ language plpgsql;
+
+create or replace function event_on_share_config_change() returns trigger as $$
+declare
+    v_share share_config%rowtype;
+begin
+    if tg_op = 'DELETE' then
+        v_share := old;
+    else
+        v_share := new;
+    end if;
+
+    perform event_add(
+        v_share.owner_id,
+        case tg_op when 'INSERT' then 'share.created' when 'UPDATE' then 'share.updated' else 'share.deleted' end,
+        jsonb_build_object(
+            'shareId', v_share.id,
+            'fileId', v_share.file_id,
+            'folderId', v_share.folder_id,
+            'expiresAt', v_share.expires_at,
+            'maxDownloads', v_share.max_downloads
+        )
+    );
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger share_config_event
+after insert or update or delete on share_config
+for each row execute function event_on_share_config_change();
+
+-- The recipients are part of the share, adding or removing one updates it
+create or replace function event_on_share_recipient_change() returns trigger as $$
+declare
+    v_share share_config%rowtype;
+begin
+    select * into v_share from share_config
+    where id = case when tg_op = 'DELETE' then old.share_config_id else new.share_config_id end;
+
+    -- The share is being deleted along with its recipients
+    if not found then
+        return null;
+    end if;
+
+    perform event_add(
+        v_share.owner_id,
+        'share.updated',
+        jsonb_build_object(
+            'shareId', v_share.id,
+            'fileId', v_share.file_id,
+            'folderId', v_share.folder_id,
+            'expiresAt', v_share.expires_at,
+            'maxDownloads', v_share.max_downloads
+        )
+    );
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger share_recipient_event
+after insert or delete on share_recipient
+for each row execute function event_on_share_recipient_change();
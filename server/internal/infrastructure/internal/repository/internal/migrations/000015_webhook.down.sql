@@ -0,0 +1,5 @@
+drop trigger if exists event_webhook on event;
+drop function if exists webhook_on_event_insert();
+drop function if exists folder_in_subtree(uuid, uuid);
+drop table if exists webhook_delivery;
+drop table if exists webhook;
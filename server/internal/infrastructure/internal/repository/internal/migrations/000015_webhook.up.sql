@@ -0,0 +1,91 @@
+-- Webhooks post the owner's events to the URLs of other tools, signed with the secret of the webhook.
+create table if not exists webhook (
+    id uuid primary key,
+    owner_id uuid not null references profile(id) on delete cascade,
+    url text not null,
+    secret text not null, -- Kept as is, the payloads are signed with it
+    event_types jsonb not null default '[]', -- e.g. ["file.created", "share.*"], empty for all the events
+    folder_id uuid references folder_info(id) on delete cascade, -- Only the events of the folder's subtree, null for all
+    enabled boolean not null default true,
+    created_at timestamp not null default (timezone('utc', now())),
+    updated_at timestamp not null default (timezone('utc', now()))
+);
+
+create index if not exists webhook_idx_owner
+on webhook(owner_id, created_at);
+
+-- Deliveries of the events to the webhooks, with the payload so that they outlive the events.
+-- They are the delivery log, kept for 30 days.
+create table if not exists webhook_delivery (
+    id uuid primary key,
+    webhook_id uuid not null references webhook(id) on delete cascade,
+    owner_id uuid not null references profile(id) on delete cascade,
+    event_seq bigint, -- null for the pings
+    event_type text not null,
+    payload jsonb not null,
+    status text not null default 'pending', -- pending, succeeded or failed once the attempts are exhausted
+    attempts integer not null default 0,
+    next_attempt_at timestamp not null default (timezone('utc', now())),
+    last_attempt_at timestamp,
+    response_status integer,
+    last_error text,
+    created_at timestamp not null default (timezone('utc', now()))
+);
+
+create index if not exists webhook_delivery_idx_webhook
+on webhook_delivery(webhook_id, created_at);
+
+create index if not exists webhook_delivery_idx_pending
+on webhook_delivery(next_attempt_at) where status = 'pending';
+
+create index if not exists webhook_delivery_idx_created_at
+on webhook_delivery(created_at);
+
+-- Whether the folder is the root folder or one of its descendants
+create or replace function folder_in_subtree(p_folder_id uuid, p_root_id uuid) returns boolean as $$
+    with recursive ancestors as (
+        select id, parent_folder_id from folder_info where id = p_folder_id
+        union all
+        select f.id, f.parent_folder_id from folder_info f
+        join ancestors a on f.id = a.parent_folder_id
+    )
+    select exists (select 1 from ancestors where id = p_root_id);
+$$ language sql stable;
+
+-- Queues a delivery for every enabled webhook of the owner that matches the event.
+-- It runs in the transaction of the change, so the deliveries are queued if and only if the change is committed.
+create or replace function webhook_on_event_insert() returns trigger as $$
+declare
+    v_folder_id uuid;
+begin
+    -- The folder the item is in, the subtree filter matches the item itself for the folders
+    v_folder_id := case
+        when new.type like 'folder.%' then (new.payload->>'itemId')::uuid
+        when new.type like 'file.%' then (new.payload->>'parentFolderId')::uuid
+        when new.type like 'share.%' then coalesce((new.payload->>'folderId')::uuid, (
+            select folder_id from file_info where id = (new.payload->>'fileId')::uuid
+        ))
+    end;
+
+    insert into webhook_delivery (id, webhook_id, owner_id, event_seq, event_type, payload, created_at)
+    select gen_random_uuid(), w.id, new.owner_id, new.seq, new.type, new.payload, new.created_at
+    from webhook w
+    where w.owner_id = new.owner_id
+    and w.enabled
+    and (
+        w.event_types = '[]'
+        or w.event_types ? new.type
+        or w.event_types ? (split_part(new.type, '.', 1) || '.*')
+    )
+    and (
+        w.folder_id is null
+        or (v_folder_id is not null and folder_in_subtree(v_folder_id, w.folder_id))
+    );
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger event_webhook
+after insert on event
+for each row execute function webhook_on_event_insert();
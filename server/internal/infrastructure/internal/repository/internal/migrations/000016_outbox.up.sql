@@ -0,0 +1,18 @@
+-- Domain events written by the repositories in the transactions of the changes,
+-- published to the in-process event bus once committed and deleted once published.
+create table if not exists outbox (
+    id bigserial primary key,
+    event_type text not null, -- e.g. media.file.uploaded
+    payload jsonb not null,
+    occurred_at timestamp not null default (timezone('utc', now())),
+    attempts integer not null default 0,
+    next_attempt_at timestamp not null default (timezone('utc', now())),
+    last_error text,
+    failed_at timestamp -- Set once the attempts are exhausted, kept 30 days to be looked into
+);
+
+create index if not exists outbox_idx_pending
+on outbox(next_attempt_at) where failed_at is null;
+
+create index if not exists outbox_idx_failed
+on outbox(failed_at) where failed_at is not null;
@@ -0,0 +1,19 @@
+-- Sessions are the sign-ins kept alive by rotating refresh tokens. The access tokens carry the session ID,
+-- so revoking the session signs out its clients. Only the hash of the current refresh token is kept.
+create table if not exists auth_session (
+    id uuid primary key,
+    profile_id uuid not null references profile(id) on delete cascade,
+    provider text not null, -- of the sign-in, e.g. email
+    refresh_token_hash text not null, -- sha256 hex of the secret of the current refresh token
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    created_at timestamp not null default (timezone('utc', now())),
+    updated_at timestamp not null default (timezone('utc', now()))
+);
+
+create index if not exists auth_session_idx_profile
+on auth_session(profile_id, created_at);
+
+-- For purging the expired sessions
+create index if not exists auth_session_idx_expires_at
+on auth_session(expires_at);
@@ -0,0 +1,6 @@
+drop index if exists auth_session_idx_profile_last_seen;
+
+alter table auth_session
+    drop column if exists user_agent,
+    drop column if exists ip_address,
+    drop column if exists last_seen_at;
@@ -0,0 +1,8 @@
+-- The clients of the sessions, so the users can tell where they are signed in
+alter table auth_session
+    add column if not exists user_agent text not null default '',
+    add column if not exists ip_address text not null default '', -- of the sign-in
+    add column if not exists last_seen_at timestamp not null default (timezone('utc', now()));
+
+create index if not exists auth_session_idx_profile_last_seen
+on auth_session(profile_id, last_seen_at);
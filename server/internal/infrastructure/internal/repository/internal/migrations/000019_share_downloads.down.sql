@@ -0,0 +1,7 @@
+drop trigger if exists share_config_event on share_config;
+create trigger share_config_event
+after insert or update or delete on share_config
+for each row execute function event_on_share_config_change();
+
+alter table share_config
+    drop column if exists downloads_count;
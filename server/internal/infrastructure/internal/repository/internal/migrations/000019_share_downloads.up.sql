@@ -0,0 +1,9 @@
+-- Downloads of a share, to enforce its max_downloads
+alter table share_config
+    add column if not exists downloads_count bigint not null default 0;
+
+-- Counting a download doesn't change the share for its owner
+drop trigger if exists share_config_event on share_config;
+create trigger share_config_event
+after insert or delete or update of file_id, folder_id, password_hash, max_downloads, expires_at on share_config
+for each row execute function event_on_share_config_change();
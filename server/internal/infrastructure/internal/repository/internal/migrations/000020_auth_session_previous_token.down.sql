@@ -0,0 +1,2 @@
+alter table auth_session
+    drop column if exists previous_refresh_token_hash;
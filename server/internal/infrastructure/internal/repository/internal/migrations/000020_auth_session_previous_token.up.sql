@@ -0,0 +1,3 @@
+-- The refresh token replaced by the last rotation, only its reuse revokes the session
+alter table auth_session
+    add column if not exists previous_refresh_token_hash text not null default '';
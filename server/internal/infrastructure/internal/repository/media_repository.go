@@ -4,7 +4,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"skyvault/internal/domain/media"
@@ -12,9 +15,12 @@ import (
 	. "skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/table"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
+	"skyvault/pkg/eventbus"
 	"skyvault/pkg/paging"
 
 	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/go-jet/jet/v2/qrm"
+	"github.com/google/uuid"
 	"github.com/jinzhu/copier"
 )
 
@@ -36,150 +42,1140 @@ func (r *MediaRepository) WithTx(ctx context.Context, tx *sql.Tx) media.Reposito
 	return &MediaRepository{repository: r.repository.withTx(ctx, tx)}
 }
 
+func (r *MediaRepository) AddDomainEvents(ctx context.Context, events ...eventbus.Event) error {
+	return r.repository.addDomainEvents(ctx, events)
+}
+
+//--------------------------------
+// File
+//--------------------------------
+
+func (r *MediaRepository) CreateFileInfo(ctx context.Context, info *media.FileInfo) (*media.FileInfo, error) {
+	dbModel := new(model.FileInfo)
+	err := copier.Copy(dbModel, info)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateFileInfo:copier.Copy")
+	}
+
+	stmt := FileInfo.INSERT(
+		FileInfo.AllColumns,
+	).MODEL(dbModel).RETURNING(FileInfo.AllColumns)
+
+	return runInsert[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) getFileInfo(ctx context.Context, fileID string, onlyTrashed bool) (*media.FileInfo, error) {
+	whereCond := FileInfo.ID.EQ(UUID(UUIDStr(fileID)))
+
+	if onlyTrashed {
+		whereCond = whereCond.AND(FileInfo.TrashedAt.IS_NOT_NULL())
+	} else {
+		whereCond = whereCond.AND(FileInfo.TrashedAt.IS_NULL())
+	}
+
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo).
+		WHERE(whereCond)
+
+	return runSelect[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileInfo(ctx context.Context, fileID string) (*media.FileInfo, error) {
+	return r.getFileInfo(ctx, fileID, false)
+}
+
+func (r *MediaRepository) GetFileInfoByName(ctx context.Context, ownerID string, folderID *string, name string) (*media.FileInfo, error) {
+	whereCond := FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+		AND(FileInfo.Name.EQ(String(name))).
+		AND(FileInfo.TrashedAt.IS_NULL())
+	if folderID == nil {
+		whereCond = whereCond.AND(FileInfo.FolderID.IS_NULL())
+	} else {
+		whereCond = whereCond.AND(FileInfo.FolderID.EQ(UUID(UUIDStr(*folderID))))
+	}
+
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo).
+		WHERE(whereCond)
+
+	return runSelect[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileInfoTrashed(ctx context.Context, fileID string) (*media.FileInfo, error) {
+	return r.getFileInfo(ctx, fileID, true)
+}
+
+// fileTypeColumn sorts the files by their lowercase extension, files without one come first.
+var fileTypeColumn = LOWER(StringExp(COALESCE(FileInfo.Extension, String(""))))
+
+var fileCategoryColumn = StringExp(COALESCE(FileInfo.Category, String("")))
+
+// fileType is the value of fileTypeColumn for the cursor.
+func fileType(extension *string) string {
+	if extension == nil {
+		return ""
+	}
+	return strings.ToLower(*extension)
+}
+
+func (r *MediaRepository) getFileInfos(ctx context.Context, whereCond BoolExpression, pagingOpt *paging.Options, ownerID string, folderID *string, includeFolderID bool) (*paging.Page[*media.FileInfo], error) {
+	if whereCond == nil {
+		whereCond = Bool(true)
+	}
+
+	whereCond = whereCond.AND(FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))))
+
+	if includeFolderID {
+		if folderID == nil {
+			whereCond = whereCond.AND(FileInfo.FolderID.IS_NULL())
+		} else {
+			whereCond = whereCond.AND(FileInfo.FolderID.EQ(UUID(UUIDStr(*folderID))))
+		}
+	}
+
+	whereCond = whereCond.AND(FileInfo.TrashedAt.IS_NULL())
+
+	// The cursor only holds the sort column and the ID, so the folder can only lead the order within a single folder
+	var orderBy []OrderByClause
+	if includeFolderID {
+		orderBy = []OrderByClause{FileInfo.OwnerID.ASC(), FileInfo.FolderID.ASC()}
+	}
+
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo)
+
+	cursorQuery := &cursorQuery{
+		ID:        FileInfo.ID,
+		Name:      FileInfo.Name,
+		Updated:   FileInfo.UpdatedAt,
+		Size:      FileInfo.Size,
+		Created:   FileInfo.CreatedAt,
+		Type:      fileTypeColumn,
+		Category:  fileCategoryColumn,
+		where:     whereCond,
+		orderBy:   orderBy,
+		pagingOpt: pagingOpt,
+	}
+
+	page, err := runSelectSlice[model.FileInfo, media.FileInfo](ctx, cursorQuery, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.GetFileInfos:runSelectSlice")
+	}
+
+	if len(page.Items) > 0 {
+		lastItem := page.Items[len(page.Items)-1]
+		nextCursor := &paging.Cursor{
+			ID:       lastItem.ID,
+			Name:     lastItem.Name,
+			Updated:  lastItem.UpdatedAt,
+			Size:     lastItem.Size,
+			Created:  lastItem.CreatedAt,
+			Type:     fileType(lastItem.Extension),
+			Category: string(lastItem.Category),
+		}
+		page.NextCursor = pagingOpt.CreateCursor(nextCursor)
+
+		firstItem := page.Items[0]
+		prevCursor := &paging.Cursor{
+			ID:       firstItem.ID,
+			Name:     firstItem.Name,
+			Updated:  firstItem.UpdatedAt,
+			Size:     firstItem.Size,
+			Created:  firstItem.CreatedAt,
+			Type:     fileType(firstItem.Extension),
+			Category: string(firstItem.Category),
+		}
+		page.PrevCursor = pagingOpt.CreateCursor(prevCursor)
+	}
+
+	return page, nil
+}
+
+func (r *MediaRepository) GetFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string, folderID *string) (*paging.Page[*media.FileInfo], error) {
+	return r.getFileInfos(ctx, nil, pagingOpt, ownerID, folderID, true)
+}
+
+func (r *MediaRepository) GetFileInfosByCategory(ctx context.Context, pagingOpt *paging.Options, ownerID string, category media.Category) (*paging.Page[*media.FileInfo], error) {
+	whereCond := FileInfo.Category.EQ(String(string(category)))
+	return r.getFileInfos(ctx, whereCond, pagingOpt, ownerID, nil, false)
+}
+
+func (r *MediaRepository) UpdateFileInfo(ctx context.Context, info *media.FileInfo) error {
+	// Preview and scan status are owned by the background jobs, see UpdateFileInfoPreviewStatus and UpdateFileInfoScanStatus
+	stmt := FileInfo.UPDATE(FileInfo.MutableColumns.Except(FileInfo.PreviewStatus, FileInfo.ScanStatus, FileInfo.ScanSignature)).
+		MODEL(info).
+		WHERE(FileInfo.ID.EQ(UUID(UUIDStr(info.ID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) ReplaceFileInfoContent(ctx context.Context, info *media.FileInfo) error {
+	// Same conversion of the statuses as CreateFileInfo
+	dbModel := new(model.FileInfo)
+	err := copier.Copy(dbModel, info)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.ReplaceFileInfoContent:copier.Copy")
+	}
+
+	stmt := FileInfo.UPDATE(
+		FileInfo.Size,
+		FileInfo.MimeType,
+		FileInfo.Category,
+		FileInfo.PreviewStatus,
+		FileInfo.ScanStatus,
+		FileInfo.ScanSignature,
+		FileInfo.UpdatedAt,
+	).MODEL(dbModel).
+		WHERE(FileInfo.ID.EQ(UUID(UUIDStr(info.ID))).AND(FileInfo.TrashedAt.IS_NULL()))
+
+	err = runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.ReplaceFileInfoContent:Update")
+	}
+
+	// Extracted from the previous content, the jobs extract them again from the new one
+	_, err = FileMetadata.DELETE().
+		WHERE(FileMetadata.FileID.EQ(UUID(UUIDStr(info.ID)))).
+		ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.ReplaceFileInfoContent:DeleteMetadata")
+	}
+
+	_, err = FileText.DELETE().
+		WHERE(FileText.FileID.EQ(UUID(UUIDStr(info.ID)))).
+		ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.ReplaceFileInfoContent:DeleteText")
+	}
+
+	return nil
+}
+
+func (r *MediaRepository) UpdateFileInfoPreviewStatus(ctx context.Context, fileID string, status media.PreviewStatus) error {
+	stmt := FileInfo.UPDATE(FileInfo.PreviewStatus).
+		SET(String(string(status))).
+		WHERE(FileInfo.ID.EQ(UUID(UUIDStr(fileID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileInfosPendingPreview(ctx context.Context, afterID string, limit int) ([]*media.FileInfo, error) {
+	// Files created before previews existed have no status, the generation marks the ones without a preview as unsupported
+	whereCond := FileInfo.TrashedAt.IS_NULL().
+		AND(FileInfo.PreviewStatus.IS_NULL().OR(FileInfo.PreviewStatus.EQ(String(string(media.PreviewStatusPending)))))
+
+	if afterID != "" {
+		whereCond = whereCond.AND(FileInfo.ID.GT(UUID(UUIDStr(afterID))))
+	}
+
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo).
+		WHERE(whereCond).
+		ORDER_BY(FileInfo.ID.ASC()).
+		LIMIT(int64(limit))
+
+	return runSelectSliceAll[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) UpdateFileInfoScanStatus(ctx context.Context, fileID string, status media.ScanStatus, signature *string) error {
+	stmt := FileInfo.UPDATE(FileInfo.ScanStatus, FileInfo.ScanSignature).
+		MODEL(model.FileInfo{ScanStatus: (*string)(&status), ScanSignature: signature}).
+		WHERE(FileInfo.ID.EQ(UUID(UUIDStr(fileID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileInfosPendingScan(ctx context.Context, afterID string, limit int) ([]*media.FileInfo, error) {
+	// Files created before scanning existed have no status
+	whereCond := FileInfo.TrashedAt.IS_NULL().
+		AND(FileInfo.ScanStatus.IS_NULL().OR(FileInfo.ScanStatus.IN(String(string(media.ScanStatusPending)), String(string(media.ScanStatusFailed)))))
+
+	if afterID != "" {
+		whereCond = whereCond.AND(FileInfo.ID.GT(UUID(UUIDStr(afterID))))
+	}
+
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo).
+		WHERE(whereCond).
+		ORDER_BY(FileInfo.ID.ASC()).
+		LIMIT(int64(limit))
+
+	return runSelectSliceAll[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetQuarantinedFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*media.FileInfo], error) {
+	whereCond := FileInfo.ScanStatus.EQ(String(string(media.ScanStatusInfected)))
+	return r.getFileInfos(ctx, whereCond, pagingOpt, ownerID, nil, false)
+}
+
+func (r *MediaRepository) DeleteFileInfo(ctx context.Context, fileID string) error {
+	stmt := FileInfo.DELETE().
+		WHERE(FileInfo.ID.EQ(UUID(UUIDStr(fileID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileInfosTrashedBefore(ctx context.Context, before time.Time, limit int) ([]*media.FileInfo, error) {
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo).
+		WHERE(FileInfo.TrashedAt.LT(TimestampT(before))).
+		ORDER_BY(FileInfo.ID.ASC()).
+		LIMIT(int64(limit))
+
+	return runSelectSliceAll[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) TrashFileInfos(ctx context.Context, ownerID string, fileIDs []string) error {
+	inExp := make([]Expression, 0, len(fileIDs))
+	for _, fileID := range fileIDs {
+		inExp = append(inExp, UUID(UUIDStr(fileID)))
+	}
+
+	now := time.Now().UTC()
+	fileInfo := model.FileInfo{
+		TrashedAt: &now,
+		UpdatedAt: now,
+	}
+
+	stmt := FileInfo.UPDATE(FileInfo.TrashedAt, FileInfo.UpdatedAt).
+		MODEL(fileInfo).
+		WHERE(
+			FileInfo.ID.IN(inExp...).
+				AND(FileInfo.TrashedAt.IS_NULL()).
+				AND(FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+		)
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+//--------------------------------
+// Metadata
+//--------------------------------
+
+func (r *MediaRepository) UpsertFileMetadata(ctx context.Context, meta *media.FileMetadata) error {
+	dbModel := new(model.FileMetadata)
+	err := copier.Copy(dbModel, meta)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UpsertFileMetadata:copier.Copy")
+	}
+
+	stmt := FileMetadata.INSERT(FileMetadata.AllColumns).
+		MODEL(dbModel).
+		ON_CONFLICT(FileMetadata.FileID).
+		DO_UPDATE(SET(
+			FileMetadata.MutableColumns.Except(FileMetadata.OwnerID, FileMetadata.CreatedAt).
+				SET(excludedRow(FileMetadata.EXCLUDED.MutableColumns.Except(FileMetadata.EXCLUDED.OwnerID, FileMetadata.EXCLUDED.CreatedAt))),
+		))
+
+	return runInsertNoReturn(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileMetadata(ctx context.Context, fileID string) (*media.FileMetadata, error) {
+	stmt := SELECT(FileMetadata.AllColumns).
+		FROM(FileMetadata).
+		WHERE(FileMetadata.FileID.EQ(UUID(UUIDStr(fileID))))
+
+	return runSelect[model.FileMetadata, media.FileMetadata](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileInfosWithoutMetadata(ctx context.Context, afterID string, limit int) ([]*media.FileInfo, error) {
+	whereCond := FileInfo.TrashedAt.IS_NULL().
+		AND(FileInfo.Category.IN(String(media.CategoryImage), String(media.CategoryAudio))).
+		AND(FileMetadata.FileID.IS_NULL())
+
+	if afterID != "" {
+		whereCond = whereCond.AND(FileInfo.ID.GT(UUID(UUIDStr(afterID))))
+	}
+
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo.LEFT_JOIN(FileMetadata, FileMetadata.FileID.EQ(FileInfo.ID))).
+		WHERE(whereCond).
+		ORDER_BY(FileInfo.ID.ASC()).
+		LIMIT(int64(limit))
+
+	return runSelectSliceAll[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+type timelineItem struct {
+	File     model.FileInfo
+	Metadata model.FileMetadata
+}
+
+func (r *MediaRepository) GetTimeline(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*media.TimelineItem], error) {
+	// The timeline is sorted by time only, "updated" is the timeline time for the cursor.
+	// The options are copied, the caller keeps its own
+	opt := *pagingOpt
+	opt.SortBy = paging.SortByUpdated
+	pagingOpt = &opt
+
+	whereCond := FileMetadata.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+		AND(FileInfo.Category.EQ(String(media.CategoryImage))).
+		AND(FileInfo.TrashedAt.IS_NULL())
+
+	stmt := SELECT(FileInfo.AllColumns, FileMetadata.AllColumns).
+		FROM(FileMetadata.INNER_JOIN(FileInfo, FileInfo.ID.EQ(FileMetadata.FileID)))
+
+	cursorQuery := &cursorQuery{
+		ID:        FileMetadata.FileID,
+		Updated:   FileMetadata.TimelineAt,
+		where:     whereCond,
+		pagingOpt: pagingOpt,
+	}
+
+	page, err := runSelectSlice[timelineItem, media.TimelineItem](ctx, cursorQuery, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.GetTimeline:runSelectSlice")
+	}
+
+	if len(page.Items) > 0 {
+		lastItem := page.Items[len(page.Items)-1]
+		page.NextCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      lastItem.File.ID,
+			Updated: lastItem.Metadata.TimelineAt,
+		})
+
+		firstItem := page.Items[0]
+		page.PrevCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      firstItem.File.ID,
+			Updated: firstItem.Metadata.TimelineAt,
+		})
+	}
+
+	return page, nil
+}
+
+//--------------------------------
+// Search
+//--------------------------------
+
+func (r *MediaRepository) UpsertFileText(ctx context.Context, text *media.FileText) error {
+	stmt := FileText.INSERT(FileText.AllColumns).
+		VALUES(
+			UUID(UUIDStr(text.FileID)),
+			UUID(UUIDStr(text.OwnerID)),
+			TO_TSVECTOR(String(text.Text)),
+			TimestampT(text.CreatedAt),
+			TimestampT(text.UpdatedAt),
+		).
+		ON_CONFLICT(FileText.FileID).
+		DO_UPDATE(SET(
+			FileText.SearchVector.SET(FileText.EXCLUDED.SearchVector),
+			FileText.UpdatedAt.SET(FileText.EXCLUDED.UpdatedAt),
+		))
+
+	return runInsertNoReturn(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFileInfosWithoutText(ctx context.Context, afterID string, limit int) ([]*media.FileInfo, error) {
+	whereCond := FileInfo.TrashedAt.IS_NULL().
+		AND(FileInfo.Category.IN(String(media.CategoryText), String(media.CategoryOther))).
+		AND(FileText.FileID.IS_NULL())
+
+	if afterID != "" {
+		whereCond = whereCond.AND(FileInfo.ID.GT(UUID(UUIDStr(afterID))))
+	}
+
+	stmt := SELECT(FileInfo.AllColumns).
+		FROM(FileInfo.LEFT_JOIN(FileText, FileText.FileID.EQ(FileInfo.ID))).
+		WHERE(whereCond).
+		ORDER_BY(FileInfo.ID.ASC()).
+		LIMIT(int64(limit))
+
+	return runSelectSliceAll[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) SearchFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string, filter *media.SearchFilter) (*paging.Page[*media.FileInfo], error) {
+	whereCond := Bool(true)
+
+	if filter.Term != "" {
+		contentMatch := FileInfo.ID.IN(
+			SELECT(FileText.FileID).
+				FROM(FileText).
+				WHERE(FileText.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+					AND(MATCHES_TSQUERY(FileText.SearchVector, filter.Term))),
+		)
+		whereCond = whereCond.AND(ILIKE(FileInfo.Name, String(containsPattern(filter.Term))).OR(contentMatch))
+	}
+
+	if filter.Category != "" {
+		whereCond = whereCond.AND(FileInfo.Category.EQ(String(string(filter.Category))))
+	}
+
+	if filter.Extension != "" {
+		whereCond = whereCond.AND(LOWER(FileInfo.Extension).EQ(String(filter.Extension)))
+	}
+
+	if filter.MinSize != nil {
+		whereCond = whereCond.AND(FileInfo.Size.GT_EQ(Int64(*filter.MinSize)))
+	}
+
+	if filter.MaxSize != nil {
+		whereCond = whereCond.AND(FileInfo.Size.LT_EQ(Int64(*filter.MaxSize)))
+	}
+
+	if filter.UpdatedFrom != nil {
+		whereCond = whereCond.AND(FileInfo.UpdatedAt.GT_EQ(TimestampT(*filter.UpdatedFrom)))
+	}
+
+	if filter.UpdatedTo != nil {
+		whereCond = whereCond.AND(FileInfo.UpdatedAt.LT_EQ(TimestampT(*filter.UpdatedTo)))
+	}
+
+	if filter.FolderIDs != nil {
+		whereCond = whereCond.AND(FileInfo.FolderID.IN(uuidList(filter.FolderIDs)...))
+	}
+
+	return r.getFileInfos(ctx, whereCond, pagingOpt, ownerID, nil, false)
+}
+
+func (r *MediaRepository) SearchFolderInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string, filter *media.SearchFilter) (*paging.Page[*media.FolderInfo], error) {
+	whereCond := FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID)))
+
+	if filter.Term != "" {
+		whereCond = whereCond.AND(ILIKE(FolderInfo.Name, String(containsPattern(filter.Term))))
+	}
+
+	if filter.UpdatedFrom != nil {
+		whereCond = whereCond.AND(FolderInfo.UpdatedAt.GT_EQ(TimestampT(*filter.UpdatedFrom)))
+	}
+
+	if filter.UpdatedTo != nil {
+		whereCond = whereCond.AND(FolderInfo.UpdatedAt.LT_EQ(TimestampT(*filter.UpdatedTo)))
+	}
+
+	if filter.FolderIDs != nil {
+		whereCond = whereCond.AND(FolderInfo.ParentFolderID.IN(uuidList(filter.FolderIDs)...))
+	}
+
+	return r.getFolderInfos(ctx, whereCond, pagingOpt, false)
+}
+
+//--------------------------------
+// Tags
+//--------------------------------
+
+func (r *MediaRepository) CreateTag(ctx context.Context, tag *media.Tag) (*media.Tag, error) {
+	dbModel := new(model.Tag)
+	err := copier.Copy(dbModel, tag)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateTag:copier.Copy")
+	}
+
+	stmt := Tag.INSERT(Tag.AllColumns).
+		MODEL(dbModel).
+		RETURNING(Tag.AllColumns)
+
+	return runInsert[model.Tag, media.Tag](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetTag(ctx context.Context, ownerID, tagID string) (*media.Tag, error) {
+	stmt := SELECT(Tag.AllColumns).
+		FROM(Tag).
+		WHERE(
+			Tag.ID.EQ(UUID(UUIDStr(tagID))).
+				AND(Tag.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+		)
+
+	return runSelect[model.Tag, media.Tag](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetTags(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*media.Tag], error) {
+	whereCond := Tag.OwnerID.EQ(UUID(UUIDStr(ownerID)))
+
+	stmt := SELECT(Tag.AllColumns).
+		FROM(Tag)
+
+	cursorQuery := &cursorQuery{
+		ID:        Tag.ID,
+		Name:      Tag.Name,
+		Updated:   Tag.UpdatedAt,
+		where:     whereCond,
+		pagingOpt: pagingOpt,
+	}
+
+	page, err := runSelectSlice[model.Tag, media.Tag](ctx, cursorQuery, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.GetTags:runSelectSlice")
+	}
+
+	if len(page.Items) > 0 {
+		lastItem := page.Items[len(page.Items)-1]
+		page.NextCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      lastItem.ID,
+			Name:    lastItem.Name,
+			Updated: lastItem.UpdatedAt,
+		})
+
+		firstItem := page.Items[0]
+		page.PrevCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      firstItem.ID,
+			Name:    firstItem.Name,
+			Updated: firstItem.UpdatedAt,
+		})
+	}
+
+	return page, nil
+}
+
+func (r *MediaRepository) UpdateTag(ctx context.Context, tag *media.Tag) error {
+	stmt := Tag.UPDATE(Tag.Name, Tag.Color, Tag.UpdatedAt).
+		MODEL(model.Tag{Name: tag.Name, Color: tag.Color, UpdatedAt: tag.UpdatedAt}).
+		WHERE(
+			Tag.ID.EQ(UUID(UUIDStr(tag.ID))).
+				AND(Tag.OwnerID.EQ(UUID(UUIDStr(tag.OwnerID)))),
+		)
+
+	err := runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+	if err != nil && apperror.Contains(err, "unique constraint") {
+		return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonDuplicateData, err), "repository.UpdateTag:runUpdateOrDelete")
+	}
+	return err
+}
+
+func (r *MediaRepository) DeleteTag(ctx context.Context, ownerID, tagID string) error {
+	stmt := Tag.DELETE().
+		WHERE(
+			Tag.ID.EQ(UUID(UUIDStr(tagID))).
+				AND(Tag.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+		)
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) TagFileInfos(ctx context.Context, ownerID, tagID string, fileIDs []string) error {
+	stmt := FileTag.INSERT(FileTag.FileID, FileTag.TagID, FileTag.CreatedAt).
+		QUERY(
+			SELECT(FileInfo.ID, Tag.ID, TimestampT(time.Now().UTC())).
+				FROM(FileInfo, Tag).
+				WHERE(
+					FileInfo.ID.IN(uuidList(fileIDs)...).
+						AND(FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID)))).
+						AND(Tag.ID.EQ(UUID(UUIDStr(tagID)))).
+						AND(Tag.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+				),
+		).
+		ON_CONFLICT(FileTag.FileID, FileTag.TagID).
+		DO_NOTHING()
+
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.TagFileInfos:ExecContext")
+	}
+	return nil
+}
+
+func (r *MediaRepository) TagFolderInfos(ctx context.Context, ownerID, tagID string, folderIDs []string) error {
+	stmt := FolderTag.INSERT(FolderTag.FolderID, FolderTag.TagID, FolderTag.CreatedAt).
+		QUERY(
+			SELECT(FolderInfo.ID, Tag.ID, TimestampT(time.Now().UTC())).
+				FROM(FolderInfo, Tag).
+				WHERE(
+					FolderInfo.ID.IN(uuidList(folderIDs)...).
+						AND(FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID)))).
+						AND(Tag.ID.EQ(UUID(UUIDStr(tagID)))).
+						AND(Tag.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+				),
+		).
+		ON_CONFLICT(FolderTag.FolderID, FolderTag.TagID).
+		DO_NOTHING()
+
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.TagFolderInfos:ExecContext")
+	}
+	return nil
+}
+
+func (r *MediaRepository) UntagFileInfos(ctx context.Context, ownerID, tagID string, fileIDs []string) error {
+	stmt := FileTag.DELETE().
+		USING(Tag).
+		WHERE(
+			FileTag.TagID.EQ(Tag.ID).
+				AND(Tag.ID.EQ(UUID(UUIDStr(tagID)))).
+				AND(Tag.OwnerID.EQ(UUID(UUIDStr(ownerID)))).
+				AND(FileTag.FileID.IN(uuidList(fileIDs)...)),
+		)
+
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UntagFileInfos:ExecContext")
+	}
+	return nil
+}
+
+func (r *MediaRepository) UntagFolderInfos(ctx context.Context, ownerID, tagID string, folderIDs []string) error {
+	stmt := FolderTag.DELETE().
+		USING(Tag).
+		WHERE(
+			FolderTag.TagID.EQ(Tag.ID).
+				AND(Tag.ID.EQ(UUID(UUIDStr(tagID)))).
+				AND(Tag.OwnerID.EQ(UUID(UUIDStr(ownerID)))).
+				AND(FolderTag.FolderID.IN(uuidList(folderIDs)...)),
+		)
+
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UntagFolderInfos:ExecContext")
+	}
+	return nil
+}
+
+func (r *MediaRepository) GetFileInfosByTag(ctx context.Context, pagingOpt *paging.Options, ownerID, tagID string) (*paging.Page[*media.FileInfo], error) {
+	whereCond := FileInfo.ID.IN(
+		SELECT(FileTag.FileID).
+			FROM(FileTag).
+			WHERE(FileTag.TagID.EQ(UUID(UUIDStr(tagID)))),
+	)
+	return r.getFileInfos(ctx, whereCond, pagingOpt, ownerID, nil, false)
+}
+
+func (r *MediaRepository) GetFolderInfosByTag(ctx context.Context, pagingOpt *paging.Options, ownerID, tagID string) (*paging.Page[*media.FolderInfo], error) {
+	whereCond := FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+		AND(FolderInfo.ID.IN(
+			SELECT(FolderTag.FolderID).
+				FROM(FolderTag).
+				WHERE(FolderTag.TagID.EQ(UUID(UUIDStr(tagID)))),
+		))
+	return r.getFolderInfos(ctx, whereCond, pagingOpt, false)
+}
+
+func (r *MediaRepository) GetFileTags(ctx context.Context, fileID string) ([]*media.Tag, error) {
+	stmt := SELECT(Tag.AllColumns).
+		FROM(Tag.INNER_JOIN(FileTag, FileTag.TagID.EQ(Tag.ID))).
+		WHERE(FileTag.FileID.EQ(UUID(UUIDStr(fileID)))).
+		ORDER_BY(Tag.Name.ASC())
+
+	return runSelectSliceAll[model.Tag, media.Tag](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetFolderTags(ctx context.Context, folderID string) ([]*media.Tag, error) {
+	stmt := SELECT(Tag.AllColumns).
+		FROM(Tag.INNER_JOIN(FolderTag, FolderTag.TagID.EQ(Tag.ID))).
+		WHERE(FolderTag.FolderID.EQ(UUID(UUIDStr(folderID)))).
+		ORDER_BY(Tag.Name.ASC())
+
+	return runSelectSliceAll[model.Tag, media.Tag](ctx, stmt, r.repository.dbTx)
+}
+
+//--------------------------------
+// Properties
+//--------------------------------
+
+func (r *MediaRepository) GetFileProperties(ctx context.Context, fileID string) (map[string]string, error) {
+	stmt := SELECT(FileProperty.AllColumns).
+		FROM(FileProperty).
+		WHERE(FileProperty.FileID.EQ(UUID(UUIDStr(fileID))))
+
+	var dbModels []model.FileProperty
+	err := stmt.QueryContext(ctx, r.repository.dbTx, &dbModels)
+	if err != nil && !errors.Is(err, qrm.ErrNoRows) {
+		return nil, apperror.NewAppError(err, "repository.GetFileProperties:QueryContext")
+	}
+
+	props := make(map[string]string, len(dbModels))
+	for _, p := range dbModels {
+		props[p.Key] = p.Value
+	}
+	return props, nil
+}
+
+func (r *MediaRepository) GetFolderProperties(ctx context.Context, folderID string) (map[string]string, error) {
+	stmt := SELECT(FolderProperty.AllColumns).
+		FROM(FolderProperty).
+		WHERE(FolderProperty.FolderID.EQ(UUID(UUIDStr(folderID))))
+
+	var dbModels []model.FolderProperty
+	err := stmt.QueryContext(ctx, r.repository.dbTx, &dbModels)
+	if err != nil && !errors.Is(err, qrm.ErrNoRows) {
+		return nil, apperror.NewAppError(err, "repository.GetFolderProperties:QueryContext")
+	}
+
+	props := make(map[string]string, len(dbModels))
+	for _, p := range dbModels {
+		props[p.Key] = p.Value
+	}
+	return props, nil
+}
+
+func (r *MediaRepository) ReplaceFileProperties(ctx context.Context, fileID string, props map[string]string) error {
+	id, err := uuid.Parse(fileID)
+	if err != nil {
+		return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "repository.ReplaceFileProperties:Parse")
+	}
+
+	_, err = FileProperty.DELETE().
+		WHERE(FileProperty.FileID.EQ(UUID(id))).
+		ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.ReplaceFileProperties:Delete")
+	}
+
+	if len(props) == 0 {
+		return nil
+	}
+
+	dbModels := make([]model.FileProperty, 0, len(props))
+	for key, value := range props {
+		dbModels = append(dbModels, model.FileProperty{FileID: id, Key: key, Value: value})
+	}
+
+	stmt := FileProperty.INSERT(FileProperty.AllColumns).
+		MODELS(dbModels)
+
+	return runInsertNoReturn(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) ReplaceFolderProperties(ctx context.Context, folderID string, props map[string]string) error {
+	id, err := uuid.Parse(folderID)
+	if err != nil {
+		return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "repository.ReplaceFolderProperties:Parse")
+	}
+
+	_, err = FolderProperty.DELETE().
+		WHERE(FolderProperty.FolderID.EQ(UUID(id))).
+		ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.ReplaceFolderProperties:Delete")
+	}
+
+	if len(props) == 0 {
+		return nil
+	}
+
+	dbModels := make([]model.FolderProperty, 0, len(props))
+	for key, value := range props {
+		dbModels = append(dbModels, model.FolderProperty{FolderID: id, Key: key, Value: value})
+	}
+
+	stmt := FolderProperty.INSERT(FolderProperty.AllColumns).
+		MODELS(dbModels)
+
+	return runInsertNoReturn(ctx, stmt, r.repository.dbTx)
+}
+
 //--------------------------------
-// File
+// Starred & Recent
 //--------------------------------
 
-func (r *MediaRepository) CreateFileInfo(ctx context.Context, info *media.FileInfo) (*media.FileInfo, error) {
-	dbModel := new(model.FileInfo)
-	err := copier.Copy(dbModel, info)
+func (r *MediaRepository) StarFileInfos(ctx context.Context, ownerID string, fileIDs []string) error {
+	stmt := StarredFile.INSERT(StarredFile.OwnerID, StarredFile.FileID, StarredFile.CreatedAt).
+		QUERY(
+			SELECT(FileInfo.OwnerID, FileInfo.ID, TimestampT(time.Now().UTC())).
+				FROM(FileInfo).
+				WHERE(
+					FileInfo.ID.IN(uuidList(fileIDs)...).
+						AND(FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+				),
+		).
+		ON_CONFLICT(StarredFile.OwnerID, StarredFile.FileID).
+		DO_NOTHING()
+
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
 	if err != nil {
-		return nil, apperror.NewAppError(err, "repository.CreateFileInfo:copier.Copy")
+		return apperror.NewAppError(err, "repository.StarFileInfos:ExecContext")
 	}
+	return nil
+}
 
-	stmt := FileInfo.INSERT(
-		FileInfo.AllColumns,
-	).MODEL(dbModel).RETURNING(FileInfo.AllColumns)
+func (r *MediaRepository) StarFolderInfos(ctx context.Context, ownerID string, folderIDs []string) error {
+	stmt := StarredFolder.INSERT(StarredFolder.OwnerID, StarredFolder.FolderID, StarredFolder.CreatedAt).
+		QUERY(
+			SELECT(FolderInfo.OwnerID, FolderInfo.ID, TimestampT(time.Now().UTC())).
+				FROM(FolderInfo).
+				WHERE(
+					FolderInfo.ID.IN(uuidList(folderIDs)...).
+						AND(FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+				),
+		).
+		ON_CONFLICT(StarredFolder.OwnerID, StarredFolder.FolderID).
+		DO_NOTHING()
 
-	return runInsert[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.StarFolderInfos:ExecContext")
+	}
+	return nil
 }
 
-func (r *MediaRepository) getFileInfo(ctx context.Context, fileID string, onlyTrashed bool) (*media.FileInfo, error) {
-	whereCond := FileInfo.ID.EQ(UUID(UUIDStr(fileID)))
+func (r *MediaRepository) UnstarFileInfos(ctx context.Context, ownerID string, fileIDs []string) error {
+	stmt := StarredFile.DELETE().
+		WHERE(
+			StarredFile.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+				AND(StarredFile.FileID.IN(uuidList(fileIDs)...)),
+		)
 
-	if onlyTrashed {
-		whereCond = whereCond.AND(FileInfo.TrashedAt.IS_NOT_NULL())
-	} else {
-		whereCond = whereCond.AND(FileInfo.TrashedAt.IS_NULL())
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UnstarFileInfos:ExecContext")
 	}
+	return nil
+}
 
-	stmt := SELECT(FileInfo.AllColumns).
-		FROM(FileInfo).
-		WHERE(whereCond)
+func (r *MediaRepository) UnstarFolderInfos(ctx context.Context, ownerID string, folderIDs []string) error {
+	stmt := StarredFolder.DELETE().
+		WHERE(
+			StarredFolder.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+				AND(StarredFolder.FolderID.IN(uuidList(folderIDs)...)),
+		)
 
-	return runSelect[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+	_, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UnstarFolderInfos:ExecContext")
+	}
+	return nil
 }
 
-func (r *MediaRepository) GetFileInfo(ctx context.Context, fileID string) (*media.FileInfo, error) {
-	return r.getFileInfo(ctx, fileID, false)
+func (r *MediaRepository) GetStarredFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*media.FileInfo], error) {
+	whereCond := FileInfo.ID.IN(
+		SELECT(StarredFile.FileID).
+			FROM(StarredFile).
+			WHERE(StarredFile.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+	)
+	return r.getFileInfos(ctx, whereCond, pagingOpt, ownerID, nil, false)
 }
 
-func (r *MediaRepository) GetFileInfoTrashed(ctx context.Context, fileID string) (*media.FileInfo, error) {
-	return r.getFileInfo(ctx, fileID, true)
+func (r *MediaRepository) GetStarredFolderInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*media.FolderInfo], error) {
+	whereCond := FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+		AND(FolderInfo.ID.IN(
+			SELECT(StarredFolder.FolderID).
+				FROM(StarredFolder).
+				WHERE(StarredFolder.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+		))
+	return r.getFolderInfos(ctx, whereCond, pagingOpt, false)
 }
 
-func (r *MediaRepository) getFileInfos(ctx context.Context, whereCond BoolExpression, pagingOpt *paging.Options, ownerID string, folderID *string, includeFolderID bool) (*paging.Page[*media.FileInfo], error) {
-	if whereCond == nil {
-		whereCond = Bool(true)
-	}
-
-	whereCond = whereCond.AND(FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))))
+func (r *MediaRepository) UpsertRecentFile(ctx context.Context, recent *media.RecentFile) error {
+	stmt := RecentFile.INSERT(RecentFile.AllColumns).
+		VALUES(
+			UUID(UUIDStr(recent.OwnerID)),
+			UUID(UUIDStr(recent.FileID)),
+			String(string(recent.Activity)),
+			TimestampT(recent.ActivityAt),
+		).
+		ON_CONFLICT(RecentFile.OwnerID, RecentFile.FileID).
+		DO_UPDATE(SET(
+			RecentFile.Activity.SET(RecentFile.EXCLUDED.Activity),
+			RecentFile.ActivityAt.SET(RecentFile.EXCLUDED.ActivityAt),
+		))
+
+	return runInsertNoReturn(ctx, stmt, r.repository.dbTx)
+}
 
-	if includeFolderID {
-		if folderID == nil {
-			whereCond = whereCond.AND(FileInfo.FolderID.IS_NULL())
-		} else {
-			whereCond = whereCond.AND(FileInfo.FolderID.EQ(UUID(UUIDStr(*folderID))))
-		}
-	}
+type recentItem struct {
+	File       model.FileInfo
+	RecentFile model.RecentFile
+}
 
-	whereCond = whereCond.AND(FileInfo.TrashedAt.IS_NULL())
+func (r *MediaRepository) GetRecentFiles(ctx context.Context, pagingOpt *paging.Options, ownerID string) (*paging.Page[*media.RecentItem], error) {
+	// The feed is sorted by time only, "updated" is the activity time for the cursor.
+	// The options are copied, the caller keeps its own
+	opt := *pagingOpt
+	opt.SortBy = paging.SortByUpdated
+	pagingOpt = &opt
 
-	orderBy := []OrderByClause{FileInfo.OwnerID.ASC(), FileInfo.FolderID.ASC()}
+	whereCond := RecentFile.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+		AND(FileInfo.TrashedAt.IS_NULL())
 
-	stmt := SELECT(FileInfo.AllColumns).
-		FROM(FileInfo)
+	stmt := SELECT(FileInfo.AllColumns, RecentFile.AllColumns).
+		FROM(RecentFile.INNER_JOIN(FileInfo, FileInfo.ID.EQ(RecentFile.FileID)))
 
 	cursorQuery := &cursorQuery{
-		ID:        FileInfo.ID,
-		Name:      FileInfo.Name,
-		Updated:   FileInfo.UpdatedAt,
+		ID:        RecentFile.FileID,
+		Updated:   RecentFile.ActivityAt,
 		where:     whereCond,
-		orderBy:   orderBy,
 		pagingOpt: pagingOpt,
 	}
 
-	page, err := runSelectSlice[model.FileInfo, media.FileInfo](ctx, cursorQuery, stmt, r.repository.dbTx)
+	page, err := runSelectSlice[recentItem, media.RecentItem](ctx, cursorQuery, stmt, r.repository.dbTx)
 	if err != nil {
-		return nil, apperror.NewAppError(err, "repository.GetFileInfos:runSelectSlice")
+		return nil, apperror.NewAppError(err, "repository.GetRecentFiles:runSelectSlice")
 	}
 
 	if len(page.Items) > 0 {
 		lastItem := page.Items[len(page.Items)-1]
-		nextCursor := &paging.Cursor{
-			ID:      lastItem.ID,
-			Name:    lastItem.Name,
-			Updated: lastItem.UpdatedAt,
-		}
-		page.NextCursor = pagingOpt.CreateCursor(nextCursor)
+		page.NextCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      lastItem.File.ID,
+			Updated: lastItem.RecentFile.ActivityAt,
+		})
 
 		firstItem := page.Items[0]
-		prevCursor := &paging.Cursor{
-			ID:      firstItem.ID,
-			Name:    firstItem.Name,
-			Updated: firstItem.UpdatedAt,
-		}
-		page.PrevCursor = pagingOpt.CreateCursor(prevCursor)
+		page.PrevCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      firstItem.File.ID,
+			Updated: firstItem.RecentFile.ActivityAt,
+		})
 	}
 
 	return page, nil
 }
 
-func (r *MediaRepository) GetFileInfos(ctx context.Context, pagingOpt *paging.Options, ownerID string, folderID *string) (*paging.Page[*media.FileInfo], error) {
-	return r.getFileInfos(ctx, nil, pagingOpt, ownerID, folderID, true)
+//--------------------------------
+// Storage Usage
+//--------------------------------
+
+func (r *MediaRepository) GetStorageUsage(ctx context.Context, ownerID string) (*media.StorageUsage, error) {
+	stmt := SELECT(StorageUsage.AllColumns).
+		FROM(StorageUsage).
+		WHERE(StorageUsage.OwnerID.EQ(UUID(UUIDStr(ownerID))))
+
+	usage, err := runSelect[model.StorageUsage, media.StorageUsage](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		// The usage is created with the first file of the owner
+		if errors.Is(err, apperror.ErrCommonNoData) {
+			return &media.StorageUsage{OwnerID: ownerID}, nil
+		}
+		return nil, apperror.NewAppError(err, "repository.GetStorageUsage:runSelect")
+	}
+
+	return usage, nil
 }
 
-func (r *MediaRepository) GetFileInfosByCategory(ctx context.Context, pagingOpt *paging.Options, ownerID string, category media.Category) (*paging.Page[*media.FileInfo], error) {
-	whereCond := FileInfo.Category.EQ(String(string(category)))
-	return r.getFileInfos(ctx, whereCond, pagingOpt, ownerID, nil, false)
+type categoryUsage struct {
+	Category *string
+	Bytes    int64
+	Files    int64
 }
 
-func (r *MediaRepository) UpdateFileInfo(ctx context.Context, info *media.FileInfo) error {
-	stmt := FileInfo.UPDATE(FileInfo.MutableColumns).
-		MODEL(info).
-		WHERE(FileInfo.ID.EQ(UUID(UUIDStr(info.ID))))
+func (r *MediaRepository) GetCategoryUsages(ctx context.Context, ownerID string) ([]*media.CategoryUsage, error) {
+	stmt := SELECT(
+		FileInfo.Category.AS("category_usage.category"),
+		CAST(SUM(FileInfo.Size)).AS_BIGINT().AS("category_usage.bytes"),
+		COUNT(FileInfo.ID).AS("category_usage.files"),
+	).
+		FROM(FileInfo).
+		WHERE(
+			FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+				AND(FileInfo.TrashedAt.IS_NULL()),
+		).
+		GROUP_BY(FileInfo.Category).
+		ORDER_BY(IntegerColumn("category_usage.bytes").DESC())
+
+	var dbModels []categoryUsage
+	err := stmt.QueryContext(ctx, r.repository.dbTx, &dbModels)
+	if err != nil && !errors.Is(err, qrm.ErrNoRows) {
+		return nil, apperror.NewAppError(err, "repository.GetCategoryUsages:QueryContext")
+	}
 
-	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+	res := make([]*media.CategoryUsage, 0, len(dbModels))
+	for _, m := range dbModels {
+		category := media.Category(media.CategoryOther)
+		if m.Category != nil {
+			category = media.Category(*m.Category)
+		}
+		res = append(res, &media.CategoryUsage{Category: category, Bytes: m.Bytes, Files: m.Files})
+	}
+
+	return res, nil
 }
 
-func (r *MediaRepository) DeleteFileInfo(ctx context.Context, fileID string) error {
-	stmt := FileInfo.DELETE().
-		WHERE(FileInfo.ID.EQ(UUID(UUIDStr(fileID))))
+func (r *MediaRepository) LockStorageUsage(ctx context.Context, ownerID string) (*media.StorageUsage, error) {
+	// The usage is created with the first file of the owner, the row must exist to be locked
+	insertStmt := StorageUsage.INSERT(StorageUsage.OwnerID).
+		VALUES(UUID(UUIDStr(ownerID))).
+		ON_CONFLICT(StorageUsage.OwnerID).
+		DO_NOTHING()
 
-	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+	_, err := insertStmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.LockStorageUsage:ExecContext")
+	}
+
+	stmt := SELECT(StorageUsage.AllColumns).
+		FROM(StorageUsage).
+		WHERE(StorageUsage.OwnerID.EQ(UUID(UUIDStr(ownerID)))).
+		FOR(UPDATE())
+
+	usage, err := runSelect[model.StorageUsage, media.StorageUsage](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.LockStorageUsage:runSelect")
+	}
+
+	return usage, nil
 }
 
-func (r *MediaRepository) TrashFileInfos(ctx context.Context, ownerID string, fileIDs []string) error {
-	inExp := make([]Expression, 0, len(fileIDs))
-	for _, fileID := range fileIDs {
-		inExp = append(inExp, UUID(UUIDStr(fileID)))
+func (r *MediaRepository) SetStorageQuota(ctx context.Context, ownerID string, quotaBytes *int64) error {
+	id, err := uuid.Parse(ownerID)
+	if err != nil {
+		return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "repository.SetStorageQuota:ParseOwnerID")
 	}
 
-	now := time.Now().UTC()
-	fileInfo := model.FileInfo{
-		TrashedAt: &now,
-		UpdatedAt: now,
+	dbModel := model.StorageUsage{
+		OwnerID:    id,
+		QuotaBytes: quotaBytes,
+		UpdatedAt:  time.Now().UTC(),
 	}
 
-	stmt := FileInfo.UPDATE(FileInfo.TrashedAt, FileInfo.UpdatedAt).
-		MODEL(fileInfo).
+	stmt := StorageUsage.INSERT(StorageUsage.OwnerID, StorageUsage.QuotaBytes, StorageUsage.UpdatedAt).
+		MODEL(dbModel).
+		ON_CONFLICT(StorageUsage.OwnerID).
+		DO_UPDATE(SET(
+			StorageUsage.QuotaBytes.SET(StorageUsage.EXCLUDED.QuotaBytes),
+			StorageUsage.UpdatedAt.SET(StorageUsage.EXCLUDED.UpdatedAt),
+		))
+
+	err = runInsertNoReturn(ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		if apperror.Contains(err, "foreign key") {
+			return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonNoData, err), "repository.SetStorageQuota:runInsertNoReturn")
+		}
+		return apperror.NewAppError(err, "repository.SetStorageQuota:runInsertNoReturn")
+	}
+
+	return nil
+}
+
+//--------------------------------
+// Changes
+//--------------------------------
+
+// The changes are recorded by the triggers on file_info and folder_info, see the change_log migration
+
+func (r *MediaRepository) GetChangeLogState(ctx context.Context, ownerID string) (*media.ChangeLogState, error) {
+	stmt := SELECT(ChangeLogState.AllColumns).
+		FROM(ChangeLogState).
+		WHERE(ChangeLogState.OwnerID.EQ(UUID(UUIDStr(ownerID))))
+
+	state, err := runSelect[model.ChangeLogState, media.ChangeLogState](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		// The state is created with the first change of the owner
+		if errors.Is(err, apperror.ErrCommonNoData) {
+			return &media.ChangeLogState{OwnerID: ownerID}, nil
+		}
+		return nil, apperror.NewAppError(err, "repository.GetChangeLogState:runSelect")
+	}
+
+	return state, nil
+}
+
+func (r *MediaRepository) GetChanges(ctx context.Context, ownerID string, afterSeq int64, limit int) ([]*media.Change, error) {
+	stmt := SELECT(ChangeLog.AllColumns).
+		FROM(ChangeLog).
 		WHERE(
-			FileInfo.ID.IN(inExp...).
-				AND(FileInfo.TrashedAt.IS_NULL()).
-				AND(FileInfo.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
-		)
+			ChangeLog.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+				AND(ChangeLog.Seq.GT(Int64(afterSeq))),
+		).
+		ORDER_BY(ChangeLog.Seq.ASC()).
+		LIMIT(int64(limit))
 
-	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+	return runSelectSliceAll[model.ChangeLog, media.Change](ctx, stmt, r.repository.dbTx)
+}
+
+type compactedChanges struct {
+	Count int64
+}
+
+func (r *MediaRepository) CompactChangeLog(ctx context.Context, before time.Time) (int64, error) {
+	// The purge and the compacted sequences of the owners are updated together by the function
+	stmt := SELECT(
+		RawInt("change_log_compact(#before)", RawArgs{"#before": before}).AS("compacted_changes.count"),
+	)
+
+	var dbModel compactedChanges
+	err := stmt.QueryContext(ctx, r.repository.dbTx, &dbModel)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.CompactChangeLog:QueryContext")
+	}
+
+	return dbModel.Count, nil
 }
 
 //--------------------------------
@@ -200,6 +1196,20 @@ func (r *MediaRepository) CreateFolderInfo(ctx context.Context, info *media.Fold
 	return runInsert[model.FolderInfo, media.FolderInfo](ctx, stmt, r.repository.dbTx)
 }
 
+// folderInfo is a folder with its recursive stats, see folderInfoWithStats.
+type folderInfo struct {
+	model.FolderInfo
+	Size        int64 `alias:"folder_stats.size"`
+	FileCount   int64 `alias:"folder_stats.file_count"`
+	FolderCount int64 `alias:"folder_stats.folder_count"`
+}
+
+// folderInfoWithStats joins the stats of the folders, kept up to date by the database triggers.
+var folderInfoWithStats = FolderInfo.LEFT_JOIN(FolderStats, FolderStats.FolderID.EQ(FolderInfo.ID))
+
+// folderSizeColumn sorts the folders by their recursive size.
+var folderSizeColumn = IntExp(COALESCE(FolderStats.Size, Int(0)))
+
 func (r *MediaRepository) getFolderInfo(ctx context.Context, ownerID, folderID string, onlyTrashed bool) (*media.FolderInfo, error) {
 	whereCond := FolderInfo.ID.EQ(UUID(UUIDStr(folderID))).AND(FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))))
 
@@ -209,17 +1219,34 @@ func (r *MediaRepository) getFolderInfo(ctx context.Context, ownerID, folderID s
 		whereCond = whereCond.AND(FolderInfo.TrashedAt.IS_NULL())
 	}
 
-	stmt := SELECT(FolderInfo.AllColumns).
-		FROM(FolderInfo).
+	stmt := SELECT(FolderInfo.AllColumns, FolderStats.Size, FolderStats.FileCount, FolderStats.FolderCount).
+		FROM(folderInfoWithStats).
 		WHERE(whereCond)
 
-	return runSelect[model.FolderInfo, media.FolderInfo](ctx, stmt, r.repository.dbTx)
+	return runSelect[folderInfo, media.FolderInfo](ctx, stmt, r.repository.dbTx)
 }
 
 func (r *MediaRepository) GetFolderInfo(ctx context.Context, ownerID, folderID string) (*media.FolderInfo, error) {
 	return r.getFolderInfo(ctx, ownerID, folderID, false)
 }
 
+func (r *MediaRepository) GetFolderInfoByName(ctx context.Context, ownerID string, parentFolderID *string, name string) (*media.FolderInfo, error) {
+	whereCond := FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))).
+		AND(FolderInfo.Name.EQ(String(name))).
+		AND(FolderInfo.TrashedAt.IS_NULL())
+	if parentFolderID == nil {
+		whereCond = whereCond.AND(FolderInfo.ParentFolderID.IS_NULL())
+	} else {
+		whereCond = whereCond.AND(FolderInfo.ParentFolderID.EQ(UUID(UUIDStr(*parentFolderID))))
+	}
+
+	stmt := SELECT(FolderInfo.AllColumns, FolderStats.Size, FolderStats.FileCount, FolderStats.FolderCount).
+		FROM(folderInfoWithStats).
+		WHERE(whereCond)
+
+	return runSelect[folderInfo, media.FolderInfo](ctx, stmt, r.repository.dbTx)
+}
+
 func (r *MediaRepository) GetFolderInfoTrashed(ctx context.Context, ownerID, folderID string) (*media.FolderInfo, error) {
 	return r.getFolderInfo(ctx, ownerID, folderID, true)
 }
@@ -232,22 +1259,35 @@ func (r *MediaRepository) GetFolderInfos(ctx context.Context, pagingOpt *paging.
 		whereCond = whereCond.AND(FolderInfo.ParentFolderID.EQ(UUID(UUIDStr(*parentFolderID))))
 	}
 
+	return r.getFolderInfos(ctx, whereCond, pagingOpt, true)
+}
+
+// getFolderInfos returns a page of the folders matching whereCond.
+// inParentFolder tells if whereCond limits the folders to a single parent folder.
+func (r *MediaRepository) getFolderInfos(ctx context.Context, whereCond BoolExpression, pagingOpt *paging.Options, inParentFolder bool) (*paging.Page[*media.FolderInfo], error) {
 	whereCond = whereCond.AND(FolderInfo.TrashedAt.IS_NULL())
-	orderBy := []OrderByClause{FolderInfo.OwnerID.ASC(), FolderInfo.ParentFolderID.ASC()}
 
-	stmt := SELECT(FolderInfo.AllColumns).
-		FROM(FolderInfo)
+	// The cursor only holds the sort column and the ID, so the parent folder can only lead the order within a single parent folder
+	var orderBy []OrderByClause
+	if inParentFolder {
+		orderBy = []OrderByClause{FolderInfo.OwnerID.ASC(), FolderInfo.ParentFolderID.ASC()}
+	}
+
+	stmt := SELECT(FolderInfo.AllColumns, FolderStats.Size, FolderStats.FileCount, FolderStats.FolderCount).
+		FROM(folderInfoWithStats)
 
 	cursorQuery := &cursorQuery{
 		ID:        FolderInfo.ID,
 		Name:      FolderInfo.Name,
 		Updated:   FolderInfo.UpdatedAt,
+		Size:      folderSizeColumn,
+		Created:   FolderInfo.CreatedAt,
 		where:     whereCond,
 		orderBy:   orderBy,
 		pagingOpt: pagingOpt,
 	}
 
-	page, err := runSelectSlice[model.FolderInfo, media.FolderInfo](ctx, cursorQuery, stmt, r.repository.dbTx)
+	page, err := runSelectSlice[folderInfo, media.FolderInfo](ctx, cursorQuery, stmt, r.repository.dbTx)
 	if err != nil {
 		return nil, apperror.NewAppError(err, "repository.GetFolderInfos:runSelectSlice")
 	}
@@ -258,6 +1298,8 @@ func (r *MediaRepository) GetFolderInfos(ctx context.Context, pagingOpt *paging.
 			ID:      lastItem.ID,
 			Name:    lastItem.Name,
 			Updated: lastItem.UpdatedAt,
+			Size:    lastItem.Size,
+			Created: lastItem.CreatedAt,
 		}
 		page.NextCursor = pagingOpt.CreateCursor(nextCursor)
 
@@ -266,6 +1308,8 @@ func (r *MediaRepository) GetFolderInfos(ctx context.Context, pagingOpt *paging.
 			ID:      firstItem.ID,
 			Name:    firstItem.Name,
 			Updated: firstItem.UpdatedAt,
+			Size:    firstItem.Size,
+			Created: firstItem.CreatedAt,
 		}
 		page.PrevCursor = pagingOpt.CreateCursor(prevCursor)
 	}
@@ -288,6 +1332,30 @@ func (r *MediaRepository) DeleteFolderInfo(ctx context.Context, folderID string)
 	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
 }
 
+func (r *MediaRepository) DeleteFolderInfosTrashedBefore(ctx context.Context, before time.Time) (int64, error) {
+	stmt := FolderInfo.DELETE().
+		WHERE(
+			FolderInfo.TrashedAt.LT(TimestampT(before)).
+				AND(NOT(EXISTS(
+					SELECT(Int(1)).
+						FROM(FileInfo).
+						WHERE(FileInfo.FolderID.EQ(FolderInfo.ID)),
+				))),
+		)
+
+	res, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteFolderInfosTrashedBefore:ExecContext")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteFolderInfosTrashedBefore:RowsAffected")
+	}
+
+	return count, nil
+}
+
 func (r *MediaRepository) TrashFolderInfos(ctx context.Context, ownerID string, folderIDs []string) error {
 	nestedFoldersCTE := r.getNestedFoldersCTE(ownerID, folderIDs, false)
 	trashFilesCTE := CTE("trash_files")
@@ -408,8 +1476,10 @@ func (r *MediaRepository) getNestedFoldersCTE(ownerID string, folderIDs []string
 func (r *MediaRepository) GetDescendantFolderIDs(ctx context.Context, ownerID, folderID string) ([]string, error) {
 	nestedFoldersCTE := r.getNestedFoldersCTE(ownerID, []string{folderID}, false)
 
-	stmt := SELECT(FolderInfo.ID.From(nestedFoldersCTE)).
-		FROM(nestedFoldersCTE)
+	stmt := WITH_RECURSIVE(nestedFoldersCTE)(
+		SELECT(FolderInfo.ID.From(nestedFoldersCTE)).
+			FROM(nestedFoldersCTE),
+	)
 
 	var folderIDs []string
 	err := stmt.QueryContext(ctx, r.repository.dbTx, &folderIDs)
@@ -422,6 +1492,90 @@ func (r *MediaRepository) GetDescendantFolderIDs(ctx context.Context, ownerID, f
 	}), nil
 }
 
+func (r *MediaRepository) GetSubtreeFolderInfos(ctx context.Context, ownerID string, folderIDs []string) ([]*media.FolderInfo, error) {
+	nestedFoldersCTE := r.getNestedFoldersCTE(ownerID, folderIDs, false)
+
+	stmt := WITH_RECURSIVE(nestedFoldersCTE)(
+		SELECT(FolderInfo.AllColumns).
+			FROM(FolderInfo).
+			WHERE(
+				FolderInfo.ID.IN(
+					SELECT(FolderInfo.ID.From(nestedFoldersCTE)).FROM(nestedFoldersCTE),
+				),
+			),
+	)
+
+	return runSelectSliceAll[model.FolderInfo, media.FolderInfo](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetSubtreeFileInfos(ctx context.Context, ownerID string, folderIDs []string) ([]*media.FileInfo, error) {
+	nestedFoldersCTE := r.getNestedFoldersCTE(ownerID, folderIDs, false)
+
+	stmt := WITH_RECURSIVE(nestedFoldersCTE)(
+		SELECT(FileInfo.AllColumns).
+			FROM(FileInfo).
+			WHERE(
+				FileInfo.FolderID.IN(
+					SELECT(FolderInfo.ID.From(nestedFoldersCTE)).FROM(nestedFoldersCTE),
+				).AND(FileInfo.TrashedAt.IS_NULL()),
+			),
+	)
+
+	return runSelectSliceAll[model.FileInfo, media.FileInfo](ctx, stmt, r.repository.dbTx)
+}
+
+// folderTreeDepth is 1 for the children of the tree's root folder, 2 for the grandchildren and so on
+var folderTreeDepth = IntegerColumn("depth")
+
+func (r *MediaRepository) GetFolderTree(ctx context.Context, ownerID string, folderID *string, depth int, limit int) ([]*media.FolderInfo, error) {
+	treeCTE := CTE("folder_tree")
+
+	rootCond := FolderInfo.ParentFolderID.IS_NULL()
+	if folderID != nil {
+		rootCond = FolderInfo.ParentFolderID.EQ(UUID(UUIDStr(*folderID)))
+	}
+	ownerCond := FolderInfo.OwnerID.EQ(UUID(UUIDStr(ownerID))).AND(FolderInfo.TrashedAt.IS_NULL())
+
+	stmt := WITH_RECURSIVE(
+		treeCTE.AS(
+			// Base case: the children of the root folder
+			SELECT(
+				FolderInfo.ID,
+				Int(1).AS(folderTreeDepth.Name()),
+			).FROM(
+				FolderInfo,
+			).WHERE(
+				rootCond.AND(ownerCond),
+			).UNION_ALL(
+				// Recursive case: the children of the folders of the previous level, down to the depth
+				SELECT(
+					FolderInfo.ID,
+					folderTreeDepth.From(treeCTE).ADD(Int(1)),
+				).FROM(
+					FolderInfo.
+						INNER_JOIN(treeCTE, FolderInfo.ParentFolderID.EQ(FolderInfo.ID.From(treeCTE))),
+				).WHERE(
+					ownerCond.AND(folderTreeDepth.From(treeCTE).LT(Int(int64(depth)))),
+				),
+			),
+		),
+	)(
+		SELECT(FolderInfo.AllColumns, FolderStats.Size, FolderStats.FileCount, FolderStats.FolderCount).
+			FROM(
+				treeCTE.
+					INNER_JOIN(FolderInfo, FolderInfo.ID.EQ(FolderInfo.ID.From(treeCTE))).
+					LEFT_JOIN(FolderStats, FolderStats.FolderID.EQ(FolderInfo.ID)),
+			).
+			ORDER_BY(folderTreeDepth.From(treeCTE).ASC(), FolderInfo.Name.ASC(), FolderInfo.ID.ASC()).
+			LIMIT(int64(limit)),
+	)
+
+	return runSelectSliceAll[folderInfo, media.FolderInfo](ctx, stmt, r.repository.dbTx)
+}
+
+// ancestorDepth is 1 for the parent folder, 2 for the grandparent and so on
+var ancestorDepth = IntegerColumn("depth")
+
 func (r *MediaRepository) getAncestorsCTE(ownerID, folderID string) CommonTableExpression {
 	ancestorsCTE := CTE("ancestors")
 
@@ -432,6 +1586,7 @@ func (r *MediaRepository) getAncestorsCTE(ownerID, folderID string) CommonTableE
 			FolderInfo.ID,
 			FolderInfo.Name,
 			FolderInfo.ParentFolderID,
+			Int(1).AS(ancestorDepth.Name()),
 		).FROM(
 			FolderInfo,
 		).WHERE(
@@ -454,6 +1609,7 @@ func (r *MediaRepository) getAncestorsCTE(ownerID, folderID string) CommonTableE
 				FolderInfo.ID,
 				FolderInfo.Name,
 				FolderInfo.ParentFolderID,
+				ancestorDepth.From(ancestorsCTE).ADD(Int(1)),
 			).FROM(
 				FolderInfo.
 					INNER_JOIN(ancestorsCTE, FolderInfo.ID.EQ(FolderInfo.ParentFolderID.From(ancestorsCTE))),
@@ -472,8 +1628,80 @@ func (r *MediaRepository) GetAncestors(ctx context.Context, ownerID, folderID st
 		SELECT(
 			FolderInfo.ID.From(ancestorsCTE),
 			FolderInfo.Name.From(ancestorsCTE),
-		).FROM(ancestorsCTE),
+		).FROM(ancestorsCTE).
+			ORDER_BY(ancestorDepth.From(ancestorsCTE).ASC()),
 	)
 
 	return runSelectSliceAll[model.FolderInfo, common.BaseInfo](ctx, stmt, r.repository.dbTx)
 }
+
+//--------------------------------
+// Archives
+//--------------------------------
+
+func (r *MediaRepository) CreateArchiveExtraction(ctx context.Context, extraction *media.ArchiveExtraction) (*media.ArchiveExtraction, error) {
+	dbModel := new(model.ArchiveExtraction)
+	err := copier.Copy(dbModel, extraction)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateArchiveExtraction:copier.Copy")
+	}
+
+	stmt := ArchiveExtraction.INSERT(ArchiveExtraction.AllColumns).
+		MODEL(dbModel).
+		RETURNING(ArchiveExtraction.AllColumns)
+
+	return runInsert[model.ArchiveExtraction, media.ArchiveExtraction](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetArchiveExtraction(ctx context.Context, ownerID, extractionID string) (*media.ArchiveExtraction, error) {
+	stmt := SELECT(ArchiveExtraction.AllColumns).
+		FROM(ArchiveExtraction).
+		WHERE(
+			ArchiveExtraction.ID.EQ(UUID(UUIDStr(extractionID))).
+				AND(ArchiveExtraction.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
+		)
+
+	return runSelect[model.ArchiveExtraction, media.ArchiveExtraction](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) UpdateArchiveExtraction(ctx context.Context, extraction *media.ArchiveExtraction) error {
+	stmt := ArchiveExtraction.UPDATE(
+		ArchiveExtraction.Status,
+		ArchiveExtraction.FileCount,
+		ArchiveExtraction.FolderCount,
+		ArchiveExtraction.SkippedCount,
+		ArchiveExtraction.ExtractedBytes,
+		ArchiveExtraction.ErrorCode,
+		ArchiveExtraction.UpdatedAt,
+	).
+		MODEL(model.ArchiveExtraction{
+			Status:         string(extraction.Status),
+			FileCount:      extraction.FileCount,
+			FolderCount:    extraction.FolderCount,
+			SkippedCount:   extraction.SkippedCount,
+			ExtractedBytes: extraction.ExtractedBytes,
+			ErrorCode:      extraction.ErrorCode,
+			UpdatedAt:      extraction.UpdatedAt,
+		}).
+		WHERE(
+			ArchiveExtraction.ID.EQ(UUID(UUIDStr(extraction.ID))).
+				AND(ArchiveExtraction.OwnerID.EQ(UUID(UUIDStr(extraction.OwnerID)))),
+		)
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *MediaRepository) GetArchiveExtractionsByStatus(ctx context.Context, status media.ExtractionStatus, afterID string, limit int) ([]*media.ArchiveExtraction, error) {
+	whereCond := ArchiveExtraction.Status.EQ(String(string(status)))
+	if afterID != "" {
+		whereCond = whereCond.AND(ArchiveExtraction.ID.GT(UUID(UUIDStr(afterID))))
+	}
+
+	stmt := SELECT(ArchiveExtraction.AllColumns).
+		FROM(ArchiveExtraction).
+		WHERE(whereCond).
+		ORDER_BY(ArchiveExtraction.ID.ASC()).
+		LIMIT(int64(limit))
+
+	return runSelectSliceAll[model.ArchiveExtraction, media.ArchiveExtraction](ctx, stmt, r.repository.dbTx)
+}
@@ -0,0 +1,141 @@
+//lint:file-ignore ST1001 Using dot import to make SQL queries more readable
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"skyvault/internal/domain/outbox"
+	"skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/model"
+	. "skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/table"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/eventbus"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/jinzhu/copier"
+)
+
+var _ outbox.Repository = (*OutboxRepository)(nil)
+
+type OutboxRepository struct {
+	repository *Repository
+}
+
+func NewOutboxRepository(repo *Repository) *OutboxRepository {
+	return &OutboxRepository{repository: repo}
+}
+
+func (r *OutboxRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.repository.db.BeginTx(ctx, nil)
+}
+
+func (r *OutboxRepository) WithTx(ctx context.Context, tx *sql.Tx) outbox.Repository {
+	return &OutboxRepository{repository: r.repository.withTx(ctx, tx)}
+}
+
+// addDomainEvents writes the events to the outbox, it backs the AddDomainEvents of the domain repositories.
+// With the repository of a transaction, the events are published only if the transaction commits.
+func (r *Repository) addDomainEvents(ctx context.Context, events []eventbus.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	dbModels := make([]model.Outbox, 0, len(events))
+	for _, e := range events {
+		payload, err := eventbus.Encode(e)
+		if err != nil {
+			return apperror.NewAppError(err, "repository.addDomainEvents:Encode").WithMetadata("event_type", e.EventType())
+		}
+
+		dbModels = append(dbModels, model.Outbox{
+			EventType: e.EventType(),
+			Payload:   string(payload),
+		})
+	}
+
+	stmt := Outbox.INSERT(Outbox.EventType, Outbox.Payload).
+		MODELS(dbModels)
+
+	_, err := stmt.ExecContext(ctx, r.dbTx)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.addDomainEvents:ExecContext")
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) ClaimMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*outbox.Message, error) {
+	// The messages locked by the claim of another server are skipped
+	due := SELECT(Outbox.ID).
+		FROM(Outbox).
+		WHERE(
+			Outbox.FailedAt.IS_NULL().
+				AND(Outbox.NextAttemptAt.LT_EQ(TimestampT(now))),
+		).
+		ORDER_BY(Outbox.ID.ASC()).
+		LIMIT(int64(limit)).
+		FOR(UPDATE().SKIP_LOCKED())
+
+	stmt := Outbox.UPDATE(Outbox.NextAttemptAt).
+		SET(TimestampT(now.Add(lease))).
+		WHERE(Outbox.ID.IN(due)).
+		RETURNING(Outbox.AllColumns)
+
+	messages, err := runSelectSliceAll[model.Outbox, outbox.Message](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.ClaimMessages:runSelectSliceAll")
+	}
+
+	// UPDATE ... RETURNING doesn't keep the order of the subquery
+	slices.SortFunc(messages, func(a, b *outbox.Message) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) UpdateMessage(ctx context.Context, message *outbox.Message) error {
+	dbModel := new(model.Outbox)
+	err := copier.Copy(dbModel, message)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UpdateMessage:copier.Copy")
+	}
+
+	stmt := Outbox.UPDATE(
+		Outbox.Attempts,
+		Outbox.NextAttemptAt,
+		Outbox.LastError,
+		Outbox.FailedAt,
+	).
+		MODEL(dbModel).
+		WHERE(Outbox.ID.EQ(Int64(message.ID)))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *OutboxRepository) DeleteMessage(ctx context.Context, messageID int64) error {
+	stmt := Outbox.DELETE().
+		WHERE(Outbox.ID.EQ(Int64(messageID)))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *OutboxRepository) DeleteFailedMessages(ctx context.Context, before time.Time) (int64, error) {
+	stmt := Outbox.DELETE().
+		WHERE(Outbox.FailedAt.LT(TimestampT(before)))
+
+	res, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteFailedMessages:ExecContext")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteFailedMessages:RowsAffected")
+	}
+
+	return count, nil
+}
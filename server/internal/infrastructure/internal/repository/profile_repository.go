@@ -9,6 +9,7 @@ import (
 	"skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/model"
 	. "skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/table"
 	"skyvault/pkg/apperror"
+	"skyvault/pkg/eventbus"
 
 	. "github.com/go-jet/jet/v2/postgres"
 	"github.com/jinzhu/copier"
@@ -32,6 +33,10 @@ func (r *ProfileRepository) WithTx(ctx context.Context, tx *sql.Tx) profile.Repo
 	return &ProfileRepository{repository: r.repository.withTx(ctx, tx)}
 }
 
+func (r *ProfileRepository) AddDomainEvents(ctx context.Context, events ...eventbus.Event) error {
+	return r.repository.addDomainEvents(ctx, events)
+}
+
 func (r *ProfileRepository) Create(ctx context.Context, pro *profile.Profile) (*profile.Profile, error) {
 	dbModel := new(model.Profile)
 	err := copier.Copy(dbModel, pro)
@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"skyvault/internal/domain/auth"
+	"skyvault/internal/domain/event"
 	"skyvault/internal/domain/media"
+	"skyvault/internal/domain/outbox"
 	"skyvault/internal/domain/profile"
 	"skyvault/internal/domain/sharing"
+	"skyvault/internal/domain/webhook"
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/applog"
 	"time"
@@ -38,6 +41,9 @@ type Repository struct {
 	Profile profile.Repository
 	Media   media.Repository
 	Sharing sharing.Repository
+	Event   event.Repository
+	Webhook webhook.Repository
+	Outbox  outbox.Repository
 }
 
 func NewRepository(app *appconfig.App) *Repository {
@@ -67,6 +73,9 @@ func (r *Repository) initRepositories() {
 	r.Profile = NewProfileRepository(r)
 	r.Media = NewMediaRepository(r)
 	r.Sharing = NewSharingRepository(r)
+	r.Event = NewEventRepository(r)
+	r.Webhook = NewWebhookRepository(r)
+	r.Outbox = NewOutboxRepository(r)
 }
 
 func connectDatabase(logger applog.Logger, dsn string) *sql.DB {
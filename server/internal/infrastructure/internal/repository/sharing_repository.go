@@ -10,6 +10,7 @@ import (
 	"skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/model"
 	. "skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/table"
 	"skyvault/pkg/apperror"
+	"skyvault/pkg/eventbus"
 	"skyvault/pkg/paging"
 	"skyvault/pkg/utils"
 
@@ -35,6 +36,10 @@ func (r *SharingRepository) WithTx(ctx context.Context, tx *sql.Tx) sharing.Repo
 	return &SharingRepository{repository: r.repository.withTx(ctx, tx)}
 }
 
+func (r *SharingRepository) AddDomainEvents(ctx context.Context, events ...eventbus.Event) error {
+	return r.repository.addDomainEvents(ctx, events)
+}
+
 //--------------------------------
 // Contacts
 //--------------------------------
@@ -290,12 +295,18 @@ func (r *SharingRepository) CreateShareConfig(ctx context.Context, config *shari
 }
 
 func (r *SharingRepository) GetShareConfig(ctx context.Context, ownerID, shareID string) (*sharing.ShareConfig, error) {
+	return r.getShareConfig(ctx, ShareConfig.ID.EQ(UUID(UUIDStr(shareID))).
+		AND(ShareConfig.OwnerID.EQ(UUID(UUIDStr(ownerID)))))
+}
+
+func (r *SharingRepository) GetPublicShareConfig(ctx context.Context, shareID string) (*sharing.ShareConfig, error) {
+	return r.getShareConfig(ctx, ShareConfig.ID.EQ(UUID(UUIDStr(shareID))))
+}
+
+func (r *SharingRepository) getShareConfig(ctx context.Context, whereCond BoolExpression) (*sharing.ShareConfig, error) {
 	stmt := SELECT(ShareConfig.AllColumns).
 		FROM(ShareConfig).
-		WHERE(
-			ShareConfig.ID.EQ(UUID(UUIDStr(shareID))).
-				AND(ShareConfig.OwnerID.EQ(UUID(UUIDStr(ownerID)))),
-		)
+		WHERE(whereCond)
 
 	config, err := runSelect[model.ShareConfig, sharing.ShareConfig](ctx, stmt, r.repository.dbTx)
 	if err != nil {
@@ -381,6 +392,22 @@ func (r *SharingRepository) DeleteShareConfig(ctx context.Context, ownerID, shar
 	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
 }
 
+// IncrementShareDownloads checks and counts in the same statement, so concurrent downloads
+// can't go over the max downloads.
+func (r *SharingRepository) IncrementShareDownloads(ctx context.Context, shareID string) error {
+	stmt := ShareConfig.UPDATE(ShareConfig.DownloadsCount).
+		SET(ShareConfig.DownloadsCount.ADD(Int(1))).
+		WHERE(
+			ShareConfig.ID.EQ(UUID(UUIDStr(shareID))).
+				AND(
+					ShareConfig.MaxDownloads.IS_NULL().
+						OR(ShareConfig.DownloadsCount.LT(ShareConfig.MaxDownloads)),
+				),
+		)
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
 //--------------------------------
 // Share Recipients
 //--------------------------------
@@ -433,12 +460,33 @@ func (r *SharingRepository) DeleteShareRecipient(ctx context.Context, ownerID, s
 }
 
 func (r *SharingRepository) GetShareRecipientByEmail(ctx context.Context, shareID string, email string) (*sharing.ShareRecipient, error) {
+	groupMemberEmails := SELECT(Contact.Email).
+		FROM(ContactGroupMember.
+			INNER_JOIN(Contact, Contact.ID.EQ(ContactGroupMember.ContactID)),
+		).
+		WHERE(ContactGroupMember.GroupID.EQ(ShareRecipient.GroupID))
+
 	stmt := SELECT(ShareRecipient.AllColumns).
-		FROM(ShareRecipient).
+		FROM(ShareRecipient.
+			LEFT_JOIN(Contact, Contact.ID.EQ(ShareRecipient.ContactID)),
+		).
 		WHERE(
 			ShareRecipient.ShareConfigID.EQ(UUID(UUIDStr(shareID))).
-				AND(ShareRecipient.Email.EQ(String(email))),
-		)
+				AND(
+					ShareRecipient.Email.EQ(String(email)).
+						OR(Contact.Email.EQ(String(email))).
+						OR(String(email).IN(groupMemberEmails)),
+				),
+		).
+		LIMIT(1)
 
 	return runSelect[model.ShareRecipient, sharing.ShareRecipient](ctx, stmt, r.repository.dbTx)
 }
+
+func (r *SharingRepository) IncrementShareRecipientDownloads(ctx context.Context, recipientID string) error {
+	stmt := ShareRecipient.UPDATE(ShareRecipient.DownloadsCount, ShareRecipient.UpdatedAt).
+		SET(ShareRecipient.DownloadsCount.ADD(Int(1)), TimestampT(time.Now().UTC())).
+		WHERE(ShareRecipient.ID.EQ(UUID(UUIDStr(recipientID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
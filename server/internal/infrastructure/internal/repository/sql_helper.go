@@ -3,11 +3,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/paging"
 	"slices"
+	"strings"
 
 	. "github.com/go-jet/jet/v2/postgres"
 	"github.com/go-jet/jet/v2/qrm"
@@ -24,13 +26,59 @@ func ILIKE(lhs, rhs StringExpression) BoolExpression {
 	return BoolExp(CustomExpression(lhs, Token("ILIKE"), rhs))
 }
 
+// containsPattern returns the LIKE pattern matching the values containing term, with the wildcards of term escaped.
+func containsPattern(term string) string {
+	return "%" + likeEscaper.Replace(term) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// TO_TSVECTOR parses the text with the "simple" configuration, which doesn't stem words,
+// as the files are in any language.
+func TO_TSVECTOR(text StringExpression) StringExpression {
+	return StringExp(CustomExpression(Token("to_tsvector('simple',"), text, Token(")")))
+}
+
+// MATCHES_TSQUERY matches the tsvector with a web search like query, e.g. `"annual report" -draft`.
+func MATCHES_TSQUERY(vector StringExpression, query string) BoolExpression {
+	return BoolExp(CustomExpression(vector, Token("@@ websearch_to_tsquery('simple',"), String(query), Token(")")))
+}
+
+// uuidList turns the IDs into expressions, to be used with IN.
+func uuidList(ids []string) []Expression {
+	exps := make([]Expression, 0, len(ids))
+	for _, id := range ids {
+		exps = append(exps, UUID(UUIDStr(id)))
+	}
+	return exps
+}
+
+// excludedRow turns the EXCLUDED columns of an upsert into a row, to be assigned to the same columns with ColumnList.SET.
+func excludedRow(columns ColumnList) RowExpression {
+	exps := make([]Expression, 0, len(columns))
+	for _, column := range columns {
+		exps = append(exps, column.(Expression))
+	}
+	return ROW(exps...)
+}
+
 type cursorQuery struct {
-	ID        ColumnString
-	Name      ColumnString
-	Updated   ColumnTimestamp
+	ID      ColumnString
+	Name    ColumnString
+	Updated ColumnTimestamp
+
+	// Optional columns, sorting or filtering by them is rejected when not set
+	Size     IntegerExpression
+	Created  TimestampExpression
+	Type     StringExpression // Lowercase extension, '' if none
+	Category StringExpression
+
 	where     BoolExpression
 	orderBy   []OrderByClause
 	pagingOpt *paging.Options
+
+	// totalWhere is where without the cursor condition, it is set by buildClauses
+	totalWhere BoolExpression
 }
 
 func (o *cursorQuery) buildClauses() error {
@@ -46,12 +94,24 @@ func (o *cursorQuery) buildClauses() error {
 
 	o.initClauses()
 
-	if o.pagingOpt.Direction == paging.DirectionForward {
-		o.buildForwardClauses(cursor)
-	} else {
-		o.buildBackwardClauses(cursor)
+	err = o.buildFilterClauses()
+	if err != nil {
+		return apperror.NewAppError(err, "repository.cursorQuery.buildClauses:buildFilterClauses")
+	}
+	o.totalWhere = o.where
+
+	if o.pagingOpt.SortBy == paging.SortByID {
+		o.buildIDClauses(cursor, o.pagingOpt.Direction == paging.DirectionForward)
+		return nil
 	}
 
+	column, value := o.sortColumn(cursor)
+	if column == nil {
+		return apperror.NewAppError(fmt.Errorf("%w: sort by %s is not supported", apperror.ErrCommonInvalidValue, o.pagingOpt.SortBy), "repository.cursorQuery.buildClauses:sortColumn")
+	}
+
+	o.buildColumnClauses(column, value, cursor, o.pagingOpt.Direction == paging.DirectionForward)
+
 	return nil
 }
 
@@ -65,26 +125,91 @@ func (o *cursorQuery) initClauses() {
 	}
 }
 
-func (o *cursorQuery) buildForwardClauses(cursor *paging.Cursor) {
-	switch o.pagingOpt.SortBy {
-	case paging.SortByID:
-		o.buildIDClauses(cursor, true)
-	case paging.SortByName:
-		o.buildNameClauses(cursor, true)
-	case paging.SortByUpdated:
-		o.buildUpdatedClauses(cursor, true)
+// buildFilterClauses adds the conditions of paging.Filter.
+func (o *cursorQuery) buildFilterClauses() error {
+	filter := &o.pagingOpt.Filter
+	if err := filter.Validate(); err != nil {
+		return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonInvalidValue, err), "repository.cursorQuery.buildFilterClauses:Validate")
+	}
+
+	unsupported := func(name string) error {
+		return apperror.NewAppError(fmt.Errorf("%w: filter by %s is not supported", apperror.ErrCommonInvalidValue, name), "repository.cursorQuery.buildFilterClauses")
+	}
+
+	if filter.Category != "" {
+		if o.Category == nil {
+			return unsupported("category")
+		}
+		o.where = o.where.AND(o.Category.EQ(String(filter.Category)))
 	}
+
+	if filter.Extension != "" {
+		if o.Type == nil {
+			return unsupported("extension")
+		}
+		o.where = o.where.AND(o.Type.EQ(String(filter.Extension)))
+	}
+
+	if filter.MinSize != nil || filter.MaxSize != nil {
+		if o.Size == nil {
+			return unsupported("size")
+		}
+		if filter.MinSize != nil {
+			o.where = o.where.AND(o.Size.GT_EQ(Int(*filter.MinSize)))
+		}
+		if filter.MaxSize != nil {
+			o.where = o.where.AND(o.Size.LT_EQ(Int(*filter.MaxSize)))
+		}
+	}
+
+	if filter.CreatedFrom != nil || filter.CreatedTo != nil {
+		if o.Created == nil {
+			return unsupported("created")
+		}
+		if filter.CreatedFrom != nil {
+			o.where = o.where.AND(o.Created.GT_EQ(TimestampT(*filter.CreatedFrom)))
+		}
+		if filter.CreatedTo != nil {
+			o.where = o.where.AND(o.Created.LT_EQ(TimestampT(*filter.CreatedTo)))
+		}
+	}
+
+	return nil
 }
 
-func (o *cursorQuery) buildBackwardClauses(cursor *paging.Cursor) {
+// sortColumn returns the column to sort by and its value in the cursor.
+// The column is nil if the query can't be sorted by it, the value is nil without cursor.
+func (o *cursorQuery) sortColumn(cursor *paging.Cursor) (Expression, Expression) {
+	var column Expression
+	var value func() Expression
+
 	switch o.pagingOpt.SortBy {
-	case paging.SortByID:
-		o.buildIDClauses(cursor, false)
 	case paging.SortByName:
-		o.buildNameClauses(cursor, false)
+		column, value = o.Name, func() Expression { return String(cursor.Name) }
 	case paging.SortByUpdated:
-		o.buildUpdatedClauses(cursor, false)
+		column, value = o.Updated, func() Expression { return TimestampT(cursor.Updated) }
+	case paging.SortBySize:
+		if o.Size != nil {
+			column, value = o.Size, func() Expression { return Int(cursor.Size) }
+		}
+	case paging.SortByCreated:
+		if o.Created != nil {
+			column, value = o.Created, func() Expression { return TimestampT(cursor.Created) }
+		}
+	case paging.SortByType:
+		if o.Type != nil {
+			column, value = o.Type, func() Expression { return String(cursor.Type) }
+		}
+	case paging.SortByCategory:
+		if o.Category != nil {
+			column, value = o.Category, func() Expression { return String(cursor.Category) }
+		}
 	}
+
+	if column == nil || cursor == nil {
+		return column, nil
+	}
+	return column, value()
 }
 
 func (o *cursorQuery) buildIDClauses(cursor *paging.Cursor, forward bool) {
@@ -120,110 +245,29 @@ func (o *cursorQuery) buildIDClauses(cursor *paging.Cursor, forward bool) {
 	o.where = o.where.AND(o.ID.GT(UUID(UUIDStr(cursor.ID))))
 }
 
-func (o *cursorQuery) buildNameClauses(cursor *paging.Cursor, forward bool) {
-	if o.pagingOpt.Sort == paging.SortAsc {
-		if cursor == nil {
-			o.orderBy = append(o.orderBy, o.Name.ASC(), o.ID.ASC())
-			return
-		}
+// buildColumnClauses sorts by the column and then by the ID, which keeps the order stable for equal values.
+// The rows after the cursor are selected with a row comparison, e.g. (name, id) > ('report', '0195...').
+func (o *cursorQuery) buildColumnClauses(column Expression, value Expression, cursor *paging.Cursor, forward bool) {
+	// Going backward reverses the order, the items are reversed back after the select
+	ascending := (o.pagingOpt.Sort == paging.SortAsc) == forward
 
-		if forward {
-			o.orderBy = append(o.orderBy, o.Name.ASC(), o.ID.ASC())
-			o.where = o.where.AND(
-				o.Name.GT(String(cursor.Name)).
-					OR(o.Name.EQ(String(cursor.Name)).AND(
-						o.ID.GT(UUID(UUIDStr(cursor.ID))),
-					)),
-			)
-			return
-		}
-
-		o.orderBy = append(o.orderBy, o.Name.DESC(), o.ID.DESC())
-		o.where = o.where.AND(
-			o.Name.LT(String(cursor.Name)).
-				OR(o.Name.EQ(String(cursor.Name)).AND(
-					o.ID.LT(UUID(UUIDStr(cursor.ID))),
-				)),
-		)
-		return
-	}
-
-	if cursor == nil {
-		o.orderBy = append(o.orderBy, o.Name.DESC(), o.ID.DESC())
-		return
-	}
-
-	if forward {
-		o.orderBy = append(o.orderBy, o.Name.DESC(), o.ID.DESC())
-		o.where = o.where.AND(
-			o.Name.LT(String(cursor.Name)).
-				OR(o.Name.EQ(String(cursor.Name)).AND(
-					o.ID.LT(UUID(UUIDStr(cursor.ID))),
-				)),
-		)
-		return
-	}
-
-	o.orderBy = append(o.orderBy, o.Name.ASC(), o.ID.ASC())
-	o.where = o.where.AND(
-		o.Name.GT(String(cursor.Name)).
-			OR(o.Name.EQ(String(cursor.Name)).AND(
-				o.ID.GT(UUID(UUIDStr(cursor.ID))),
-			)),
-	)
-}
-
-func (o *cursorQuery) buildUpdatedClauses(cursor *paging.Cursor, forward bool) {
-	if o.pagingOpt.Sort == paging.SortAsc {
-		if cursor == nil {
-			o.orderBy = append(o.orderBy, o.Updated.ASC(), o.ID.ASC())
-			return
-		}
-
-		if forward {
-			o.orderBy = append(o.orderBy, o.Updated.ASC(), o.ID.ASC())
-			o.where = o.where.AND(
-				o.Updated.GT(TimestampT(cursor.Updated)).
-					OR(o.Updated.EQ(TimestampT(cursor.Updated)).AND(
-						o.ID.GT(UUID(UUIDStr(cursor.ID))),
-					)),
-			)
-			return
-		}
-
-		o.orderBy = append(o.orderBy, o.Updated.DESC(), o.ID.DESC())
-		o.where = o.where.AND(
-			o.Updated.LT(TimestampT(cursor.Updated)).
-				OR(o.Updated.EQ(TimestampT(cursor.Updated)).AND(
-					o.ID.LT(UUID(UUIDStr(cursor.ID))),
-				)),
-		)
-		return
+	if ascending {
+		o.orderBy = append(o.orderBy, column.ASC(), o.ID.ASC())
+	} else {
+		o.orderBy = append(o.orderBy, column.DESC(), o.ID.DESC())
 	}
 
 	if cursor == nil {
-		o.orderBy = append(o.orderBy, o.Updated.DESC(), o.ID.DESC())
 		return
 	}
 
-	if forward {
-		o.orderBy = append(o.orderBy, o.Updated.DESC(), o.ID.DESC())
-		o.where = o.where.AND(
-			o.Updated.LT(TimestampT(cursor.Updated)).
-				OR(o.Updated.EQ(TimestampT(cursor.Updated)).AND(
-					o.ID.LT(UUID(UUIDStr(cursor.ID))),
-				)),
-		)
-		return
+	lhs := ROW(column, o.ID)
+	rhs := ROW(value, UUID(UUIDStr(cursor.ID)))
+	if ascending {
+		o.where = o.where.AND(lhs.GT(rhs))
+	} else {
+		o.where = o.where.AND(lhs.LT(rhs))
 	}
-
-	o.orderBy = append(o.orderBy, o.Updated.ASC(), o.ID.ASC())
-	o.where = o.where.AND(
-		o.Updated.GT(TimestampT(cursor.Updated)).
-			OR(o.Updated.EQ(TimestampT(cursor.Updated)).AND(
-				o.ID.GT(UUID(UUIDStr(cursor.ID))),
-			)),
-	)
 }
 
 // runSelect is to be used with Select statements that return a single row.
@@ -289,9 +333,93 @@ func runSelectSlice[TDBModel any, TRes any](ctx context.Context, cursorQuery *cu
 
 	page.Items = items
 
+	if cursorQuery.pagingOpt.IncludeTotal {
+		total, estimated, err := countTotal(ctx, cursorQuery, stmt, dbTx)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "repository.runSelectSlice:countTotal")
+		}
+		page.Total = &total
+		page.TotalEstimated = estimated
+	}
+
 	return page, nil
 }
 
+type pageTotal struct {
+	Total int64 `alias:"page_total.total"`
+}
+
+// countTotal counts the rows of all the pages of the cursor query.
+// The count stops at paging.MaxExactTotal, above that it returns the planner's estimate of the row count,
+// which is cheap but can be off, e.g. when the table statistics are outdated.
+func countTotal(ctx context.Context, cursorQuery *cursorQuery, stmt SelectStatement, dbTx qrm.DB) (int64, bool, error) {
+	// The select statement is modified in place, it still has the clauses of the page
+	stmt = stmt.WHERE(cursorQuery.totalWhere).
+		ORDER_BY().
+		LIMIT(paging.MaxExactTotal + 1)
+
+	countStmt := SELECT(COUNT(STAR).AS("page_total.total")).
+		FROM(stmt.AsTable("page_rows"))
+
+	var res pageTotal
+	err := countStmt.QueryContext(ctx, dbTx, &res)
+	if err != nil {
+		return 0, false, apperror.NewAppError(err, "repository.countTotal:QueryContext")
+	}
+
+	if res.Total <= paging.MaxExactTotal {
+		return res.Total, false, nil
+	}
+
+	// A negative limit removes the limit
+	estimate, err := estimateRows(ctx, stmt.LIMIT(-1), dbTx)
+	if err != nil {
+		return 0, false, apperror.NewAppError(err, "repository.countTotal:estimateRows")
+	}
+
+	// There are more rows than were counted whatever the planner thinks
+	return max(estimate, res.Total), true, nil
+}
+
+// estimateRows returns the number of rows the planner expects the statement to return.
+func estimateRows(ctx context.Context, stmt Statement, dbTx qrm.DB) (int64, error) {
+	query, args := stmt.Sql()
+
+	var plan []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+
+	rows, err := dbTx.QueryContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.estimateRows:QueryContext")
+	}
+	defer rows.Close()
+
+	var planJSON []byte
+	if rows.Next() {
+		err = rows.Scan(&planJSON)
+		if err != nil {
+			return 0, apperror.NewAppError(err, "repository.estimateRows:Scan")
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, apperror.NewAppError(err, "repository.estimateRows:Err")
+	}
+
+	err = json.Unmarshal(planJSON, &plan)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.estimateRows:Unmarshal")
+	}
+
+	if len(plan) == 0 {
+		return 0, apperror.NewAppError(errors.New("empty query plan"), "repository.estimateRows")
+	}
+
+	return int64(plan[0].Plan.Rows), nil
+}
+
 // runSelectSliceAll is to be used with Select statements that return multiple rows without pagination.
 func runSelectSliceAll[TDBModel any, TRes any](ctx context.Context, stmt Statement, dbTx qrm.DB) ([]*TRes, error) {
 	var dbModels []*TDBModel
@@ -0,0 +1,275 @@
+//lint:file-ignore ST1001 Using dot import to make SQL queries more readable
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"skyvault/internal/domain/webhook"
+	"skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/model"
+	. "skyvault/internal/infrastructure/internal/repository/internal/gen_jet/skyvault/public/table"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/paging"
+
+	. "github.com/go-jet/jet/v2/postgres"
+	"github.com/jinzhu/copier"
+)
+
+var _ webhook.Repository = (*WebhookRepository)(nil)
+
+type WebhookRepository struct {
+	repository *Repository
+}
+
+func NewWebhookRepository(repo *Repository) *WebhookRepository {
+	return &WebhookRepository{repository: repo}
+}
+
+func (r *WebhookRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.repository.db.BeginTx(ctx, nil)
+}
+
+func (r *WebhookRepository) WithTx(ctx context.Context, tx *sql.Tx) webhook.Repository {
+	return &WebhookRepository{repository: r.repository.withTx(ctx, tx)}
+}
+
+//--------------------------------
+// Webhooks
+//--------------------------------
+
+// The event types are a JSON array in the db, copier can't convert them
+func webhookToModel(w *webhook.Webhook) (*model.Webhook, error) {
+	dbModel := new(model.Webhook)
+	err := copier.Copy(dbModel, w)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.webhookToModel:copier.Copy")
+	}
+
+	eventTypes := w.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	b, err := json.Marshal(eventTypes)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.webhookToModel:Marshal")
+	}
+	dbModel.EventTypes = string(b)
+
+	return dbModel, nil
+}
+
+func webhookFromModel(dbModel *model.Webhook) (*webhook.Webhook, error) {
+	w := new(webhook.Webhook)
+	err := copier.Copy(w, dbModel)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.webhookFromModel:copier.Copy")
+	}
+
+	w.EventTypes = []string{}
+	err = json.Unmarshal([]byte(dbModel.EventTypes), &w.EventTypes)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.webhookFromModel:Unmarshal")
+	}
+
+	return w, nil
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, w *webhook.Webhook) (*webhook.Webhook, error) {
+	dbModel, err := webhookToModel(w)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateWebhook:webhookToModel")
+	}
+
+	stmt := Webhook.INSERT(Webhook.AllColumns).
+		MODEL(dbModel).
+		RETURNING(Webhook.AllColumns)
+
+	res, err := runInsert[model.Webhook, model.Webhook](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateWebhook:runInsert")
+	}
+
+	return webhookFromModel(res)
+}
+
+func (r *WebhookRepository) GetWebhook(ctx context.Context, webhookID string) (*webhook.Webhook, error) {
+	stmt := SELECT(Webhook.AllColumns).
+		FROM(Webhook).
+		WHERE(Webhook.ID.EQ(UUID(UUIDStr(webhookID))))
+
+	res, err := runSelect[model.Webhook, model.Webhook](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.GetWebhook:runSelect")
+	}
+
+	return webhookFromModel(res)
+}
+
+func (r *WebhookRepository) GetWebhooks(ctx context.Context, ownerID string) ([]*webhook.Webhook, error) {
+	stmt := SELECT(Webhook.AllColumns).
+		FROM(Webhook).
+		WHERE(Webhook.OwnerID.EQ(UUID(UUIDStr(ownerID)))).
+		ORDER_BY(Webhook.CreatedAt.DESC())
+
+	dbModels, err := runSelectSliceAll[model.Webhook, model.Webhook](ctx, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.GetWebhooks:runSelectSliceAll")
+	}
+
+	webhooks := make([]*webhook.Webhook, 0, len(dbModels))
+	for _, dbModel := range dbModels {
+		w, err := webhookFromModel(dbModel)
+		if err != nil {
+			return nil, apperror.NewAppError(err, "repository.GetWebhooks:webhookFromModel")
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, w *webhook.Webhook) error {
+	dbModel, err := webhookToModel(w)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UpdateWebhook:webhookToModel")
+	}
+
+	stmt := Webhook.UPDATE(Webhook.URL, Webhook.Secret, Webhook.EventTypes, Webhook.FolderID, Webhook.Enabled, Webhook.UpdatedAt).
+		MODEL(dbModel).
+		WHERE(Webhook.ID.EQ(UUID(UUIDStr(w.ID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, webhookID string) error {
+	stmt := Webhook.DELETE().
+		WHERE(Webhook.ID.EQ(UUID(UUIDStr(webhookID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+//--------------------------------
+// Deliveries
+//--------------------------------
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *webhook.Delivery) (*webhook.Delivery, error) {
+	dbModel := new(model.WebhookDelivery)
+	err := copier.Copy(dbModel, delivery)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.CreateDelivery:copier.Copy")
+	}
+
+	stmt := WebhookDelivery.INSERT(WebhookDelivery.AllColumns).
+		MODEL(dbModel).
+		RETURNING(WebhookDelivery.AllColumns)
+
+	return runInsert[model.WebhookDelivery, webhook.Delivery](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, deliveryID string) (*webhook.Delivery, error) {
+	stmt := SELECT(WebhookDelivery.AllColumns).
+		FROM(WebhookDelivery).
+		WHERE(WebhookDelivery.ID.EQ(UUID(UUIDStr(deliveryID))))
+
+	return runSelect[model.WebhookDelivery, webhook.Delivery](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, webhookID string, pagingOpt *paging.Options) (*paging.Page[*webhook.Delivery], error) {
+	// The delivery log is sorted by creation only, the IDs of the queued deliveries are random.
+	// The options are copied, the caller keeps its own
+	opt := *pagingOpt
+	opt.SortBy = paging.SortByCreated
+	pagingOpt = &opt
+
+	stmt := SELECT(WebhookDelivery.AllColumns).
+		FROM(WebhookDelivery)
+
+	cursorQuery := &cursorQuery{
+		ID:        WebhookDelivery.ID,
+		Created:   WebhookDelivery.CreatedAt,
+		where:     WebhookDelivery.WebhookID.EQ(UUID(UUIDStr(webhookID))),
+		pagingOpt: pagingOpt,
+	}
+
+	page, err := runSelectSlice[model.WebhookDelivery, webhook.Delivery](ctx, cursorQuery, stmt, r.repository.dbTx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "repository.GetDeliveries:runSelectSlice")
+	}
+
+	if len(page.Items) > 0 {
+		lastItem := page.Items[len(page.Items)-1]
+		page.NextCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      lastItem.ID,
+			Created: lastItem.CreatedAt,
+		})
+
+		firstItem := page.Items[0]
+		page.PrevCursor = pagingOpt.CreateCursor(&paging.Cursor{
+			ID:      firstItem.ID,
+			Created: firstItem.CreatedAt,
+		})
+	}
+
+	return page, nil
+}
+
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*webhook.Delivery, error) {
+	// The deliveries locked by the claim of another server are skipped
+	due := SELECT(WebhookDelivery.ID).
+		FROM(WebhookDelivery.INNER_JOIN(Webhook, Webhook.ID.EQ(WebhookDelivery.WebhookID))).
+		WHERE(
+			WebhookDelivery.Status.EQ(String(string(webhook.DeliveryStatusPending))).
+				AND(WebhookDelivery.NextAttemptAt.LT_EQ(TimestampT(now))).
+				AND(Webhook.Enabled.IS_TRUE()),
+		).
+		ORDER_BY(WebhookDelivery.NextAttemptAt.ASC()).
+		LIMIT(int64(limit)).
+		FOR(UPDATE().OF(WebhookDelivery).SKIP_LOCKED())
+
+	stmt := WebhookDelivery.UPDATE(WebhookDelivery.NextAttemptAt).
+		SET(TimestampT(now.Add(lease))).
+		WHERE(WebhookDelivery.ID.IN(due)).
+		RETURNING(WebhookDelivery.AllColumns)
+
+	return runSelectSliceAll[model.WebhookDelivery, webhook.Delivery](ctx, stmt, r.repository.dbTx)
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *webhook.Delivery) error {
+	dbModel := new(model.WebhookDelivery)
+	err := copier.Copy(dbModel, delivery)
+	if err != nil {
+		return apperror.NewAppError(err, "repository.UpdateDelivery:copier.Copy")
+	}
+
+	stmt := WebhookDelivery.UPDATE(
+		WebhookDelivery.Status,
+		WebhookDelivery.Attempts,
+		WebhookDelivery.NextAttemptAt,
+		WebhookDelivery.LastAttemptAt,
+		WebhookDelivery.ResponseStatus,
+		WebhookDelivery.LastError,
+	).
+		MODEL(dbModel).
+		WHERE(WebhookDelivery.ID.EQ(UUID(UUIDStr(delivery.ID))))
+
+	return runUpdateOrDelete(ctx, stmt, r.repository.dbTx)
+}
+
+func (r *WebhookRepository) DeleteDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	stmt := WebhookDelivery.DELETE().
+		WHERE(WebhookDelivery.CreatedAt.LT(TimestampT(before)))
+
+	res, err := stmt.ExecContext(ctx, r.repository.dbTx)
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteDeliveries:ExecContext")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, apperror.NewAppError(err, "repository.DeleteDeliveries:RowsAffected")
+	}
+
+	return count, nil
+}
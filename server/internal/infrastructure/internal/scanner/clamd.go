@@ -0,0 +1,159 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"skyvault/internal/domain/media"
+	"skyvault/pkg/apperror"
+	"strings"
+	"time"
+)
+
+// Max. size of the chunks streamed to clamd, it must be less than its StreamMaxLength
+const clamdChunkSize = 64 * 1024
+
+var _ media.Scanner = (*ClamdScanner)(nil)
+
+// ClamdScanner scans files with the clamd daemon of ClamAV, using the INSTREAM command.
+// See https://docs.clamav.net/manual/Usage/Scanning.html#clamd
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner accepts addresses like "unix:///run/clamav/clamd.ctl" or "tcp://localhost:3310".
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	network, addr, ok := strings.Cut(address, "://")
+	if !ok || addr == "" || (network != "unix" && network != "tcp") {
+		return nil, fmt.Errorf("invalid clamd address %q", address)
+	}
+	return &ClamdScanner{network: network, address: addr, timeout: timeout}, nil
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, file io.Reader) (*media.ScanResult, error) {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "scanner.ClamdScanner.Scan:dial")
+	}
+	defer conn.Close()
+
+	if err := writeCommand(conn, "INSTREAM"); err != nil {
+		return nil, apperror.NewAppError(err, "scanner.ClamdScanner.Scan:writeCommand")
+	}
+
+	// The stream is a sequence of chunks prefixed with their length, terminated by an empty chunk
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, readErr := io.ReadFull(file, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				// clamd closes the connection when the stream is too long, its reply tells why
+				if reply, replyErr := readReply(conn); replyErr == nil {
+					return nil, apperror.NewAppError(fmt.Errorf("clamd: %s", reply), "scanner.ClamdScanner.Scan:Write")
+				}
+				return nil, apperror.NewAppError(err, "scanner.ClamdScanner.Scan:Write")
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return nil, apperror.NewAppError(readErr, "scanner.ClamdScanner.Scan:Read")
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, apperror.NewAppError(err, "scanner.ClamdScanner.Scan:WriteEnd")
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "scanner.ClamdScanner.Scan:readReply")
+	}
+
+	res, err := parseScanReply(reply)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "scanner.ClamdScanner.Scan:parseScanReply")
+	}
+	return res, nil
+}
+
+// Ping checks that clamd is up.
+func (s *ClamdScanner) Ping(ctx context.Context) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return apperror.NewAppError(err, "scanner.ClamdScanner.Ping:dial")
+	}
+	defer conn.Close()
+
+	if err := writeCommand(conn, "PING"); err != nil {
+		return apperror.NewAppError(err, "scanner.ClamdScanner.Ping:writeCommand")
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return apperror.NewAppError(err, "scanner.ClamdScanner.Ping:readReply")
+	}
+	if reply != "PONG" {
+		return apperror.NewAppError(fmt.Errorf("unexpected clamd reply %q", reply), "scanner.ClamdScanner.Ping")
+	}
+	return nil
+}
+
+func (s *ClamdScanner) dial(ctx context.Context) (net.Conn, error) {
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// writeCommand uses the null terminated form of the commands, e.g. "zINSTREAM\0".
+func writeCommand(conn net.Conn, command string) error {
+	_, err := conn.Write([]byte("z" + command + "\x00"))
+	return err
+}
+
+func readReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && !(errors.Is(err, io.EOF) && len(reply) > 0) {
+		return "", err
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+// parseScanReply parses replies like "stream: OK" or "stream: Eicar-Test-Signature FOUND".
+func parseScanReply(reply string) (*media.ScanResult, error) {
+	_, result, ok := strings.Cut(reply, ": ")
+	if !ok {
+		return nil, fmt.Errorf("unexpected clamd reply %q", reply)
+	}
+
+	switch {
+	case result == "OK":
+		return &media.ScanResult{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &media.ScanResult{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		// e.g. "INSTREAM size limit exceeded. ERROR"
+		return nil, fmt.Errorf("clamd: %s", result)
+	}
+}
@@ -0,0 +1,183 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// startFakeClamd serves PING and INSTREAM on a unix socket, reporting streams containing the EICAR test string.
+// Streams longer than maxStream are rejected like clamd does with StreamMaxLength.
+func startFakeClamd(t *testing.T, maxStream int) string {
+	t.Helper()
+
+	// Unix socket paths are limited to ~100 bytes, t.TempDir() may be too long
+	dir, err := os.MkdirTemp("", "clamd")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socketPath := filepath.Join(dir, "clamd.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeClamd(conn, maxStream)
+		}
+	}()
+
+	return "unix://" + socketPath
+}
+
+func serveFakeClamd(conn net.Conn, maxStream int) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	command, err := reader.ReadString(0)
+	if err != nil {
+		return
+	}
+
+	switch command {
+	case "zPING\x00":
+		conn.Write([]byte("PONG\x00"))
+	case "zINSTREAM\x00":
+		var stream bytes.Buffer
+		for {
+			var size uint32
+			if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if stream.Len()+int(size) > maxStream {
+				conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+				return
+			}
+			if _, err := io.CopyN(&stream, reader, int64(size)); err != nil {
+				return
+			}
+		}
+
+		if bytes.Contains(stream.Bytes(), []byte(eicar)) {
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		} else {
+			conn.Write([]byte("stream: OK\x00"))
+		}
+	default:
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+	}
+}
+
+func TestClamdScanner_Scan(t *testing.T) {
+	t.Parallel()
+	address := startFakeClamd(t, 1024*1024)
+	scanner, err := NewClamdScanner(address, 5*time.Second)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		content   string
+		infected  bool
+		signature string
+	}{
+		{"clean", "hello world", false, ""},
+		{"empty", "", false, ""},
+		{"infected", eicar, true, "Eicar-Test-Signature"},
+		// Split across chunks of the stream
+		{"infected in large file", strings.Repeat("a", clamdChunkSize-10) + eicar, true, "Eicar-Test-Signature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res, err := scanner.Scan(context.Background(), strings.NewReader(tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.infected, res.Infected)
+			assert.Equal(t, tt.signature, res.Signature)
+		})
+	}
+}
+
+func TestClamdScanner_ScanTooLarge(t *testing.T) {
+	t.Parallel()
+	address := startFakeClamd(t, 100)
+	scanner, err := NewClamdScanner(address, 5*time.Second)
+	require.NoError(t, err)
+
+	_, err = scanner.Scan(context.Background(), strings.NewReader(strings.Repeat("a", 1000)))
+	require.Error(t, err)
+}
+
+func TestClamdScanner_Ping(t *testing.T) {
+	t.Parallel()
+	scanner, err := NewClamdScanner(startFakeClamd(t, 100), 5*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, scanner.Ping(context.Background()))
+
+	down, err := NewClamdScanner("unix:///nonexistent/clamd.sock", time.Second)
+	require.NoError(t, err)
+	require.Error(t, down.Ping(context.Background()))
+}
+
+func TestNewClamdScanner(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		address     string
+		expectError bool
+	}{
+		{"unix:///run/clamav/clamd.ctl", false},
+		{"tcp://localhost:3310", false},
+		{"localhost:3310", true},
+		{"udp://localhost:3310", true},
+		{"tcp://", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewClamdScanner(tt.address, time.Second)
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseScanReply(t *testing.T) {
+	t.Parallel()
+	res, err := parseScanReply("stream: OK")
+	require.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	res, err = parseScanReply("stream: Win.Test.EICAR_HDB-1 FOUND")
+	require.NoError(t, err)
+	assert.True(t, res.Infected)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", res.Signature)
+
+	_, err = parseScanReply("stream: Can't allocate memory ERROR")
+	require.Error(t, err)
+	_, err = parseScanReply("INSTREAM size limit exceeded. ERROR")
+	require.Error(t, err)
+}
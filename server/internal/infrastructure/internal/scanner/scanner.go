@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"context"
+	"skyvault/internal/domain/media"
+	"skyvault/pkg/appconfig"
+	"time"
+)
+
+type Scanner struct {
+	Scanner media.Scanner
+	clamd   *ClamdScanner // nil if clamd is not configured
+}
+
+// NewScanner uses clamd if configured, otherwise files are not scanned.
+func NewScanner(app *appconfig.App) *Scanner {
+	cfg := app.Config.Scanner.Clamd
+	if cfg.Address == "" {
+		return &Scanner{Scanner: NoopScanner{}}
+	}
+
+	clamd, err := NewClamdScanner(cfg.Address, time.Duration(cfg.TimeoutSec)*time.Second)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Str("address", cfg.Address).Msg("failed to init clamd scanner")
+	}
+	return &Scanner{Scanner: clamd, clamd: clamd}
+}
+
+// Health checks that clamd is reachable, if configured
+func (s *Scanner) Health(ctx context.Context) error {
+	if s.clamd == nil {
+		return nil
+	}
+	return s.clamd.Ping(ctx)
+}
@@ -20,6 +20,7 @@ import (
 
 const localStorageBaseDir = "uploads"
 const chunksDir = "chunks"
+const previewsDir = "previews"
 
 var _ media.Storage = (*LocalStorage)(nil)
 
@@ -262,6 +263,21 @@ func (s *LocalStorage) DeleteFile(ctx context.Context, name, ownerID string) err
 	return removeFile(deletePath)
 }
 
+func (s *LocalStorage) ReplaceFile(ctx context.Context, fromName, name, ownerID string) error {
+	ownerDirPath := getOwnerDirPath(s.baseDir, ownerID)
+	fromPath := getFilePath(ownerDirPath, fromName)
+
+	// A rename within the same directory replaces the file atomically
+	err := os.Rename(fromPath, getFilePath(ownerDirPath, name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonNoData, err), "storage.LocalStorage.ReplaceFile:Rename.NotExist").WithMetadata("from_path", fromPath)
+		}
+		return apperror.NewAppError(err, "storage.LocalStorage.ReplaceFile:Rename").WithMetadata("from_path", fromPath)
+	}
+	return nil
+}
+
 func (s *LocalStorage) DeleteChunk(ctx context.Context, uploadID string, chunkIndex int64, ownerID string) error {
 	chunkPath := getChunkPath(getChunksDirPath(s.baseDir, ownerID, uploadID), chunkIndex)
 	return removeFile(chunkPath)
@@ -291,6 +307,85 @@ func (s *LocalStorage) OpenFile(ctx context.Context, name, ownerID string) (io.R
 	return f, nil
 }
 
+func (s *LocalStorage) OpenChunk(ctx context.Context, uploadID string, chunkIndex int64, ownerID string) (io.ReadSeekCloser, error) {
+	openPath := getChunkPath(getChunksDirPath(s.baseDir, ownerID, uploadID), chunkIndex)
+	f, err := os.Open(openPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonNoData, err), "storage.LocalStorage.OpenChunk:Open").WithMetadata("open_path", openPath)
+		}
+
+		return nil, apperror.NewAppError(err, "storage.LocalStorage.OpenChunk:Open").WithMetadata("open_path", openPath)
+	}
+	return f, nil
+}
+
+func (s *LocalStorage) SavePreview(ctx context.Context, preview io.Reader, fileID string, size media.PreviewSize, ownerID string) error {
+	previewsDirPath := getPreviewsDirPath(s.baseDir, ownerID)
+	previewPath := getPreviewPath(previewsDirPath, fileID, size)
+
+	err := os.MkdirAll(previewsDirPath, 0700)
+	if err != nil {
+		return apperror.NewAppError(err, "storage.LocalStorage.SavePreview:MkdirAll").WithMetadata("previews_dir_path", previewsDirPath)
+	}
+
+	// Write to a temp file first, so that readers never see a partially written preview
+	tmp, err := os.CreateTemp(previewsDirPath, ".tmp_*")
+	if err != nil {
+		return apperror.NewAppError(err, "storage.LocalStorage.SavePreview:CreateTemp").WithMetadata("previews_dir_path", previewsDirPath)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, preview)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return apperror.NewAppError(err, "storage.LocalStorage.SavePreview:Copy").WithMetadata("preview_path", previewPath)
+	}
+
+	err = os.Rename(tmp.Name(), previewPath)
+	if err != nil {
+		return apperror.NewAppError(err, "storage.LocalStorage.SavePreview:Rename").WithMetadata("preview_path", previewPath)
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) OpenPreview(ctx context.Context, fileID string, size media.PreviewSize, ownerID string) (io.ReadSeekCloser, error) {
+	previewsDirPath := getPreviewsDirPath(s.baseDir, ownerID)
+	openPath := getPreviewPath(previewsDirPath, fileID, size)
+	f, err := os.Open(openPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Move the preview saved under the legacy path, if any, and retry
+		if renameErr := os.Rename(getLegacyPreviewPath(previewsDirPath, fileID, size), openPath); renameErr == nil {
+			f, err = os.Open(openPath)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, apperror.NewAppError(fmt.Errorf("%w: %w", apperror.ErrCommonNoData, err), "storage.LocalStorage.OpenPreview:Open").WithMetadata("open_path", openPath)
+		}
+
+		return nil, apperror.NewAppError(err, "storage.LocalStorage.OpenPreview:Open").WithMetadata("open_path", openPath)
+	}
+	return f, nil
+}
+
+func (s *LocalStorage) DeletePreviews(ctx context.Context, fileID string, ownerID string) error {
+	previewsDirPath := getPreviewsDirPath(s.baseDir, ownerID)
+	for _, size := range media.PreviewSizes {
+		for _, path := range []string{getPreviewPath(previewsDirPath, fileID, size), getLegacyPreviewPath(previewsDirPath, fileID, size)} {
+			err := removeFile(path)
+			if err != nil && !errors.Is(err, apperror.ErrCommonNoData) {
+				return apperror.NewAppError(err, "storage.LocalStorage.DeletePreviews:removeFile").WithMetadata("file_id", fileID).WithMetadata("size", size)
+			}
+		}
+	}
+
+	return nil
+}
+
 func getOwnerDirPath(baseDir string, ownerID string) string {
 	return filepath.Join(baseDir, ownerID)
 }
@@ -306,3 +401,17 @@ func getChunksDirPath(baseDir string, ownerID string, uploadID string) string {
 func getChunkPath(chunksDir string, chunkIndex int64) string {
 	return filepath.Join(chunksDir, fmt.Sprintf("chunk_%d", chunkIndex))
 }
+
+func getPreviewsDirPath(baseDir string, ownerID string) string {
+	return filepath.Join(getOwnerDirPath(baseDir, ownerID), previewsDir)
+}
+
+func getPreviewPath(previewsDir string, fileID string, size media.PreviewSize) string {
+	return filepath.Join(previewsDir, fmt.Sprintf("%s_%s", fileID, size))
+}
+
+// getLegacyPreviewPath returns the path of the previews saved when only images had previews.
+// They are moved to the current path when first opened.
+func getLegacyPreviewPath(previewsDir string, fileID string, size media.PreviewSize) string {
+	return filepath.Join(previewsDir, fmt.Sprintf("%s_%s.jpg", fileID, size))
+}
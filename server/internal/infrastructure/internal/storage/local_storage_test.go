@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"skyvault/internal/domain/media"
 	"skyvault/pkg/appconfig"
 	"skyvault/pkg/apperror"
 	"skyvault/pkg/utils"
@@ -129,3 +130,67 @@ func TestOpenFile(t *testing.T) {
 	require.NoError(t, err, "Should be able to read opened file")
 	require.Equal(t, fileContent, content, "Opened file content should match")
 }
+
+func TestPreviews(t *testing.T) {
+	t.Parallel()
+	app := setupTestApp()
+	local := NewLocalStorage(app)
+	ctx := context.Background()
+
+	ownerID := "1"
+	fileID := "file1"
+
+	_, err := local.OpenPreview(ctx, fileID, media.PreviewSizeSmall, ownerID)
+	require.ErrorIs(t, err, apperror.ErrCommonNoData, "OpenPreview should return ErrNoData when preview does not exist")
+
+	err = local.SavePreview(ctx, bytes.NewReader([]byte("small v1")), fileID, media.PreviewSizeSmall, ownerID)
+	require.NoError(t, err, "SavePreview should not return an error")
+
+	// Saving again overwrites the existing preview
+	err = local.SavePreview(ctx, bytes.NewReader([]byte("small v2")), fileID, media.PreviewSizeSmall, ownerID)
+	require.NoError(t, err, "SavePreview should overwrite the existing preview")
+
+	preview, err := local.OpenPreview(ctx, fileID, media.PreviewSizeSmall, ownerID)
+	require.NoError(t, err, "OpenPreview should not return an error")
+	content, err := io.ReadAll(preview)
+	preview.Close()
+	require.NoError(t, err, "Should be able to read opened preview")
+	require.Equal(t, []byte("small v2"), content, "Preview content should match the last saved one")
+
+	// Deleting previews ignores the missing sizes
+	err = local.DeletePreviews(ctx, fileID, ownerID)
+	require.NoError(t, err, "DeletePreviews should not return an error")
+
+	_, err = local.OpenPreview(ctx, fileID, media.PreviewSizeSmall, ownerID)
+	require.ErrorIs(t, err, apperror.ErrCommonNoData, "Deleted preview should not exist")
+}
+
+func TestLegacyPreviews(t *testing.T) {
+	t.Parallel()
+	app := setupTestApp()
+	local := NewLocalStorage(app)
+	ctx := context.Background()
+
+	ownerID := "2"
+	fileID := "file1"
+
+	// Save a preview under the path used before non image previews
+	previewsDirPath := getPreviewsDirPath(local.baseDir, ownerID)
+	require.NoError(t, os.MkdirAll(previewsDirPath, 0700))
+	legacyPath := getLegacyPreviewPath(previewsDirPath, fileID, media.PreviewSizeSmall)
+	require.NoError(t, os.WriteFile(legacyPath, []byte("legacy"), 0600))
+
+	preview, err := local.OpenPreview(ctx, fileID, media.PreviewSizeSmall, ownerID)
+	require.NoError(t, err, "OpenPreview should find the legacy preview")
+	content, err := io.ReadAll(preview)
+	preview.Close()
+	require.NoError(t, err, "Should be able to read opened preview")
+	require.Equal(t, []byte("legacy"), content, "Preview content should match the legacy one")
+	require.NoFileExists(t, legacyPath, "Legacy preview should be moved to the current path")
+
+	err = local.DeletePreviews(ctx, fileID, ownerID)
+	require.NoError(t, err, "DeletePreviews should not return an error")
+
+	_, err = local.OpenPreview(ctx, fileID, media.PreviewSizeSmall, ownerID)
+	require.ErrorIs(t, err, apperror.ErrCommonNoData, "Deleted preview should not exist")
+}
@@ -0,0 +1,93 @@
+package webhookinfra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"skyvault/internal/domain/webhook"
+	"syscall"
+	"time"
+)
+
+var _ webhook.Sender = (*HTTPSender)(nil)
+
+var errPrivateAddress = errors.New("private address not allowed")
+
+// HTTPSender posts the deliveries, the timeout is set by the caller's context.
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender rejects the receivers on private, loopback and link-local addresses, the URLs are
+// given by the users and must not reach the internal services. allowPrivate lifts it, e.g. to test
+// a local receiver.
+func NewHTTPSender(allowPrivate bool) *HTTPSender {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !allowPrivate {
+		// Checked on the resolved address, so a public host can't resolve to a private one
+		dialer.Control = denyPrivateAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Dialed directly, a proxy would be the checked address instead of the receiver
+	transport.Proxy = nil
+
+	return &HTTPSender{
+		client: &http.Client{
+			Transport: transport,
+			// A redirect is a failure, the receivers must give the final URL
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func denyPrivateAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", errPrivateAddress, ip)
+	}
+	return nil
+}
+
+func (s *HTTPSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "SkyVault-Webhook")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Drained so that the connection is reused. The body is not kept, it is shown to the owner in
+	// the delivery log and could leak what the receiver or a redirect target serves.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, nil
+	}
+	return res.StatusCode, fmt.Errorf("response status %d", res.StatusCode)
+}
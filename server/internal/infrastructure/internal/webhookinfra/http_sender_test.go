@@ -0,0 +1,97 @@
+package webhookinfra
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"skyvault/internal/domain/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSenderSend(t *testing.T) {
+	t.Parallel()
+
+	// A local receiver, verifying the signature as the integrations do
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		ts, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if err != nil || webhook.Sign("secret", ts, body) != r.Header.Get(webhook.HeaderSignature) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := NewHTTPSender(true)
+	body := []byte(`{"type":"file.created"}`)
+	now := time.Now().Unix()
+	headers := map[string]string{
+		webhook.HeaderTimestamp: strconv.FormatInt(now, 10),
+		webhook.HeaderSignature: webhook.Sign("secret", now, body),
+	}
+
+	status, err := sender.Send(context.Background(), receiver.URL, headers, body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	headers[webhook.HeaderSignature] = webhook.Sign("other", now, body)
+	status, err = sender.Send(context.Background(), receiver.URL, headers, body)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.EqualError(t, err, "response status 401", "without the body")
+}
+
+func TestHTTPSenderSendFailures(t *testing.T) {
+	t.Parallel()
+
+	redirect := httptest.NewServer(http.RedirectHandler("https://example.com", http.StatusFound))
+	defer redirect.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	sender := NewHTTPSender(true)
+
+	status, err := sender.Send(context.Background(), redirect.URL, nil, nil)
+	assert.Equal(t, http.StatusFound, status, "not followed")
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	status, err = sender.Send(ctx, slow.URL, nil, nil)
+	assert.Zero(t, status)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHTTPSenderSendPrivate(t *testing.T) {
+	t.Parallel()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := NewHTTPSender(false)
+	status, err := sender.Send(context.Background(), receiver.URL, nil, nil)
+	assert.Zero(t, status)
+	assert.ErrorIs(t, err, errPrivateAddress)
+
+	for _, addr := range []string{"10.0.0.1:80", "192.168.1.1:443", "169.254.169.254:80", "[::1]:80", "[::ffff:127.0.0.1]:80", "0.0.0.0:80"} {
+		assert.ErrorIs(t, denyPrivateAddress("tcp", addr, nil), errPrivateAddress, addr)
+	}
+	assert.NoError(t, denyPrivateAddress("tcp", "93.184.216.34:443", nil))
+}
@@ -0,0 +1,14 @@
+package webhookinfra
+
+import (
+	"skyvault/internal/domain/webhook"
+	"skyvault/pkg/appconfig"
+)
+
+type WebhookInfra struct {
+	Sender webhook.Sender
+}
+
+func NewWebhookInfra(app *appconfig.App) *WebhookInfra {
+	return &WebhookInfra{Sender: NewHTTPSender(app.Config.Webhook.AllowPrivateURLs)}
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"skyvault/internal/api/helper/dtos"
 	"skyvault/internal/domain/auth"
+	"skyvault/pkg/utils"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -160,3 +163,236 @@ func TestAuthErrors(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, resp.Code, "should return NotFound for invalid credentials")
 	})
 }
+
+// TestRefreshTokenAndLogout tests the rotation of the refresh tokens, the reuse detection and the logout
+func TestRefreshTokenAndLogout(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+
+	signUp := func(t *testing.T) dtos.SignUp {
+		t.Helper()
+		jsonBody, err := json.Marshal(map[string]interface{}{
+			"email":    utils.RandomEmail(),
+			"fullName": "Test User",
+			"provider": string(auth.ProviderEmail),
+			"password": "password123",
+		})
+		require.NoError(t, err, "should marshal sign up request")
+
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/pub/auth/sign-up", bytes.NewBuffer(jsonBody))
+		require.NoError(t, err, "should create new request for sign up")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := executeRequest(t, env, req)
+		require.Equal(t, http.StatusCreated, resp.Code, "should return status created for sign up")
+
+		var res dtos.SignUp
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res), "should decode sign up response")
+		require.NotEmpty(t, res.RefreshToken, "should return refresh token")
+		return res
+	}
+
+	refresh := func(t *testing.T, refreshToken string) (*dtos.RefreshToken, int) {
+		t.Helper()
+		jsonBody, err := json.Marshal(map[string]string{"refreshToken": refreshToken})
+		require.NoError(t, err, "should marshal refresh request")
+
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/pub/auth/refresh", bytes.NewBuffer(jsonBody))
+		require.NoError(t, err, "should create new request for refresh")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := executeRequest(t, env, req)
+		if resp.Code != http.StatusOK {
+			return nil, resp.Code
+		}
+
+		var res dtos.RefreshToken
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res), "should decode refresh response")
+		return &res, resp.Code
+	}
+
+	getAppPasswords := func(t *testing.T, token string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, "/api/v1/auth/app-passwords", nil)
+		require.NoError(t, err, "should create new request for app passwords")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return executeRequest(t, env, req).Code
+	}
+
+	t.Run("Refresh Rotates Token", func(t *testing.T) {
+		t.Parallel()
+		signUpRes := signUp(t)
+
+		res, code := refresh(t, signUpRes.RefreshToken)
+		require.Equal(t, http.StatusOK, code, "should refresh")
+		assert.NotEqual(t, signUpRes.RefreshToken, res.RefreshToken, "should rotate refresh token")
+		assert.Equal(t, http.StatusOK, getAppPasswords(t, res.Token), "should accept new access token")
+
+		res, code = refresh(t, res.RefreshToken)
+		require.Equal(t, http.StatusOK, code, "should refresh with rotated token")
+		assert.NotEmpty(t, res.Token, "should return access token")
+	})
+
+	t.Run("Reused Refresh Token Revokes Session", func(t *testing.T) {
+		t.Parallel()
+		signUpRes := signUp(t)
+
+		res, code := refresh(t, signUpRes.RefreshToken)
+		require.Equal(t, http.StatusOK, code, "should refresh")
+
+		_, code = refresh(t, signUpRes.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, code, "should reject reused refresh token")
+
+		_, code = refresh(t, res.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, code, "should reject refresh of revoked session")
+		assert.Equal(t, http.StatusSeeOther, getAppPasswords(t, res.Token), "should reject access token of revoked session")
+	})
+
+	t.Run("Unknown Refresh Token Keeps Session", func(t *testing.T) {
+		t.Parallel()
+		signUpRes := signUp(t)
+		sessionID, _, ok := strings.Cut(signUpRes.RefreshToken, ".")
+		require.True(t, ok, "refresh token should start with the session id")
+
+		_, code := refresh(t, sessionID+".garbage")
+		assert.Equal(t, http.StatusUnauthorized, code, "should reject unknown refresh token")
+
+		res, code := refresh(t, signUpRes.RefreshToken)
+		require.Equal(t, http.StatusOK, code, "should still refresh with the current token")
+		assert.Equal(t, http.StatusOK, getAppPasswords(t, res.Token), "should accept new access token")
+	})
+
+	t.Run("Logout Revokes Session", func(t *testing.T) {
+		t.Parallel()
+		signUpRes := signUp(t)
+
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+		require.NoError(t, err, "should create new request for logout")
+		req.Header.Set("Authorization", "Bearer "+signUpRes.Token)
+
+		resp := executeRequest(t, env, req)
+		require.Equal(t, http.StatusNoContent, resp.Code, "should logout")
+
+		assert.Equal(t, http.StatusSeeOther, getAppPasswords(t, signUpRes.Token), "should reject access token after logout")
+
+		_, code := refresh(t, signUpRes.RefreshToken)
+		assert.Equal(t, http.StatusUnauthorized, code, "should reject refresh after logout")
+	})
+}
+
+// TestSessions tests listing and revoking the sessions, and the revocation by a password change
+func TestSessions(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+
+	email, password := utils.RandomEmail(), "password123"
+
+	signIn := func(t *testing.T, userAgent string) string {
+		t.Helper()
+		jsonBody, err := json.Marshal(map[string]interface{}{
+			"provider":       string(auth.ProviderEmail),
+			"providerUserId": email,
+			"password":       password,
+		})
+		require.NoError(t, err, "should marshal sign in request")
+
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/pub/auth/sign-in", bytes.NewBuffer(jsonBody))
+		require.NoError(t, err, "should create new request for sign in")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+
+		resp := executeRequest(t, env, req)
+		require.Equal(t, http.StatusOK, resp.Code, "should return status ok for sign in")
+
+		var res dtos.SignUp
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res), "should decode sign in response")
+		return res.Token
+	}
+
+	request := func(t *testing.T, method, url, token string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body), "should marshal request")
+		}
+
+		req, err := http.NewRequest(method, url, &buf)
+		require.NoError(t, err, "should create new request")
+		req.Header.Set("Authorization", "Bearer "+token)
+		return executeRequest(t, env, req)
+	}
+
+	getSessions := func(t *testing.T, token string) []*dtos.GetSession {
+		t.Helper()
+		resp := request(t, http.MethodGet, "/api/v1/auth/sessions", token, nil)
+		require.Equal(t, http.StatusOK, resp.Code, "should list sessions")
+
+		var sessions []*dtos.GetSession
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions), "should decode sessions")
+		return sessions
+	}
+
+	jsonBody, err := json.Marshal(map[string]interface{}{
+		"email":    email,
+		"fullName": "Test User",
+		"provider": string(auth.ProviderEmail),
+		"password": password,
+	})
+	require.NoError(t, err, "should marshal sign up request")
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/pub/auth/sign-up", bytes.NewBuffer(jsonBody))
+	require.NoError(t, err, "should create new request for sign up")
+	resp := executeRequest(t, env, req)
+	require.Equal(t, http.StatusCreated, resp.Code, "should return status created for sign up")
+
+	laptop := signIn(t, "Laptop")
+	phone := signIn(t, "Phone")
+	tablet := signIn(t, "Tablet")
+
+	// The sign-up session and the three sign-ins
+	sessions := getSessions(t, laptop)
+	require.Len(t, sessions, 4, "should list all sessions")
+	var current *dtos.GetSession
+	for _, session := range sessions {
+		if session.Current {
+			require.Nil(t, current, "should mark one session as current")
+			current = session
+		}
+	}
+	require.NotNil(t, current, "should mark the session of the request")
+	assert.Equal(t, "Laptop", current.UserAgent, "should record the user agent")
+	assert.NotEmpty(t, current.IPAddress, "should record the ip address")
+
+	// Revoke one
+	var phoneSessionID string
+	for _, session := range sessions {
+		if session.UserAgent == "Phone" {
+			phoneSessionID = session.ID
+		}
+	}
+	resp = request(t, http.MethodDelete, "/api/v1/auth/sessions/"+phoneSessionID, laptop, nil)
+	require.Equal(t, http.StatusNoContent, resp.Code, "should revoke session")
+	assert.Equal(t, http.StatusSeeOther, request(t, http.MethodGet, "/api/v1/auth/sessions", phone, nil).Code, "should reject revoked session")
+	assert.Len(t, getSessions(t, laptop), 3, "should not list revoked session")
+
+	// Revoke all others
+	resp = request(t, http.MethodPost, "/api/v1/auth/sessions/revoke-others", laptop, nil)
+	require.Equal(t, http.StatusOK, resp.Code, "should revoke other sessions")
+	var revoked dtos.RevokeSessions
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&revoked), "should decode revoked sessions")
+	assert.Equal(t, 2, revoked.Count, "should revoke the sign-up and tablet sessions")
+	assert.Equal(t, http.StatusSeeOther, request(t, http.MethodGet, "/api/v1/auth/sessions", tablet, nil).Code, "should reject revoked session")
+	assert.Len(t, getSessions(t, laptop), 1, "should keep current session")
+
+	// Change the password
+	resp = request(t, http.MethodPut, "/api/v1/auth/password", laptop, map[string]string{"currentPassword": "wrong-password", "newPassword": "password456"})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, "should require current password")
+
+	desktop := signIn(t, "Desktop")
+	resp = request(t, http.MethodPut, "/api/v1/auth/password", laptop, map[string]string{"currentPassword": password, "newPassword": "password456"})
+	require.Equal(t, http.StatusNoContent, resp.Code, "should change password")
+	assert.Equal(t, http.StatusSeeOther, request(t, http.MethodGet, "/api/v1/auth/sessions", laptop, nil).Code, "should revoke current session")
+	assert.Equal(t, http.StatusSeeOther, request(t, http.MethodGet, "/api/v1/auth/sessions", desktop, nil).Code, "should revoke other sessions")
+
+	password = "password456"
+	assert.Len(t, getSessions(t, signIn(t, "Laptop")), 1, "should sign in with new password")
+}
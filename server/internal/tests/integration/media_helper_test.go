@@ -7,9 +7,11 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"skyvault/internal/api/helper/dtos"
+	"skyvault/pkg/apperror"
 	"skyvault/pkg/paging"
 	"testing"
 
@@ -202,6 +204,9 @@ func getFolderContentsWithPaging(t *testing.T, env *testEnv, token string, folde
 	if opt.PrevCursor != "" {
 		url += "&file-prev-cursor=" + opt.PrevCursor
 	}
+	if opt.IncludeTotal {
+		url += "&file-include-total=true"
+	}
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	require.NoError(t, err, "should create new request for folder contents")
@@ -215,3 +220,109 @@ func getFolderContentsWithPaging(t *testing.T, env *testEnv, token string, folde
 	require.NoError(t, err)
 	return &content
 }
+
+func search(t *testing.T, env *testEnv, token string, term string, folderID string) *dtos.Search {
+	t.Helper()
+
+	query := url.Values{"q": {term}}
+	if folderID != "" {
+		query.Set("folder-id", folderID)
+	}
+
+	return searchWithQuery(t, env, token, query)
+}
+
+func searchWithQuery(t *testing.T, env *testEnv, token string, query url.Values) *dtos.Search {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/media/search?"+query.Encode(), nil)
+	require.NoError(t, err, "should create new request for search")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp := executeRequest(t, env, req)
+	require.Equal(t, http.StatusOK, resp.Code, "should return status ok for search")
+
+	var result dtos.Search
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	return &result
+}
+
+func trashFiles(t *testing.T, env *testEnv, token string, fileIDs []string) {
+	t.Helper()
+	body := map[string][]string{"fileIds": fileIDs}
+	jsonBody, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodDelete, filesURL(), bytes.NewBuffer(jsonBody))
+	require.NoError(t, err, "should create new request for file trash")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp := executeRequest(t, env, req)
+	require.Equal(t, http.StatusNoContent, resp.Code, "should return status no content for file trash")
+}
+
+// getChanges returns the changes since the cursor, or the error code with the status
+func getChanges(t *testing.T, env *testEnv, token string, cursor string) (int, *dtos.GetChanges, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/media/changes?"+url.Values{"cursor": {cursor}}.Encode(), nil)
+	require.NoError(t, err, "should create new request for changes")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp := executeRequest(t, env, req)
+	if resp.Code != http.StatusOK {
+		var publicErr apperror.PublicError
+		err = json.NewDecoder(resp.Body).Decode(&publicErr)
+		require.NoError(t, err)
+		return resp.Code, nil, publicErr.Code
+	}
+
+	var changes dtos.GetChanges
+	err = json.NewDecoder(resp.Body).Decode(&changes)
+	require.NoError(t, err)
+	return resp.Code, &changes, ""
+}
+
+func getFolderInfo(t *testing.T, env *testEnv, token string, folderID string) *dtos.GetFolderInfo {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, folderURL(folderID), nil)
+	require.NoError(t, err, "should create new request for folder info")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp := executeRequest(t, env, req)
+	require.Equal(t, http.StatusOK, resp.Code, "should return status ok for folder info")
+
+	var folderInfo dtos.GetFolderInfo
+	err = json.NewDecoder(resp.Body).Decode(&folderInfo)
+	require.NoError(t, err)
+	return &folderInfo
+}
+
+func moveFolder(t *testing.T, env *testEnv, token string, folderID string, newParentID string) {
+	t.Helper()
+	body := map[string]string{"folderId": newParentID}
+	jsonBody, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPatch, folderURL(folderID)+"/move", bytes.NewBuffer(jsonBody))
+	require.NoError(t, err, "should create new request for folder move")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp := executeRequest(t, env, req)
+	require.Equal(t, http.StatusNoContent, resp.Code, "should return status no content for folder move")
+}
+
+func restoreFile(t *testing.T, env *testEnv, token string, fileID string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPatch, fileURL(fileID)+"/restore", nil)
+	require.NoError(t, err, "should create new request for file restore")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp := executeRequest(t, env, req)
+	require.Equal(t, http.StatusNoContent, resp.Code, "should return status no content for file restore")
+}
@@ -1,9 +1,20 @@
 package integration
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"skyvault/internal/api/helper/dtos"
+	"skyvault/internal/bootstrap"
+	"skyvault/internal/domain/media"
+	"skyvault/pkg/apperror"
 	"skyvault/pkg/common"
 	"skyvault/pkg/paging"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -127,6 +138,8 @@ func TestPagination(t *testing.T) {
 					Limit:      3,
 					Sort:       tc.sort,
 					SortBy:     paging.SortByName,
+					// The total ignores the cursor, it is the same on every page
+					IncludeTotal: true,
 				}
 			}
 
@@ -142,6 +155,9 @@ func TestPagination(t *testing.T) {
 			require.Len(t, contents.FilePage.Items, 3, "first page should contain exactly 3 files")
 			require.True(t, contents.FilePage.HasMore, "should have more pages")
 			require.NotEmpty(t, contents.FilePage.NextCursor, "first page should have next cursor")
+			require.NotNil(t, contents.FilePage.Total, "first page should have the total")
+			require.EqualValues(t, len(tc.expected), *contents.FilePage.Total, "total should count all the files")
+			require.False(t, contents.FilePage.TotalEstimated, "total should be exact")
 
 			// Verify first page files
 			for i := 0; i < 3; i++ {
@@ -172,6 +188,7 @@ func TestPagination(t *testing.T) {
 			)
 			require.Len(t, contents.FilePage.Items, 2, "last page should contain exactly 2 files")
 			require.False(t, contents.FilePage.HasMore, "should not have more pages")
+			require.EqualValues(t, len(tc.expected), *contents.FilePage.Total, "last page should have the same total")
 			require.NotEmpty(t, contents.FilePage.PrevCursor, "last page should have prev cursor")
 
 			// Verify last page files
@@ -284,3 +301,341 @@ func TestPagination(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchInFolder(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+	_, token := createTestUser(t, env)
+
+	folderA := createFolder(t, env, token, "0", "Projects")
+	folderB := createFolder(t, env, token, folderA.ID, "Archive")
+
+	uploadFile(t, env, token, "0", "report-root.txt", common.BytesPerKB)
+	uploadFile(t, env, token, folderA.ID, "report-projects.txt", common.BytesPerKB)
+	uploadFile(t, env, token, folderB.ID, "report-archive.txt", common.BytesPerKB)
+
+	// Unscoped search covers the whole drive
+	result := search(t, env, token, "report", "")
+	assert.Len(t, result.FilePage.Items, 3, "unscoped search should match every file")
+
+	// Scoped search covers the folder and all of its descendants
+	result = search(t, env, token, "report", folderA.ID)
+	names := make([]string, 0, len(result.FilePage.Items))
+	for _, item := range result.FilePage.Items {
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{"report-projects.txt", "report-archive.txt"}, names)
+
+	result = search(t, env, token, "archive", folderA.ID)
+	require.Len(t, result.FolderPage.Items, 1, "scoped search should match nested folders")
+	assert.Equal(t, folderB.ID, result.FolderPage.Items[0].ID)
+}
+
+// TestPaginationAcrossFolders pages through listings mixing the items of several folders,
+// the keyset must not skip or repeat items of the other folders.
+func TestPaginationAcrossFolders(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+	_, token := createTestUser(t, env)
+
+	folderA := createFolder(t, env, token, "0", "A")
+	folderB := createFolder(t, env, token, "0", "B")
+
+	// Sorted by name, the items alternate between the folders
+	for i := 1; i <= 6; i++ {
+		folderID := folderA.ID
+		if i%2 == 0 {
+			folderID = folderB.ID
+		}
+		uploadFile(t, env, token, folderID, fmt.Sprintf("doc-%d.txt", i), common.BytesPerKB)
+		createFolder(t, env, token, folderID, fmt.Sprintf("dir-%d", i))
+	}
+
+	expected := func(prefix string) []string {
+		names := []string{}
+		for i := 1; i <= 6; i++ {
+			names = append(names, fmt.Sprintf(prefix, i))
+		}
+		return names
+	}
+
+	// Files
+	names := []string{}
+	query := url.Values{"q": {"doc-"}, "file-limit": {"2"}, "file-sort": {"asc"}, "file-sort-by": {"name"}}
+	for {
+		result := searchWithQuery(t, env, token, query)
+		for _, item := range result.FilePage.Items {
+			names = append(names, item.Name)
+		}
+		if !result.FilePage.HasMore {
+			break
+		}
+		require.Less(t, len(names), 6, "should stop once all the files are listed")
+		query.Set("file-next-cursor", result.FilePage.NextCursor)
+	}
+	assert.Equal(t, expected("doc-%d.txt"), names)
+
+	// Folders
+	names = []string{}
+	query = url.Values{"q": {"dir-"}, "folder-limit": {"2"}, "folder-sort": {"asc"}, "folder-sort-by": {"name"}}
+	for {
+		result := searchWithQuery(t, env, token, query)
+		for _, item := range result.FolderPage.Items {
+			names = append(names, item.Name)
+		}
+		if !result.FolderPage.HasMore {
+			break
+		}
+		require.Less(t, len(names), 6, "should stop once all the folders are listed")
+		query.Set("folder-next-cursor", result.FolderPage.NextCursor)
+	}
+	assert.Equal(t, expected("dir-%d"), names)
+}
+
+// TestChanges follows the change feed of a file through its life, as a sync client would
+func TestChanges(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+	_, token := createTestUser(t, env)
+
+	// The cursor is taken before the full sync, it has no changes
+	code, res, _ := getChanges(t, env, token, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, res.Changes)
+	cursor := res.Cursor
+
+	// next returns the only change since the cursor and moves the cursor after it
+	next := func(t *testing.T) *dtos.GetChange {
+		t.Helper()
+		code, res, _ := getChanges(t, env, token, cursor)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Changes, 1)
+		cursor = res.Cursor
+		return res.Changes[0]
+	}
+
+	folder := createFolder(t, env, token, "0", "docs")
+	change := next(t)
+	assert.Equal(t, "folder", change.ItemType)
+	assert.Equal(t, folder.ID, change.ItemID)
+	assert.Equal(t, "created", change.ChangeType)
+
+	file := uploadFile(t, env, token, "0", "report.txt", 1024)
+	change = next(t)
+	assert.Equal(t, "file", change.ItemType)
+	assert.Equal(t, file.ID, change.ItemID)
+	assert.Equal(t, "created", change.ChangeType)
+	assert.Nil(t, change.ParentFolderID)
+	assert.Equal(t, int64(1024), change.Size)
+
+	renameFile(t, env, token, file.ID, "summary.txt")
+	change = next(t)
+	assert.Equal(t, "renamed", change.ChangeType)
+	assert.Equal(t, "summary.txt", change.Name)
+
+	moveFile(t, env, token, file.ID, folder.ID)
+	change = next(t)
+	assert.Equal(t, "moved", change.ChangeType)
+	require.NotNil(t, change.ParentFolderID)
+	assert.Equal(t, folder.ID, *change.ParentFolderID)
+
+	trashFiles(t, env, token, []string{file.ID})
+	change = next(t)
+	assert.Equal(t, "trashed", change.ChangeType)
+	assert.True(t, change.Trashed)
+
+	// There is no API to delete a file for good yet
+	beforeDelete := cursor
+	err := env.infra.Repository.Media.DeleteFileInfo(context.Background(), file.ID)
+	require.NoError(t, err)
+	change = next(t)
+	assert.Equal(t, file.ID, change.ItemID)
+	assert.Equal(t, "deleted", change.ChangeType)
+
+	// Only the last change of an item is kept
+	code, res, _ = getChanges(t, env, token, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, cursor, res.Cursor, "should return the cursor of the last change")
+
+	t.Run("Expired Cursor", func(t *testing.T) {
+		// The delete is older than the retention once compacted
+		db, err := sql.Open("pgx", env.app.Config.DB.DSN)
+		require.NoError(t, err)
+		defer db.Close()
+		_, err = db.Exec("update change_log set changed_at = changed_at - interval '1 year' where item_id = $1", file.ID)
+		require.NoError(t, err)
+
+		count, err := bootstrap.InitMediaCommands(env.app, env.infra).CompactChangeLog(context.Background(), &media.CompactChangeLogCommand{})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, count, 1, "should purge the delete")
+
+		code, _, errCode := getChanges(t, env, token, beforeDelete)
+		assert.Equal(t, http.StatusGone, code, "should not follow a cursor before a purged delete")
+		assert.Equal(t, apperror.ErrMediaChangeCursorExpired.Code, errCode)
+
+		code, res, _ := getChanges(t, env, token, cursor)
+		assert.Equal(t, http.StatusOK, code, "should follow the cursor of the purged delete")
+		assert.Empty(t, res.Changes)
+	})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+	profile, token := createTestUser(t, env)
+	ctx := context.Background()
+
+	folder := createFolder(t, env, token, "0", "old")
+	inFolder := uploadFile(t, env, token, folder.ID, "in-folder.txt", 1024)
+	file := uploadFile(t, env, token, "0", "old.txt", 1024)
+	recent := uploadFile(t, env, token, "0", "recent.txt", 1024)
+	trashFolders(t, env, token, []string{folder.ID})
+	trashFiles(t, env, token, []string{file.ID, recent.ID})
+
+	// Only the old items are past the retention
+	db, err := sql.Open("pgx", env.app.Config.DB.DSN)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("update file_info set trashed_at = trashed_at - interval '1 year' where id = any($1)", []string{file.ID, inFolder.ID})
+	require.NoError(t, err)
+	_, err = db.Exec("update folder_info set trashed_at = trashed_at - interval '1 year' where id = $1", folder.ID)
+	require.NoError(t, err)
+
+	count, err := bootstrap.InitMediaCommands(env.app, env.infra).PurgeTrash(ctx, &media.PurgeTrashCommand{BatchSize: 1})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, count, 3, "should purge the old files and folder")
+
+	for _, id := range []string{file.ID, inFolder.ID} {
+		_, err = env.infra.Repository.Media.GetFileInfoTrashed(ctx, id)
+		assert.ErrorIs(t, err, apperror.ErrCommonNoData, "should delete the info of the purged file")
+		_, err = env.infra.Storage.LocalStorage.OpenFile(ctx, id, profile.ID)
+		assert.ErrorIs(t, err, apperror.ErrCommonNoData, "should delete the stored purged file")
+	}
+	_, err = env.infra.Repository.Media.GetFolderInfoTrashed(ctx, profile.ID, folder.ID)
+	assert.ErrorIs(t, err, apperror.ErrCommonNoData, "should delete the purged folder")
+
+	_, err = env.infra.Repository.Media.GetFileInfoTrashed(ctx, recent.ID)
+	assert.NoError(t, err, "should keep the recently trashed file")
+
+	usage, err := env.infra.Repository.Media.GetStorageUsage(ctx, profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), usage.TrashedBytes, "should free the quota of the purged files")
+}
+
+func TestReplaceFileContent(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+	profile, token := createTestUser(t, env)
+	ctx := context.Background()
+
+	file := uploadFile(t, env, token, "0", "notes.txt", 1024)
+
+	content := strings.Repeat("replaced\n", 200)
+	replaced, err := bootstrap.InitMediaCommands(env.app, env.infra).ReplaceFileContent(ctx, &media.ReplaceFileContentCommand{
+		OwnerID: profile.ID,
+		FileID:  file.ID,
+		Size:    int64(len(content)),
+		File:    strings.NewReader(content),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, file.ID, replaced.ID, "should keep the ID of the file")
+
+	info, err := env.infra.Repository.Media.GetFileInfo(ctx, file.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size, "should save the size of the new content")
+
+	stored, err := env.infra.Storage.LocalStorage.OpenFile(ctx, file.ID, profile.ID)
+	require.NoError(t, err)
+	defer stored.Close()
+	data, err := io.ReadAll(stored)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data), "should store the new content under the same ID")
+
+	usage, err := env.infra.Repository.Media.GetStorageUsage(ctx, profile.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), usage.UsedBytes, "should only count the new content")
+	assert.Zero(t, usage.TrashedBytes, "should not trash the previous content")
+}
+
+// TestFolderStats tests the recursive size and counts of the folders, maintained by the folder_stats triggers.
+func TestFolderStats(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+	_, token := createTestUser(t, env)
+
+	assertStats := func(t *testing.T, folderID string, size, fileCount, folderCount int64) {
+		t.Helper()
+		info := getFolderInfo(t, env, token, folderID)
+		assert.Equal(t, size, info.Size, "size of %s", info.Name)
+		assert.Equal(t, fileCount, info.FileCount, "file count of %s", info.Name)
+		assert.Equal(t, folderCount, info.FolderCount, "folder count of %s", info.Name)
+	}
+
+	docs := createFolder(t, env, token, "0", "docs")
+	reports := createFolder(t, env, token, docs.ID, "reports")
+	uploadFile(t, env, token, docs.ID, "readme.txt", 1000)
+	report := uploadFile(t, env, token, reports.ID, "report.txt", 2000)
+
+	t.Run("Upload", func(t *testing.T) {
+		assertStats(t, docs.ID, 3000, 2, 1)
+		assertStats(t, reports.ID, 2000, 1, 0)
+	})
+
+	archive := createFolder(t, env, token, "0", "archive")
+
+	t.Run("Move Subtree", func(t *testing.T) {
+		moveFolder(t, env, token, reports.ID, archive.ID)
+		assertStats(t, docs.ID, 1000, 1, 0)
+		assertStats(t, archive.ID, 2000, 1, 1)
+		assertStats(t, reports.ID, 2000, 1, 0)
+	})
+
+	t.Run("Trash And Restore Folder", func(t *testing.T) {
+		trashFolders(t, env, token, []string{reports.ID})
+		assertStats(t, archive.ID, 0, 0, 0)
+
+		restoreFolder(t, env, token, reports.ID)
+		assertStats(t, archive.ID, 2000, 1, 1)
+		assertStats(t, reports.ID, 2000, 1, 0)
+	})
+
+	t.Run("Restore File Into Root", func(t *testing.T) {
+		trashFolders(t, env, token, []string{reports.ID})
+		restoreFile(t, env, token, report.ID)
+		assertStats(t, archive.ID, 0, 0, 0)
+
+		root := getFolderContents(t, env, token, "0")
+		var restored *dtos.GetFileInfo
+		for _, file := range root.FilePage.Items {
+			if file.ID == report.ID {
+				restored = file
+			}
+		}
+		require.NotNil(t, restored, "should restore the file into the root folder")
+		assert.Nil(t, restored.FolderID)
+	})
+
+	t.Run("Sort By Size", func(t *testing.T) {
+		parent := createFolder(t, env, token, "0", "sorted")
+		big := createFolder(t, env, token, parent.ID, "big")
+		small := createFolder(t, env, token, parent.ID, "small")
+		empty := createFolder(t, env, token, parent.ID, "empty")
+		uploadFile(t, env, token, big.ID, "big.bin", 5000)
+		uploadFile(t, env, token, small.ID, "small.bin", 100)
+
+		url := fmt.Sprintf("%s/content?folder-sort=%s&folder-sort-by=%s", folderURL(parent.ID), paging.SortAsc, paging.SortBySize)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := executeRequest(t, env, req)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var content dtos.GetFolderContent
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&content))
+		ids := make([]string, 0, len(content.FolderPage.Items))
+		for _, folder := range content.FolderPage.Items {
+			ids = append(ids, folder.ID)
+		}
+		assert.Equal(t, []string{empty.ID, small.ID, big.ID}, ids, "should sort the folders by their recursive size")
+	})
+}
@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"skyvault/internal/domain/sharing"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/common"
+	"skyvault/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shareDownloadURL(shareID string) string {
+	return fmt.Sprintf("%s/pub/shares/%s/download", baseURL, shareID)
+}
+
+// createShare shares the file with the recipient email, there is no API to create shares yet
+func createShare(t *testing.T, env *testEnv, ownerID string, fileID string, email string, maxDownloads *int64) *sharing.ShareConfig {
+	t.Helper()
+
+	ctx := context.WithValue(context.Background(), common.CtxKeyProfileID, ownerID)
+	sharingCmd := sharing.NewCommandHandlers(env.app, env.infra.Repository.Sharing, env.infra.Repository.Media)
+	share, err := sharingCmd.CreateShare(ctx, &sharing.CreateShareCommand{
+		FileID:       &fileID,
+		MaxDownloads: maxDownloads,
+		Recipients:   []*sharing.ShareRecipientInput{{Email: &email}},
+	})
+	require.NoError(t, err, "should create share")
+
+	return share
+}
+
+func downloadShare(t *testing.T, env *testEnv, shareID string, email *string) (int, string) {
+	t.Helper()
+
+	jsonBody, err := json.Marshal(map[string]any{"email": email})
+	require.NoError(t, err, "should marshal share download request")
+
+	req, err := http.NewRequest(http.MethodPost, shareDownloadURL(shareID), bytes.NewBuffer(jsonBody))
+	require.NoError(t, err, "should create share download request")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := executeRequest(t, env, req)
+	if resp.Code != http.StatusOK {
+		var publicErr apperror.PublicError
+		err = json.NewDecoder(resp.Body).Decode(&publicErr)
+		require.NoError(t, err, "should decode share download error")
+		return resp.Code, publicErr.Code
+	}
+
+	return resp.Code, ""
+}
+
+func TestShareDownload(t *testing.T) {
+	t.Parallel()
+	env := setupTestEnv(t)
+	owner, token := createTestUser(t, env)
+	file := uploadFile(t, env, token, "0", "shared.txt", 1024)
+	email := utils.RandomEmail()
+
+	t.Run("Recipient Email Required", func(t *testing.T) {
+		t.Parallel()
+		share := createShare(t, env, owner.ID, file.ID, email, nil)
+
+		code, errCode := downloadShare(t, env, share.ID, nil)
+		assert.Equal(t, http.StatusForbidden, code, "should not download the share without an email")
+		assert.Equal(t, apperror.ErrSharingInvalidCredentials.Code, errCode)
+
+		code, errCode = downloadShare(t, env, share.ID, utils.Ptr(utils.RandomEmail()))
+		assert.Equal(t, http.StatusForbidden, code, "should not download the share with another email")
+		assert.Equal(t, apperror.ErrSharingInvalidCredentials.Code, errCode)
+
+		code, _ = downloadShare(t, env, share.ID, &email)
+		assert.Equal(t, http.StatusOK, code, "should download the share with the recipient email")
+	})
+
+	t.Run("Max Downloads", func(t *testing.T) {
+		t.Parallel()
+		share := createShare(t, env, owner.ID, file.ID, email, utils.Ptr(int64(2)))
+
+		for i := range 2 {
+			code, _ := downloadShare(t, env, share.ID, &email)
+			require.Equal(t, http.StatusOK, code, "should download the share %d time(s)", i+1)
+		}
+
+		code, errCode := downloadShare(t, env, share.ID, &email)
+		assert.Equal(t, http.StatusForbidden, code, "should not download the share over its max downloads")
+		assert.Equal(t, apperror.ErrSharingMaxDownloadsReached.Code, errCode)
+	})
+}
@@ -0,0 +1,96 @@
+package workflows
+
+import (
+	"context"
+	"skyvault/internal/domain/event"
+	"skyvault/internal/domain/media"
+	"skyvault/internal/domain/sharing"
+	"skyvault/pkg/apperror"
+	"skyvault/pkg/applog"
+)
+
+type ShareDownloadFlow struct {
+	sharingCommands sharing.Commands
+	sharingQueries  sharing.Queries
+	mediaQueries    media.Queries
+	eventCommands   event.Commands
+}
+
+func NewShareDownloadFlow(sharingCommands sharing.Commands, sharingQueries sharing.Queries, mediaQueries media.Queries, eventCommands event.Commands) *ShareDownloadFlow {
+	return &ShareDownloadFlow{
+		sharingCommands: sharingCommands,
+		sharingQueries:  sharingQueries,
+		mediaQueries:    mediaQueries,
+		eventCommands:   eventCommands,
+	}
+}
+
+type ShareDownloadReq struct {
+	ShareID  string
+	Email    *string
+	Password *string
+}
+
+// Run prepares the shared file or folder as a zip archive for a visitor of the share link.
+//
+// App Errors:
+// - ErrCommonNoData
+// - ErrCommonNoAccess
+// - ErrCommonInvalidValue
+// - ErrSharingExpired
+// - ErrSharingInvalidCredentials
+// - ErrSharingMaxDownloadsReached
+// - ErrMediaFileInfected
+func (f *ShareDownloadFlow) Run(ctx context.Context, req *ShareDownloadReq) (*media.ZipArchive, error) {
+	// 1. Validate the access to the share
+	// 2. Get the archive of the shared item on behalf of the share owner
+	// 3. Count the download, before any of it is streamed
+	// 4. Tell the owner the share was accessed
+
+	share, err := f.sharingQueries.GetPublicShare(ctx, &sharing.ValidateShareAccessQuery{
+		ShareID:  req.ShareID,
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "ShareDownloadFlow.Run:sharingQueries.GetPublicShare")
+	}
+
+	query := &media.GetZipArchiveQuery{OwnerID: share.OwnerID}
+	if share.FileID != nil {
+		query.FileIDs = []string{*share.FileID}
+	}
+	if share.FolderID != nil {
+		query.FolderIDs = []string{*share.FolderID}
+	}
+
+	archive, err := f.mediaQueries.GetZipArchive(ctx, query)
+	if err != nil {
+		return nil, apperror.NewAppError(err, "ShareDownloadFlow.Run:mediaQueries.GetZipArchive")
+	}
+
+	err = f.sharingCommands.RecordShareDownload(ctx, &sharing.RecordShareDownloadCommand{
+		ShareID: share.ID,
+		Email:   req.Email,
+	})
+	if err != nil {
+		return nil, apperror.NewAppError(err, "ShareDownloadFlow.Run:sharingCommands.RecordShareDownload")
+	}
+
+	// The download doesn't depend on the event, a failure is only logged
+	err = f.eventCommands.AddEvent(ctx, &event.AddEventCommand{
+		OwnerID: share.OwnerID,
+		Type:    event.TypeShareAccessed,
+		Payload: map[string]any{
+			"shareId":  share.ID,
+			"fileId":   share.FileID,
+			"folderId": share.FolderID,
+			"email":    req.Email,
+		},
+	})
+	if err != nil {
+		applog.GetLoggerFromContext(ctx).Warn().Err(apperror.NewAppError(err, "ShareDownloadFlow.Run:eventCommands.AddEvent")).Str("share_id", share.ID).Msg("failed to add the share accessed event")
+	}
+
+	return archive, nil
+}
@@ -27,11 +27,13 @@ type SignInReq struct {
 	Provider       auth.Provider
 	ProviderUserID string
 	Password       *string
+	Client         auth.SessionClient // Recorded with the session
 }
 
 type SignInRes struct {
-	Profile *profile.Profile
-	Token   string
+	Profile      *profile.Profile
+	Token        string // Access token of the session
+	RefreshToken string
 }
 
 // App Errors:
@@ -43,7 +45,7 @@ type SignInRes struct {
 func (f *SignInFlow) Run(ctx context.Context, req *SignInReq) (*SignInRes, error) {
 	// 1. Get auth by provider and provider user id
 	// 2. Get profile by profile id
-	// 3. Signin to get token
+	// 3. Signin to start a session and get its tokens
 	// 4. Return response
 
 	au, err := f.authQueries.GetByProvider(ctx, &auth.GetByProviderQuery{
@@ -59,18 +61,20 @@ func (f *SignInFlow) Run(ctx context.Context, req *SignInReq) (*SignInRes, error
 		return nil, apperror.NewAppError(err, "SignInFlow.Run:profileQueries.Get")
 	}
 
-	token, err := f.authCommands.SignIn(ctx, &auth.SignInCommand{
+	tokens, err := f.authCommands.SignIn(ctx, &auth.SignInCommand{
 		ProfileID:    au.ProfileID,
 		Provider:     au.Provider,
 		Password:     req.Password,
 		PasswordHash: au.PasswordHash,
+		Client:       req.Client,
 	})
 	if err != nil {
 		return nil, apperror.NewAppError(err, "SignInFlow.Run:authCommands.SignIn")
 	}
 
 	return &SignInRes{
-		Profile: pro,
-		Token:   token,
+		Profile:      pro,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
@@ -32,11 +32,13 @@ type SignUpReq struct {
 	Provider       auth.Provider
 	ProviderUserID string
 	Password       *string
+	Client         auth.SessionClient // Recorded with the session
 }
 
 type SignUpRes struct {
-	Profile *profile.Profile
-	Token   string
+	Profile      *profile.Profile
+	Token        string // Access token of the session
+	RefreshToken string
 }
 
 // App Errors:
@@ -45,7 +47,7 @@ type SignUpRes struct {
 func (f *SignUpFlow) Run(ctx context.Context, req *SignUpReq) (*SignUpRes, error) {
 	// 1. Create profile with transaction
 	// 2. Create auth with transaction
-	// 3. Generate JWT
+	// 3. Start the first session and get its tokens
 	// 4. Commit transaction
 	// 5. Return response
 
@@ -69,11 +71,12 @@ func (f *SignUpFlow) Run(ctx context.Context, req *SignUpReq) (*SignUpRes, error
 		return nil, apperror.NewAppError(err, "SignUpFlow.Run:Create")
 	}
 
-	token, err := authCmdTx.SignUp(ctx, &auth.SignUpCommand{
+	tokens, err := authCmdTx.SignUp(ctx, &auth.SignUpCommand{
 		ProfileID:      pro.ID,
 		Provider:       req.Provider,
 		ProviderUserID: req.ProviderUserID,
 		Password:       req.Password,
+		Client:         req.Client,
 	})
 	if err != nil {
 		return nil, apperror.NewAppError(err, "SignUpFlow.Run:SignUp")
@@ -82,7 +85,8 @@ func (f *SignUpFlow) Run(ctx context.Context, req *SignUpReq) (*SignUpRes, error
 	tx.Commit()
 
 	return &SignUpRes{
-		Profile: pro,
-		Token:   token,
+		Profile:      pro,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
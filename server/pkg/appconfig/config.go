@@ -2,9 +2,11 @@ package appconfig
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,11 +14,14 @@ import (
 )
 
 type Config struct {
-	Server ServerConfig
-	DB     DBConfig
-	Auth   AuthConfig
-	Media  MediaConfig
-	Log    LogConfig
+	Server  ServerConfig
+	DB      DBConfig
+	Auth    AuthConfig
+	Media   MediaConfig
+	Scanner ScannerConfig
+	Jobs    JobsConfig
+	Webhook WebhookConfig
+	Log     LogConfig
 }
 
 type ServerConfig struct {
@@ -55,12 +60,43 @@ type JWTConfig struct {
 
 type AuthConfig struct {
 	JWT JWTConfig
+
+	RefreshTokenTimeoutDays int // How long a session lasts without a refresh, 0 uses the default.
 }
 
 type MediaConfig struct {
 	MaxUploadSizeMB       int64 // Max upload size even when including chunking strategy.
 	MaxDirectUploadSizeMB int64 // Max size allowed for an upload, before chunking strategy is applied. This value must be less than MaxUploadSizeMB.
 	MaxChunkSizeMB        int64 // Max size of a chunk. This value must be less than MaxDirectUploadSizeMB.
+
+	// Mime types detected from the file content, e.g. "image/png" or "image/*".
+	AllowedMimeTypes []string // If set, only these types can be uploaded.
+	DeniedMimeTypes  []string // These types can't be uploaded, even if allowed.
+
+	DefaultQuotaMB int64 // Max storage of a user, 0 is unlimited. Can be overridden per user.
+
+	ChangeRetentionDays int // How long the deletes are kept for the sync clients, 0 uses the default.
+	TrashRetentionDays  int // How long the trashed files are kept before they are purged, 0 uses the default.
+}
+
+// ScannerConfig selects the malware scanner of the uploads. Files are not scanned if no scanner is set.
+type ScannerConfig struct {
+	Clamd ClamdConfig
+}
+
+type ClamdConfig struct {
+	Address    string // e.g. "unix:///run/clamav/clamd.ctl" or "tcp://localhost:3310"
+	TimeoutSec int    // Max. time to scan a single file.
+}
+
+type JobsConfig struct {
+	Workers    int // Number of background workers.
+	QueueSize  int // Max. number of jobs waiting for a worker. New jobs are rejected when the queue is full.
+	TimeoutSec int // Max. time a single job is allowed to run.
+}
+
+type WebhookConfig struct {
+	AllowPrivateURLs bool // Deliver to private, loopback and link-local addresses, e.g. to test a local receiver.
 }
 
 type LogConfig struct {
@@ -101,11 +137,29 @@ func LoadConfig(path string, isDev bool) *Config {
 	// Auth config
 	config.Auth.JWT.Key = []byte(envMap["AUTH__JWT__KEY"])
 	config.Auth.JWT.TokenTimeoutMin = getIntOrZero(envMap["AUTH__JWT__TOKEN_TIMEOUT_MIN"])
+	config.Auth.RefreshTokenTimeoutDays = getIntOrZero(envMap["AUTH__REFRESH_TOKEN_TIMEOUT_DAYS"])
 
 	// Media config
 	config.Media.MaxUploadSizeMB = getInt64OrZero(envMap["MEDIA__MAX_UPLOAD_SIZE_MB"])
 	config.Media.MaxDirectUploadSizeMB = getInt64OrZero(envMap["MEDIA__MAX_DIRECT_UPLOAD_SIZE_MB"])
 	config.Media.MaxChunkSizeMB = getInt64OrZero(envMap["MEDIA__MAX_CHUNK_SIZE_MB"])
+	config.Media.AllowedMimeTypes = getStringSlice(envMap["MEDIA__ALLOWED_MIME_TYPES"])
+	config.Media.DeniedMimeTypes = getStringSlice(envMap["MEDIA__DENIED_MIME_TYPES"])
+	config.Media.DefaultQuotaMB = getInt64OrZero(envMap["MEDIA__DEFAULT_QUOTA_MB"])
+	config.Media.ChangeRetentionDays = getIntOrZero(envMap["MEDIA__CHANGE_RETENTION_DAYS"])
+	config.Media.TrashRetentionDays = getIntOrZero(envMap["MEDIA__TRASH_RETENTION_DAYS"])
+
+	// Scanner config
+	config.Scanner.Clamd.Address = envMap["SCANNER__CLAMD__ADDRESS"]
+	config.Scanner.Clamd.TimeoutSec = getIntOrZero(envMap["SCANNER__CLAMD__TIMEOUT_SEC"])
+
+	// Jobs config
+	config.Jobs.Workers = getIntOrZero(envMap["JOBS__WORKERS"])
+	config.Jobs.QueueSize = getIntOrZero(envMap["JOBS__QUEUE_SIZE"])
+	config.Jobs.TimeoutSec = getIntOrZero(envMap["JOBS__TIMEOUT_SEC"])
+
+	// Webhook config
+	config.Webhook.AllowPrivateURLs = getBoolOrFalse(envMap["WEBHOOK__ALLOW_PRIVATE_URLS"])
 
 	// Log config
 	config.Log.Level = envMap["LOG__LEVEL"]
@@ -160,6 +214,17 @@ func getBoolOrFalse(s string) bool {
 	return v
 }
 
+// getStringSlice splits a comma separated list, ignoring empty values
+func getStringSlice(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 // validate use default values if possible, otherwise log error
 func (c *Config) validate(logger zerolog.Logger, isDev bool) {
 	var foundErr bool
@@ -267,6 +332,11 @@ func (c *Config) validate(logger zerolog.Logger, isDev bool) {
 		logger.Warn().Msgf("JWT token timeout not set, using default timeout %d minutes", c.Auth.JWT.TokenTimeoutMin)
 	}
 
+	if c.Auth.RefreshTokenTimeoutDays <= 0 {
+		c.Auth.RefreshTokenTimeoutDays = 30
+		logger.Warn().Msgf("refresh token timeout not set, using default timeout %d days", c.Auth.RefreshTokenTimeoutDays)
+	}
+
 	// Media
 	if c.Media.MaxUploadSizeMB <= 0 {
 		c.Media.MaxUploadSizeMB = 100
@@ -296,6 +366,45 @@ func (c *Config) validate(logger zerolog.Logger, isDev bool) {
 		logger.Warn().Msgf("media max chunk size is greater than max direct upload size, using default size %dMB", c.Media.MaxChunkSizeMB)
 	}
 
+	if c.Media.DefaultQuotaMB < 0 {
+		c.Media.DefaultQuotaMB = 0
+		logger.Warn().Msg("media default quota is negative, storage is unlimited")
+	}
+
+	if c.Media.DefaultQuotaMB > math.MaxInt64/(1024*1024) {
+		c.Media.DefaultQuotaMB = 0
+		logger.Warn().Msg("media default quota is too large, storage is unlimited")
+	}
+
+	// Scanner
+	if c.Scanner.Clamd.Address == "" {
+		logger.Warn().Msg("no malware scanner set, uploaded files are not scanned")
+	} else if c.Scanner.Clamd.TimeoutSec <= 0 {
+		c.Scanner.Clamd.TimeoutSec = 120
+		logger.Warn().Msgf("clamd timeout not set, using default timeout %d seconds", c.Scanner.Clamd.TimeoutSec)
+	}
+
+	// Jobs
+	if c.Jobs.Workers <= 0 {
+		c.Jobs.Workers = 4
+		logger.Warn().Msgf("jobs workers not set, using default %d workers", c.Jobs.Workers)
+	}
+
+	if c.Jobs.QueueSize <= 0 {
+		c.Jobs.QueueSize = 1000
+		logger.Warn().Msgf("jobs queue size not set, using default size %d", c.Jobs.QueueSize)
+	}
+
+	if c.Jobs.TimeoutSec <= 0 {
+		c.Jobs.TimeoutSec = 300
+		logger.Warn().Msgf("jobs timeout not set, using default timeout %d seconds", c.Jobs.TimeoutSec)
+	}
+
+	// Webhook
+	if c.Webhook.AllowPrivateURLs {
+		logger.Warn().Msg("webhooks allowed to private addresses, the users can reach the internal services")
+	}
+
 	// Logging
 	if c.Log.Level == "" {
 		c.Log.Level = "info"
@@ -29,11 +29,23 @@ var (
 	ErrAuthInvalidToken       = PublicError{Code: "AUTH_INVALID_TOKEN"}
 	ErrAuthTokenExpired       = PublicError{Code: "AUTH_TOKEN_EXPIRED"}
 	ErrAuthWrongProvider      = PublicError{Code: "AUTH_WRONG_PROVIDER"}
+	ErrAuthSessionRevoked     = PublicError{Code: "AUTH_SESSION_REVOKED"}      // Signed out, the client must sign in again
+	ErrAuthRefreshTokenReused = PublicError{Code: "AUTH_REFRESH_TOKEN_REUSED"} // The session is revoked, the token may be stolen
 
 	// Sharing errors
 	ErrSharingExpired             = PublicError{Code: "SHARING_EXPIRED"}
 	ErrSharingMaxDownloadsReached = PublicError{Code: "SHARING_MAX_DOWNLOADS_REACHED"}
 	ErrSharingInvalidCredentials  = PublicError{Code: "SHARING_INVALID_CREDENTIALS"}
+
+	// Media errors
+	ErrMediaFileTypeNotAllowed  = PublicError{Code: "MEDIA_FILE_TYPE_NOT_ALLOWED"}
+	ErrMediaFileInfected        = PublicError{Code: "MEDIA_FILE_INFECTED"}
+	ErrMediaQuotaExceeded       = PublicError{Code: "MEDIA_QUOTA_EXCEEDED"}
+	ErrMediaUnsafeArchive       = PublicError{Code: "MEDIA_UNSAFE_ARCHIVE"}
+	ErrMediaChangeCursorExpired = PublicError{Code: "MEDIA_CHANGE_CURSOR_EXPIRED"} // The changes since the cursor were compacted, the client must resync
+
+	// Event errors
+	ErrEventExpired = PublicError{Code: "EVENT_EXPIRED"} // The events since the last event ID were purged, the client must reload
 )
 
 func (e PublicError) Error() string {
@@ -64,10 +76,18 @@ func (e PublicError) HTTPStatus() int {
 		return http.StatusConflict
 	case ErrCommonInvalidValue, ErrAuthWrongProvider:
 		return http.StatusBadRequest
-	case ErrAuthInvalidCredentials, ErrAuthInvalidToken, ErrAuthTokenExpired:
+	case ErrAuthInvalidCredentials, ErrAuthInvalidToken, ErrAuthTokenExpired, ErrAuthSessionRevoked, ErrAuthRefreshTokenReused:
 		return http.StatusUnauthorized
-	case ErrSharingExpired, ErrSharingMaxDownloadsReached, ErrSharingInvalidCredentials:
+	case ErrSharingExpired, ErrSharingMaxDownloadsReached, ErrSharingInvalidCredentials, ErrMediaFileInfected:
 		return http.StatusForbidden
+	case ErrMediaFileTypeNotAllowed:
+		return http.StatusUnsupportedMediaType
+	case ErrMediaQuotaExceeded:
+		return http.StatusInsufficientStorage
+	case ErrMediaUnsafeArchive:
+		return http.StatusUnprocessableEntity
+	case ErrMediaChangeCursorExpired, ErrEventExpired:
+		return http.StatusGone
 	default:
 		return http.StatusInternalServerError
 	}
@@ -13,8 +13,15 @@ type CtxKey string
 const (
 	CtxKeyLogger    CtxKey = "logger"
 	CtxKeyProfileID CtxKey = "profile_id"
+	CtxKeySessionID CtxKey = "session_id"
 )
 
 func GetProfileIDFromContext(ctx context.Context) string {
 	return ctx.Value(CtxKeyProfileID).(string)
 }
+
+// GetSessionIDFromContext returns the session of the access token, empty when signed in without one, e.g. with an app password.
+func GetSessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(CtxKeySessionID).(string)
+	return sessionID
+}
@@ -0,0 +1,93 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Event is a typed domain event, e.g. auth.SignedIn.
+// The events are JSON encoded in the outbox, so their fields must be exported.
+type Event interface {
+	// EventType is the name of the event, e.g. "media.file.uploaded".
+	// It must have a value receiver, it is called on the zero value by Subscribe.
+	EventType() string
+}
+
+type subscriber struct {
+	name   string
+	handle func(ctx context.Context, payload []byte) error
+}
+
+// Bus is an in-process publish-subscribe of the domain events.
+//
+// The events reach the bus through the outbox, after the transaction of their change commits,
+// and are delivered at least once: the subscribers must be idempotent.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: map[string][]subscriber{}}
+}
+
+// Subscribe registers the handler for the events of type T, T is the event struct, not a pointer to it.
+// The name identifies the subscriber in the errors and the logs, e.g. "audit".
+func Subscribe[T Event](b *Bus, name string, handler func(ctx context.Context, event T) error) {
+	var zero T
+	eventType := zero.EventType()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs[eventType] = append(b.subs[eventType], subscriber{
+		name: name,
+		handle: func(ctx context.Context, payload []byte) error {
+			var event T
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", eventType, err)
+			}
+			return handler(ctx, event)
+		},
+	})
+}
+
+// Encode returns the payload of the event, as published.
+func Encode(event Event) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", event.EventType(), err)
+	}
+	return payload, nil
+}
+
+// Publish delivers the event to all its subscribers, one after the other.
+// The events without subscribers are dropped.
+//
+// Returns the errors of the failed subscribers joined, the event is to be published again to all of them.
+func (b *Bus) Publish(ctx context.Context, eventType string, payload []byte) error {
+	b.mu.RLock()
+	subs := b.subs[eventType]
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if err := b.handle(ctx, sub, payload); err != nil {
+			errs = append(errs, fmt.Errorf("subscriber %s: %w", sub.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Bus) handle(ctx context.Context, sub subscriber, payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return sub.handle(ctx, payload)
+}
@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCreated struct {
+	ID   string
+	Name string
+}
+
+func (testCreated) EventType() string { return "test.created" }
+
+type testDeleted struct {
+	ID string
+}
+
+func (testDeleted) EventType() string { return "test.deleted" }
+
+func TestBus(t *testing.T) {
+	t.Parallel()
+	bus := NewBus()
+
+	var created []testCreated
+	Subscribe(bus, "first", func(ctx context.Context, e testCreated) error {
+		created = append(created, e)
+		return nil
+	})
+	Subscribe(bus, "failing", func(ctx context.Context, e testCreated) error {
+		return errors.New("boom")
+	})
+	Subscribe(bus, "panicking", func(ctx context.Context, e testCreated) error {
+		panic("boom")
+	})
+
+	var deleted int
+	Subscribe(bus, "other", func(ctx context.Context, e testDeleted) error {
+		deleted++
+		return nil
+	})
+
+	payload, err := Encode(testCreated{ID: "1", Name: "a"})
+	require.NoError(t, err)
+
+	err = bus.Publish(context.Background(), "test.created", payload)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "subscriber failing: boom")
+	assert.Contains(t, err.Error(), "subscriber panicking: panic: boom")
+	assert.Equal(t, []testCreated{{ID: "1", Name: "a"}}, created, "the others are delivered despite the failures")
+	assert.Zero(t, deleted)
+
+	err = bus.Publish(context.Background(), "test.unknown", []byte("{}"))
+	assert.NoError(t, err, "no subscribers")
+
+	err = bus.Publish(context.Background(), "test.deleted", []byte("not json"))
+	assert.ErrorContains(t, err, "subscriber other: failed to decode test.deleted")
+}
@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"skyvault/pkg/applog"
+	"skyvault/pkg/common"
+	"sync"
+	"time"
+)
+
+var (
+	ErrQueueFull   = errors.New("job queue is full")
+	ErrQueueClosed = errors.New("job queue is closed")
+)
+
+// Job is a unit of background work.
+// The context carries a logger, so applog.GetLoggerFromContext can be used inside the job.
+type Job func(ctx context.Context) error
+
+// Queue accepts jobs to be run in the background.
+type Queue interface {
+	// Enqueue never blocks. Callers should treat the job as best-effort
+	// and have a way to recover (e.g. a backfill) if it is rejected.
+	//
+	// Errors:
+	// - ErrQueueFull
+	// - ErrQueueClosed
+	Enqueue(name string, job Job) error
+}
+
+type Config struct {
+	Workers    int
+	QueueSize  int
+	JobTimeout time.Duration
+}
+
+type namedJob struct {
+	name string
+	job  Job
+}
+
+var _ Queue = (*WorkerPool)(nil)
+
+// WorkerPool is an in-process Queue that runs the jobs on a fixed number of goroutines.
+// On Stop, the jobs still waiting in the queue are run until the ctx of Stop is done,
+// then the running jobs are cancelled and the remaining ones are dropped.
+type WorkerPool struct {
+	cfg    Config
+	logger applog.Logger
+	jobs   chan namedJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewWorkerPool(cfg Config, logger applog.Logger) *WorkerPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WorkerPool{
+		cfg:    cfg,
+		logger: logger,
+		jobs:   make(chan namedJob, cfg.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start spawns the workers. It must be called once.
+func (p *WorkerPool) Start() {
+	for i := 0; i < p.cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.work(i)
+	}
+}
+
+func (p *WorkerPool) Enqueue(name string, job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case p.jobs <- namedJob{name: name, job: job}:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrQueueFull, name)
+	}
+}
+
+// Stop rejects the new jobs and waits for the queued and running jobs to finish
+// or the ctx to be done, whichever is first.
+func (p *WorkerPool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+func (p *WorkerPool) work(workerID int) {
+	defer p.wg.Done()
+
+	for j := range p.jobs {
+		if p.ctx.Err() != nil {
+			return
+		}
+
+		p.run(workerID, j)
+	}
+}
+
+func (p *WorkerPool) run(workerID int, j namedJob) {
+	logger := p.logger.With().Str("job", j.name).Int("worker_id", workerID).Logger()
+
+	ctx := context.WithValue(p.ctx, common.CtxKeyLogger, logger)
+	if p.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.JobTimeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().Any("panic", r).Msg("job panicked")
+		}
+	}()
+
+	start := time.Now()
+	if err := j.job(ctx); err != nil {
+		logger.Error().Err(err).Msg("job failed")
+		return
+	}
+
+	logger.Debug().Int64("duration_ms", time.Since(start).Milliseconds()).Msg("job done")
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"skyvault/pkg/applog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	t.Parallel()
+	pool := NewWorkerPool(Config{Workers: 2, QueueSize: 10}, applog.NewLogger(nil))
+	pool.Start()
+
+	var ran atomic.Int64
+	for i := 0; i < 5; i++ {
+		err := pool.Enqueue("test", func(ctx context.Context) error {
+			// The logger must be available inside the job
+			applog.GetLoggerFromContext(ctx).Debug().Msg("running")
+			ran.Add(1)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	err := pool.Enqueue("failing", func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+	require.NoError(t, err)
+
+	err = pool.Enqueue("panicking", func(ctx context.Context) error {
+		panic("boom")
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, pool.Stop(ctx))
+	require.Equal(t, int64(5), ran.Load(), "all queued jobs should run before stop returns")
+
+	err = pool.Enqueue("late", func(ctx context.Context) error { return nil })
+	require.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestWorkerPool_QueueFull(t *testing.T) {
+	t.Parallel()
+	// No workers are started, so nothing drains the queue.
+	pool := NewWorkerPool(Config{Workers: 1, QueueSize: 1}, applog.NewLogger(nil))
+
+	noop := func(ctx context.Context) error { return nil }
+	require.NoError(t, pool.Enqueue("first", noop))
+	require.ErrorIs(t, pool.Enqueue("second", noop), ErrQueueFull)
+}
+
+func TestWorkerPool_StopDeadline(t *testing.T) {
+	t.Parallel()
+	pool := NewWorkerPool(Config{Workers: 1, QueueSize: 10}, applog.NewLogger(nil))
+	pool.Start()
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	err := pool.Enqueue("blocking", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	<-started
+
+	var ran atomic.Int64
+	for i := 0; i < 3; i++ {
+		err := pool.Enqueue("pending", func(ctx context.Context) error {
+			ran.Add(1)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, pool.Stop(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the running job should be cancelled once the stop deadline is over")
+	}
+
+	pool.wg.Wait()
+	require.Equal(t, int64(0), ran.Load(), "the pending jobs should be dropped after the stop deadline")
+}
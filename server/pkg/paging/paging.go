@@ -3,6 +3,8 @@ package paging
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -11,24 +13,71 @@ const (
 	DefaultLimit     = 100
 	MaxLimit         = 1000
 	MaxCursorLen     = 200
+	MaxExactTotal    = 10000 // Larger sets get an estimated total, counting them would be slow
 	DirectionForward = "forward"
 	SortAsc          = "asc"
 	SortByID         = "id"
 	SortByName       = "name"
 	SortByUpdated    = "updated"
+	SortBySize       = "size"
+	SortByCreated    = "created"
+	SortByType       = "type"     // File extension
+	SortByCategory   = "category" // File category
 )
 
 var (
 	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidFilter = errors.New("invalid filter")
 )
 
+var sortByValues = []string{SortByID, SortByName, SortByUpdated, SortBySize, SortByCreated, SortByType, SortByCategory}
+
 type Options struct {
 	PrevCursor string
 	NextCursor string
 	Direction  string // "forward", "backward"
 	Limit      int
 	Sort       string // "asc" or "desc"
-	SortBy     string // "id", "name", "updated"
+	SortBy     string // "id", "name", "updated", "size", "created", "type", "category"
+	Filter     Filter
+
+	// IncludeTotal counts the items matching the filters, see Page.Total
+	IncludeTotal bool
+}
+
+// Filter narrows down the listing, the zero value matches everything.
+// The same filter must be sent with the cursors of the listing.
+type Filter struct {
+	Category    string
+	Extension   string // e.g. ".pdf", case insensitive
+	MinSize     *int64
+	MaxSize     *int64
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// Validate normalizes the filter, it returns ErrInvalidFilter for inconsistent values.
+func (f *Filter) Validate() error {
+	f.Category = strings.ToLower(strings.TrimSpace(f.Category))
+
+	f.Extension = strings.ToLower(strings.TrimSpace(f.Extension))
+	if f.Extension != "" && !strings.HasPrefix(f.Extension, ".") {
+		f.Extension = "." + f.Extension
+	}
+
+	if (f.MinSize != nil && *f.MinSize < 0) || (f.MaxSize != nil && *f.MaxSize < 0) {
+		return fmt.Errorf("%w: negative size", ErrInvalidFilter)
+	}
+
+	if f.MinSize != nil && f.MaxSize != nil && *f.MinSize > *f.MaxSize {
+		return fmt.Errorf("%w: min size is greater than max size", ErrInvalidFilter)
+	}
+
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return fmt.Errorf("%w: created from is after created to", ErrInvalidFilter)
+	}
+
+	return nil
 }
 
 func (o *Options) Validate() {
@@ -46,16 +95,20 @@ func (o *Options) Validate() {
 		o.Sort = "desc"
 	}
 
-	if o.SortBy != SortByID && o.SortBy != SortByName && o.SortBy != SortByUpdated {
+	if !slices.Contains(sortByValues, o.SortBy) {
 		o.SortBy = SortByName
 	}
 }
 
 // Cursor holds the column values that make up the cursor.
 type Cursor struct {
-	ID      string
-	Name    string
-	Updated time.Time
+	ID       string
+	Name     string
+	Updated  time.Time
+	Size     int64
+	Created  time.Time
+	Type     string
+	Category string
 }
 
 func (o *Options) CreateCursor(cursor *Cursor) string {
@@ -63,15 +116,23 @@ func (o *Options) CreateCursor(cursor *Cursor) string {
 		return ""
 	}
 
-	if o.SortBy == SortByID {
-		return fmt.Sprintf("%s", cursor.ID)
-	}
-
-	if o.SortBy == SortByName {
+	switch o.SortBy {
+	case SortByID:
+		return cursor.ID
+	case SortByName:
 		return fmt.Sprintf("%s,%s", cursor.ID, cursor.Name)
+	case SortBySize:
+		return fmt.Sprintf("%s,%d", cursor.ID, cursor.Size)
+	case SortByCreated:
+		return fmt.Sprintf("%s,%s", cursor.ID, cursor.Created.Format(time.RFC3339Nano))
+	case SortByType:
+		return fmt.Sprintf("%s,%s", cursor.ID, cursor.Type)
+	case SortByCategory:
+		return fmt.Sprintf("%s,%s", cursor.ID, cursor.Category)
 	}
 
-	return fmt.Sprintf("%s,%s", cursor.ID, cursor.Updated.Format(time.RFC3339))
+	// Sub-second precision, so that the items updated within the same second are not skipped
+	return fmt.Sprintf("%s,%s", cursor.ID, cursor.Updated.Format(time.RFC3339Nano))
 }
 
 func (o *Options) GetCursor() (*Cursor, error) {
@@ -88,7 +149,8 @@ func (o *Options) GetCursor() (*Cursor, error) {
 		return nil, fmt.Errorf("%w: cursor too long: %d: %s", ErrInvalidCursor, len(cursorStr), cursorStr[:MaxCursorLen/2]+"...")
 	}
 
-	vals := strings.Split(cursorStr, ",")
+	// The ID never has a comma, the value may have some, e.g. in names
+	vals := strings.SplitN(cursorStr, ",", 2)
 
 	id := vals[0]
 
@@ -106,15 +168,23 @@ func (o *Options) GetCursor() (*Cursor, error) {
 		return nil, fmt.Errorf("%w: non-id", ErrInvalidCursor)
 	}
 
-	if o.SortBy == SortByName {
+	var err error
+	switch o.SortBy {
+	case SortByName:
 		cursor.Name = vals[1]
-	} else if o.SortBy == SortByUpdated {
-		updated, err := time.Parse(time.RFC3339, vals[1])
-		if err != nil {
-			return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
-		}
-
-		cursor.Updated = updated
+	case SortByUpdated:
+		cursor.Updated, err = time.Parse(time.RFC3339Nano, vals[1])
+	case SortBySize:
+		cursor.Size, err = strconv.ParseInt(vals[1], 10, 64)
+	case SortByCreated:
+		cursor.Created, err = time.Parse(time.RFC3339Nano, vals[1])
+	case SortByType:
+		cursor.Type = vals[1]
+	case SortByCategory:
+		cursor.Category = vals[1]
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
 	}
 
 	return cursor, nil
@@ -125,4 +195,9 @@ type Page[TItem any] struct {
 	PrevCursor string  `json:"prevCursor"` // Cursor to the first item in the list
 	NextCursor string  `json:"nextCursor"` // Cursor to the last item in the list
 	HasMore    bool    `json:"hasMore"`
+
+	// Total is the number of items of all the pages, set only with Options.IncludeTotal.
+	// Above MaxExactTotal it is an estimate and TotalEstimated is true.
+	Total          *int64 `json:"total,omitempty"`
+	TotalEstimated bool   `json:"totalEstimated,omitempty"`
 }
@@ -0,0 +1,151 @@
+package paging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+	cursor := &Cursor{
+		ID:       "0195f0a2-6b8c-7d3e-9f1a-2b3c4d5e6f70",
+		Name:     "report, final.pdf",
+		Updated:  time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC),
+		Size:     4096,
+		Created:  time.Date(2024, 4, 1, 8, 30, 0, 987654000, time.UTC),
+		Type:     ".pdf",
+		Category: "document",
+	}
+
+	tests := []struct {
+		sortBy string
+		want   *Cursor
+	}{
+		{sortBy: SortByID, want: &Cursor{ID: cursor.ID}},
+		{sortBy: SortByName, want: &Cursor{ID: cursor.ID, Name: cursor.Name}},
+		{sortBy: SortByUpdated, want: &Cursor{ID: cursor.ID, Updated: cursor.Updated}},
+		{sortBy: SortBySize, want: &Cursor{ID: cursor.ID, Size: cursor.Size}},
+		{sortBy: SortByCreated, want: &Cursor{ID: cursor.ID, Created: cursor.Created}},
+		{sortBy: SortByType, want: &Cursor{ID: cursor.ID, Type: cursor.Type}},
+		{sortBy: SortByCategory, want: &Cursor{ID: cursor.ID, Category: cursor.Category}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			t.Parallel()
+			opt := &Options{SortBy: tt.sortBy, Direction: DirectionForward}
+			opt.NextCursor = opt.CreateCursor(cursor)
+
+			got, err := opt.GetCursor()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetCursorInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		sortBy string
+		cursor string
+	}{
+		{name: "id with value", sortBy: SortByID, cursor: "id,name"},
+		{name: "name without value", sortBy: SortByName, cursor: "id"},
+		{name: "size not a number", sortBy: SortBySize, cursor: "id,big"},
+		{name: "created not a time", sortBy: SortByCreated, cursor: "id,yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opt := &Options{SortBy: tt.sortBy, Direction: DirectionForward, NextCursor: tt.cursor}
+
+			_, err := opt.GetCursor()
+			require.True(t, errors.Is(err, ErrInvalidCursor), "got %v", err)
+		})
+	}
+}
+
+func TestOptionsValidateSortBy(t *testing.T) {
+	t.Parallel()
+	opt := &Options{SortBy: SortByCategory}
+	opt.Validate()
+	require.Equal(t, SortByCategory, opt.SortBy)
+
+	opt = &Options{SortBy: "owner"}
+	opt.Validate()
+	require.Equal(t, SortByName, opt.SortBy)
+}
+
+func TestFilterValidate(t *testing.T) {
+	t.Parallel()
+	size := func(v int64) *int64 { return &v }
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		want    Filter
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			filter: Filter{},
+			want:   Filter{},
+		},
+		{
+			name:   "normalizes category and extension",
+			filter: Filter{Category: " Image ", Extension: "JPG"},
+			want:   Filter{Category: "image", Extension: ".jpg"},
+		},
+		{
+			name:   "keeps the extension dot",
+			filter: Filter{Extension: ".Pdf"},
+			want:   Filter{Extension: ".pdf"},
+		},
+		{
+			name:   "size range",
+			filter: Filter{MinSize: size(10), MaxSize: size(10)},
+			want:   Filter{MinSize: size(10), MaxSize: size(10)},
+		},
+		{
+			name:    "negative size",
+			filter:  Filter{MinSize: size(-1)},
+			wantErr: true,
+		},
+		{
+			name:    "min size greater than max size",
+			filter:  Filter{MinSize: size(20), MaxSize: size(10)},
+			wantErr: true,
+		},
+		{
+			name:   "created range",
+			filter: Filter{CreatedFrom: &from, CreatedTo: &to},
+			want:   Filter{CreatedFrom: &from, CreatedTo: &to},
+		},
+		{
+			name:    "created from after created to",
+			filter:  Filter{CreatedFrom: &to, CreatedTo: &from},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				require.True(t, errors.Is(err, ErrInvalidFilter), "got %v", err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tt.filter)
+		})
+	}
+}
@@ -0,0 +1,712 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// Max. size of the tags read, larger ones (e.g. with embedded cover art) are truncated
+	maxAudioTagSize = 1 << 20
+	// Max. number of bytes scanned for the first mp3 frame after the tags
+	maxMP3SyncSearch = 64 * 1024
+	// The last ogg page is searched in this many bytes at the end of the file
+	maxOggLastPageSearch = 64 * 1024
+	// Size of the fields read from the flac STREAMINFO block and the wav fmt chunk, the rest is skipped
+	flacStreamInfoSize = 18
+	wavFmtSize         = 16
+)
+
+// AudioMetadata holds the tags and properties of an audio file. Missing fields are left empty.
+type AudioMetadata struct {
+	Title    string
+	Artist   string
+	Album    string
+	Genre    string
+	Year     int
+	Track    int
+	Duration time.Duration
+}
+
+// ReadAudioMetadata returns the metadata of a mp3 (ID3v1/ID3v2), flac, ogg (vorbis/opus) or wav file.
+// The format is the subtype of the mime type, e.g. "mpeg" for "audio/mpeg".
+//
+// Errors:
+// - ErrUnsupportedFormat, if the format is not supported or the file is malformed
+func ReadAudioMetadata(format string, reader io.ReadSeeker) (*AudioMetadata, error) {
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	meta := &AudioMetadata{}
+	switch strings.ToLower(format) {
+	case "mpeg", "mp3", "mpeg3", "x-mpeg", "x-mp3", "x-mpeg-3":
+		err = readMP3(reader, size, meta)
+	case "flac", "x-flac":
+		err = readFLAC(reader, meta)
+	case "ogg", "x-ogg", "vorbis", "opus":
+		err = readOgg(reader, size, meta)
+	case "wav", "x-wav", "wave", "vnd.wave":
+		err = readWAV(reader, meta)
+	default:
+		return nil, fmt.Errorf("%w: audio: %s", ErrUnsupportedFormat, format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: audio: %w", ErrUnsupportedFormat, err)
+	}
+	return meta, nil
+}
+
+// setTag sets the field of the common tag key (ID3 frame id or vorbis comment name), if it's still empty.
+func (m *AudioMetadata) setTag(key, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	switch key {
+	case "TIT2", "TT2", "TITLE", "INAM":
+		if m.Title == "" {
+			m.Title = value
+		}
+	case "TPE1", "TP1", "ARTIST", "IART":
+		if m.Artist == "" {
+			m.Artist = value
+		}
+	case "TALB", "TAL", "ALBUM", "IPRD":
+		if m.Album == "" {
+			m.Album = value
+		}
+	case "TCON", "TCO", "GENRE", "IGNR":
+		if m.Genre == "" {
+			m.Genre = id3Genre(value)
+		}
+	case "TYER", "TYE", "TDRC", "DATE", "YEAR", "ICRD":
+		if m.Year == 0 {
+			m.Year = leadingInt(value, 4)
+		}
+	case "TRCK", "TRK", "TRACKNUMBER", "ITRK":
+		if m.Track == 0 {
+			m.Track = leadingInt(value, 0)
+		}
+	}
+}
+
+//--------------------------------
+// MP3
+//--------------------------------
+
+func readMP3(r io.ReadSeeker, size int64, meta *AudioMetadata) error {
+	audioStart, err := readID3v2(r, meta)
+	if err != nil {
+		return err
+	}
+
+	audioEnd := size
+	if size-audioStart >= 128 {
+		if _, err := r.Seek(size-128, io.SeekStart); err != nil {
+			return err
+		}
+		var tag [128]byte
+		if _, err := io.ReadFull(r, tag[:]); err != nil {
+			return err
+		}
+		if readID3v1(tag[:], meta) {
+			audioEnd -= 128
+		}
+	}
+
+	if _, err := r.Seek(audioStart, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxMP3SyncSearch))
+	if err != nil {
+		return err
+	}
+
+	// The duration is best effort, tags alone are still useful
+	meta.Duration = mp3Duration(data, audioEnd-audioStart)
+	return nil
+}
+
+// readID3v2 reads the ID3v2 tag at the current position, if any, and returns the offset right after it.
+func readID3v2(r io.ReadSeeker, meta *AudioMetadata) (int64, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	var header [10]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil || string(header[:3]) != "ID3" {
+		_, err := r.Seek(start, io.SeekStart)
+		return start, err
+	}
+
+	version := header[3]
+	flags := header[5]
+	tagSize := int64(syncsafe(header[6:10]))
+	end := start + 10 + tagSize
+	if flags&0x10 != 0 {
+		end += 10 // Footer
+	}
+
+	if version < 2 || version > 4 {
+		return end, nil
+	}
+
+	data := make([]byte, min(tagSize, maxAudioTagSize))
+	n, err := io.ReadFull(r, data)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+	data = data[:n]
+
+	if flags&0x80 != 0 {
+		data = bytes.ReplaceAll(data, []byte{0xFF, 0x00}, []byte{0xFF})
+	}
+
+	if flags&0x40 != 0 && version >= 3 && len(data) >= 4 {
+		extSize := int(binary.BigEndian.Uint32(data))
+		if version == 3 {
+			extSize += 4
+		} else {
+			extSize = int(syncsafe(data[:4]))
+		}
+		if extSize > len(data) {
+			return end, nil
+		}
+		data = data[extSize:]
+	}
+
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	for len(data) >= headerLen && data[0] != 0 {
+		id := string(data[:idLen])
+
+		var frameSize int
+		switch version {
+		case 2:
+			frameSize = int(data[3])<<16 | int(data[4])<<8 | int(data[5])
+		case 3:
+			frameSize = int(binary.BigEndian.Uint32(data[4:8]))
+		default:
+			frameSize = int(syncsafe(data[4:8]))
+		}
+
+		if frameSize <= 0 || frameSize > len(data)-headerLen {
+			break
+		}
+
+		frame := data[headerLen : headerLen+frameSize]
+		data = data[headerLen+frameSize:]
+
+		switch id {
+		case "TLEN", "TLE":
+			if ms := leadingInt(decodeID3Text(frame), 0); ms > 0 && meta.Duration == 0 {
+				meta.Duration = time.Duration(ms) * time.Millisecond
+			}
+		default:
+			if id[0] == 'T' {
+				meta.setTag(id, decodeID3Text(frame))
+			}
+		}
+	}
+
+	return end, nil
+}
+
+// readID3v1 reads the fixed size tag at the end of the file and tells if there was one.
+func readID3v1(tag []byte, meta *AudioMetadata) bool {
+	if string(tag[:3]) != "TAG" {
+		return false
+	}
+
+	meta.setTag("TITLE", decodeLatin1(tag[3:33]))
+	meta.setTag("ARTIST", decodeLatin1(tag[33:63]))
+	meta.setTag("ALBUM", decodeLatin1(tag[63:93]))
+	meta.setTag("YEAR", decodeLatin1(tag[93:97]))
+
+	// ID3v1.1 stores the track in the last byte of the comment
+	if tag[125] == 0 && tag[126] != 0 && meta.Track == 0 {
+		meta.Track = int(tag[126])
+	}
+
+	if int(tag[127]) < len(id3Genres) && meta.Genre == "" {
+		meta.Genre = id3Genres[tag[127]]
+	}
+
+	return true
+}
+
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3SampleRate = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG 1
+		2: {22050, 24000, 16000}, // MPEG 2
+		0: {11025, 12000, 8000},  // MPEG 2.5
+	}
+)
+
+// audioDuration returns the duration of samples at sampleRate.
+// It is computed in float to not overflow, the durations out of the range of time.Duration are ignored.
+func audioDuration(samples, sampleRate int64) time.Duration {
+	if samples <= 0 || sampleRate <= 0 {
+		return 0
+	}
+
+	d := float64(samples) / float64(sampleRate) * float64(time.Second)
+	if d >= math.MaxInt64 {
+		return 0
+	}
+	return time.Duration(d)
+}
+
+// mp3Duration returns the duration from the Xing/Info or VBRI header of the first frame,
+// assuming a constant bitrate otherwise. Only layer III is supported.
+func mp3Duration(data []byte, audioSize int64) time.Duration {
+	for i := 0; i+4 <= len(data); i++ {
+		if data[i] != 0xFF || data[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+
+		version := (data[i+1] >> 3) & 0x03
+		layer := (data[i+1] >> 1) & 0x03
+		bitrateIdx := data[i+2] >> 4
+		sampleRateIdx := (data[i+2] >> 2) & 0x03
+		mono := data[i+3]>>6 == 3
+
+		rates, ok := mp3SampleRate[version]
+		if !ok || layer != 1 || bitrateIdx == 0 || bitrateIdx == 15 || sampleRateIdx == 3 {
+			continue
+		}
+
+		sampleRate := rates[sampleRateIdx]
+		bitrate := mp3BitratesV1[bitrateIdx]
+		samplesPerFrame, sideInfo := 1152, 32
+		if mono {
+			sideInfo = 17
+		}
+		if version != 3 {
+			bitrate = mp3BitratesV2[bitrateIdx]
+			samplesPerFrame, sideInfo = 576, 17
+			if mono {
+				sideInfo = 9
+			}
+		}
+
+		frame := data[i:]
+		frames := uint32(0)
+		if xing := 4 + sideInfo; len(frame) >= xing+12 {
+			tag := string(frame[xing : xing+4])
+			if (tag == "Xing" || tag == "Info") && binary.BigEndian.Uint32(frame[xing+4:])&0x01 != 0 {
+				frames = binary.BigEndian.Uint32(frame[xing+8:])
+			}
+		}
+		if vbri := 4 + 32; frames == 0 && len(frame) >= vbri+18 && string(frame[vbri:vbri+4]) == "VBRI" {
+			frames = binary.BigEndian.Uint32(frame[vbri+14:])
+		}
+
+		if frames > 0 {
+			return time.Duration(float64(frames) * float64(samplesPerFrame) / float64(sampleRate) * float64(time.Second))
+		}
+
+		audioSize -= int64(i)
+		if audioSize <= 0 {
+			return 0
+		}
+		return time.Duration(float64(audioSize) * 8 / float64(bitrate*1000) * float64(time.Second))
+	}
+
+	return 0
+}
+
+//--------------------------------
+// FLAC
+//--------------------------------
+
+func readFLAC(r io.ReadSeeker, meta *AudioMetadata) error {
+	// Some taggers prepend an ID3v2 tag, which is not part of the format
+	end, err := readID3v2(r, meta)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Seek(end, io.SeekStart); err != nil {
+		return err
+	}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil || string(magic[:]) != "fLaC" {
+		return fmt.Errorf("flac: invalid signature")
+	}
+
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return err
+		}
+
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		switch blockType {
+		case 0: // STREAMINFO
+			if length < flacStreamInfoSize {
+				return fmt.Errorf("flac: invalid streaminfo")
+			}
+			var block [flacStreamInfoSize]byte
+			if _, err := io.ReadFull(r, block[:]); err != nil {
+				return err
+			}
+			if _, err := r.Seek(length-flacStreamInfoSize, io.SeekCurrent); err != nil {
+				return err
+			}
+			sampleRate := int64(block[10])<<12 | int64(block[11])<<4 | int64(block[12])>>4
+			totalSamples := int64(block[13]&0x0F)<<32 | int64(binary.BigEndian.Uint32(block[14:18]))
+			meta.Duration = audioDuration(totalSamples, sampleRate)
+		case 4: // VORBIS_COMMENT
+			block := make([]byte, min(length, maxAudioTagSize))
+			if _, err := io.ReadFull(r, block); err != nil {
+				return err
+			}
+			if _, err := r.Seek(length-int64(len(block)), io.SeekCurrent); err != nil {
+				return err
+			}
+			readVorbisComment(block, meta)
+		default:
+			if _, err := r.Seek(length, io.SeekCurrent); err != nil {
+				return err
+			}
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+// readVorbisComment reads the comment block shared by flac, ogg vorbis and opus. Truncated blocks are read partially.
+func readVorbisComment(data []byte, meta *AudioMetadata) {
+	next := func() ([]byte, bool) {
+		if len(data) < 4 {
+			return nil, false
+		}
+		n := int(binary.LittleEndian.Uint32(data))
+		if n < 0 || n > len(data)-4 {
+			return nil, false
+		}
+		field := data[4 : 4+n]
+		data = data[4+n:]
+		return field, true
+	}
+
+	// Vendor string
+	if _, ok := next(); !ok || len(data) < 4 {
+		return
+	}
+	count := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+
+	for i := uint32(0); i < count; i++ {
+		field, ok := next()
+		if !ok {
+			return
+		}
+		key, value, ok := strings.Cut(string(field), "=")
+		if ok && utf8.ValidString(value) {
+			meta.setTag(strings.ToUpper(key), value)
+		}
+	}
+}
+
+//--------------------------------
+// OGG
+//--------------------------------
+
+func readOgg(r io.ReadSeeker, size int64, meta *AudioMetadata) error {
+	packets := &oggPacketReader{r: r}
+
+	ident, err := packets.next()
+	if err != nil {
+		return err
+	}
+
+	var sampleRate, preSkip int64
+	var comment []byte
+	switch {
+	case len(ident) >= 16 && bytes.HasPrefix(ident, []byte("\x01vorbis")):
+		sampleRate = int64(binary.LittleEndian.Uint32(ident[12:16]))
+		if comment, err = packets.next(); err != nil {
+			return err
+		}
+		comment = bytes.TrimPrefix(comment, []byte("\x03vorbis"))
+	case len(ident) >= 12 && bytes.HasPrefix(ident, []byte("OpusHead")):
+		// Opus granule positions are always in 48kHz samples
+		sampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(ident[10:12]))
+		if comment, err = packets.next(); err != nil {
+			return err
+		}
+		comment = bytes.TrimPrefix(comment, []byte("OpusTags"))
+	default:
+		return fmt.Errorf("ogg: unsupported codec")
+	}
+
+	readVorbisComment(comment, meta)
+
+	if granule := oggLastGranule(r, size); granule > preSkip {
+		meta.Duration = audioDuration(granule-preSkip, sampleRate)
+	}
+	return nil
+}
+
+// oggPacketReader reassembles the packets of the first logical stream, from the start of the file.
+type oggPacketReader struct {
+	r        io.Reader
+	segments []byte // Lacing values of the current page not read yet
+}
+
+func (o *oggPacketReader) next() ([]byte, error) {
+	var packet []byte
+	for {
+		if len(o.segments) == 0 {
+			var header [27]byte
+			if _, err := io.ReadFull(o.r, header[:]); err != nil {
+				return nil, err
+			}
+			if string(header[:4]) != "OggS" {
+				return nil, fmt.Errorf("ogg: invalid page")
+			}
+			o.segments = make([]byte, header[26])
+			if _, err := io.ReadFull(o.r, o.segments); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		n := int(o.segments[0])
+		o.segments = o.segments[1:]
+
+		segment := make([]byte, n)
+		if _, err := io.ReadFull(o.r, segment); err != nil {
+			return nil, err
+		}
+		if len(packet) < maxAudioTagSize {
+			packet = append(packet, segment...)
+		}
+
+		// A lacing value lower than 255 ends the packet
+		if n < 255 {
+			return packet, nil
+		}
+	}
+}
+
+// oggLastGranule returns the granule position of the last page, which is the number of samples of the stream.
+func oggLastGranule(r io.ReadSeeker, size int64) int64 {
+	start := max(0, size-maxOggLastPageSearch)
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxOggLastPageSearch))
+	if err != nil {
+		return 0
+	}
+
+	i := bytes.LastIndex(data, []byte("OggS"))
+	if i < 0 || len(data)-i < 14 {
+		return 0
+	}
+	return int64(binary.LittleEndian.Uint64(data[i+6 : i+14]))
+}
+
+//--------------------------------
+// WAV
+//--------------------------------
+
+func readWAV(r io.ReadSeeker, meta *AudioMetadata) error {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return fmt.Errorf("wav: invalid signature")
+	}
+
+	var byteRate int64
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			// The data chunk is usually last, tags may come after it
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		id := string(chunkHeader[:4])
+		size := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+		padded := size + size%2
+
+		switch id {
+		case "fmt ":
+			if size < wavFmtSize {
+				return fmt.Errorf("wav: invalid fmt chunk")
+			}
+			var chunk [wavFmtSize]byte
+			if _, err := io.ReadFull(r, chunk[:]); err != nil {
+				return err
+			}
+			byteRate = int64(binary.LittleEndian.Uint32(chunk[8:12]))
+			padded -= wavFmtSize
+		case "data":
+			// The duration in bytes at the byte rate, same as samples at the sample rate
+			meta.Duration = audioDuration(size, byteRate)
+		case "LIST":
+			if size >= 4 && size <= maxAudioTagSize {
+				chunk := make([]byte, padded)
+				if _, err := io.ReadFull(r, chunk); err != nil {
+					return err
+				}
+				if string(chunk[:4]) == "INFO" {
+					readRIFFInfo(chunk[4:], meta)
+				}
+				continue
+			}
+		}
+
+		if _, err := r.Seek(padded, io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+}
+
+// readRIFFInfo reads the sub-chunks of a LIST INFO chunk, e.g. INAM for the title.
+func readRIFFInfo(data []byte, meta *AudioMetadata) {
+	for len(data) >= 8 {
+		id := string(data[:4])
+		size := int(binary.LittleEndian.Uint32(data[4:8]))
+		if size < 0 || size > len(data)-8 {
+			return
+		}
+
+		meta.setTag(id, decodeLatin1(data[8:8+size]))
+
+		size += size % 2
+		if size > len(data)-8 {
+			return
+		}
+		data = data[8+size:]
+	}
+}
+
+//--------------------------------
+// Helpers
+//--------------------------------
+
+// syncsafe decodes the 28 bits integers of ID3v2, where the msb of each byte is unused.
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+// decodeID3Text decodes the first value of a text frame, according to its encoding byte.
+func decodeID3Text(frame []byte) string {
+	if len(frame) < 2 {
+		return ""
+	}
+
+	data := frame[1:]
+	switch frame[0] {
+	case 0: // ISO-8859-1
+		return decodeLatin1(data)
+	case 1, 2: // UTF-16 with BOM, UTF-16BE
+		var order binary.ByteOrder = binary.BigEndian
+		if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+			order = binary.LittleEndian
+			data = data[2:]
+		} else if len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF {
+			data = data[2:]
+		}
+
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i+1 < len(data); i += 2 {
+			u := order.Uint16(data[i:])
+			if u == 0 {
+				break
+			}
+			units = append(units, u)
+		}
+		return string(utf16.Decode(units))
+	default: // UTF-8
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			data = data[:i]
+		}
+		if !utf8.Valid(data) {
+			return ""
+		}
+		return string(data)
+	}
+}
+
+func decodeLatin1(data []byte) string {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+// leadingInt parses the digits at the beginning of s, e.g. 3 for "3/12". At most maxDigits are read if not 0.
+func leadingInt(s string, maxDigits int) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' && (maxDigits == 0 || end < maxDigits) {
+		end++
+	}
+
+	n, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// id3Genre resolves the numeric references to the ID3v1 genres, e.g. "(17)" or "17" for "Rock".
+func id3Genre(value string) string {
+	ref := strings.TrimSuffix(strings.TrimPrefix(value, "("), ")")
+	if n, err := strconv.Atoi(ref); err == nil && n >= 0 && n < len(id3Genres) {
+		return id3Genres[n]
+	}
+	return value
+}
+
+var id3Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge", "Hip-Hop", "Jazz", "Metal",
+	"New Age", "Oldies", "Other", "Pop", "R&B", "Rap", "Reggae", "Rock", "Techno", "Industrial",
+	"Alternative", "Ska", "Death Metal", "Pranks", "Soundtrack", "Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk",
+	"Fusion", "Trance", "Classical", "Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative", "Instrumental Pop", "Instrumental Rock", "Ethnic", "Gothic",
+	"Darkwave", "Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream", "Southern Rock", "Comedy", "Cult", "Gangsta",
+	"Top 40", "Christian Rap", "Pop/Funk", "Jungle", "Native American", "Cabaret", "New Wave", "Psychadelic", "Rave", "Showtunes",
+	"Trailer", "Lo-Fi", "Tribal", "Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+}
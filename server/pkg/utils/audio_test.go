@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func id3v23Frame(id, text string) []byte {
+	frame := []byte(id)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(text)+1))
+	frame = append(frame, 0, 0) // Flags
+	frame = append(frame, 3)    // UTF-8
+	return append(frame, text...)
+}
+
+func id3v2Tag(frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	size := len(body)
+	tag := []byte{'I', 'D', '3', 3, 0, 0}
+	tag = append(tag, byte(size>>21&0x7F), byte(size>>14&0x7F), byte(size>>7&0x7F), byte(size&0x7F))
+	return append(tag, body...)
+}
+
+// mp3Frame returns a MPEG 1 layer III frame at 128kbps and 44.1kHz, with a Xing header if frames > 0.
+func mp3Frame(frames uint32) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+	if frames > 0 {
+		copy(frame[36:], "Xing")
+		binary.BigEndian.PutUint32(frame[40:], 1)
+		binary.BigEndian.PutUint32(frame[44:], frames)
+	}
+	return frame
+}
+
+func vorbisComment(comments ...string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, 4)
+	data = append(data, "test"...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(comments)))
+	for _, c := range comments {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(c)))
+		data = append(data, c...)
+	}
+	return data
+}
+
+func oggPage(granule uint64, packet []byte) []byte {
+	page := []byte("OggS\x00\x00")
+	page = binary.LittleEndian.AppendUint64(page, granule)
+	page = append(page, make([]byte, 12)...) // Serial, sequence, checksum
+
+	var lacing []byte
+	n := len(packet)
+	for ; n >= 255; n -= 255 {
+		lacing = append(lacing, 255)
+	}
+	lacing = append(lacing, byte(n))
+
+	page = append(page, byte(len(lacing)))
+	page = append(page, lacing...)
+	return append(page, packet...)
+}
+
+func TestReadAudioMetadata(t *testing.T) {
+	t.Parallel()
+
+	id3v1 := make([]byte, 128)
+	copy(id3v1, "TAG")
+	copy(id3v1[3:], "Old Title")
+	copy(id3v1[33:], "Old Artist")
+	copy(id3v1[93:], "1999")
+	id3v1[126] = 7  // Track
+	id3v1[127] = 17 // Rock
+
+	vorbisIdent := append([]byte("\x01vorbis"), make([]byte, 23)...)
+	binary.LittleEndian.PutUint32(vorbisIdent[12:], 44100)
+
+	opusHead := append([]byte("OpusHead\x01\x02"), make([]byte, 9)...)
+	binary.LittleEndian.PutUint16(opusHead[10:], 312)
+
+	flacInfo := make([]byte, 34)
+	flacInfo[10], flacInfo[11], flacInfo[12] = 0x0A, 0xC4, 0x40 // 44100Hz
+	binary.BigEndian.PutUint32(flacInfo[14:], 441000)           // 10s
+	comment := vorbisComment("TITLE=Flac Song", "artist=Someone", "DATE=2021-05-01", "TRACKNUMBER=2/10")
+	flac := []byte("fLaC\x00\x00\x00\x22")
+	flac = append(flac, flacInfo...)
+	flac = append(flac, 0x84, 0, byte(len(comment)>>8), byte(len(comment)))
+	flac = append(flac, comment...)
+
+	wavInfo := []byte("INFOINAM\x06\x00\x00\x00Wave!\x00IART\x03\x00\x00\x00Bob\x00")
+	wav := []byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00")
+	wavFmt := make([]byte, 16)
+	binary.LittleEndian.PutUint32(wavFmt[8:], 176400) // 44.1kHz, 16 bits, stereo
+	wav = append(wav, wavFmt...)
+	wav = append(wav, "LIST"...)
+	wav = binary.LittleEndian.AppendUint32(wav, uint32(len(wavInfo)))
+	wav = append(wav, wavInfo...)
+	wav = append(wav, "data"...)
+	wav = binary.LittleEndian.AppendUint32(wav, 352800)
+	wav = append(wav, make([]byte, 352800)...)
+
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+		want   *AudioMetadata
+	}{
+		{
+			name:   "mp3 with ID3v2 and xing header",
+			format: "mpeg",
+			data: append(id3v2Tag(
+				id3v23Frame("TIT2", "Song"),
+				id3v23Frame("TPE1", "Artist"),
+				id3v23Frame("TALB", "Album"),
+				id3v23Frame("TCON", "(13)"),
+				id3v23Frame("TYER", "2019"),
+				id3v23Frame("TRCK", "3/12"),
+			), mp3Frame(100)...),
+			want: &AudioMetadata{
+				Title: "Song", Artist: "Artist", Album: "Album", Genre: "Pop", Year: 2019, Track: 3,
+				Duration: time.Duration(100 * 1152 * int64(time.Second) / 44100),
+			},
+		},
+		{
+			name:   "mp3 with ID3v1 and constant bitrate",
+			format: "mp3",
+			data:   append(append(mp3Frame(0), make([]byte, 16000-417)...), id3v1...),
+			want: &AudioMetadata{
+				Title: "Old Title", Artist: "Old Artist", Genre: "Rock", Year: 1999, Track: 7,
+				Duration: time.Second,
+			},
+		},
+		{
+			name:   "flac",
+			format: "flac",
+			data:   flac,
+			want: &AudioMetadata{
+				Title: "Flac Song", Artist: "Someone", Year: 2021, Track: 2, Duration: 10 * time.Second,
+			},
+		},
+		{
+			name:   "ogg vorbis",
+			format: "ogg",
+			data: bytes.Join([][]byte{
+				oggPage(0, vorbisIdent),
+				oggPage(0, append([]byte("\x03vorbis"), vorbisComment("TITLE=Ogg Song", "GENRE=Jazz")...)),
+				oggPage(88200, make([]byte, 300)),
+			}, nil),
+			want: &AudioMetadata{Title: "Ogg Song", Genre: "Jazz", Duration: 2 * time.Second},
+		},
+		{
+			name:   "opus",
+			format: "opus",
+			data: bytes.Join([][]byte{
+				oggPage(0, opusHead),
+				oggPage(0, append([]byte("OpusTags"), vorbisComment("ALBUM=Opus Album")...)),
+				oggPage(48000*3+312, make([]byte, 10)),
+			}, nil),
+			want: &AudioMetadata{Album: "Opus Album", Duration: 3 * time.Second},
+		},
+		{
+			name:   "wav",
+			format: "x-wav",
+			data:   wav,
+			want:   &AudioMetadata{Title: "Wave!", Artist: "Bob", Duration: 2 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ReadAudioMetadata(tt.format, bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadAudioMetadata_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadAudioMetadata("aac", bytes.NewReader([]byte("data")))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = ReadAudioMetadata("flac", bytes.NewReader([]byte("not a flac file")))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = ReadAudioMetadata("ogg", bytes.NewReader(oggPage(0, []byte("unknown codec"))))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	// Tags are optional for mp3 files
+	got, err := ReadAudioMetadata("mpeg", bytes.NewReader([]byte("garbage")))
+	require.NoError(t, err)
+	require.Equal(t, &AudioMetadata{}, got)
+}
+
+func TestReadAudioMetadata_Bounds(t *testing.T) {
+	t.Parallel()
+
+	flacWithSamples := func(sampleRate byte, totalSamples uint32) []byte {
+		info := make([]byte, 34)
+		info[12] = sampleRate << 4
+		info[13] = 0x0F // Highest bits of the 36 bits sample count
+		binary.BigEndian.PutUint32(info[14:], totalSamples)
+		return append([]byte("fLaC\x80\x00\x00\x22"), info...)
+	}
+
+	// The fmt chunk declares 4GB, only the fields are read
+	wav := []byte("RIFF\x00\x00\x00\x00WAVEfmt \xF0\xFF\xFF\xFF")
+	wavFmt := make([]byte, 16)
+	binary.LittleEndian.PutUint32(wavFmt[8:], 176400)
+	wav = append(wav, wavFmt...)
+
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+		want   *AudioMetadata
+	}{
+		{
+			name:   "flac sample count overflowing in integer nanoseconds",
+			format: "flac",
+			data:   flacWithSamples(15, 0xFFFFFFFF),
+			want:   &AudioMetadata{Duration: time.Duration(float64(0xFFFFFFFFF) / 15 * float64(time.Second))},
+		},
+		{
+			name:   "flac duration out of range",
+			format: "flac",
+			data:   flacWithSamples(1, 0xFFFFFFFF),
+			want:   &AudioMetadata{},
+		},
+		{
+			name:   "wav with oversized fmt chunk",
+			format: "wav",
+			data:   wav,
+			want:   &AudioMetadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ReadAudioMetadata(tt.format, bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/ledongthuc/pdf"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	// Longer lines are truncated in text previews
+	maxTextPreviewLineLen = 500
+	highlightStyle        = "github"
+	// Larger pdf files are not summarized, the parser keeps most of the file in memory
+	maxPDFSummarySize = 100 * 1024 * 1024
+)
+
+var (
+	markdown       = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	markdownPolicy = bluemonday.UGCPolicy()
+)
+
+// HighlightText renders the first maxLines lines of a text file as html, with inline styles.
+// The lexer is picked from the file name, then from the mime type, falling back to plain text.
+//
+// Errors:
+// - ErrUnsupportedFormat, if the file is not valid utf-8 text
+func HighlightText(reader io.ReadSeeker, fileName, mimeType string, maxLines int) ([]byte, error) {
+	text, err := readTextLines(reader, maxLines)
+	if err != nil {
+		return nil, err
+	}
+
+	lexer := lexers.Match(fileName)
+	if lexer == nil {
+		lexer = lexers.MatchMimeType(mimeType)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, text)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	formatter := html.New(html.WithClasses(false), html.WithLineNumbers(true), html.TabWidth(4))
+	err = formatter.Format(buf, styles.Get(highlightStyle), iterator)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderMarkdown renders the first maxBytes of a markdown file as sanitized html.
+//
+// Errors:
+// - ErrUnsupportedFormat, if the file is not valid utf-8 text
+func RenderMarkdown(reader io.ReadSeeker, maxBytes int64) ([]byte, error) {
+	_, err := reader.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := io.ReadAll(io.LimitReader(reader, maxBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	source = trimIncompleteRune(source)
+	if !isText(source) {
+		return nil, ErrUnsupportedFormat
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = markdown.Convert(source, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return markdownPolicy.SanitizeBytes(buf.Bytes()), nil
+}
+
+type PDFSummary struct {
+	PageCount int    `json:"pageCount"`
+	Title     string `json:"title,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	Text      string `json:"text,omitempty"` // Beginning of the first page
+}
+
+// SummarizePDF returns the json encoded PDFSummary of a pdf file.
+// The text is truncated to maxTextLen runes. The parsing stops with the error of ctx when it is done.
+//
+// Errors:
+// - ErrUnsupportedFormat, if the pdf is encrypted, too large or cannot be parsed
+func SummarizePDF(ctx context.Context, reader io.ReadSeeker, maxTextLen int) ([]byte, error) {
+	results, err := SummarizePDFSizes(ctx, reader, []int{maxTextLen})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// SummarizePDFSizes is SummarizePDF for several text lengths, parsing the pdf only once.
+// The results are in the order of maxTextLens.
+//
+// Errors:
+// - ErrUnsupportedFormat, if the pdf is encrypted, too large or cannot be parsed
+func SummarizePDFSizes(ctx context.Context, reader io.ReadSeeker, maxTextLens []int) (res [][]byte, err error) {
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > maxPDFSummarySize {
+		return nil, fmt.Errorf("%w: pdf: size %d over %d", ErrUnsupportedFormat, size, maxPDFSummarySize)
+	}
+
+	// The pdf parser panics on some malformed files, and on the read errors once ctx is done
+	defer func() {
+		if r := recover(); r != nil {
+			if ctx.Err() != nil {
+				res, err = nil, ctx.Err()
+				return
+			}
+			res, err = nil, fmt.Errorf("%w: pdf: %v", ErrUnsupportedFormat, r)
+		}
+	}()
+
+	r, err := pdf.NewReader(&ctxReaderAt{ctx: ctx, r: &seekReaderAt{reader}}, size)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: pdf: %w", ErrUnsupportedFormat, err)
+	}
+
+	info := r.Trailer().Key("Info")
+	summary := &PDFSummary{
+		PageCount: r.NumPage(),
+		Title:     strings.TrimSpace(info.Key("Title").Text()),
+		Author:    strings.TrimSpace(info.Key("Author").Text()),
+		Subject:   strings.TrimSpace(info.Key("Subject").Text()),
+	}
+
+	var text string
+	if summary.PageCount > 0 {
+		// Text extraction is best effort, the summary is still useful without it
+		if plain, err := r.Page(1).GetPlainText(nil); err == nil {
+			text = strings.TrimSpace(plain)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	res = make([][]byte, len(maxTextLens))
+	for i, maxTextLen := range maxTextLens {
+		summary.Text = truncateRunes(text, maxTextLen)
+		res[i], err = json.Marshal(summary)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// ctxReaderAt fails the reads once ctx is done, to stop parsers that don't take a context.
+type ctxReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+func (c *ctxReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.ReadAt(p, off)
+}
+
+// readTextLines reads at most maxLines lines, truncating the long ones.
+func readTextLines(reader io.ReadSeeker, maxLines int) (string, error) {
+	_, err := reader.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	br := bufio.NewReader(reader)
+	var sb strings.Builder
+	for i := 0; i < maxLines; i++ {
+		line, err := readLine(br)
+		if len(line) > 0 || err == nil {
+			if !isText(line) {
+				return "", ErrUnsupportedFormat
+			}
+			sb.Write(line)
+			sb.WriteByte('\n')
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// readLine reads a line without the line ending, keeping at most maxTextPreviewLineLen bytes of it.
+func readLine(br *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err != nil {
+			return line, err
+		}
+
+		if room := maxTextPreviewLineLen - len(line); room > 0 {
+			line = append(line, chunk[:min(room, len(chunk))]...)
+		}
+
+		if !isPrefix {
+			return trimIncompleteRune(line), nil
+		}
+	}
+}
+
+// isText tells if the data looks like utf-8 text, rather than binary.
+func isText(data []byte) bool {
+	return utf8.Valid(data) && bytes.IndexByte(data, 0) == -1
+}
+
+// trimIncompleteRune removes the partial rune left at the end by a truncation.
+func trimIncompleteRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+func truncateRunes(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	return string([]rune(s)[:maxLen])
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightText(t *testing.T) {
+	t.Parallel()
+	source := "package main\n\nfunc main() {}\n" + strings.Repeat("// comment\n", 100)
+
+	preview, err := HighlightText(strings.NewReader(source), "main.go", "text/plain", 3)
+	require.NoError(t, err)
+	html := string(preview)
+	require.Contains(t, html, "<pre")
+	require.Contains(t, html, "style=", "highlighting should use inline styles")
+	require.Contains(t, html, "main")
+	require.NotContains(t, html, "comment", "only the first lines should be rendered")
+
+	_, err = HighlightText(bytes.NewReader([]byte{0x00, 0x01, 0xFF}), "data.bin", "text/plain", 3)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestHighlightText_LongLine(t *testing.T) {
+	t.Parallel()
+	// Multi-byte runes must not be cut in half by the truncation
+	source := strings.Repeat("é", maxTextPreviewLineLen)
+
+	preview, err := HighlightText(strings.NewReader(source), "a.txt", "text/plain", 1)
+	require.NoError(t, err)
+	require.Equal(t, maxTextPreviewLineLen/2, strings.Count(string(preview), "é"))
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	t.Parallel()
+	source := "# Title\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n<script>alert(1)</script>\n\n[link](javascript:alert(1))\n"
+
+	preview, err := RenderMarkdown(strings.NewReader(source), 1024)
+	require.NoError(t, err)
+	html := string(preview)
+	require.Contains(t, html, "<h1")
+	require.Contains(t, html, "<table>", "tables should be rendered")
+	require.NotContains(t, html, "<script")
+	require.NotContains(t, html, "javascript:")
+
+	_, err = RenderMarkdown(bytes.NewReader([]byte{0x00, 0x01, 0xFF}), 1024)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+// minimalPDF builds a single page pdf with the given title and text.
+func minimalPDF(title, text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 10 10 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Title (%s) >>", title),
+	}
+
+	buf := bytes.NewBufferString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestSummarizePDF(t *testing.T) {
+	t.Parallel()
+	data := minimalPDF("My Document", "Hello PDF")
+
+	preview, err := SummarizePDF(context.Background(), bytes.NewReader(data), 5)
+	require.NoError(t, err)
+
+	var summary PDFSummary
+	require.NoError(t, json.Unmarshal(preview, &summary))
+	require.Equal(t, 1, summary.PageCount)
+	require.Equal(t, "My Document", summary.Title)
+	require.Equal(t, "Hello", summary.Text, "text should be truncated")
+
+	_, err = SummarizePDF(context.Background(), bytes.NewReader([]byte("not a pdf")), 5)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = SummarizePDF(ctx, bytes.NewReader(data), 5)
+	require.ErrorIs(t, err, context.Canceled, "parsing should stop once the context is done")
+
+	_, err = SummarizePDF(context.Background(), &oversizedReader{bytes.NewReader(data)}, 5)
+	require.ErrorIs(t, err, ErrUnsupportedFormat, "large pdf files should not be parsed")
+}
+
+func TestSummarizePDFSizes(t *testing.T) {
+	t.Parallel()
+	data := minimalPDF("My Document", "Hello PDF")
+
+	previews, err := SummarizePDFSizes(context.Background(), bytes.NewReader(data), []int{5, 100})
+	require.NoError(t, err)
+	require.Len(t, previews, 2)
+
+	for i, text := range []string{"Hello", "Hello PDF"} {
+		var summary PDFSummary
+		require.NoError(t, json.Unmarshal(previews[i], &summary))
+		require.Equal(t, "My Document", summary.Title)
+		require.Equal(t, text, summary.Text)
+	}
+}
+
+// oversizedReader reports a size over maxPDFSummarySize
+type oversizedReader struct {
+	io.ReadSeeker
+}
+
+func (r *oversizedReader) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd {
+		return maxPDFSummarySize + 1, nil
+	}
+	return r.ReadSeeker.Seek(offset, whence)
+}
@@ -0,0 +1,423 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// EXIF orientation values, see the orientation tag (0x0112) of the EXIF spec.
+const (
+	OrientationNormal      = 1
+	OrientationFlipH       = 2
+	OrientationRotate180   = 3
+	OrientationFlipV       = 4
+	OrientationTranspose   = 5
+	OrientationRotate90CW  = 6
+	OrientationTransverse  = 7
+	OrientationRotate90CCW = 8
+)
+
+const (
+	exifTagMake               = 0x010F
+	exifTagModel              = 0x0110
+	exifTagOrientation        = 0x0112
+	exifTagDateTime           = 0x0132
+	exifTagExifIFD            = 0x8769
+	exifTagGPSIFD             = 0x8825
+	exifTagDateTimeOriginal   = 0x9003
+	exifTagOffsetTimeOriginal = 0x9011
+	exifTagPixelXDimension    = 0xA002
+	exifTagPixelYDimension    = 0xA003
+
+	gpsTagLatitudeRef  = 0x0001
+	gpsTagLatitude     = 0x0002
+	gpsTagLongitudeRef = 0x0003
+	gpsTagLongitude    = 0x0004
+
+	tiffTypeASCII    = 2
+	tiffTypeShort    = 3
+	tiffTypeLong     = 4
+	tiffTypeRational = 5
+
+	// Longer strings are ignored
+	maxExifStringLen = 256
+
+	// Guards against corrupted files with huge IFDs
+	maxIFDEntries = 1024
+	// Max. size of the jpeg/webp segments read while looking for EXIF data
+	maxExifSegmentSize = 1 << 20
+)
+
+var errNoExif = errors.New("no exif data")
+
+// ExifData holds the EXIF fields used by SkyVault. Missing fields are left empty.
+type ExifData struct {
+	Orientation int
+	CameraMake  string
+	CameraModel string
+	// CapturedAt is the local time of the camera, in UTC if the offset is missing from the EXIF data.
+	CapturedAt *time.Time
+	Width      int
+	Height     int
+	Latitude   *float64
+	Longitude  *float64
+}
+
+// ReadExif returns the EXIF data of a jpeg, tiff or webp image.
+//
+// Errors:
+// - ErrUnsupportedFormat, if the image has no EXIF data
+func ReadExif(format string, reader io.ReadSeeker) (*ExifData, error) {
+	tr, err := newExifReader(format, reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+
+	ifd0, err := tr.readIFD(tr.ifd0Offset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+
+	data := &ExifData{
+		Orientation: OrientationNormal,
+		CameraMake:  tr.string(ifd0[exifTagMake]),
+		CameraModel: tr.string(ifd0[exifTagModel]),
+	}
+
+	if o, ok := tr.uint(ifd0[exifTagOrientation]); ok && o >= OrientationNormal && o <= OrientationRotate90CCW {
+		data.Orientation = int(o)
+	}
+
+	dateTime := tr.string(ifd0[exifTagDateTime])
+	offsetTime := ""
+
+	// The sub-IFDs are optional, failing to read them only loses their fields
+	if offset, ok := tr.uint(ifd0[exifTagExifIFD]); ok {
+		if exifIFD, err := tr.readIFD(offset); err == nil {
+			if v := tr.string(exifIFD[exifTagDateTimeOriginal]); v != "" {
+				dateTime = v
+				offsetTime = tr.string(exifIFD[exifTagOffsetTimeOriginal])
+			}
+			if w, ok := tr.uint(exifIFD[exifTagPixelXDimension]); ok {
+				data.Width = int(w)
+			}
+			if h, ok := tr.uint(exifIFD[exifTagPixelYDimension]); ok {
+				data.Height = int(h)
+			}
+		}
+	}
+
+	data.CapturedAt = parseExifTime(dateTime, offsetTime)
+
+	if offset, ok := tr.uint(ifd0[exifTagGPSIFD]); ok {
+		if gpsIFD, err := tr.readIFD(offset); err == nil {
+			data.Latitude = tr.gpsCoordinate(gpsIFD[gpsTagLatitude], tr.string(gpsIFD[gpsTagLatitudeRef]), "S")
+			data.Longitude = tr.gpsCoordinate(gpsIFD[gpsTagLongitude], tr.string(gpsIFD[gpsTagLongitudeRef]), "W")
+		}
+	}
+
+	return data, nil
+}
+
+// ExifOrientation returns the EXIF orientation of a jpeg, tiff or webp image.
+// OrientationNormal is returned if the image has no (valid) orientation.
+func ExifOrientation(format string, reader io.ReadSeeker) int {
+	data, err := ReadExif(format, reader)
+	if err != nil {
+		return OrientationNormal
+	}
+
+	return data.Orientation
+}
+
+// parseExifTime parses the "2006:01:02 15:04:05" EXIF format, with the optional "+07:00" offset.
+func parseExifTime(dateTime, offsetTime string) *time.Time {
+	dateTime = strings.TrimSpace(dateTime)
+	if dateTime == "" {
+		return nil
+	}
+
+	t, err := time.Parse("2006:01:02 15:04:05", dateTime)
+	if err != nil {
+		return nil
+	}
+
+	if offsetTime = strings.TrimSpace(offsetTime); offsetTime != "" {
+		if withOffset, err := time.Parse("2006:01:02 15:04:05-07:00", dateTime+offsetTime); err == nil {
+			t = withOffset.UTC()
+		}
+	}
+
+	return &t
+}
+
+// newExifReader finds the TIFF structured EXIF block of the image.
+func newExifReader(format string, reader io.ReadSeeker) (*tiffReader, error) {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "jpeg", "jpg", "pjpeg":
+		data, err := findJPEGExif(reader)
+		if err != nil {
+			return nil, err
+		}
+		return newTIFFReader(bytes.NewReader(data))
+	case "webp":
+		data, err := findWebPExif(reader)
+		if err != nil {
+			return nil, err
+		}
+		return newTIFFReader(bytes.NewReader(data))
+	case "tiff":
+		return newTIFFReader(&seekReaderAt{reader})
+	default:
+		return nil, errNoExif
+	}
+}
+
+// findJPEGExif returns the payload of the APP1 Exif segment, without the "Exif\0\0" header.
+func findJPEGExif(r io.Reader) ([]byte, error) {
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil {
+		return nil, err
+	}
+	if soi[0] != 0xFF || soi[1] != 0xD8 {
+		return nil, errNoExif
+	}
+
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, err
+		}
+		if header[0] != 0xFF {
+			return nil, errNoExif
+		}
+
+		marker := header[1]
+		// Start of scan or end of image, metadata segments always come before
+		if marker == 0xDA || marker == 0xD9 {
+			return nil, errNoExif
+		}
+
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if length < 0 || length > maxExifSegmentSize {
+			return nil, errNoExif
+		}
+
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return nil, err
+		}
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+	}
+}
+
+// findWebPExif returns the payload of the EXIF chunk of the RIFF container.
+func findWebPExif(r io.Reader) ([]byte, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WEBP" {
+		return nil, errNoExif
+	}
+
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			return nil, err
+		}
+
+		size := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+		// Chunks are padded to an even size
+		padded := size + size%2
+
+		if string(chunkHeader[:4]) != "EXIF" {
+			if _, err := io.CopyN(io.Discard, r, padded); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if size > maxExifSegmentSize {
+			return nil, errNoExif
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		// Some encoders keep the jpeg header
+		return bytes.TrimPrefix(data, []byte("Exif\x00\x00")), nil
+	}
+}
+
+// tiffReader reads the IFDs of a TIFF structured block, which is the format of EXIF data.
+type tiffReader struct {
+	r          io.ReaderAt
+	order      binary.ByteOrder
+	ifd0Offset uint32
+}
+
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	value [4]byte // value, if it fits in 4 bytes, offset otherwise
+}
+
+func newTIFFReader(r io.ReaderAt) (*tiffReader, error) {
+	var header [8]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return nil, err
+	}
+
+	var order binary.ByteOrder
+	switch string(header[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errNoExif
+	}
+
+	if order.Uint16(header[2:4]) != 42 {
+		return nil, errNoExif
+	}
+
+	return &tiffReader{r: r, order: order, ifd0Offset: order.Uint32(header[4:])}, nil
+}
+
+func (t *tiffReader) readIFD(offset uint32) (map[uint16]tiffEntry, error) {
+	var countBuf [2]byte
+	if _, err := t.r.ReadAt(countBuf[:], int64(offset)); err != nil {
+		return nil, err
+	}
+
+	count := int(t.order.Uint16(countBuf[:]))
+	if count > maxIFDEntries {
+		return nil, errNoExif
+	}
+
+	buf := make([]byte, count*12)
+	if _, err := t.r.ReadAt(buf, int64(offset)+2); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uint16]tiffEntry, count)
+	for i := 0; i < count; i++ {
+		raw := buf[i*12 : (i+1)*12]
+		entry := tiffEntry{
+			typ:   t.order.Uint16(raw[2:4]),
+			count: t.order.Uint32(raw[4:8]),
+		}
+		copy(entry.value[:], raw[8:12])
+		entries[t.order.Uint16(raw[0:2])] = entry
+	}
+
+	return entries, nil
+}
+
+// uint returns the first value of a SHORT or LONG entry.
+func (t *tiffReader) uint(e tiffEntry) (uint32, bool) {
+	if e.count == 0 {
+		return 0, false
+	}
+
+	switch e.typ {
+	case tiffTypeShort:
+		return uint32(t.order.Uint16(e.value[:2])), true
+	case tiffTypeLong:
+		return t.order.Uint32(e.value[:]), true
+	default:
+		return 0, false
+	}
+}
+
+// string returns the value of an ASCII entry, without the trailing NULs.
+func (t *tiffReader) string(e tiffEntry) string {
+	if e.typ != tiffTypeASCII || e.count == 0 || e.count > maxExifStringLen {
+		return ""
+	}
+
+	data := e.value[:]
+	if e.count > 4 {
+		data = make([]byte, e.count)
+		if _, err := t.r.ReadAt(data, int64(t.order.Uint32(e.value[:]))); err != nil {
+			return ""
+		}
+	}
+
+	data = data[:min(int(e.count), len(data))]
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+
+	if !utf8.Valid(data) {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// rationals returns the values of a RATIONAL entry.
+func (t *tiffReader) rationals(e tiffEntry) ([]float64, bool) {
+	if e.typ != tiffTypeRational || e.count == 0 || e.count > 16 {
+		return nil, false
+	}
+
+	data := make([]byte, e.count*8)
+	if _, err := t.r.ReadAt(data, int64(t.order.Uint32(e.value[:]))); err != nil {
+		return nil, false
+	}
+
+	res := make([]float64, e.count)
+	for i := range res {
+		num := t.order.Uint32(data[i*8:])
+		den := t.order.Uint32(data[i*8+4:])
+		if den == 0 {
+			return nil, false
+		}
+		res[i] = float64(num) / float64(den)
+	}
+	return res, true
+}
+
+// gpsCoordinate converts a degrees, minutes, seconds entry to decimal degrees.
+// The coordinate is negative if ref is negativeRef, e.g. "S" for latitudes.
+func (t *tiffReader) gpsCoordinate(e tiffEntry, ref, negativeRef string) *float64 {
+	dms, ok := t.rationals(e)
+	if !ok || len(dms) != 3 {
+		return nil
+	}
+
+	coord := dms[0] + dms[1]/60 + dms[2]/3600
+	if ref == negativeRef {
+		coord = -coord
+	}
+	return &coord
+}
+
+// seekReaderAt adapts a ReadSeeker to a ReaderAt. It is not safe for concurrent use.
+type seekReaderAt struct {
+	io.ReadSeeker
+}
+
+func (s *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := s.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(s, p)
+}
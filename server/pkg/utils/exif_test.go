@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testIFDEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+	ifd   int // Index of the IFD pointed to by the entry, if data is nil
+}
+
+func asciiEntry(tag uint16, s string) testIFDEntry {
+	return testIFDEntry{tag: tag, typ: tiffTypeASCII, count: uint32(len(s) + 1), data: append([]byte(s), 0)}
+}
+
+func rationalEntry(tag uint16, values ...[2]uint32) testIFDEntry {
+	var data []byte
+	for _, v := range values {
+		data = binary.BigEndian.AppendUint32(data, v[0])
+		data = binary.BigEndian.AppendUint32(data, v[1])
+	}
+	return testIFDEntry{tag: tag, typ: tiffTypeRational, count: uint32(len(values)), data: data}
+}
+
+func pointerEntry(tag uint16, ifd int) testIFDEntry {
+	return testIFDEntry{tag: tag, typ: tiffTypeLong, count: 1, ifd: ifd}
+}
+
+// buildTIFF lays out the IFDs one after the other, big endian, the first one being IFD0.
+func buildTIFF(ifds ...[]testIFDEntry) []byte {
+	offsets := make([]uint32, len(ifds))
+	offset := uint32(8)
+	for i, entries := range ifds {
+		offsets[i] = offset
+		offset += 2 + 12*uint32(len(entries)) + 4
+		for _, e := range entries {
+			if len(e.data) > 4 {
+				offset += uint32(len(e.data) + len(e.data)%2)
+			}
+		}
+	}
+
+	buf := []byte("MM\x00\x2a")
+	buf = binary.BigEndian.AppendUint32(buf, 8)
+	for i, entries := range ifds {
+		dataOffset := offsets[i] + 2 + 12*uint32(len(entries)) + 4
+		var extra []byte
+
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(entries)))
+		for _, e := range entries {
+			buf = binary.BigEndian.AppendUint16(buf, e.tag)
+			buf = binary.BigEndian.AppendUint16(buf, e.typ)
+			buf = binary.BigEndian.AppendUint32(buf, e.count)
+
+			var value [4]byte
+			switch {
+			case e.data == nil:
+				binary.BigEndian.PutUint32(value[:], offsets[e.ifd])
+			case len(e.data) <= 4:
+				copy(value[:], e.data)
+			default:
+				binary.BigEndian.PutUint32(value[:], dataOffset+uint32(len(extra)))
+				extra = append(extra, e.data...)
+				if len(e.data)%2 == 1 {
+					extra = append(extra, 0)
+				}
+			}
+			buf = append(buf, value[:]...)
+		}
+		buf = binary.BigEndian.AppendUint32(buf, 0) // Next IFD offset
+		buf = append(buf, extra...)
+	}
+
+	return buf
+}
+
+func TestReadExif(t *testing.T) {
+	t.Parallel()
+
+	orientation := binary.BigEndian.AppendUint16(nil, OrientationRotate90CW)
+	tiffData := buildTIFF(
+		[]testIFDEntry{
+			asciiEntry(exifTagMake, "Canon"),
+			asciiEntry(exifTagModel, "Canon EOS R6"),
+			{tag: exifTagOrientation, typ: tiffTypeShort, count: 1, data: orientation},
+			pointerEntry(exifTagExifIFD, 1),
+			pointerEntry(exifTagGPSIFD, 2),
+		},
+		[]testIFDEntry{
+			asciiEntry(exifTagDateTimeOriginal, "2024:07:14 18:30:05"),
+			asciiEntry(exifTagOffsetTimeOriginal, "+02:00"),
+			{tag: exifTagPixelXDimension, typ: tiffTypeLong, count: 1, data: binary.BigEndian.AppendUint32(nil, 6000)},
+			{tag: exifTagPixelYDimension, typ: tiffTypeShort, count: 1, data: binary.BigEndian.AppendUint16(nil, 4000)},
+		},
+		[]testIFDEntry{
+			asciiEntry(gpsTagLatitudeRef, "N"),
+			rationalEntry(gpsTagLatitude, [2]uint32{48, 1}, [2]uint32{51, 1}, [2]uint32{2952, 100}),
+			asciiEntry(gpsTagLongitudeRef, "W"),
+			rationalEntry(gpsTagLongitude, [2]uint32{2, 1}, [2]uint32{17, 1}, [2]uint32{4008, 100}),
+		},
+	)
+
+	data, err := ReadExif("tiff", bytes.NewReader(tiffData))
+	require.NoError(t, err)
+	require.Equal(t, OrientationRotate90CW, data.Orientation)
+	require.Equal(t, "Canon", data.CameraMake)
+	require.Equal(t, "Canon EOS R6", data.CameraModel)
+	require.Equal(t, 6000, data.Width)
+	require.Equal(t, 4000, data.Height)
+	require.NotNil(t, data.CapturedAt)
+	require.Equal(t, time.Date(2024, 7, 14, 16, 30, 5, 0, time.UTC), *data.CapturedAt)
+	require.NotNil(t, data.Latitude)
+	require.InDelta(t, 48.8582, *data.Latitude, 0.0001)
+	require.NotNil(t, data.Longitude)
+	require.InDelta(t, -2.2945, *data.Longitude, 0.0001)
+}
+
+func TestReadExif_Partial(t *testing.T) {
+	t.Parallel()
+
+	// Only the IFD0 date, without offset, and a GPS pointer to a garbage offset
+	tiffData := buildTIFF([]testIFDEntry{
+		asciiEntry(exifTagDateTime, "2020:01:02 03:04:05"),
+		{tag: exifTagGPSIFD, typ: tiffTypeLong, count: 1, data: binary.BigEndian.AppendUint32(nil, 1<<30)},
+	})
+
+	data, err := ReadExif("tiff", bytes.NewReader(tiffData))
+	require.NoError(t, err)
+	require.Equal(t, OrientationNormal, data.Orientation)
+	require.Empty(t, data.CameraMake)
+	require.NotNil(t, data.CapturedAt)
+	require.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), *data.CapturedAt)
+	require.Nil(t, data.Latitude)
+	require.Nil(t, data.Longitude)
+
+	_, err = ReadExif("png", bytes.NewReader(tiffData))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+	_, err = ReadExif("tiff", bytes.NewReader([]byte("garbage")))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestImageDimensions(t *testing.T) {
+	t.Parallel()
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, jpeg.Encode(buf, img, nil))
+	w, h, err := ImageDimensions("jpeg", bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, []int{40, 20}, []int{w, h})
+
+	rotated := jpegWithOrientation(t, img, OrientationRotate90CW)
+	w, h, err = ImageDimensions("jpeg", bytes.NewReader(rotated))
+	require.NoError(t, err)
+	require.Equal(t, []int{20, 40}, []int{w, h}, "displayed dimensions")
+
+	_, _, err = ImageDimensions("heic", bytes.NewReader(buf.Bytes()))
+	require.ErrorIs(t, err, ErrUnsupportedImageFormat)
+}
@@ -3,59 +3,131 @@ package utils
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"sort"
 
 	"golang.org/x/image/draw"
 )
 
+// MaxImagePixels is the largest image decoded, a decoded image takes about 4 bytes per pixel.
+// The header of a small file can declare a huge image.
+const MaxImagePixels = 50_000_000
+
 var (
-	ErrUnsupportedImageFormat = errors.New("unsupported image format")
+	ErrUnsupportedFormat      = errors.New("unsupported format")
+	ErrUnsupportedImageFormat = fmt.Errorf("%w: image", ErrUnsupportedFormat)
 )
 
-// ScaleDownImageTo resizes an image of type jpeg or png to the given width and height
+// ScaleDownImageToFit resizes an image of any format with a registered decoder (see RegisterImageDecoder)
+// so that it fits in a maxSize x maxSize box, keeping the aspect ratio.
+// Images smaller than the box are not upscaled. The EXIF orientation, if any, is applied.
+// The result is always encoded as jpeg.
 //
 // App Errors:
-// - ErrUnsupportedImageFormat
-func ScaleDownImageTo(format string, reader io.ReadSeeker, maxWidth, maxHeight int) ([]byte, error) {
-	if format != "jpeg" && format != "png" {
+// - ErrUnsupportedImageFormat (also for images over MaxImagePixels)
+func ScaleDownImageToFit(format string, reader io.ReadSeeker, maxSize int) ([]byte, error) {
+	results, err := ScaleDownImageToFitSizes(format, reader, []int{maxSize})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// ScaleDownImageToFitSizes is ScaleDownImageToFit for several box sizes, decoding the image only once.
+// The results are in the order of maxSizes. Each size is scaled from the next larger one,
+// so most of the work is done once, on the largest size.
+//
+// App Errors:
+// - ErrUnsupportedImageFormat (also for images over MaxImagePixels)
+func ScaleDownImageToFitSizes(format string, reader io.ReadSeeker, maxSizes []int) ([][]byte, error) {
+	decode, ok := getImageDecoder(format)
+	if !ok {
 		return nil, ErrUnsupportedImageFormat
 	}
 
-	reader.Seek(0, io.SeekStart)
+	err := validateImagePixels(reader)
+	if err != nil {
+		return nil, err
+	}
 
-	var img image.Image
-	var err error
+	orientation := ExifOrientation(format, reader)
 
-	if format == "jpeg" {
-		img, err = jpeg.Decode(reader)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		img, err = png.Decode(reader)
+	_, err = reader.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := decode(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(maxSizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return maxSizes[order[a]] > maxSizes[order[b]] })
+
+	results := make([][]byte, len(maxSizes))
+	var src image.Image = img
+	for _, i := range order {
+		width, height := FitDimensions(img.Bounds().Dx(), img.Bounds().Dy(), maxSizes[i])
+
+		// Paint a white background first, so that transparent pixels don't turn black in jpeg.
+		resized := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(resized, resized.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(resized, resized.Bounds(), src, src.Bounds(), draw.Over, nil)
+		src = resized
+
+		// Orienting after scaling is much cheaper and gives the same result, since the box is a square
+		buf := bytes.NewBuffer(nil)
+		err = jpeg.Encode(buf, orientImage(resized, orientation), &jpeg.Options{Quality: 85})
 		if err != nil {
 			return nil, err
 		}
+		results[i] = buf.Bytes()
 	}
 
-	resized := image.NewRGBA(image.Rect(0, 0, maxWidth, maxHeight))
-	draw.CatmullRom.Scale(resized, resized.Bounds(), img, img.Bounds(), draw.Over, nil)
+	return results, nil
+}
 
-	buf := bytes.NewBuffer(nil)
-	if format == "jpeg" {
-		err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: 100})
-	} else {
-		err = png.Encode(buf, resized)
+// validateImagePixels rejects the images over MaxImagePixels from their header, before they are decoded.
+// The formats unknown to the image package can't be checked, their decoders must bound their memory.
+//
+// Errors:
+// - ErrUnsupportedImageFormat
+func validateImagePixels(reader io.ReadSeeker) error {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return err
 	}
 
+	config, _, err := image.DecodeConfig(reader)
 	if err != nil {
-		return nil, err
+		return nil
+	}
+
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return fmt.Errorf("%w: %dx%d pixels, at most %d", ErrUnsupportedImageFormat, config.Width, config.Height, MaxImagePixels)
+	}
+	return nil
+}
+
+// FitDimensions returns the width and height that fit in a maxSize x maxSize box keeping the aspect ratio.
+// Dimensions already inside the box are returned as is.
+func FitDimensions(width, height, maxSize int) (int, int) {
+	if width <= maxSize && height <= maxSize {
+		return width, height
+	}
+
+	if width >= height {
+		return maxSize, max(1, height*maxSize/width)
 	}
 
-	return buf.Bytes(), nil
+	return max(1, width*maxSize/height), maxSize
 }
 
 // SampleImage generates a small test image
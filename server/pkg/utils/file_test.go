@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitDimensions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		maxSize    int
+		wantWidth  int
+		wantHeight int
+	}{
+		{
+			name:       "already fits",
+			width:      100,
+			height:     50,
+			maxSize:    256,
+			wantWidth:  100,
+			wantHeight: 50,
+		},
+		{
+			name:       "landscape",
+			width:      2000,
+			height:     1000,
+			maxSize:    256,
+			wantWidth:  256,
+			wantHeight: 128,
+		},
+		{
+			name:       "portrait",
+			width:      1000,
+			height:     4000,
+			maxSize:    512,
+			wantWidth:  128,
+			wantHeight: 512,
+		},
+		{
+			name:       "very thin image keeps at least one pixel",
+			width:      10000,
+			height:     1,
+			maxSize:    256,
+			wantWidth:  256,
+			wantHeight: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w, h := FitDimensions(tt.width, tt.height, tt.maxSize)
+			require.Equal(t, tt.wantWidth, w)
+			require.Equal(t, tt.wantHeight, h)
+		})
+	}
+}
+
+func TestScaleDownImageToFit(t *testing.T) {
+	t.Parallel()
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 400, 200)))
+	require.NoError(t, err)
+
+	preview, err := ScaleDownImageToFit("png", bytes.NewReader(buf.Bytes()), 100)
+	require.NoError(t, err)
+
+	img, err := jpeg.Decode(bytes.NewReader(preview))
+	require.NoError(t, err, "preview should be a jpeg")
+	require.Equal(t, 100, img.Bounds().Dx())
+	require.Equal(t, 50, img.Bounds().Dy())
+
+	_, err = ScaleDownImageToFit("heic", bytes.NewReader(buf.Bytes()), 100)
+	require.ErrorIs(t, err, ErrUnsupportedImageFormat)
+}
+
+func TestScaleDownImageToFitSizes(t *testing.T) {
+	t.Parallel()
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 400, 200)))
+	require.NoError(t, err)
+
+	previews, err := ScaleDownImageToFitSizes("png", bytes.NewReader(buf.Bytes()), []int{100, 800, 200})
+	require.NoError(t, err)
+	require.Len(t, previews, 3)
+
+	expected := [][2]int{{100, 50}, {400, 200}, {200, 100}}
+	for i, preview := range previews {
+		img, err := jpeg.Decode(bytes.NewReader(preview))
+		require.NoError(t, err, "preview should be a jpeg")
+		require.Equal(t, expected[i][0], img.Bounds().Dx())
+		require.Equal(t, expected[i][1], img.Bounds().Dy())
+	}
+}
+
+func TestScaleDownImageToFitTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// A png header declaring 60000x60000 pixels, about 14GB once decoded
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], 60000)
+	binary.BigEndian.PutUint32(ihdr[4:8], 60000)
+	ihdr[8], ihdr[9] = 8, 6 // 8 bits RGBA
+
+	buf := bytes.NewBufferString("\x89PNG\r\n\x1a\n")
+	require.NoError(t, binary.Write(buf, binary.BigEndian, uint32(len(ihdr))))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	require.NoError(t, binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(chunk)))
+
+	_, err := ScaleDownImageToFit("png", bytes.NewReader(buf.Bytes()), 100)
+	require.ErrorIs(t, err, ErrUnsupportedImageFormat)
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
+)
+
+// ImageDecoder decodes an image. For animated formats only the first frame is decoded.
+type ImageDecoder func(r io.Reader) (image.Image, error)
+
+var (
+	imageDecodersMu sync.RWMutex
+	// Keyed by the subtype of the mime type, e.g. "png" for "image/png"
+	imageDecoders = map[string]ImageDecoder{
+		"jpeg":     jpeg.Decode,
+		"jpg":      jpeg.Decode,
+		"pjpeg":    jpeg.Decode,
+		"png":      png.Decode,
+		"gif":      gif.Decode,
+		"webp":     webp.Decode,
+		"bmp":      bmp.Decode,
+		"x-bmp":    bmp.Decode,
+		"x-ms-bmp": bmp.Decode,
+		"tiff":     tiff.Decode,
+	}
+)
+
+// RegisterImageDecoder adds or replaces the decoder of the given format.
+func RegisterImageDecoder(format string, decoder ImageDecoder) {
+	imageDecodersMu.Lock()
+	defer imageDecodersMu.Unlock()
+	imageDecoders[strings.ToLower(format)] = decoder
+}
+
+func getImageDecoder(format string) (ImageDecoder, bool) {
+	imageDecodersMu.RLock()
+	defer imageDecodersMu.RUnlock()
+	decoder, ok := imageDecoders[strings.ToLower(format)]
+	return decoder, ok
+}
+
+// SupportsImageFormat tells if an image of the given format can be decoded.
+func SupportsImageFormat(format string) bool {
+	_, ok := getImageDecoder(format)
+	return ok
+}
+
+// orientImage transforms the image so that it is displayed upright, according to the EXIF orientation.
+func orientImage(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= OrientationNormal || orientation > OrientationRotate90CCW {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= OrientationTranspose {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case OrientationFlipH:
+				dx, dy = w-1-x, y
+			case OrientationRotate180:
+				dx, dy = w-1-x, h-1-y
+			case OrientationFlipV:
+				dx, dy = x, h-1-y
+			case OrientationTranspose:
+				dx, dy = y, x
+			case OrientationRotate90CW:
+				dx, dy = h-1-y, x
+			case OrientationTransverse:
+				dx, dy = h-1-y, w-1-x
+			case OrientationRotate90CCW:
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, img.RGBAAt(img.Bounds().Min.X+x, img.Bounds().Min.Y+y))
+		}
+	}
+
+	return dst
+}
+
+// ImageDimensions returns the width and height of the image, as displayed after applying the EXIF orientation.
+//
+// Errors:
+// - ErrUnsupportedImageFormat
+func ImageDimensions(format string, reader io.ReadSeeker) (width, height int, err error) {
+	decoder, ok := getImageDecoder(format)
+	if !ok {
+		return 0, 0, ErrUnsupportedImageFormat
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	// Reading the header is enough for the built-in formats, custom ones must be decoded
+	config, _, err := image.DecodeConfig(reader)
+	if err == nil {
+		width, height = config.Width, config.Height
+	} else {
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			return 0, 0, err
+		}
+
+		img, err := decoder(reader)
+		if err != nil {
+			return 0, 0, err
+		}
+		width, height = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+
+	if ExifOrientation(format, reader) >= OrientationTranspose {
+		width, height = height, width
+	}
+
+	return width, height, nil
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+func TestScaleDownImageToFit_Formats(t *testing.T) {
+	t.Parallel()
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+
+	tests := []struct {
+		name   string
+		format string
+		encode func(w io.Writer, img image.Image) error
+	}{
+		{
+			name:   "gif",
+			format: "gif",
+			encode: func(w io.Writer, img image.Image) error { return gif.Encode(w, img, nil) },
+		},
+		{
+			name:   "bmp",
+			format: "bmp",
+			encode: bmp.Encode,
+		},
+		{
+			name:   "bmp alias",
+			format: "x-ms-bmp",
+			encode: bmp.Encode,
+		},
+		{
+			name:   "tiff",
+			format: "tiff",
+			encode: func(w io.Writer, img image.Image) error { return tiff.Encode(w, img, nil) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			buf := new(bytes.Buffer)
+			require.NoError(t, tt.encode(buf, img))
+
+			preview, err := ScaleDownImageToFit(tt.format, bytes.NewReader(buf.Bytes()), 100)
+			require.NoError(t, err)
+
+			decoded, err := jpeg.Decode(bytes.NewReader(preview))
+			require.NoError(t, err)
+			require.Equal(t, 100, decoded.Bounds().Dx())
+			require.Equal(t, 50, decoded.Bounds().Dy())
+		})
+	}
+}
+
+func TestRegisterImageDecoder(t *testing.T) {
+	t.Parallel()
+	require.False(t, SupportsImageFormat("x-test-format"))
+
+	RegisterImageDecoder("x-test-format", func(r io.Reader) (image.Image, error) {
+		return image.NewRGBA(image.Rect(0, 0, 10, 20)), nil
+	})
+	require.True(t, SupportsImageFormat("x-test-format"))
+
+	preview, err := ScaleDownImageToFit("x-test-format", bytes.NewReader(nil), 100)
+	require.NoError(t, err)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(preview))
+	require.NoError(t, err)
+	require.Equal(t, 10, decoded.Bounds().Dx())
+	require.Equal(t, 20, decoded.Bounds().Dy())
+}
+
+// jpegWithOrientation encodes img as jpeg with an EXIF segment holding the orientation.
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	buf := new(bytes.Buffer)
+	require.NoError(t, jpeg.Encode(buf, img, nil))
+	encoded := buf.Bytes()
+
+	tiffData := new(bytes.Buffer)
+	tiffData.WriteString("MM\x00\x2a")
+	binary.Write(tiffData, binary.BigEndian, uint32(8)) // IFD0 offset
+	binary.Write(tiffData, binary.BigEndian, uint16(1)) // Entry count
+	binary.Write(tiffData, binary.BigEndian, uint16(exifTagOrientation))
+	binary.Write(tiffData, binary.BigEndian, uint16(tiffTypeShort))
+	binary.Write(tiffData, binary.BigEndian, uint32(1))
+	binary.Write(tiffData, binary.BigEndian, orientation)
+	binary.Write(tiffData, binary.BigEndian, uint16(0))
+	binary.Write(tiffData, binary.BigEndian, uint32(0)) // Next IFD offset
+
+	payload := append([]byte("Exif\x00\x00"), tiffData.Bytes()...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	// Insert right after the SOI marker
+	res := append([]byte{}, encoded[:2]...)
+	res = append(res, segment...)
+	return append(res, encoded[2:]...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	t.Parallel()
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+
+	data := jpegWithOrientation(t, img, OrientationRotate90CW)
+	require.Equal(t, OrientationRotate90CW, ExifOrientation("jpeg", bytes.NewReader(data)))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, jpeg.Encode(buf, img, nil))
+	require.Equal(t, OrientationNormal, ExifOrientation("jpeg", bytes.NewReader(buf.Bytes())), "no exif")
+	require.Equal(t, OrientationNormal, ExifOrientation("png", bytes.NewReader(data)), "format without exif")
+	require.Equal(t, OrientationNormal, ExifOrientation("jpeg", bytes.NewReader([]byte("garbage"))), "corrupted file")
+}
+
+func TestScaleDownImageToFit_Orientation(t *testing.T) {
+	t.Parallel()
+	// Landscape image, with the top left corner red
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	data := jpegWithOrientation(t, img, OrientationRotate90CW)
+	preview, err := ScaleDownImageToFit("jpeg", bytes.NewReader(data), 100)
+	require.NoError(t, err)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(preview))
+	require.NoError(t, err)
+	require.Equal(t, 20, decoded.Bounds().Dx(), "rotated image should be portrait")
+	require.Equal(t, 40, decoded.Bounds().Dy(), "rotated image should be portrait")
+
+	// Rotating clockwise moves the top left corner to the top right
+	r, g, b, _ := decoded.At(17, 2).RGBA()
+	require.Greater(t, r>>8, uint32(200))
+	require.Less(t, g>>8, uint32(60))
+	require.Less(t, b>>8, uint32(60))
+}
@@ -0,0 +1,356 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Number of bytes read by DetectMimeType, same as http.DetectContentType
+	sniffLen = 512
+
+	mimeTypeOctetStream = "application/octet-stream"
+)
+
+type mimeSignature struct {
+	mimeType string
+	match    func(data []byte) bool
+}
+
+// mimeSignatures are checked before http.DetectContentType, which only knows a few formats.
+// The order matters, the first match wins.
+var mimeSignatures = []mimeSignature{
+	{"image/svg+xml", isSVG},
+	{"image/tiff", hasPrefix(0, "II*\x00")},
+	{"image/tiff", hasPrefix(0, "MM\x00*")},
+	{"image/vnd.adobe.photoshop", hasPrefix(0, "8BPS")},
+	{"image/avif", hasFtypBrand("avif", "avis")},
+	{"image/heic", hasFtypBrand("heic", "heix", "hevc", "hevx", "heim", "heis")},
+	{"image/heif", hasFtypBrand("mif1", "msf1")},
+	{"audio/mp4", hasFtypBrand("M4A ", "M4B ", "M4P ")},
+	{"video/x-m4v", hasFtypBrand("M4V ", "M4VH", "M4VP")},
+	{"video/quicktime", hasFtypBrand("qt  ")},
+	{"video/3gpp", hasFtypBrand("3gp4", "3gp5", "3gp6", "3gg6")},
+	{"video/3gpp2", hasFtypBrand("3g2a", "3g2b", "3g2c")},
+	{"video/mp4", hasFtypBrand("isom", "iso2", "iso4", "iso5", "iso6", "mp41", "mp42", "avc1", "dash", "mmp4", "MSNV")},
+	{"image/webp", hasRIFF("WEBP")},
+	{"audio/wav", hasRIFF("WAVE")},
+	{"video/x-msvideo", hasRIFF("AVI ")},
+	{"audio/ogg", hasOggCodec("OpusHead", "\x01vorbis", "fLaC", "\x7fFLAC", "Speex")},
+	{"video/ogg", hasOggCodec("\x80theora")},
+	{"application/ogg", hasPrefix(0, "OggS")},
+	{"audio/flac", hasPrefix(0, "fLaC")},
+	{"audio/mpeg", hasPrefix(0, "ID3")},
+	{"audio/mpeg", isMP3Frame},
+	{"audio/aac", isADTSFrame},
+	{"video/webm", isMatroska("webm")},
+	{"video/x-matroska", isMatroska("")},
+	{"application/x-7z-compressed", hasPrefix(0, "7z\xBC\xAF\x27\x1C")},
+	{"application/vnd.rar", hasPrefix(0, "Rar!\x1A\x07")},
+	{"application/x-xz", hasPrefix(0, "\xFD7zXZ\x00")},
+	{"application/x-bzip2", isBzip2},
+	{"application/zstd", hasPrefix(0, "\x28\xB5\x2F\xFD")},
+	{"application/x-tar", hasPrefix(257, "ustar")},
+	{"application/vnd.sqlite3", hasPrefix(0, "SQLite format 3\x00")},
+	{"application/x-ole-storage", hasPrefix(0, "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")},
+	{"application/rtf", hasPrefix(0, "{\\rtf")},
+	{"application/x-executable", hasPrefix(0, "\x7FELF")},
+	{"application/vnd.microsoft.portable-executable", isPE},
+	{"application/java-vm", hasPrefix(0, "\xCA\xFE\xBA\xBE")},
+}
+
+// extensionMimeTypes complements mime.TypeByExtension, whose table depends on the system.
+var extensionMimeTypes = map[string]string{
+	// Zip based
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	".odp":  "application/vnd.oasis.opendocument.presentation",
+	".epub": "application/epub+zip",
+	".jar":  "application/java-archive",
+	".apk":  "application/vnd.android.package-archive",
+	// OLE based
+	".doc": "application/msword",
+	".xls": "application/vnd.ms-excel",
+	".ppt": "application/vnd.ms-powerpoint",
+	".msg": "application/vnd.ms-outlook",
+	// Text
+	".txt":  "text/plain",
+	".md":   "text/markdown",
+	".csv":  "text/csv",
+	".tsv":  "text/tab-separated-values",
+	".html": "text/html",
+	".htm":  "text/html",
+	".css":  "text/css",
+	".xml":  "application/xml",
+	".json": "application/json",
+	".js":   "application/javascript",
+	".mjs":  "application/javascript",
+	".ts":   "application/typescript",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".toml": "application/toml",
+	".sql":  "application/sql",
+	".sh":   "application/x-sh",
+	".go":   "text/x-go",
+	".py":   "text/x-python",
+	".java": "text/x-java",
+	".c":    "text/x-c",
+	".h":    "text/x-c",
+	".cpp":  "text/x-c++",
+	".rs":   "text/x-rust",
+	".rb":   "text/x-ruby",
+	".php":  "text/x-php",
+	".ics":  "text/calendar",
+	".vcf":  "text/vcard",
+	".srt":  "application/x-subrip",
+	".vtt":  "text/vtt",
+}
+
+// Zip and OLE are containers, the extension tells the actual format
+var containerMimeTypes = map[string]map[string]bool{
+	"application/zip": {
+		".docx": true, ".xlsx": true, ".pptx": true, ".odt": true, ".ods": true, ".odp": true,
+		".epub": true, ".jar": true, ".apk": true,
+	},
+	"application/x-ole-storage": {
+		".doc": true, ".xls": true, ".ppt": true, ".msg": true,
+	},
+}
+
+// Text types outside of text/*
+var textMimeTypes = map[string]bool{
+	"application/xml":        true,
+	"application/json":       true,
+	"application/javascript": true,
+	"application/typescript": true,
+	"application/yaml":       true,
+	"application/x-yaml":     true,
+	"application/toml":       true,
+	"application/sql":        true,
+	"application/x-sh":       true,
+	"application/x-subrip":   true,
+}
+
+// DetectMimeType detects the mime type of a file from its first bytes, reconciled with the extension of its name:
+//   - The content wins when it is recognized, e.g. a png named "photo.jpg" is image/png.
+//   - The extension refines generic types, e.g. a zip named "report.docx" or plain text named "notes.md".
+//   - The extension is used for unrecognized binary content, unless the content should have been recognized,
+//     e.g. random bytes named "photo.png" are application/octet-stream.
+//
+// The reader is rewound to the start.
+func DetectMimeType(reader io.ReadSeeker, fileName string) (string, error) {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	data := make([]byte, sniffLen)
+	n, err := io.ReadFull(reader, data)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	data = data[:n]
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	// Nothing to sniff, only the extension tells the type
+	if n == 0 {
+		if extType := mimeTypeByExtension(strings.ToLower(filepath.Ext(fileName))); extType != "" {
+			return extType, nil
+		}
+		return mimeTypeOctetStream, nil
+	}
+
+	return reconcileMimeType(sniffMimeType(data), fileName), nil
+}
+
+func sniffMimeType(data []byte) string {
+	if len(data) == 0 {
+		return mimeTypeOctetStream
+	}
+
+	for _, sig := range mimeSignatures {
+		if sig.match(data) {
+			return sig.mimeType
+		}
+	}
+
+	return http.DetectContentType(data)
+}
+
+func reconcileMimeType(detected, fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	extType := mimeTypeByExtension(ext)
+	if extType == "" {
+		return detected
+	}
+
+	base := baseMimeType(detected)
+	switch {
+	case containerMimeTypes[base][ext]:
+		return extType
+	case base == "text/plain" && isTextMimeType(extType):
+		return withParams(extType, detected)
+	case base == "text/xml" && (extType == "application/xml" || strings.HasSuffix(extType, "+xml")):
+		return withParams(extType, detected)
+	case base == mimeTypeOctetStream && !isTextMimeType(extType) && !isSniffable(extType):
+		return extType
+	default:
+		return detected
+	}
+}
+
+func mimeTypeByExtension(ext string) string {
+	if ext == "" {
+		return ""
+	}
+	if t, ok := extensionMimeTypes[ext]; ok {
+		return t
+	}
+	return baseMimeType(mime.TypeByExtension(ext))
+}
+
+// baseMimeType returns the mime type without parameters, e.g. "text/plain" for "text/plain; charset=utf-8".
+func baseMimeType(mimeType string) string {
+	base, _, _ := strings.Cut(mimeType, ";")
+	return strings.ToLower(strings.TrimSpace(base))
+}
+
+// withParams keeps the parameters of the detected type, e.g. the charset.
+func withParams(mimeType, detected string) string {
+	if _, params, ok := strings.Cut(detected, ";"); ok {
+		return mimeType + ";" + params
+	}
+	return mimeType
+}
+
+func isTextMimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") || textMimeTypes[mimeType]
+}
+
+// isSniffable tells if the content of files of this type would have been recognized.
+func isSniffable(mimeType string) bool {
+	kind, _, _ := strings.Cut(mimeType, "/")
+	if kind == "image" || kind == "audio" || kind == "video" {
+		return true
+	}
+	for _, sig := range mimeSignatures {
+		if sig.mimeType == mimeType {
+			return true
+		}
+	}
+	_, isContainer := containerMimeTypes[mimeType]
+	return isContainer || mimeType == "application/pdf" || mimeType == "application/zip" || mimeType == "application/gzip"
+}
+
+//--------------------------------
+// Signatures
+//--------------------------------
+
+func hasPrefix(offset int, magic string) func([]byte) bool {
+	return func(data []byte) bool {
+		return len(data) >= offset+len(magic) && string(data[offset:offset+len(magic)]) == magic
+	}
+}
+
+// hasFtypBrand matches the major brand of ISO base media files (mp4, heic, ...).
+func hasFtypBrand(brands ...string) func([]byte) bool {
+	return func(data []byte) bool {
+		if len(data) < 12 || string(data[4:8]) != "ftyp" {
+			return false
+		}
+		major := string(data[8:12])
+		for _, brand := range brands {
+			if major == brand {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func hasRIFF(format string) func([]byte) bool {
+	return func(data []byte) bool {
+		return len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == format
+	}
+}
+
+// hasOggCodec matches the first packet of the first page, right after the segment table.
+func hasOggCodec(magics ...string) func([]byte) bool {
+	return func(data []byte) bool {
+		if len(data) < 27 || string(data[:4]) != "OggS" {
+			return false
+		}
+		start := 27 + int(data[26])
+		if start > len(data) {
+			return false
+		}
+		for _, magic := range magics {
+			if bytes.HasPrefix(data[start:], []byte(magic)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// isMatroska matches the EBML header, with the given doc type if not empty.
+func isMatroska(docType string) func([]byte) bool {
+	return func(data []byte) bool {
+		if !bytes.HasPrefix(data, []byte("\x1A\x45\xDF\xA3")) {
+			return false
+		}
+		// The doc type element (0x4282) is in the header, which is always small
+		return docType == "" || bytes.Contains(data[:min(len(data), 64)], append([]byte{0x42, 0x82, byte(0x80 | len(docType))}, docType...))
+	}
+}
+
+// isMP3Frame matches a MPEG audio frame header without ID3 tag.
+func isMP3Frame(data []byte) bool {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return false
+	}
+	layer := (data[1] >> 1) & 0x03
+	bitrateIdx := data[2] >> 4
+	sampleRateIdx := (data[2] >> 2) & 0x03
+	return layer != 0 && (data[1]>>3)&0x03 != 1 && bitrateIdx != 0 && bitrateIdx != 15 && sampleRateIdx != 3
+}
+
+// isADTSFrame matches the header of raw aac streams.
+func isADTSFrame(data []byte) bool {
+	return len(data) >= 7 && data[0] == 0xFF && data[1]&0xF6 == 0xF0 && (data[2]>>2)&0x0F < 13
+}
+
+func isBzip2(data []byte) bool {
+	return len(data) >= 4 && string(data[:3]) == "BZh" && data[3] >= '1' && data[3] <= '9'
+}
+
+// isPE matches windows executables, whose DOS header points to the PE header.
+func isPE(data []byte) bool {
+	if len(data) < 64 || string(data[:2]) != "MZ" {
+		return false
+	}
+	offset := int(binary.LittleEndian.Uint32(data[0x3C:]))
+	// The PE header may be past the sniffed bytes, the DOS header is enough then
+	return offset+4 > len(data) || string(data[offset:offset+4]) == "PE\x00\x00"
+}
+
+func isSVG(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	data = bytes.TrimLeft(data, " \t\r\n")
+	lower := bytes.ToLower(data)
+
+	if !bytes.HasPrefix(lower, []byte("<svg")) && !bytes.HasPrefix(lower, []byte("<?xml")) && !bytes.HasPrefix(lower, []byte("<!doctype svg")) && !bytes.HasPrefix(lower, []byte("<!--")) {
+		return false
+	}
+	return bytes.Contains(lower, []byte("<svg"))
+}
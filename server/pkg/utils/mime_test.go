@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectMimeType(t *testing.T) {
+	t.Parallel()
+
+	pngBuf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(pngBuf, image.NewRGBA(image.Rect(0, 0, 1, 1))))
+
+	ftyp := func(brand string) []byte {
+		return append([]byte("\x00\x00\x00\x18ftyp"), brand+"\x00\x00\x00\x00"...)
+	}
+	tar := make([]byte, 512)
+	copy(tar[257:], "ustar")
+
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+		want     string
+	}{
+		{"content wins over extension", "photo.jpg", pngBuf.Bytes(), "image/png"},
+		{"content without extension", "photo", pngBuf.Bytes(), "image/png"},
+		{"heic", "IMG_0001.HEIC", ftyp("heic"), "image/heic"},
+		{"mp4", "clip.bin", ftyp("isom"), "video/mp4"},
+		{"m4a", "song.m4a", ftyp("M4A "), "audio/mp4"},
+		{"webp", "image", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "image/webp"},
+		{"wav", "sound", []byte("RIFF\x00\x00\x00\x00WAVEfmt "), "audio/wav"},
+		{"flac", "song", []byte("fLaC\x00\x00\x00\x22"), "audio/flac"},
+		{"mp3 with id3", "song", []byte("ID3\x03\x00\x00\x00\x00\x00\x00"), "audio/mpeg"},
+		{"mp3 frame", "song", []byte{0xFF, 0xFB, 0x90, 0x00}, "audio/mpeg"},
+		{"tiff", "scan", []byte("II*\x00\x08\x00\x00\x00"), "image/tiff"},
+		{"svg", "logo.png", []byte("<?xml version=\"1.0\"?>\n<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), "image/svg+xml"},
+		{"html named as text", "notes.txt", []byte("<!DOCTYPE html><html><script>alert(1)</script></html>"), "text/html; charset=utf-8"},
+		{"tar", "backup", tar, "application/x-tar"},
+		{"7z", "archive", []byte("7z\xBC\xAF\x27\x1C\x00\x04"), "application/x-7z-compressed"},
+		{"docx is a zip", "report.docx", []byte("PK\x03\x04\x14\x00\x06\x00"), "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"zip with unrelated extension", "report.pdf", []byte("PK\x03\x04\x14\x00\x06\x00"), "application/zip"},
+		{"text refined by extension", "README.md", []byte("# Title\n\nSome text"), "text/markdown; charset=utf-8"},
+		{"json", "data.json", []byte(`{"a": 1}`), "application/json; charset=utf-8"},
+		{"text with binary extension", "photo.png", []byte("just text"), "text/plain; charset=utf-8"},
+		{"binary with sniffable extension", "photo.png", []byte{0x00, 0x01, 0x02, 0x03, 0xFE}, "application/octet-stream"},
+		{"binary with unknown format extension", "disk.dmg", []byte{0x00, 0x01, 0x02, 0x03, 0xFE}, "application/x-apple-diskimage"},
+		{"empty file", "empty.txt", nil, "text/plain"},
+		{"text starting like an executable", "notes", []byte("MZ is not always a header"), "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reader := bytes.NewReader(tt.data)
+			got, err := DetectMimeType(reader, tt.fileName)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+
+			pos, err := reader.Seek(0, io.SeekCurrent)
+			require.NoError(t, err)
+			require.Zero(t, pos, "rewound")
+		})
+	}
+}
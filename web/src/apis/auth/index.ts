@@ -5,7 +5,13 @@ import type {
   SignUpReq,
   SignUpRes,
 } from "./models";
-import { postPub, handleJSONResponse } from "@sv/apis/common";
+import {
+  post,
+  postPub,
+  handleJSONResponse,
+  clearTokens,
+  setTokens,
+} from "@sv/apis/common";
 import { LOCAL_STORAGE_KEYS } from "@sv/utils/consts";
 
 const urlAuth = "auth";
@@ -17,7 +23,11 @@ export function getProfile(): Profile | null {
 }
 
 export function signOut() {
-  localStorage.removeItem(LOCAL_STORAGE_KEYS.TOKEN);
+  // Revoke the session on the server too, signing out locally doesn't wait for it
+  if (localStorage.getItem(LOCAL_STORAGE_KEYS.TOKEN)) {
+    post(`${urlAuth}/logout`, {}).catch(() => {});
+  }
+  clearTokens();
   localStorage.removeItem(LOCAL_STORAGE_KEYS.PROFILE);
 }
 
@@ -31,7 +41,7 @@ async function handleAuthResponse<T extends SignInRes | SignUpRes>(
     contentView: "list",
   };
 
-  localStorage.setItem(LOCAL_STORAGE_KEYS.TOKEN, data.token);
+  setTokens(data.token, data.refreshToken);
   localStorage.setItem(
     LOCAL_STORAGE_KEYS.PROFILE,
     JSON.stringify(data.profile)
@@ -17,6 +17,7 @@ export interface SignInReq {
 
 export interface SignInRes {
   token: string;
+  refreshToken: string;
   profile: Profile;
 }
 
@@ -28,5 +29,6 @@ export interface SignUpReq {
 
 export interface SignUpRes {
   token: string;
+  refreshToken: string;
   profile: Profile;
 }
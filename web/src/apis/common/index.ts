@@ -1,4 +1,4 @@
-import { LOCAL_STORAGE_KEYS } from "@sv/utils/consts";
+import { CLIENT_URLS, LOCAL_STORAGE_KEYS } from "@sv/utils/consts";
 
 // In production (same origin), use relative URL. In dev, use full URL to backend.
 const SERVER_URL = import.meta.env.VITE_SERVER_URL || "";
@@ -6,42 +6,153 @@ export const ROOT_URL = `${SERVER_URL}/api/v1`;
 // public api root url
 export const ROOT_URL_PUB = `${ROOT_URL}/pub`;
 
+// The access token is refreshed this long before it expires
+const TOKEN_REFRESH_MARGIN_MS = 30 * 1000;
+
 interface ErrRes {
   code: string;
 }
 
-export function postPub(url: string, data: any) {
-  return fetch(`${ROOT_URL_PUB}/${url}`, {
-    method: "POST",
-    headers: {
-      "Content-Type": "application/json",
-    },
-    body: JSON.stringify(data),
-  });
+interface RefreshTokenRes {
+  token: string;
+  refreshToken: string; // Replaces the one sent, which can't be used again
 }
 
-export function post(url: string, data: any) {
+export function setTokens(token: string, refreshToken: string) {
+  localStorage.setItem(LOCAL_STORAGE_KEYS.TOKEN, token);
+  localStorage.setItem(LOCAL_STORAGE_KEYS.REFRESH_TOKEN, refreshToken);
+}
+
+export function clearTokens() {
+  localStorage.removeItem(LOCAL_STORAGE_KEYS.TOKEN);
+  localStorage.removeItem(LOCAL_STORAGE_KEYS.REFRESH_TOKEN);
+}
+
+// Expiry of the access token in ms, 0 if it can't be read
+function tokenExpiry(token: string): number {
+  try {
+    const payload = token.split(".")[1].replace(/-/g, "+").replace(/_/g, "/");
+    const { exp } = JSON.parse(atob(payload)) as { exp?: number };
+    return exp ? exp * 1000 : 0;
+  } catch {
+    return 0;
+  }
+}
+
+// The refreshes of all the tabs hold this lock, see refreshToken
+const REFRESH_LOCK = "skyvault-refresh-token";
+
+// A refresh token can only be used once, the concurrent requests share the same refresh
+let refreshing: Promise<string> | null = null;
+
+// withRefreshLock runs fn once no other tab of the origin is refreshing.
+// Browsers without Web Locks only serialize the refreshes of the current tab.
+function withRefreshLock<T>(fn: () => Promise<T>): Promise<T> {
+  if (!navigator.locks) return fn();
+  return navigator.locks.request(REFRESH_LOCK, fn);
+}
+
+function refreshToken(): Promise<string> {
+  if (!refreshing) {
+    // The tabs share the tokens, a second use of the same refresh token would revoke the session
+    const staleToken = localStorage.getItem(LOCAL_STORAGE_KEYS.REFRESH_TOKEN);
+    refreshing = withRefreshLock(async () => {
+      const token = localStorage.getItem(LOCAL_STORAGE_KEYS.REFRESH_TOKEN);
+      if (!token) throw new Error("No refresh token found");
+
+      const accessToken = localStorage.getItem(LOCAL_STORAGE_KEYS.TOKEN);
+      if (token !== staleToken && accessToken) {
+        // Another tab refreshed while this one waited for the lock
+        return accessToken;
+      }
+
+      const res = await postPub("auth/refresh", { refreshToken: token });
+      if (res.status === 401) {
+        // The session ended, the user must sign in again
+        clearTokens();
+        localStorage.removeItem(LOCAL_STORAGE_KEYS.PROFILE);
+        window.location.assign(CLIENT_URLS.SIGN_IN);
+      }
+      const data = await handleJSONResponse<RefreshTokenRes>(res);
+      setTokens(data.token, data.refreshToken);
+      return data.token;
+    }).finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+}
+
+// getToken returns the access token, refreshed first if it expires soon
+async function getToken(): Promise<string> {
   const token = localStorage.getItem(LOCAL_STORAGE_KEYS.TOKEN);
   if (!token) throw new Error("No token found");
 
-  return fetch(`${ROOT_URL}/${url}`, {
+  const expiry = tokenExpiry(token);
+  if (expiry && expiry - Date.now() < TOKEN_REFRESH_MARGIN_MS) {
+    return refreshToken();
+  }
+  return token;
+}
+
+// The server redirects the requests with an expired token to the sign-in page
+function isUnauthorized(res: Response): boolean {
+  return (
+    res.status === 401 ||
+    (res.redirected && new URL(res.url).pathname === "/sign-in")
+  );
+}
+
+// fetchWithToken sends the request with the access token,
+// it is refreshed and the request sent again once if rejected
+async function fetchWithToken(
+  send: (token: string) => Promise<Response>
+): Promise<Response> {
+  const res = await send(await getToken());
+  if (!isUnauthorized(res)) return res;
+
+  return send(await refreshToken());
+}
+
+export function postPub(url: string, data: any) {
+  return fetch(`${ROOT_URL_PUB}/${url}`, {
     method: "POST",
     headers: {
       "Content-Type": "application/json",
-      Authorization: `Bearer ${token}`,
     },
     body: JSON.stringify(data),
   });
 }
 
+export function post(url: string, data: any) {
+  return fetchWithToken((token) =>
+    fetch(`${ROOT_URL}/${url}`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify(data),
+    })
+  );
+}
+
 export function postFormData(
   url: string,
   formData: FormData,
   onProgress?: (progress: number) => void
 ) {
-  const token = localStorage.getItem(LOCAL_STORAGE_KEYS.TOKEN);
-  if (!token) throw new Error("No token found");
+  return fetchWithToken((token) =>
+    sendFormData(url, formData, token, onProgress)
+  );
+}
 
+function sendFormData(
+  url: string,
+  formData: FormData,
+  token: string,
+  onProgress?: (progress: number) => void
+) {
   return new Promise<Response>((resolve, reject) => {
     const xhr = new XMLHttpRequest();
     xhr.open("POST", `${ROOT_URL}/${url}`);
@@ -81,15 +192,14 @@ export function postFormData(
 }
 
 export function get(url: string) {
-  const token = localStorage.getItem(LOCAL_STORAGE_KEYS.TOKEN);
-  if (!token) throw new Error("No token found");
-
-  return fetch(`${ROOT_URL}/${url}`, {
-    headers: {
-      Authorization: `Bearer ${token}`,
-      Accept: "application/json",
-    },
-  });
+  return fetchWithToken((token) =>
+    fetch(`${ROOT_URL}/${url}`, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+        Accept: "application/json",
+      },
+    })
+  );
 }
 
 export async function handleJSONResponse<T>(res: Response): Promise<T> {
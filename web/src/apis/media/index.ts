@@ -12,6 +12,7 @@ import type {
   FileInfo,
   FolderContent,
   FolderInfo,
+  PreviewSize,
   UploadConfig,
   UploadFileInfo,
   UploadFileResult,
@@ -181,3 +182,11 @@ export async function downloadFile(
   const blob = await handleBlobResponse(response);
   FileUtils.downloadBlob(blob, fileName);
 }
+
+export async function fetchPreview(
+  fileId: string,
+  size: PreviewSize = "small"
+): Promise<Blob> {
+  const res = await get(`${urlFiles}/${fileId}/preview?size=${size}`);
+  return handleBlobResponse(res);
+}
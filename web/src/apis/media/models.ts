@@ -28,11 +28,15 @@ export interface FileInfo {
   extension?: string;
   mimeType: string;
   category: CATEGORY;
-  previewBase64?: string;
+  previewStatus?: PreviewStatus; // Absent for files without previews
   createdAt: string;
   updatedAt: string;
 }
 
+export type PreviewStatus = "pending" | "ready" | "unsupported" | "failed";
+
+export type PreviewSize = "small" | "medium" | "large";
+
 export interface FolderInfo {
   id: string;
   ownerId: string;
@@ -48,6 +52,8 @@ export interface Page<T> {
   prevCursor: string;
   nextCursor: string;
   hasMore: boolean;
+  total?: number; // only with includeTotal
+  totalEstimated?: boolean;
 }
 
 export interface BaseInfo {
@@ -1,9 +1,15 @@
-import { downloadFile } from "@sv/apis/media";
+import { downloadFile, fetchPreview } from "@sv/apis/media";
 import type { FileInfo, FolderInfo } from "@sv/apis/media/models";
 import Icon, { FileIcon } from "@sv/components/icons";
 import { FOLDER_CONTENT_TYPES } from "@sv/utils/consts";
 import Format from "@sv/utils/format";
-import { Show, createSignal } from "solid-js";
+import {
+  Show,
+  createEffect,
+  createResource,
+  createSignal,
+  onCleanup,
+} from "solid-js";
 import useCtx from "./ctxProvider";
 
 interface GridItemProps {
@@ -17,6 +23,23 @@ function GridItem(props: GridItemProps) {
 
   const isSelected = () => ctx.selectedItem()?.id === props.item.id;
 
+  // The preview needs the auth header, so it is fetched and shown from an object URL
+  const [previewUrl] = createResource(
+    () => props.item.previewStatus === "ready" && props.item.id,
+    async (id) => {
+      try {
+        return URL.createObjectURL(await fetchPreview(id));
+      } catch (error) {
+        console.error("Preview failed:", error);
+        return undefined; // Falls back to the icon
+      }
+    }
+  );
+  createEffect(() => {
+    const url = previewUrl();
+    if (url) onCleanup(() => URL.revokeObjectURL(url));
+  });
+
   const handleClick = () => {
     ctx.handleTap({
       id: props.item.id,
@@ -69,9 +92,9 @@ function GridItem(props: GridItemProps) {
 
       {/* File/folder icon or preview */}
       <div class="flex-center h-28 md:h-34 rounded-t-lg border-b border-border bg-bg-subtle">
-        {props.item.previewBase64 ? (
+        {previewUrl() ? (
           <img
-            src={`data:image/png;base64,${props.item.previewBase64}`}
+            src={previewUrl()}
             alt={props.item.name}
             class="object-cover h-full w-full"
           />
@@ -30,4 +30,5 @@ export const LOCAL_STORAGE_KEYS = {
   CONTENT_VIEW: "content-view",
   PROFILE: "profile",
   TOKEN: "token",
+  REFRESH_TOKEN: "refresh-token",
 } as const;
@@ -20,6 +20,9 @@ const authErrorMessages: Record<string, string> = {
   AUTH_INVALID_TOKEN: "Your session is invalid. Please sign in again.",
   AUTH_TOKEN_EXPIRED: "Your session has expired. Please sign in again.",
   AUTH_WRONG_PROVIDER: "Please use the correct sign-in method.",
+  AUTH_SESSION_REVOKED: "You have been signed out. Please sign in again.",
+  AUTH_REFRESH_TOKEN_REUSED:
+    "Your session was ended for your security. Please sign in again.",
 };
 
 export function defaultErrorMessage(code: string): string {